@@ -0,0 +1,203 @@
+// Command esctl operates the event store of the configured Postgres database. It lists aggregates, dumps and
+// tails streams, shows aggregate state at a version, manages snapshots, verifies stream integrity and enqueues
+// replays that the running service picks up. The connection is configured like the service, by environment.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/config"
+	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/logger"
+	"github.com/th1enq/es-demo/pkg/postgres"
+	"go.uber.org/zap"
+)
+
+const usage = `esctl operates the event store of the configured Postgres database.
+
+Usage:
+  esctl [-v] <command> [flags] [arguments]
+
+Commands:
+  aggregates       list aggregate ids of a type
+  dump             print the events of a stream
+  state            print the state of an aggregate, at a version with -version
+  snapshot take    save a snapshot of an aggregate
+  snapshot delete  delete snapshots of an aggregate
+  replay start     enqueue a replay run by the service
+  replay list      list the latest replay jobs
+  replay get       print a replay job
+  verify           check streams for version gaps and events that do not deserialize or apply
+  tail             print new events as they are appended
+
+Run "esctl <command> -h" for the flags of a command.
+`
+
+// errInvalidUsage wrong command or arguments, the usage is printed already.
+var errInvalidUsage = errors.New("invalid usage")
+
+type command struct {
+	name string
+	run  func(ctx context.Context, app *cli, args []string) error
+}
+
+var commands = []command{
+	{name: "aggregates", run: runAggregates},
+	{name: "dump", run: runDump},
+	{name: "state", run: runState},
+	{name: "snapshot", run: runSnapshot},
+	{name: "replay", run: runReplay},
+	{name: "verify", run: runVerify},
+	{name: "tail", run: runTail},
+}
+
+// cli event store of the configured database shared by the commands.
+type cli struct {
+	db         *pgxpool.Pool
+	store      es.AggregateStore
+	serializer es.Serializer
+	jobs       es.ReplayJobStore
+	out        io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("esctl", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(flags.Output(), usage) }
+	verbose := flags.Bool("v", false, "log event store operations with the configured logger")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	name := flags.Arg(0)
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "esctl: unknown command %q\n\n%s", name, usage)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// commands parse their flags before using the store, help is printed without database
+	var app *cli
+	if !helpRequested(flags.Args()[1:]) {
+		var err error
+		if app, err = newCLI(*verbose); err != nil {
+			fmt.Fprintf(os.Stderr, "esctl: %v\n", err)
+			return 1
+		}
+		defer app.db.Close()
+	}
+
+	if err := cmd.run(ctx, app, flags.Args()[1:]); err != nil {
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errInvalidUsage):
+			return 2
+		case errors.Is(err, context.Canceled) && ctx.Err() != nil:
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "esctl %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+func newCLI(verbose bool) (*cli, error) {
+	cfg := config.Load()
+
+	log := zap.NewNop()
+	if verbose {
+		configured, err := logger.Load(cfg.Logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "logger.Load")
+		}
+		log = configured
+	}
+
+	db, err := postgres.NewPgxConn(cfg.Postgres)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres.NewPgxConn")
+	}
+
+	serializer := domain.NewEventSerializer()
+	return &cli{
+		db: db,
+		// esctl never appends events, snapshots are not published
+		store:      es.NewPgEventStore(cfg.PgStore, db, serializer, log, noopEventsBus{}, nil),
+		serializer: serializer,
+		jobs:       es.NewPgReplayJobStore(db, log),
+		out:        os.Stdout,
+	}, nil
+}
+
+func helpRequested(args []string) bool {
+	for _, arg := range args {
+		switch arg {
+		case "-h", "-help", "--help":
+			return true
+		case "--":
+			return false
+		}
+	}
+	return false
+}
+
+type noopEventsBus struct{}
+
+func (noopEventsBus) ProcessEvents(context.Context, []es.Event) error {
+	return nil
+}
+
+// newFlagSet flag set of the command printing its usage line before the flags.
+func newFlagSet(name, arguments, summary string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: esctl %s\n\n%s\n\nFlags:\n", strings.TrimSpace(name+" [flags] "+arguments), summary)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags parses the command flags and checks the number of positional arguments.
+func parseFlags(flags *flag.FlagSet, args []string, arguments int) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errInvalidUsage
+	}
+	if flags.NArg() != arguments {
+		fmt.Fprintf(flags.Output(), "esctl %s: expected %d argument(s), got %d\n", flags.Name(), arguments, flags.NArg())
+		flags.Usage()
+		return errInvalidUsage
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/service"
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+var errReplayUnsuccessful = errors.New("replay job did not complete")
+
+func runReplay(ctx context.Context, app *cli, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "start":
+			return runReplayStart(ctx, app, args[1:])
+		case "list":
+			return runReplayList(ctx, app, args[1:])
+		case "get":
+			return runReplayGet(ctx, app, args[1:])
+		}
+	}
+	fmt.Fprint(os.Stderr, "Usage: esctl replay start|list|get [flags] [job-id]\n")
+	return errInvalidUsage
+}
+
+func runReplayStart(ctx context.Context, app *cli, args []string) error {
+	flags := newFlagSet("replay start", "", "Enqueue a replay of the read model, the running service starts it once no other replay is running.\n"+
+		"Any filter flag makes the replay selective: only matching streams are rebuilt from their full event stream.")
+	target := flags.String("target", string(service.ReplayTargetElasticsearch), "read model to replay: elasticsearch or mongo")
+	recreate := flags.Bool("recreate", false, "rebuild the read model from scratch next to the live one and swap it in")
+	aggregateType := flags.String("type", "", "filter: aggregate type")
+	ids := flags.String("ids", "", "filter: comma separated aggregate ids")
+	fromPosition := flags.Uint64("from-position", 0, "filter: streams with events after this global position")
+	toPosition := flags.Uint64("to-position", 0, "filter: streams with events up to this global position")
+	from := flags.String("from", "", "filter: streams with events at or after this time (RFC 3339 or YYYY-MM-DD)")
+	to := flags.String("to", "", "filter: streams with events at or before this time (RFC 3339 or YYYY-MM-DD)")
+	wait := flags.Bool("wait", false, "wait until the job is finished, fails unless it completes")
+	interval := flags.Duration("interval", 2*time.Second, "poll interval of -wait")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	filter := es.StreamFilter{
+		AggregateType: es.AggregateType(*aggregateType),
+		FromPosition:  *fromPosition,
+		ToPosition:    *toPosition,
+	}
+	if *ids != "" {
+		filter.AggregateIDs = strings.Split(*ids, ",")
+	}
+	for _, bound := range []struct {
+		value  string
+		target **time.Time
+	}{{*from, &filter.From}, {*to, &filter.To}} {
+		if bound.value == "" {
+			continue
+		}
+		parsed, err := parseTime(bound.value)
+		if err != nil {
+			return err
+		}
+		*bound.target = &parsed
+	}
+
+	var selective *es.StreamFilter
+	if !filter.IsEmpty() {
+		selective = &filter
+	}
+
+	job, err := service.EnqueueReplay(ctx, app.jobs, service.ReplayTarget(*target), *recreate, selective)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "replay job %s enqueued\n", job.ID)
+
+	if *wait {
+		if job, err = waitReplay(ctx, app, job.ID, *interval); err != nil {
+			return err
+		}
+	}
+
+	if err := printJSON(app, job); err != nil {
+		return err
+	}
+	if *wait && job.Status != es.ReplayJobStatusCompleted {
+		return errors.Wrapf(errReplayUnsuccessful, "status: %s", job.Status)
+	}
+	return nil
+}
+
+// waitReplay polls the job until it is neither pending nor running, printing progress to stderr.
+func waitReplay(ctx context.Context, app *cli, id string, interval time.Duration) (*es.ReplayJob, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		job, err := app.jobs.Get(ctx, id)
+		if err != nil {
+			return nil, errors.Wrap(err, "jobs.Get")
+		}
+		if !job.IsActive() {
+			return job, nil
+		}
+		fmt.Fprintf(os.Stderr, "%s: position %d of %d, %d processed, %d failed\n",
+			job.Status, job.Position, job.HeadPosition, job.Processed, job.Failed)
+	}
+}
+
+func runReplayList(ctx context.Context, app *cli, args []string) error {
+	flags := newFlagSet("replay list", "", "List the latest replay jobs.")
+	limit := flags.Int("limit", 20, "maximum number of jobs")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	jobs, err := app.jobs.List(ctx, *limit)
+	if err != nil {
+		return errors.Wrap(err, "jobs.List")
+	}
+
+	table := tabwriter.NewWriter(app.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tPROJECTION\tSTATUS\tKIND\tPOSITION\tHEAD\tPROCESSED\tFAILED\tCREATED")
+	for _, job := range jobs {
+		kind := "full"
+		switch {
+		case job.IsSelective():
+			kind = "selective"
+		case job.Recreate:
+			kind = "recreate"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\n",
+			job.ID,
+			job.Projection,
+			job.Status,
+			kind,
+			job.Position,
+			job.HeadPosition,
+			job.Processed,
+			job.Failed,
+			job.CreatedAt.UTC().Format(time.RFC3339))
+	}
+	return errors.Wrap(table.Flush(), "tabwriter.Flush")
+}
+
+func runReplayGet(ctx context.Context, app *cli, args []string) error {
+	flags := newFlagSet("replay get", "<job-id>", "Print the replay job with its progress as JSON.")
+	if err := parseFlags(flags, args, 1); err != nil {
+		return err
+	}
+
+	job, err := app.jobs.Get(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printJSON(app, job)
+}
+
+func parseTime(value string) (time.Time, error) {
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		return day, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "time: %s", value)
+	}
+	return parsed, nil
+}
+
+func printJSON(app *cli, v any) error {
+	encoder := json.NewEncoder(app.out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return errors.Wrap(encoder.Encode(v), "json.Encode")
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+func runSnapshot(ctx context.Context, app *cli, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "take":
+			return runSnapshotTake(ctx, app, args[1:])
+		case "delete":
+			return runSnapshotDelete(ctx, app, args[1:])
+		}
+	}
+	fmt.Fprint(os.Stderr, "Usage: esctl snapshot take|delete [flags] <aggregate-id>\n")
+	return errInvalidUsage
+}
+
+func runSnapshotTake(ctx context.Context, app *cli, args []string) error {
+	flags := newFlagSet("snapshot take", "<aggregate-id>", "Save a snapshot of the aggregate state, loads of later versions start from it.")
+	version := flags.Uint64("version", 0, "snapshot the state after this version, the latest version when 0")
+	if err := parseFlags(flags, args, 1); err != nil {
+		return err
+	}
+
+	aggregate, err := loadAggregate(ctx, app, flags.Arg(0), *version)
+	if err != nil {
+		return err
+	}
+
+	aggregate.ToSnapshot()
+	if err := app.store.SaveSnapshot(ctx, aggregate); err != nil {
+		return errors.Wrap(err, "store.SaveSnapshot")
+	}
+
+	fmt.Fprintf(app.out, "snapshot of %s saved at version %d\n", aggregate.GetID(), aggregate.GetVersion())
+	return nil
+}
+
+func runSnapshotDelete(ctx context.Context, app *cli, args []string) error {
+	flags := newFlagSet("snapshot delete", "<aggregate-id>", "Delete snapshots of the aggregate, its state is then loaded from events.")
+	version := flags.Uint64("version", 0, "delete the snapshot of this version only, all snapshots when 0")
+	if err := parseFlags(flags, args, 1); err != nil {
+		return err
+	}
+
+	deleted, err := app.store.DeleteSnapshots(ctx, flags.Arg(0), *version)
+	if err != nil {
+		return errors.Wrap(err, "store.DeleteSnapshots")
+	}
+
+	fmt.Fprintf(app.out, "%d snapshots of %s deleted\n", deleted, flags.Arg(0))
+	return nil
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	aggregateIDsBatchSize = 500
+	tailBatchSize         = 500
+)
+
+var (
+	errStreamNotFound = errors.New("stream not found")
+	errStreamsInvalid = errors.New("streams failed verification")
+)
+
+// aggregateFactories creates empty aggregates of the types stored in the event store.
+var aggregateFactories = map[es.AggregateType]func(id string) es.Aggregate{
+	domain.BankAccountAggregateType:   func(id string) es.Aggregate { return domain.NewBankAccountAggregate(id) },
+	domain.StandingOrderAggregateType: func(id string) es.Aggregate { return domain.NewStandingOrderAggregate(id) },
+	domain.PayrollRunAggregateType:    func(id string) es.Aggregate { return domain.NewPayrollRunAggregate(id) },
+}
+
+func newAggregate(aggregateType es.AggregateType, id string) (es.Aggregate, error) {
+	factory, ok := aggregateFactories[aggregateType]
+	if !ok {
+		return nil, errors.Errorf("unknown aggregate type: %s", aggregateType)
+	}
+	return factory(id), nil
+}
+
+// eventRecord event as printed by dump and tail with -json, one record per line.
+type eventRecord struct {
+	Position      uint64           `json:"position"`
+	AggregateID   string           `json:"aggregateId"`
+	AggregateType es.AggregateType `json:"aggregateType"`
+	EventType     es.EventType     `json:"eventType"`
+	Version       uint64           `json:"version"`
+	Timestamp     time.Time        `json:"timestamp"`
+	Data          json.RawMessage  `json:"data,omitempty"`
+	Metadata      json.RawMessage  `json:"metadata,omitempty"`
+}
+
+// rawJSON keeps valid JSON as is and quotes anything else so the record stays valid.
+func rawJSON(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	if json.Valid(data) {
+		return data
+	}
+	quoted, _ := json.Marshal(string(data))
+	return quoted
+}
+
+// eventPrinter prints events as table or as JSON lines.
+type eventPrinter struct {
+	asJSON  bool
+	encoder *json.Encoder
+	table   *tabwriter.Writer
+}
+
+func newEventPrinter(w io.Writer, asJSON bool) *eventPrinter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	printer := &eventPrinter{asJSON: asJSON, encoder: encoder, table: tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)}
+	if !asJSON {
+		fmt.Fprintln(printer.table, "POSITION\tTIMESTAMP\tAGGREGATE TYPE\tAGGREGATE ID\tVERSION\tEVENT TYPE")
+	}
+	return printer
+}
+
+func (p *eventPrinter) print(events []es.Event) error {
+	for _, event := range events {
+		if p.asJSON {
+			if err := p.encoder.Encode(eventRecord{
+				Position:      event.Position,
+				AggregateID:   event.AggregateID,
+				AggregateType: event.AggregateType,
+				EventType:     event.EventType,
+				Version:       event.Version,
+				Timestamp:     event.Timestamp.UTC(),
+				Data:          rawJSON(event.Data),
+				Metadata:      rawJSON(event.Metadata),
+			}); err != nil {
+				return errors.Wrap(err, "json.Encode")
+			}
+			continue
+		}
+		fmt.Fprintf(p.table, "%d\t%s\t%s\t%s\t%d\t%s\n",
+			event.Position,
+			event.Timestamp.UTC().Format(time.RFC3339),
+			event.AggregateType,
+			event.AggregateID,
+			event.Version,
+			event.EventType)
+	}
+	if p.asJSON {
+		return nil
+	}
+	return errors.Wrap(p.table.Flush(), "tabwriter.Flush")
+}
+
+func runAggregates(ctx context.Context, app *cli, args []string) error {
+	flags := newFlagSet("aggregates", "", "List ids of aggregates with events, ordered by id.")
+	aggregateType := flags.String("type", "", "aggregate type, e.g. BankAccount, all types when empty")
+	after := flags.String("after", "", "list ids after this id, continues a previous listing")
+	limit := flags.Int("limit", 100, "maximum number of ids, 0 lists all")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	filter := es.StreamFilter{AggregateType: es.AggregateType(*aggregateType)}
+	cursor := *after
+	for listed := 0; *limit == 0 || listed < *limit; {
+		batch := aggregateIDsBatchSize
+		if *limit > 0 && *limit-listed < batch {
+			batch = *limit - listed
+		}
+
+		ids, err := app.store.LoadAggregateIDs(ctx, filter, cursor, batch)
+		if err != nil {
+			return errors.Wrap(err, "store.LoadAggregateIDs")
+		}
+		for _, id := range ids {
+			fmt.Fprintln(app.out, id)
+		}
+		listed += len(ids)
+
+		if len(ids) < batch {
+			break
+		}
+		cursor = ids[len(ids)-1]
+	}
+	return nil
+}
+
+func runDump(ctx context.Context, app *cli, args []string) error {
+	flags := newFlagSet("dump", "<aggregate-id>", "Print the events of the stream in version order.")
+	asJSON := flags.Bool("json", false, "print events with data and metadata as JSON lines")
+	if err := parseFlags(flags, args, 1); err != nil {
+		return err
+	}
+
+	events, err := loadStream(ctx, app, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return newEventPrinter(app.out, *asJSON).print(events)
+}
+
+func runState(ctx context.Context, app *cli, args []string) error {
+	flags := newFlagSet("state", "<aggregate-id>", "Print the aggregate state rebuilt from snapshot and events as JSON.")
+	version := flags.Uint64("version", 0, "state after this version, the latest version when 0")
+	if err := parseFlags(flags, args, 1); err != nil {
+		return err
+	}
+
+	aggregate, err := loadAggregate(ctx, app, flags.Arg(0), *version)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(app, aggregate)
+}
+
+// loadStream loads the events of the stream, a stream without events is not found.
+func loadStream(ctx context.Context, app *cli, aggregateID string) ([]es.Event, error) {
+	events, err := app.store.LoadEvents(ctx, aggregateID)
+	if err != nil {
+		return nil, errors.Wrap(err, "store.LoadEvents")
+	}
+	if len(events) == 0 {
+		return nil, errors.Wrapf(errStreamNotFound, "id: %s", aggregateID)
+	}
+	return events, nil
+}
+
+// loadAggregate loads the aggregate of the stream type at the version, the latest version when 0.
+func loadAggregate(ctx context.Context, app *cli, aggregateID string, version uint64) (es.Aggregate, error) {
+	events, err := loadStream(ctx, app, aggregateID)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := events[len(events)-1].Version
+	if version > latest {
+		return nil, errors.Errorf("version %d is after the latest version %d of the stream", version, latest)
+	}
+
+	aggregate, err := newAggregate(events[0].AggregateType, aggregateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == 0 {
+		err = app.store.Load(ctx, aggregate)
+	} else {
+		err = app.store.LoadByVersion(ctx, aggregate, version)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "store.Load")
+	}
+	return aggregate, nil
+}
+
+func runVerify(ctx context.Context, app *cli, args []string) error {
+	flags := newFlagSet("verify", "", "Check that stream versions start at 1 without gaps and every event deserializes and applies to its aggregate.")
+	aggregateType := flags.String("type", "", "verify streams of this aggregate type only")
+	ids := flags.String("ids", "", "comma separated aggregate ids to verify, all streams when empty")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	filter := es.StreamFilter{AggregateType: es.AggregateType(*aggregateType)}
+	if *ids != "" {
+		filter.AggregateIDs = strings.Split(*ids, ",")
+	}
+
+	var streams, events, problems int
+	for cursor := ""; ; {
+		aggregateIDs, err := app.store.LoadAggregateIDs(ctx, filter, cursor, aggregateIDsBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "store.LoadAggregateIDs")
+		}
+
+		for _, aggregateID := range aggregateIDs {
+			stream, err := app.store.LoadEvents(ctx, aggregateID)
+			if err != nil {
+				return errors.Wrap(err, "store.LoadEvents")
+			}
+			for _, problem := range verifyStream(app.serializer, aggregateID, stream) {
+				fmt.Fprintf(app.out, "%s: %s\n", aggregateID, problem)
+				problems++
+			}
+			streams++
+			events += len(stream)
+		}
+
+		if len(aggregateIDs) < aggregateIDsBatchSize {
+			break
+		}
+		cursor = aggregateIDs[len(aggregateIDs)-1]
+	}
+
+	fmt.Fprintf(app.out, "verified %d streams with %d events, %d problems\n", streams, events, problems)
+	if problems > 0 {
+		return errors.Wrapf(errStreamsInvalid, "%d problems", problems)
+	}
+	return nil
+}
+
+// verifyStream returns the integrity problems of the stream. Events are applied to the aggregate until the first
+// event that fails, the following events are only deserialized as the aggregate state is unknown after it.
+func verifyStream(serializer es.Serializer, aggregateID string, events []es.Event) []string {
+	var problems []string
+
+	aggregateType := events[0].AggregateType
+	aggregate, err := newAggregate(aggregateType, aggregateID)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	expected := uint64(1)
+	for _, event := range events {
+		at := fmt.Sprintf("version %d (position %d, %s)", event.Version, event.Position, event.EventType)
+
+		switch {
+		case event.Version == expected+1:
+			problems = append(problems, fmt.Sprintf("version gap: version %d is missing before %s", expected, at))
+		case event.Version > expected:
+			problems = append(problems, fmt.Sprintf("version gap: versions %d to %d are missing before %s", expected, event.Version-1, at))
+		case event.Version < expected:
+			problems = append(problems, fmt.Sprintf("version out of order: expected %d, got %s", expected, at))
+		}
+		expected = event.Version + 1
+
+		if event.AggregateType != aggregateType {
+			problems = append(problems, fmt.Sprintf("aggregate type %s differs from stream type %s at %s", event.AggregateType, aggregateType, at))
+		}
+
+		deserialized, err := serializer.DeserializeEvent(event)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("event does not deserialize at %s: %v", at, err))
+			continue
+		}
+
+		if aggregate == nil {
+			continue
+		}
+		if err := aggregate.RaiseEvent(deserialized); err != nil {
+			problems = append(problems, fmt.Sprintf("event does not apply at %s: %v", at, err))
+			aggregate = nil
+		}
+	}
+	return problems
+}
+
+func runTail(ctx context.Context, app *cli, args []string) error {
+	flags := newFlagSet("tail", "", "Print events appended to the store until interrupted.")
+	from := flags.Uint64("from", 0, "print events after this global position, the current head when 0")
+	aggregateType := flags.String("type", "", "print events of this aggregate type only")
+	ids := flags.String("ids", "", "comma separated aggregate ids to print events of")
+	interval := flags.Duration("interval", time.Second, "poll interval")
+	asJSON := flags.Bool("json", false, "print events with data and metadata as JSON lines")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	filter := es.StreamFilter{AggregateType: es.AggregateType(*aggregateType)}
+	if *ids != "" {
+		filter.AggregateIDs = strings.Split(*ids, ",")
+	}
+
+	position := *from
+	if position == 0 {
+		head, err := app.store.GetHeadPosition(ctx)
+		if err != nil {
+			return errors.Wrap(err, "store.GetHeadPosition")
+		}
+		position = head
+	}
+
+	printer := newEventPrinter(app.out, *asJSON)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		events, err := app.store.LoadEventsByFilter(ctx, filter, position, tailBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "store.LoadEventsByFilter")
+		}
+		if err := printer.print(events); err != nil {
+			return err
+		}
+		if len(events) > 0 {
+			position = events[len(events)-1].Position
+		}
+		if len(events) == tailBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"os"
 
 	_ "github.com/th1enq/es-demo/docs" // Import swagger docs
 	"github.com/th1enq/es-demo/internal/app"
@@ -28,5 +29,7 @@ func main() {
 	if err != nil {
 		panic("Failed to initialize application: " + err.Error())
 	}
-	app.Start(context.Background())
+	if err := app.Start(context.Background()); err != nil {
+		os.Exit(1)
+	}
 }
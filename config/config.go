@@ -1,21 +1,33 @@
 package config
 
 import (
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 	"github.com/th1enq/es-demo/internal/delivery/http"
+	"github.com/th1enq/es-demo/internal/query"
+	"github.com/th1enq/es-demo/internal/service"
 	"github.com/th1enq/es-demo/pkg/es"
 	kafkaClient "github.com/th1enq/es-demo/pkg/kafka"
+	"github.com/th1enq/es-demo/pkg/lifecycle"
 	"github.com/th1enq/es-demo/pkg/logger"
 	"github.com/th1enq/es-demo/pkg/mongodb"
 	"github.com/th1enq/es-demo/pkg/postgres"
+	"github.com/th1enq/es-demo/pkg/tracing"
 )
 
 type JWTConfig struct {
 	SecretKey     string        `json:"secret_key"`
 	TokenDuration time.Duration `json:"token_duration"`
 	Issuer        string        `json:"issuer"`
+	// AdminAccountIDs accounts whose tokens carry the admin role
+	AdminAccountIDs []string `json:"admin_account_ids"`
+}
+
+type HealthConfig struct {
+	CheckTimeout           time.Duration `json:"check_timeout"`
+	ProjectionLagThreshold int64         `json:"projection_lag_threshold"`
 }
 
 type ElasticsearchConfig struct {
@@ -33,11 +45,23 @@ type Config struct {
 	KafkaPublisherConfig es.KafkaEventsBusConfig
 	Kafka                *kafkaClient.Config
 	Projections          Projections
+	Tracing              tracing.Config
+	Health               HealthConfig
+	Lifecycle            lifecycle.Config
+	Query                query.Config
+	Scheduler            service.SchedulerConfig
+	Interest             service.InterestConfig
+	Holds                service.HoldsConfig
 }
 
 type Projections struct {
 	MongoGroup                string
 	MongoSubscriptionPoolSize int
+	// ElasticsearchSubscriptionPoolSize workers of the live Elasticsearch projection consumer group
+	ElasticsearchSubscriptionPoolSize int
+	// TransactionsSubscriptionPoolSize workers of the transactions ledger consumer group
+	TransactionsSubscriptionPoolSize int
+	Runner                           es.ProjectionConfig
 }
 
 func Load() *Config {
@@ -73,8 +97,10 @@ func Load() *Config {
 	}
 
 	viper.SetDefault("SNAPSHOT_FREQUENCY", 5)
+	viper.SetDefault("EVENT_SCHEMA_VALIDATION", string(es.SchemaValidationWarn))
 	pgStoreEnv := es.Config{
 		SnapshotFrequency: viper.GetUint64("SNAPSHOT_FREQUENCY"),
+		SchemaValidation:  es.SchemaValidationMode(viper.GetString("EVENT_SCHEMA_VALIDATION")),
 	}
 
 	viper.SetDefault("MONGODB_URI", "mongodb://localhost:27017")
@@ -88,11 +114,14 @@ func Load() *Config {
 		Password: viper.GetString("MONGODB_PASSWORD"),
 	}
 
+	viper.SetDefault("SERVICE_NAME", "es-demo")
+
 	viper.SetDefault("SERVER_HOST", "localhost")
 	viper.SetDefault("SERVER_PORT", 8080)
 	serverEnv := http.Config{
-		Host: viper.GetString("SERVER_HOST"),
-		Port: viper.GetInt("SERVER_PORT"),
+		Host:        viper.GetString("SERVER_HOST"),
+		Port:        viper.GetInt("SERVER_PORT"),
+		ServiceName: viper.GetString("SERVICE_NAME"),
 	}
 
 	viper.SetDefault("JWT_SECRET_KEY", "your-super-secret-jwt-key-change-in-production")
@@ -103,6 +132,8 @@ func Load() *Config {
 		SecretKey:     viper.GetString("JWT_SECRET_KEY"),
 		TokenDuration: tokenDuration,
 		Issuer:        viper.GetString("JWT_ISSUER"),
+		// comma separated account ids
+		AdminAccountIDs: splitList(viper.GetString("JWT_ADMIN_ACCOUNT_IDS")),
 	}
 
 	// Kafka Configuration
@@ -127,9 +158,17 @@ func Load() *Config {
 	// Projections Configuration
 	viper.SetDefault("PROJECTION_MONGO_GROUP", "mongoGroup")
 	viper.SetDefault("PROJECTION_MONGO_POOL_SIZE", 10)
+	viper.SetDefault("PROJECTION_ELASTICSEARCH_POOL_SIZE", 3)
+	viper.SetDefault("PROJECTION_TRANSACTIONS_POOL_SIZE", 3)
+	viper.SetDefault("PROJECTION_REPLAY_BATCH_SIZE", 500)
 	projectionsEnv := Projections{
-		MongoGroup:                viper.GetString("PROJECTION_MONGO_GROUP"),
-		MongoSubscriptionPoolSize: viper.GetInt("PROJECTION_MONGO_POOL_SIZE"),
+		MongoGroup:                        viper.GetString("PROJECTION_MONGO_GROUP"),
+		MongoSubscriptionPoolSize:         viper.GetInt("PROJECTION_MONGO_POOL_SIZE"),
+		ElasticsearchSubscriptionPoolSize: viper.GetInt("PROJECTION_ELASTICSEARCH_POOL_SIZE"),
+		TransactionsSubscriptionPoolSize:  viper.GetInt("PROJECTION_TRANSACTIONS_POOL_SIZE"),
+		Runner: es.ProjectionConfig{
+			ReplayBatchSize: viper.GetInt("PROJECTION_REPLAY_BATCH_SIZE"),
+		},
 	}
 
 	// Elasticsearch Configuration
@@ -138,6 +177,77 @@ func Load() *Config {
 		URL: viper.GetString("ELASTICSEARCH_URL"),
 	}
 
+	// Tracing Configuration
+	viper.SetDefault("TRACING_EXPORTER", tracing.ExporterNone)
+	viper.SetDefault("TRACING_OTLP_ENDPOINT", "localhost:4317")
+	viper.SetDefault("TRACING_OTLP_INSECURE", true)
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	tracingEnv := tracing.Config{
+		Exporter:    viper.GetString("TRACING_EXPORTER"),
+		Endpoint:    viper.GetString("TRACING_OTLP_ENDPOINT"),
+		Insecure:    viper.GetBool("TRACING_OTLP_INSECURE"),
+		ServiceName: viper.GetString("SERVICE_NAME"),
+		SampleRatio: viper.GetFloat64("TRACING_SAMPLE_RATIO"),
+	}
+
+	// Health Configuration
+	viper.SetDefault("HEALTH_CHECK_TIMEOUT", "2s")
+	viper.SetDefault("HEALTH_PROJECTION_LAG_THRESHOLD", 1000)
+	healthCheckTimeout, _ := time.ParseDuration(viper.GetString("HEALTH_CHECK_TIMEOUT"))
+	healthEnv := HealthConfig{
+		CheckTimeout:           healthCheckTimeout,
+		ProjectionLagThreshold: viper.GetInt64("HEALTH_PROJECTION_LAG_THRESHOLD"),
+	}
+
+	// Lifecycle Configuration
+	viper.SetDefault("SHUTDOWN_TIMEOUT", "30s")
+	shutdownTimeout, _ := time.ParseDuration(viper.GetString("SHUTDOWN_TIMEOUT"))
+	lifecycleEnv := lifecycle.Config{
+		ShutdownTimeout: shutdownTimeout,
+	}
+
+	// Query Configuration
+	viper.SetDefault("QUERY_MIN_VERSION_TIMEOUT", "2s")
+	viper.SetDefault("QUERY_MIN_VERSION_POLL_INTERVAL", "50ms")
+	minVersionTimeout, _ := time.ParseDuration(viper.GetString("QUERY_MIN_VERSION_TIMEOUT"))
+	minVersionPollInterval, _ := time.ParseDuration(viper.GetString("QUERY_MIN_VERSION_POLL_INTERVAL"))
+	queryEnv := query.Config{
+		MinVersionTimeout:      minVersionTimeout,
+		MinVersionPollInterval: minVersionPollInterval,
+	}
+
+	// Standing Orders Scheduler Configuration
+	viper.SetDefault("SCHEDULER_POLL_INTERVAL", "5s")
+	viper.SetDefault("SCHEDULER_BATCH_SIZE", 50)
+	viper.SetDefault("SCHEDULER_MAX_ATTEMPTS", 3)
+	viper.SetDefault("SCHEDULER_RETRY_DELAY", "1m")
+	schedulerPollInterval, _ := time.ParseDuration(viper.GetString("SCHEDULER_POLL_INTERVAL"))
+	schedulerRetryDelay, _ := time.ParseDuration(viper.GetString("SCHEDULER_RETRY_DELAY"))
+	schedulerEnv := service.SchedulerConfig{
+		PollInterval: schedulerPollInterval,
+		BatchSize:    viper.GetInt("SCHEDULER_BATCH_SIZE"),
+		MaxAttempts:  viper.GetInt("SCHEDULER_MAX_ATTEMPTS"),
+		RetryDelay:   schedulerRetryDelay,
+	}
+
+	// Interest Accrual Configuration
+	viper.SetDefault("INTEREST_RUN_INTERVAL", "1h")
+	viper.SetDefault("INTEREST_BATCH_SIZE", 100)
+	interestRunInterval, _ := time.ParseDuration(viper.GetString("INTEREST_RUN_INTERVAL"))
+	interestEnv := service.InterestConfig{
+		RunInterval: interestRunInterval,
+		BatchSize:   viper.GetInt("INTEREST_BATCH_SIZE"),
+	}
+
+	// Holds Configuration
+	viper.SetDefault("HOLDS_EXPIRY_INTERVAL", "1m")
+	viper.SetDefault("HOLDS_BATCH_SIZE", 100)
+	holdsExpiryInterval, _ := time.ParseDuration(viper.GetString("HOLDS_EXPIRY_INTERVAL"))
+	holdsEnv := service.HoldsConfig{
+		ExpiryInterval: holdsExpiryInterval,
+		BatchSize:      viper.GetInt("HOLDS_BATCH_SIZE"),
+	}
+
 	return &Config{
 		Logger:               loggerEnv,
 		Postgres:             postgresEnv,
@@ -149,5 +259,23 @@ func Load() *Config {
 		Kafka:                kafkaEnv,
 		KafkaPublisherConfig: kafkaPublisherEnv,
 		Projections:          projectionsEnv,
+		Tracing:              tracingEnv,
+		Health:               healthEnv,
+		Lifecycle:            lifecycleEnv,
+		Query:                queryEnv,
+		Scheduler:            schedulerEnv,
+		Interest:             interestEnv,
+		Holds:                holdsEnv,
+	}
+}
+
+// splitList splits a comma separated value, empty items are dropped.
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
@@ -2,6 +2,7 @@ import React, { useState, useEffect } from 'react';
 import { BankAccountService } from '../services/api';
 import type { BankAccount } from '../types';
 import { User, DollarSign, Mail, CreditCard, RefreshCw } from 'lucide-react';
+import { fromMinorUnits } from '../utils/money';
 
 const AccountInfo: React.FC = () => {
   const [account, setAccount] = useState<BankAccount | null>(null);
@@ -137,7 +138,7 @@ const AccountInfo: React.FC = () => {
                 </div>
                 <div className="flex items-baseline space-x-2">
                   <span className="text-3xl font-bold text-green-900">
-                    {account.balance.amount.toLocaleString('vi-VN')}
+                    {fromMinorUnits(account.balance.amount, account.balance.currency).toLocaleString('vi-VN')}
                   </span>
                   <span className="text-lg font-medium text-green-700">
                     {account.balance.currency}
@@ -1,8 +1,9 @@
 import React, { useState } from 'react';
-import { Activity, DollarSign, Minus, Plus } from 'lucide-react';
+import { Activity, DollarSign, Lock, Minus, Plus, Unlock, XCircle } from 'lucide-react';
 import toast, { Toaster } from 'react-hot-toast';
 import { BankAccountService } from '../services/api';
 import type { BankAccount, EventsHistoryResponse } from '../types';
+import { DEFAULT_CURRENCY, formatMoney, toMinorUnits } from '../utils/money';
 
 const AccountOperations: React.FC = () => {
   const [accountId, setAccountId] = useState('');
@@ -11,6 +12,9 @@ const AccountOperations: React.FC = () => {
   const [loading, setLoading] = useState(false);
   const [depositAmount, setDepositAmount] = useState('');
   const [withdrawAmount, setWithdrawAmount] = useState('');
+  const [statusReason, setStatusReason] = useState('');
+  const [payoutTarget, setPayoutTarget] = useState('');
+  const currency = account?.balance.currency || DEFAULT_CURRENCY;
 
   const generatePaymentId = () => {
     // Tạo payment_id dạng UUID
@@ -25,7 +29,7 @@ const AccountOperations: React.FC = () => {
     });
   };
 
-  const handleGetAccount = async () => {
+  const handleGetAccount = async (minVersion?: number) => {
     if (!accountId.trim()) {
       toast.error('Please enter an account ID');
       return;
@@ -33,7 +37,7 @@ const AccountOperations: React.FC = () => {
 
     setLoading(true);
     try {
-      const response = await BankAccountService.getAccount(accountId);
+      const response = await BankAccountService.getAccount(accountId, false, minVersion);
       if (response.success) {
         setAccount(response.data || null);
         toast.success('Account loaded successfully');
@@ -57,14 +61,15 @@ const AccountOperations: React.FC = () => {
     try {
       const paymentId = generatePaymentId();
       const response = await BankAccountService.deposit(accountId, { 
-        amount: parseFloat(depositAmount),
+        amount: toMinorUnits(parseFloat(depositAmount), currency),
+        currency,
         payment_id: paymentId
       });
       if (response.success) {
-        toast.success(`Deposit successful (${parseFloat(depositAmount).toLocaleString('vi-VN')} VND - Payment ID: ${paymentId.slice(0, 8)}...)`);
+        toast.success(`Deposit successful (${formatMoney(toMinorUnits(parseFloat(depositAmount), currency), currency)} - Payment ID: ${paymentId.slice(0, 8)}...)`);
         setDepositAmount('');
-        // Refresh account data
-        handleGetAccount();
+        // Refresh account data including this operation
+        handleGetAccount(response.data?.version);
       } else {
         toast.error(response.error?.message || 'Deposit failed');
       }
@@ -85,14 +90,15 @@ const AccountOperations: React.FC = () => {
     try {
       const paymentId = generatePaymentId();
       const response = await BankAccountService.withdraw(accountId, { 
-        amount: parseFloat(withdrawAmount),
+        amount: toMinorUnits(parseFloat(withdrawAmount), currency),
+        currency,
         payment_id: paymentId
       });
       if (response.success) {
-        toast.success(`Withdrawal successful (${parseFloat(withdrawAmount).toLocaleString('vi-VN')} VND - Payment ID: ${paymentId.slice(0, 8)}...)`);
+        toast.success(`Withdrawal successful (${formatMoney(toMinorUnits(parseFloat(withdrawAmount), currency), currency)} - Payment ID: ${paymentId.slice(0, 8)}...)`);
         setWithdrawAmount('');
-        // Refresh account data
-        handleGetAccount();
+        // Refresh account data including this operation
+        handleGetAccount(response.data?.version);
       } else {
         toast.error(response.error?.message || 'Withdrawal failed');
       }
@@ -103,6 +109,34 @@ const AccountOperations: React.FC = () => {
     }
   };
 
+  const handleStatusChange = async (action: 'freeze' | 'unfreeze' | 'close') => {
+    if (!accountId.trim() || !statusReason.trim()) {
+      toast.error('Please enter account ID and reason');
+      return;
+    }
+
+    setLoading(true);
+    try {
+      const response = action === 'close'
+        ? await BankAccountService.close(accountId, { reason: statusReason, payout_target: payoutTarget || undefined })
+        : action === 'freeze'
+          ? await BankAccountService.freeze(accountId, { reason: statusReason })
+          : await BankAccountService.unfreeze(accountId, { reason: statusReason });
+      if (response.success) {
+        toast.success(response.message || 'Account status changed');
+        setStatusReason('');
+        setPayoutTarget('');
+        handleGetAccount(response.data?.version);
+      } else {
+        toast.error(response.error?.message || 'Failed to change account status');
+      }
+    } catch (error: any) {
+      toast.error(error.response?.data?.error?.message || 'Failed to change account status');
+    } finally {
+      setLoading(false);
+    }
+  };
+
   const handleGetEvents = async () => {
     if (!accountId.trim()) {
       toast.error('Please enter an account ID');
@@ -141,7 +175,7 @@ const AccountOperations: React.FC = () => {
             className="input-field flex-1"
           />
           <button
-            onClick={handleGetAccount}
+            onClick={() => handleGetAccount()}
             disabled={loading}
             className="btn-primary"
           >
@@ -166,9 +200,26 @@ const AccountOperations: React.FC = () => {
             <div>
               <p className="text-sm text-gray-500">Balance</p>
               <p className="font-medium text-2xl text-green-600">
-                {account.balance.amount.toLocaleString('vi-VN')} {account.balance.currency}
+                {formatMoney(account.balance.amount, account.balance.currency)}
               </p>
             </div>
+            <div>
+              <p className="text-sm text-gray-500">Status</p>
+              <p className="font-medium capitalize">{account.status || 'active'}</p>
+            </div>
+            {account.withdrawal_policy && (
+              <div>
+                <p className="text-sm text-gray-500">Withdrawal Limits (per transaction / daily / monthly / overdraft)</p>
+                <p className="font-medium">
+                  {[
+                    account.withdrawal_policy.max_per_transaction,
+                    account.withdrawal_policy.daily_limit,
+                    account.withdrawal_policy.monthly_limit,
+                    account.withdrawal_policy.overdraft_limit,
+                  ].map((limit) => (limit ? formatMoney(limit, account.balance.currency) : 'none')).join(' / ')}
+                </p>
+              </div>
+            )}
           </div>
         </div>
       )}
@@ -184,7 +235,7 @@ const AccountOperations: React.FC = () => {
           <div className="space-y-4">
             <input
               type="number"
-              placeholder="Enter amount (VND)"
+              placeholder={`Enter amount (${currency})`}
               value={depositAmount}
               onChange={(e) => setDepositAmount(e.target.value)}
               className="input-field"
@@ -211,7 +262,7 @@ const AccountOperations: React.FC = () => {
           <div className="space-y-4">
             <input
               type="number"
-              placeholder="Enter amount (VND)"
+              placeholder={`Enter amount (${currency})`}
               value={withdrawAmount}
               onChange={(e) => setWithdrawAmount(e.target.value)}
               className="input-field"
@@ -230,6 +281,53 @@ const AccountOperations: React.FC = () => {
         </div>
       </div>
 
+      {/* Account Status */}
+      <div className="card p-6">
+        <h3 className="text-lg font-semibold text-gray-900 mb-4">Account Status</h3>
+        <div className="grid grid-cols-1 md:grid-cols-2 gap-4 mb-4">
+          <input
+            type="text"
+            placeholder="Reason"
+            value={statusReason}
+            onChange={(e) => setStatusReason(e.target.value)}
+            className="input-field"
+          />
+          <input
+            type="text"
+            placeholder="Payout target (required to close account with balance)"
+            value={payoutTarget}
+            onChange={(e) => setPayoutTarget(e.target.value)}
+            className="input-field"
+          />
+        </div>
+        <div className="flex space-x-4">
+          <button
+            onClick={() => handleStatusChange('freeze')}
+            disabled={loading || !accountId || account?.status === 'frozen' || account?.status === 'closed'}
+            className="btn-secondary flex items-center space-x-2"
+          >
+            <Lock size={16} />
+            <span>Freeze</span>
+          </button>
+          <button
+            onClick={() => handleStatusChange('unfreeze')}
+            disabled={loading || !accountId || account?.status !== 'frozen'}
+            className="btn-secondary flex items-center space-x-2"
+          >
+            <Unlock size={16} />
+            <span>Unfreeze</span>
+          </button>
+          <button
+            onClick={() => handleStatusChange('close')}
+            disabled={loading || !accountId || account?.status === 'closed'}
+            className="btn-primary flex items-center space-x-2 bg-red-600 hover:bg-red-700"
+          >
+            <XCircle size={16} />
+            <span>Close Account</span>
+          </button>
+        </div>
+      </div>
+
       {/* Events History */}
       <div className="card p-6">
         <div className="flex items-center justify-between mb-4">
@@ -1,7 +1,10 @@
 import React, { useState, useMemo } from 'react';
 import type { EventResponse } from '../types';
-import { Download, Eye, EyeOff, Search, Filter } from 'lucide-react';
-import { exportTransactionsToCSV, TransactionRecord } from '../utils/csvExport';
+import { Download, Eye, EyeOff, Search, Filter, FileText } from 'lucide-react';
+import { BankAccountService } from '../services/api';
+import type { TransactionRecord } from '../utils/csvExport';
+
+const toDay = (date: Date) => date.toISOString().slice(0, 10);
 
 interface CSVViewerProps {
   events: EventResponse[];
@@ -14,6 +17,13 @@ const CSVViewer: React.FC<CSVViewerProps> = ({ events, accountId }) => {
   const [filterType, setFilterType] = useState('all');
   const [sortField, setSortField] = useState<keyof TransactionRecord>('date');
   const [sortDirection, setSortDirection] = useState<'asc' | 'desc'>('desc');
+  const [statementFrom, setStatementFrom] = useState(() => {
+    const today = new Date();
+    return toDay(new Date(Date.UTC(today.getUTCFullYear(), today.getUTCMonth(), 1)));
+  });
+  const [statementTo, setStatementTo] = useState(() => toDay(new Date()));
+  const [downloading, setDownloading] = useState(false);
+  const [downloadError, setDownloadError] = useState('');
 
   // Convert events to transaction records for display
   const transactionRecords: TransactionRecord[] = useMemo(() => {
@@ -102,8 +112,38 @@ const CSVViewer: React.FC<CSVViewerProps> = ({ events, accountId }) => {
     return filtered;
   }, [transactionRecords, searchTerm, filterType, sortField, sortDirection]);
 
-  const handleExportCSV = () => {
-    exportTransactionsToCSV(events, accountId);
+  // Statements are rendered by the server from the event store, with opening and closing balance
+  const handleDownloadStatement = async (format: 'csv' | 'camt.053') => {
+    setDownloading(true);
+    setDownloadError('');
+    try {
+      const { filename, blob } = await BankAccountService.downloadStatement(accountId, format, {
+        from: statementFrom,
+        to: statementTo,
+      });
+      const url = URL.createObjectURL(blob);
+      const link = document.createElement('a');
+      link.href = url;
+      link.download = filename;
+      document.body.appendChild(link);
+      link.click();
+      document.body.removeChild(link);
+      URL.revokeObjectURL(url);
+    } catch (err: any) {
+      let message = err.message || 'Failed to download statement';
+      // Error responses arrive as blob because of the requested response type
+      if (err.response?.data instanceof Blob) {
+        try {
+          const body = JSON.parse(await err.response.data.text());
+          message = body.error?.message || body.message || message;
+        } catch {
+          // keep the generic message
+        }
+      }
+      setDownloadError(message);
+    } finally {
+      setDownloading(false);
+    }
   };
 
   const handleSort = (field: keyof TransactionRecord) => {
@@ -138,13 +178,40 @@ const CSVViewer: React.FC<CSVViewerProps> = ({ events, accountId }) => {
             {isViewerOpen ? 'Hide CSV View' : 'View as CSV'}
           </button>
           
+          <input
+            type="date"
+            value={statementFrom}
+            max={statementTo}
+            onChange={(e) => setStatementFrom(e.target.value)}
+            className="px-3 py-2 border border-gray-300 rounded-lg text-sm"
+            title="Statement from"
+          />
+          <input
+            type="date"
+            value={statementTo}
+            min={statementFrom}
+            onChange={(e) => setStatementTo(e.target.value)}
+            className="px-3 py-2 border border-gray-300 rounded-lg text-sm"
+            title="Statement to"
+          />
+
           <button
-            onClick={handleExportCSV}
-            className="inline-flex items-center px-4 py-2 bg-green-600 hover:bg-green-700 text-white rounded-lg transition-colors"
+            onClick={() => handleDownloadStatement('csv')}
+            disabled={downloading}
+            className="inline-flex items-center px-4 py-2 bg-green-600 hover:bg-green-700 disabled:opacity-50 text-white rounded-lg transition-colors"
           >
             <Download className="w-4 h-4 mr-2" />
             Download CSV
           </button>
+
+          <button
+            onClick={() => handleDownloadStatement('camt.053')}
+            disabled={downloading}
+            className="inline-flex items-center px-4 py-2 bg-gray-700 hover:bg-gray-800 disabled:opacity-50 text-white rounded-lg transition-colors"
+          >
+            <FileText className="w-4 h-4 mr-2" />
+            camt.053
+          </button>
         </div>
 
         <div className="text-sm text-gray-600">
@@ -152,6 +219,10 @@ const CSVViewer: React.FC<CSVViewerProps> = ({ events, accountId }) => {
         </div>
       </div>
 
+      {downloadError && (
+        <div className="text-sm text-red-600">{downloadError}</div>
+      )}
+
       {/* CSV Viewer */}
       {isViewerOpen && (
         <div className="border border-gray-200 rounded-lg overflow-hidden bg-white">
@@ -1,7 +1,7 @@
 import React, { useState, useEffect } from 'react';
 import { BankAccountService } from '../services/api';
 import type { EventsHistoryResponse, EventResponse } from '../types';
-import { Activity, Database, RefreshCw, Eye, Clock } from 'lucide-react';
+import { Activity, Database, RefreshCw, Eye, Clock, RotateCcw } from 'lucide-react';
 import CSVViewer from './CSVViewer';
 
 const Events: React.FC = () => {
@@ -53,6 +53,9 @@ const Events: React.FC = () => {
       case 'balance_withdrawed_v1':
       case 'balance_withdrawn':
         return 'bg-red-100 text-red-800 border-red-200';
+      case 'deposit_reversed_v1':
+      case 'withdrawal_reversed_v1':
+        return 'bg-amber-100 text-amber-800 border-amber-200';
       default:
         return 'bg-gray-100 text-gray-800 border-gray-200';
     }
@@ -74,6 +77,9 @@ const Events: React.FC = () => {
         return <div className="h-4 w-4 bg-red-600 rounded-full flex items-center justify-center">
           <span className="text-white font-bold text-xs">-</span>
         </div>;
+      case 'deposit_reversed_v1':
+      case 'withdrawal_reversed_v1':
+        return <RotateCcw className="h-4 w-4 text-amber-600" />;
       default:
         return <Activity className="h-4 w-4" />;
     }
@@ -91,6 +97,10 @@ const Events: React.FC = () => {
       case 'balance_withdrawn':
       case 'balance_withdrawed_v1':
         return `Withdrew ${data.amount?.toLocaleString('vi-VN') || 'N/A'} VND`;
+      case 'deposit_reversed_v1':
+        return `Reversed deposit of ${data.amount?.toLocaleString('vi-VN') || 'N/A'} VND`;
+      case 'withdrawal_reversed_v1':
+        return `Reversed withdrawal of ${data.amount?.toLocaleString('vi-VN') || 'N/A'} VND`;
       default:
         return 'Unknown event';
     }
@@ -108,6 +118,9 @@ const Events: React.FC = () => {
       case 'balance_withdrawed_v1':
       case 'balance_withdrawn':
         return 'hover:bg-red-50';
+      case 'deposit_reversed_v1':
+      case 'withdrawal_reversed_v1':
+        return 'hover:bg-amber-50';
       default:
         return 'hover:bg-gray-50';
     }
@@ -120,6 +133,15 @@ const Events: React.FC = () => {
       .join(' ');
   };
 
+  const eventVersion = (eventId: string) => {
+    return events?.events.find(e => e.event_id === eventId)?.version;
+  };
+
+  // Scrolls to the linked original or reversal event in the timeline
+  const scrollToEvent = (eventId: string) => {
+    document.getElementById(`event-${eventId}`)?.scrollIntoView({ behavior: 'smooth', block: 'center' });
+  };
+
   const formatTimestamp = (timestamp: string) => {
     return new Date(timestamp).toLocaleString('en-US', {
       year: 'numeric',
@@ -236,7 +258,8 @@ const Events: React.FC = () => {
                   <p className="text-sm font-medium text-gray-500">Withdrawals</p>
                   <p className="text-2xl font-bold text-red-600">
                     {events.events.filter(e => 
-                      e.event_type.toLowerCase().includes('withdraw')
+                      e.event_type.toLowerCase().includes('withdraw') &&
+                      !e.event_type.toLowerCase().includes('reversed')
                     ).length}
                   </p>
                 </div>
@@ -288,6 +311,10 @@ const Events: React.FC = () => {
                     <div className="w-3 h-3 bg-red-100 border border-red-200 rounded"></div>
                     <span className="text-gray-600">Withdrawal</span>
                   </div>
+                  <div className="flex items-center space-x-1">
+                    <div className="w-3 h-3 bg-amber-100 border border-amber-200 rounded"></div>
+                    <span className="text-gray-600">Reversal</span>
+                  </div>
                 </div>
               </div>
             </div>
@@ -295,7 +322,7 @@ const Events: React.FC = () => {
             {events.events.length > 0 ? (
               <div className="divide-y divide-gray-200">
                 {events.events.map((event) => (
-                  <div key={event.event_id} className={`p-6 transition-colors ${getEventRowColor(event.event_type)}`}>
+                  <div key={event.event_id} id={`event-${event.event_id}`} className={`p-6 transition-colors ${getEventRowColor(event.event_type)}`}>
                     <div className="flex items-start space-x-4">
                       {/* Event Icon */}
                       <div className={`flex-shrink-0 p-2 rounded-lg border ${getEventTypeColor(event.event_type)}`}>
@@ -320,6 +347,22 @@ const Events: React.FC = () => {
                               <span className="text-sm text-gray-500">
                                 Version: {event.version}
                               </span>
+                              {event.reverses && (
+                                <button
+                                  onClick={() => scrollToEvent(event.reverses!)}
+                                  className="text-sm text-amber-700 hover:underline"
+                                >
+                                  Reverses version {eventVersion(event.reverses) ?? event.data?.original_version}
+                                </button>
+                              )}
+                              {event.reversed_by && (
+                                <button
+                                  onClick={() => scrollToEvent(event.reversed_by!)}
+                                  className="text-sm text-amber-700 hover:underline"
+                                >
+                                  Reversed by version {eventVersion(event.reversed_by)}
+                                </button>
+                              )}
                             </div>
                           </div>
                           <button
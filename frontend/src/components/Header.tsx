@@ -2,6 +2,7 @@ import React, { useState, useEffect } from 'react';
 import { Bell, User, LogOut, Settings, RefreshCw } from 'lucide-react';
 import { BankAccountService } from '../services/api';
 import type { BankAccount } from '../types';
+import { fromMinorUnits } from '../utils/money';
 
 interface User {
   id: string;
@@ -68,7 +69,7 @@ const Header: React.FC<HeaderProps> = ({ title, user, onLogout }) => {
           {user && account && (
             <div className="flex items-center space-x-2">
               <span className="text-xl font-bold text-gray-900">
-                {account.balance.amount.toLocaleString('vi-VN')}
+                {fromMinorUnits(account.balance.amount, account.balance.currency).toLocaleString('vi-VN')}
               </span>
               <span className="text-lg font-medium text-gray-600">
                 {account.balance.currency}
@@ -1,7 +1,10 @@
 import React, { useState, useEffect } from 'react';
-import { BankAccountService } from '../services/api';
-import type { BankAccount } from '../types';
-import { DollarSign, Plus, Minus, CreditCard, AlertCircle, CheckCircle } from 'lucide-react';
+import { BankAccountService, PayrollService } from '../services/api';
+import type { BankAccount, PayrollLineError, PayrollRun } from '../types';
+import { formatMoney, fromMinorUnits, toMinorUnits } from '../utils/money';
+import { DollarSign, Plus, Minus, CreditCard, AlertCircle, CheckCircle, Upload } from 'lucide-react';
+
+const PAYROLL_RUN_POLL_INTERVAL = 2000;
 
 const Payroll: React.FC = () => {
   const [account, setAccount] = useState<BankAccount | null>(null);
@@ -10,11 +13,39 @@ const Payroll: React.FC = () => {
   const [depositAmount, setDepositAmount] = useState('');
   const [withdrawAmount, setWithdrawAmount] = useState('');
   const [message, setMessage] = useState<{ type: 'success' | 'error'; text: string } | null>(null);
+  const [batchCsv, setBatchCsv] = useState('account_id,amount,reference\n');
+  const [batchRun, setBatchRun] = useState<PayrollRun | null>(null);
+  const [batchErrors, setBatchErrors] = useState<PayrollLineError[]>([]);
+  const [batchSubmitting, setBatchSubmitting] = useState(false);
 
   useEffect(() => {
     loadAccountInfo();
   }, []);
 
+  // Poll the run until every line has succeeded or failed
+  useEffect(() => {
+    if (!batchRun || batchRun.status === 'completed') {
+      return;
+    }
+
+    const timer = setTimeout(async () => {
+      try {
+        const response = await PayrollService.getRun(batchRun.id);
+        if (response.success && response.data) {
+          setBatchRun(response.data);
+          if (response.data.status === 'completed') {
+            await loadAccountInfo();
+            window.dispatchEvent(new CustomEvent('balanceUpdated'));
+          }
+        }
+      } catch (err) {
+        console.error('Error loading payroll run:', err);
+      }
+    }, PAYROLL_RUN_POLL_INTERVAL);
+
+    return () => clearTimeout(timer);
+  }, [batchRun]);
+
   const loadAccountInfo = async () => {
     try {
       setLoading(true);
@@ -74,13 +105,15 @@ const Payroll: React.FC = () => {
     setLoading(true);
     try {
       const paymentId = generatePaymentId();
+      const currency = account.balance.currency;
       const response = await BankAccountService.deposit(account.aggregateID, {
-        amount: parseFloat(depositAmount),
+        amount: toMinorUnits(parseFloat(depositAmount), currency),
+        currency,
         payment_id: paymentId
       });
 
       if (response.success) {
-        showMessage('success', `Successfully deposited ${parseFloat(depositAmount).toLocaleString('vi-VN')} VND (Payment ID: ${paymentId.slice(0, 8)}...)`);
+        showMessage('success', `Successfully deposited ${formatMoney(toMinorUnits(parseFloat(depositAmount), account.balance.currency), account.balance.currency)} (Payment ID: ${paymentId.slice(0, 8)}...)`);
         setDepositAmount('');
         // Reload account info to get updated balance
         await loadAccountInfo();
@@ -108,8 +141,10 @@ const Payroll: React.FC = () => {
       return;
     }
 
-    const amount = parseFloat(withdrawAmount);
-    if (amount > account.balance.amount) {
+    const currency = account.balance.currency;
+    const amount = toMinorUnits(parseFloat(withdrawAmount), currency);
+    const overdraftLimit = account.withdrawal_policy?.overdraft_limit || 0;
+    if (amount > account.balance.amount + overdraftLimit) {
       showMessage('error', 'Insufficient balance for this withdrawal');
       return;
     }
@@ -119,11 +154,12 @@ const Payroll: React.FC = () => {
       const paymentId = generatePaymentId();
       const response = await BankAccountService.withdraw(account.aggregateID, {
         amount: amount,
+        currency,
         payment_id: paymentId
       });
 
       if (response.success) {
-        showMessage('success', `Successfully withdrew ${parseFloat(withdrawAmount).toLocaleString('vi-VN')} VND (Payment ID: ${paymentId.slice(0, 8)}...)`);
+        showMessage('success', `Successfully withdrew ${formatMoney(amount, currency)} (Payment ID: ${paymentId.slice(0, 8)}...)`);
         setWithdrawAmount('');
         // Reload account info to get updated balance
         await loadAccountInfo();
@@ -140,6 +176,37 @@ const Payroll: React.FC = () => {
     }
   };
 
+  const handleBatchFile = async (e: React.ChangeEvent<HTMLInputElement>) => {
+    const file = e.target.files?.[0];
+    if (file) {
+      setBatchCsv(await file.text());
+    }
+  };
+
+  const handleBatchSubmit = async () => {
+    setBatchSubmitting(true);
+    setBatchErrors([]);
+    try {
+      // The run id makes resubmitting the same batch return the existing run
+      const response = await PayrollService.createRunFromCSV(generatePaymentId(), batchCsv);
+      if (response.success && response.data) {
+        setBatchRun(response.data);
+        showMessage('success', `Payroll run started with ${response.data.total} lines`);
+      } else {
+        showMessage('error', response.error?.message || 'Payroll run failed');
+      }
+    } catch (err: any) {
+      console.error('Error creating payroll run:', err);
+      const details = err.response?.data?.error?.details;
+      if (Array.isArray(details)) {
+        setBatchErrors(details);
+      }
+      showMessage('error', err.response?.data?.error?.message || 'Payroll run failed');
+    } finally {
+      setBatchSubmitting(false);
+    }
+  };
+
   if (error && !account) {
     return (
       <div className="p-6">
@@ -192,7 +259,7 @@ const Payroll: React.FC = () => {
               <div>
                 <p className="text-sm font-medium text-gray-500">Current Balance</p>
                 <p className="text-2xl font-bold text-gray-900">
-                  {account.balance.amount.toLocaleString('vi-VN')} {account.balance.currency}
+                  {formatMoney(account.balance.amount, account.balance.currency)}
                 </p>
               </div>
             </div>
@@ -282,13 +349,13 @@ const Payroll: React.FC = () => {
                   className="w-full pl-10 pr-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-red-500 focus:border-red-500"
                   min="0"
                   step="1000"
-                  max={account?.balance.amount || 0}
+                  max={account ? fromMinorUnits(account.balance.amount + (account.withdrawal_policy?.overdraft_limit || 0), account.balance.currency) : 0}
                   disabled={loading}
                 />
               </div>
               {account && (
                 <p className="text-xs text-gray-500 mt-1">
-                  Maximum: {account.balance.amount.toLocaleString('vi-VN')} VND
+                  Maximum: {formatMoney(account.balance.amount + (account.withdrawal_policy?.overdraft_limit || 0), account.balance.currency)}
                 </p>
               )}
             </div>
@@ -311,6 +378,68 @@ const Payroll: React.FC = () => {
         </div>
       </div>
 
+      {/* Batch Payroll Run */}
+      <div className="mt-6 bg-white rounded-lg shadow-sm border border-gray-200 p-6">
+        <div className="flex items-center space-x-3 mb-4">
+          <div className="p-2 bg-indigo-100 rounded-lg">
+            <Upload className="h-6 w-6 text-indigo-600" />
+          </div>
+          <div>
+            <h3 className="text-lg font-semibold text-gray-900">Batch Payroll Run</h3>
+            <p className="text-sm text-gray-500">
+              CSV with account_id, amount in minor units, reference and optional currency columns
+            </p>
+          </div>
+        </div>
+
+        <div className="space-y-4">
+          <input
+            type="file"
+            accept=".csv,text/csv"
+            onChange={handleBatchFile}
+            className="block text-sm text-gray-600"
+            disabled={batchSubmitting}
+          />
+          <textarea
+            value={batchCsv}
+            onChange={(e) => setBatchCsv(e.target.value)}
+            rows={6}
+            className="w-full px-3 py-2 font-mono text-sm border border-gray-300 rounded-lg focus:ring-2 focus:ring-indigo-500 focus:border-indigo-500"
+            disabled={batchSubmitting}
+          />
+          <button
+            onClick={handleBatchSubmit}
+            disabled={batchSubmitting || !batchCsv.trim() || batchRun?.status === 'running'}
+            className="bg-indigo-600 text-white py-2 px-4 rounded-lg hover:bg-indigo-700 disabled:bg-gray-300 disabled:cursor-not-allowed transition-colors"
+          >
+            Start Payroll Run
+          </button>
+
+          {batchErrors.length > 0 && (
+            <ul className="text-sm text-red-700 list-disc pl-5">
+              {batchErrors.map((lineError, i) => (
+                <li key={i}>
+                  {lineError.line_no > 0 ? `Line ${lineError.line_no}: ` : ''}{lineError.error}
+                </li>
+              ))}
+            </ul>
+          )}
+
+          {batchRun && (
+            <div className="text-sm">
+              <p className="text-gray-700">
+                Run {batchRun.id.slice(0, 8)}... {batchRun.status}: {batchRun.succeeded} succeeded, {batchRun.failed} failed, {batchRun.pending} pending of {batchRun.total}
+              </p>
+              {batchRun.lines.filter(line => line.status === 'failed').map(line => (
+                <p key={line.line_no} className="text-red-700">
+                  Line {line.line_no} ({line.reference}): {line.error}
+                </p>
+              ))}
+            </div>
+          )}
+        </div>
+      </div>
+
       {/* Quick Actions */}
       <div className="mt-6 bg-gray-50 rounded-lg p-4">
         <h4 className="text-sm font-medium text-gray-700 mb-3">Quick Actions</h4>
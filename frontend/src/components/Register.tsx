@@ -1,5 +1,6 @@
 import React, { useState } from 'react';
 import { AuthService } from '../services/api';
+import { DEFAULT_CURRENCY, SUPPORTED_CURRENCIES, currencyFractionDigits } from '../utils/money';
 
 interface RegisterFormData {
   email: string;
@@ -7,6 +8,7 @@ interface RegisterFormData {
   firstName: string;
   lastName: string;
   initialBalance: number;
+  currency: string;
 }
 
 interface RegisterProps {
@@ -20,12 +22,13 @@ const Register: React.FC<RegisterProps> = ({ onRegisterSuccess, onSwitchToLogin
     password: '',
     firstName: '',
     lastName: '',
-    initialBalance: 0
+    initialBalance: 0,
+    currency: DEFAULT_CURRENCY
   });
   const [isLoading, setIsLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
 
-  const handleChange = (e: React.ChangeEvent<HTMLInputElement>) => {
+  const handleChange = (e: React.ChangeEvent<HTMLInputElement | HTMLSelectElement>) => {
     const { name, value, type } = e.target;
     setFormData(prev => ({
       ...prev,
@@ -139,18 +142,34 @@ const Register: React.FC<RegisterProps> = ({ onRegisterSuccess, onSwitchToLogin
                 onChange={handleChange}
               />
             </div>
+            <div>
+              <label htmlFor="currency" className="block text-sm font-medium text-gray-700">
+                Currency
+              </label>
+              <select
+                id="currency"
+                name="currency"
+                className="mt-1 block w-full px-3 py-2 border border-gray-300 text-gray-900 rounded-md focus:outline-none focus:ring-indigo-500 focus:border-indigo-500 sm:text-sm"
+                value={formData.currency}
+                onChange={handleChange}
+              >
+                {SUPPORTED_CURRENCIES.map((currency) => (
+                  <option key={currency} value={currency}>{currency}</option>
+                ))}
+              </select>
+            </div>
             <div>
               <label htmlFor="initialBalance" className="block text-sm font-medium text-gray-700">
-                Initial Balance (USD)
+                Initial Balance ({formData.currency})
               </label>
               <input
                 id="initialBalance"
                 name="initialBalance"
                 type="number"
                 min="0"
-                step="0.01"
+                step={Math.pow(10, -currencyFractionDigits(formData.currency))}
                 className="mt-1 appearance-none relative block w-full px-3 py-2 border border-gray-300 placeholder-gray-500 text-gray-900 rounded-md focus:outline-none focus:ring-indigo-500 focus:border-indigo-500 sm:text-sm"
-                placeholder="0.00"
+                placeholder="0"
                 value={formData.initialBalance}
                 onChange={handleChange}
               />
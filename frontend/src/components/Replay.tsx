@@ -1,6 +1,9 @@
 import React, { useState, useEffect } from 'react';
 import { ReplayService } from '../services/api';
-import type { ElasticsearchAccount, ReplayResult, SystemSummary } from '../types';
+import type { ElasticsearchAccount, ReplayJob, SystemSummary } from '../types';
+import { formatMoney } from '../utils/money';
+
+const REPLAY_JOB_POLL_INTERVAL = 1000;
 
 interface ReplayProps {}
 
@@ -17,13 +20,51 @@ const Replay: React.FC<ReplayProps> = () => {
     email: '',
     status: ''
   });
-  const [replayResult, setReplayResult] = useState<ReplayResult | null>(null);
+  const [replayJob, setReplayJob] = useState<ReplayJob | null>(null);
+
+  // jobs enqueued by esctl are pending until the service picks them up
+  const replayRunning = replayJob?.status === 'running' || replayJob?.status === 'pending';
 
-  // Load accounts and summary on component mount
+  // Load accounts, summary and latest replay job on component mount
   useEffect(() => {
     loadAccountsAndSummary();
+    loadLatestReplayJob();
   }, []);
 
+  // Poll running replay job until it is finished
+  useEffect(() => {
+    if (!replayJob || !replayRunning) {
+      return;
+    }
+
+    const timer = setInterval(async () => {
+      try {
+        const response = await ReplayService.getReplayJob(replayJob.id);
+        if (response.success && response.data) {
+          setReplayJob(response.data);
+          if (response.data.status !== 'running' && response.data.status !== 'pending') {
+            await loadAccountsAndSummary();
+          }
+        }
+      } catch (err) {
+        console.error('Error polling replay job:', err);
+      }
+    }, REPLAY_JOB_POLL_INTERVAL);
+
+    return () => clearInterval(timer);
+  }, [replayJob?.id, replayRunning]);
+
+  const loadLatestReplayJob = async () => {
+    try {
+      const response = await ReplayService.listReplayJobs();
+      if (response.success && response.data && response.data.length > 0) {
+        setReplayJob(response.data[0]);
+      }
+    } catch (err) {
+      console.error('Error loading replay jobs:', err);
+    }
+  };
+
   const loadAccountsAndSummary = async () => {
     setLoading(true);
     setError(null);
@@ -51,26 +92,58 @@ const Replay: React.FC<ReplayProps> = () => {
   const handleReplay = async (recreateIndex = false) => {
     setReplayLoading(true);
     setError(null);
-    setReplayResult(null);
-    
+
     try {
       const response = await ReplayService.replayAllEvents(recreateIndex);
-      
+
       if (response.success) {
-        setReplayResult(response.data || null);
-        // Reload accounts and summary after replay
-        await loadAccountsAndSummary();
+        setReplayJob(response.data || null);
       } else {
-        setError(response.message || 'Failed to replay events');
+        setError(response.message || 'Failed to start replay');
       }
     } catch (err) {
       console.error('Error replaying events:', err);
-      setError('Failed to replay events');
+      setError('Failed to start replay');
     } finally {
       setReplayLoading(false);
     }
   };
 
+  const handleCancelReplay = async () => {
+    if (!replayJob) {
+      return;
+    }
+
+    try {
+      const response = await ReplayService.cancelReplayJob(replayJob.id);
+      if (!response.success) {
+        setError(response.message || 'Failed to cancel replay');
+      }
+    } catch (err) {
+      console.error('Error cancelling replay:', err);
+      setError('Failed to cancel replay');
+    }
+  };
+
+  const handleResumeReplay = async () => {
+    if (!replayJob) {
+      return;
+    }
+
+    setError(null);
+    try {
+      const response = await ReplayService.resumeReplayJob(replayJob.id);
+      if (response.success) {
+        setReplayJob(response.data || null);
+      } else {
+        setError(response.message || 'Failed to resume replay');
+      }
+    } catch (err) {
+      console.error('Error resuming replay:', err);
+      setError('Failed to resume replay');
+    }
+  };
+
   const handleDeleteIndex = async () => {
     if (!window.confirm('Are you sure you want to delete all data from Elasticsearch? This action cannot be undone.')) {
       return;
@@ -85,7 +158,6 @@ const Replay: React.FC<ReplayProps> = () => {
       if (response.success) {
         setAccounts([]);
         setSummary(null);
-        setReplayResult(null);
       } else {
         setError(response.message || 'Failed to delete index');
       }
@@ -122,18 +194,31 @@ const Replay: React.FC<ReplayProps> = () => {
   };
 
   const formatCurrency = (amount: number, currency: string) => {
-    return new Intl.NumberFormat('en-US', {
-      style: 'currency',
-      currency: currency || 'USD'
-    }).format(amount);
+    return formatMoney(amount, currency, 'en-US');
   };
 
   const formatDate = (dateString: string) => {
     return new Date(dateString).toLocaleString();
   };
 
-  const formatDuration = (duration: number) => {
-    return `${(duration / 1000000).toFixed(2)} ms`;
+  const replayProgress = (job: ReplayJob) => {
+    if (job.status === 'completed' || job.headPosition === 0) {
+      return job.status === 'completed' ? 100 : 0;
+    }
+    return Math.min(100, Math.round((job.position / job.headPosition) * 100));
+  };
+
+  const replayStatusStyle = (job: ReplayJob) => {
+    switch (job.status) {
+      case 'completed':
+        return 'bg-green-50 border-green-200 text-green-800';
+      case 'running':
+        return 'bg-blue-50 border-blue-200 text-blue-800';
+      case 'failed':
+        return 'bg-red-50 border-red-200 text-red-800';
+      default:
+        return 'bg-yellow-50 border-yellow-200 text-yellow-800';
+    }
   };
 
   return (
@@ -144,17 +229,17 @@ const Replay: React.FC<ReplayProps> = () => {
         <div className="flex space-x-4">
           <button
             onClick={() => handleReplay(false)}
-            disabled={replayLoading}
+            disabled={replayLoading || replayRunning}
             className="bg-blue-600 hover:bg-blue-700 disabled:bg-blue-300 text-white px-4 py-2 rounded-md transition-colors"
           >
-            {replayLoading ? 'Replaying...' : 'Replay Events'}
+            {replayLoading || replayRunning ? 'Replaying...' : 'Replay Events'}
           </button>
           <button
             onClick={() => handleReplay(true)}
-            disabled={replayLoading}
+            disabled={replayLoading || replayRunning}
             className="bg-green-600 hover:bg-green-700 disabled:bg-green-300 text-white px-4 py-2 rounded-md transition-colors"
           >
-            {replayLoading ? 'Replaying...' : 'Replay with Index Recreation'}
+            {replayLoading || replayRunning ? 'Replaying...' : 'Replay with Index Recreation'}
           </button>
           <button
             onClick={handleDeleteIndex}
@@ -173,29 +258,63 @@ const Replay: React.FC<ReplayProps> = () => {
         </div>
       )}
 
-      {/* Replay Result */}
-      {replayResult && (
-        <div className="bg-green-50 border border-green-200 p-4 rounded-md">
-          <h3 className="text-lg font-semibold text-green-800 mb-2">Replay Completed Successfully!</h3>
-          <div className="grid grid-cols-2 md:grid-cols-4 gap-4 text-sm">
+      {/* Replay Job */}
+      {replayJob && (
+        <div className={`border p-4 rounded-md ${replayStatusStyle(replayJob)}`}>
+          <div className="flex justify-between items-center mb-2">
+            <h3 className="text-lg font-semibold">
+              Replay {replayJob.status}{replayJob.recreate ? ` (building ${replayJob.targetIndex ?? 'new index'})` : ''}
+            </h3>
+            <div className="flex space-x-2">
+              {replayJob.status === 'running' && (
+                <button
+                  onClick={handleCancelReplay}
+                  className="bg-red-600 hover:bg-red-700 text-white px-3 py-1 rounded-md text-sm transition-colors"
+                >
+                  Cancel
+                </button>
+              )}
+              {['cancelled', 'interrupted', 'failed'].includes(replayJob.status) && (
+                <button
+                  onClick={handleResumeReplay}
+                  className="bg-blue-600 hover:bg-blue-700 text-white px-3 py-1 rounded-md text-sm transition-colors"
+                >
+                  Resume
+                </button>
+              )}
+            </div>
+          </div>
+          <div className="w-full bg-white rounded-full h-2 mb-3">
+            <div
+              className="bg-blue-600 h-2 rounded-full transition-all"
+              style={{ width: `${replayProgress(replayJob)}%` }}
+            ></div>
+          </div>
+          <div className="grid grid-cols-2 md:grid-cols-5 gap-4 text-sm">
             <div>
-              <span className="font-medium">Total Events:</span> {replayResult.totalEvents}
+              <span className="font-medium">Position:</span> {replayJob.position} / {replayJob.headPosition}
             </div>
             <div>
-              <span className="font-medium">Processed:</span> {replayResult.processedEvents}
+              <span className="font-medium">Total Events:</span> {replayJob.total}
             </div>
             <div>
-              <span className="font-medium">Accounts Created:</span> {replayResult.createdAccounts}
+              <span className="font-medium">Processed:</span> {replayJob.processed}
             </div>
             <div>
-              <span className="font-medium">Duration:</span> {formatDuration(replayResult.duration)}
+              <span className="font-medium">Skipped:</span> {replayJob.skipped}
+            </div>
+            <div>
+              <span className="font-medium">Failed:</span> {replayJob.failed}
             </div>
           </div>
-          {replayResult.errors && replayResult.errors.length > 0 && (
+          {replayJob.error && (
+            <div className="mt-2 text-red-600 text-sm">{replayJob.error}</div>
+          )}
+          {replayJob.errors && replayJob.errors.length > 0 && (
             <div className="mt-2">
               <span className="font-medium text-red-600">Errors:</span>
               <ul className="list-disc list-inside text-red-600 text-sm">
-                {replayResult.errors.map((error, index) => (
+                {replayJob.errors.map((error, index) => (
                   <li key={index}>{error}</li>
                 ))}
               </ul>
@@ -213,7 +332,11 @@ const Replay: React.FC<ReplayProps> = () => {
           </div>
           <div className="bg-white p-6 rounded-lg shadow">
             <h3 className="text-sm font-medium text-gray-500">Total Balance</h3>
-            <p className="text-2xl font-bold text-green-600">{formatCurrency(summary.totalBalance, 'USD')}</p>
+            {summary.currencies.map((currencySummary) => (
+              <p key={currencySummary.currency} className="text-2xl font-bold text-green-600">
+                {formatCurrency(currencySummary.totalBalance, currencySummary.currency)}
+              </p>
+            ))}
           </div>
           <div className="bg-white p-6 rounded-lg shadow">
             <h3 className="text-sm font-medium text-gray-500">Total Transactions</h3>
@@ -221,7 +344,11 @@ const Replay: React.FC<ReplayProps> = () => {
           </div>
           <div className="bg-white p-6 rounded-lg shadow">
             <h3 className="text-sm font-medium text-gray-500">Net Flow</h3>
-            <p className="text-2xl font-bold text-purple-600">{formatCurrency(summary.netFlow, 'USD')}</p>
+            {summary.currencies.map((currencySummary) => (
+              <p key={currencySummary.currency} className="text-2xl font-bold text-purple-600">
+                {formatCurrency(currencySummary.netFlow, currencySummary.currency)}
+              </p>
+            ))}
           </div>
         </div>
       )}
@@ -11,6 +11,7 @@ import {
   Legend,
 } from 'chart.js';
 import { BankAccountService } from '../services/api';
+import { DEFAULT_CURRENCY, formatMoney } from '../utils/money';
 import { TrendingUp, AlertCircle, RefreshCw } from 'lucide-react';
 
 // Register Chart.js components
@@ -43,6 +44,7 @@ const Reports: React.FC = () => {
   const [error, setError] = useState('');
   const [user, setUser] = useState<User | null>(null);
   const [maxVersion, setMaxVersion] = useState(20);
+  const [currency, setCurrency] = useState(DEFAULT_CURRENCY);
 
   // Get current user from localStorage
   useEffect(() => {
@@ -62,10 +64,7 @@ const Reports: React.FC = () => {
   }, []);
 
   const formatCurrency = (amount: number) => {
-    return new Intl.NumberFormat('vi-VN', {
-      style: 'currency',
-      currency: 'VND'
-    }).format(amount);
+    return formatMoney(amount, currency);
   };
 
   const fetchVersionData = async (accountId?: string) => {
@@ -81,43 +80,25 @@ const Reports: React.FC = () => {
     setVersionData([]);
 
     try {
-      // First, get current account to check if it exists
-      const currentAccount = await BankAccountService.getAccount(targetAccountId);
-      if (!currentAccount.success || !currentAccount.data) {
+      // Ledger entries carry the running balance of every version, newest first
+      const response = await BankAccountService.getTransactions(targetAccountId, { size: maxVersion });
+      if (!response.success || !response.data) {
         throw new Error('Không tìm thấy tài khoản');
       }
 
-      // Get events to determine actual max version
-      const eventsResponse = await BankAccountService.getEventsHistory(targetAccountId);
-      const actualMaxVersion = eventsResponse.data?.events?.length || maxVersion;
-      
-      const promises: Promise<VersionData | null>[] = [];
-      
-      // Fetch data for each version from 1 to actual max version
-      for (let version = 1; version <= Math.min(actualMaxVersion, maxVersion); version++) {
-        promises.push(
-          BankAccountService.getAccountByVersion(targetAccountId, version)
-            .then(response => {
-              if (response.success && response.data) {
-                return {
-                  version,
-                  balance: response.data.balance?.amount || 0,
-                  timestamp: response.data.updated_at
-                };
-              }
-              return null;
-            })
-            .catch(() => null) // Skip failed versions
-        );
-      }
+      const validData: VersionData[] = response.data.transactions
+        .map(transaction => ({
+          version: transaction.version,
+          balance: transaction.balance_after,
+          timestamp: transaction.timestamp,
+        }))
+        .reverse();
 
-      const results = await Promise.all(promises);
-      const validData = results.filter((data): data is VersionData => data !== null);
-      
       if (validData.length === 0) {
         throw new Error('Không có dữ liệu version nào được tìm thấy');
       }
 
+      setCurrency(response.data.transactions[0].currency || DEFAULT_CURRENCY);
       setVersionData(validData);
     } catch (err: any) {
       setError(err.message || 'Có lỗi xảy ra khi tải dữ liệu');
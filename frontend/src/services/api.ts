@@ -2,15 +2,41 @@ import axios from 'axios';
 import type { 
   APIResponse, 
   BankAccount, 
+  CommandResult,
   CreateBankAccountRequest, 
   DepositRequest, 
   WithdrawRequest,
+  AccountStatusRequest,
+  CloseAccountRequest,
+  ChangeWithdrawalPolicyRequest,
+  ChangeInterestRateRequest,
+  PlaceHoldRequest,
+  CaptureHoldRequest,
+  ReleaseHoldRequest,
+  ReverseTransactionRequest,
+  RollbackRequest,
+  ChangeEmailRequest,
+  UpdateProfileRequest,
+  ChangePasswordRequest,
   EventsHistoryResponse,
-  ReplayResult,
+  TransactionsPage,
+  TransactionsQuery,
+  Statement,
+  StatementFile,
+  StatementQuery,
+  ReplayJob,
+  ReplayTarget,
+  SelectiveReplayRequest,
+  IndexStatus,
   ElasticsearchAccount,
   AccountSummary,
-  SystemSummary
+  SystemSummary,
+  PayrollRun,
+  PayrollRunRequest,
+  StandingOrder,
+  StandingOrderRequest
 } from '../types';
+import { toMinorUnits } from '../utils/money';
 
 const api = axios.create({
   baseURL: '/api/v1',
@@ -62,6 +88,7 @@ export class AuthService {
     firstName: string;
     lastName: string;
     initialBalance: number;
+    currency: string;
   }): Promise<APIResponse> {
     // Generate UUID for the account - fallback for environments without crypto.randomUUID
     const generateUUID = () => {
@@ -83,7 +110,8 @@ export class AuthService {
       password: data.password,
       first_name: data.firstName,    // Map to first_name
       last_name: data.lastName,      // Map to last_name
-      initial_balance: data.initialBalance, // Map to initial_balance
+      initial_balance: toMinorUnits(data.initialBalance, data.currency), // Map to initial_balance in minor units
+      currency: data.currency,
     };
     const response = await api.post('/auth/register', requestData);
     return response.data;
@@ -106,42 +134,196 @@ export class BankAccountService {
     return response.data;
   }
 
-  static async getAccount(id: string, fromEventStore = false): Promise<APIResponse<BankAccount>> {
+  static async getAccount(id: string, fromEventStore = false, minVersion?: number): Promise<APIResponse<BankAccount>> {
     const response = await api.get(`/bank_accounts/${id}`, {
-      params: { from_event_store: fromEventStore }
+      params: { from_event_store: fromEventStore, min_version: minVersion }
     });
     return response.data;
   }
 
-  static async deposit(id: string, data: DepositRequest): Promise<APIResponse> {
+  static async deposit(id: string, data: DepositRequest): Promise<APIResponse<CommandResult>> {
     const response = await api.post(`/bank_accounts/${id}/deposite`, data);
     return response.data;
   }
 
-  static async withdraw(id: string, data: WithdrawRequest): Promise<APIResponse> {
+  static async withdraw(id: string, data: WithdrawRequest): Promise<APIResponse<CommandResult>> {
     const response = await api.post(`/bank_accounts/${id}/withdraw`, data);
     return response.data;
   }
 
+  static async changeWithdrawalPolicy(id: string, data: ChangeWithdrawalPolicyRequest): Promise<APIResponse<CommandResult>> {
+    const response = await api.put(`/bank_accounts/${id}/withdrawal_policy`, data);
+    return response.data;
+  }
+
+  static async changeInterestRate(id: string, data: ChangeInterestRateRequest): Promise<APIResponse<CommandResult>> {
+    const response = await api.put(`/bank_accounts/${id}/interest_rate`, data);
+    return response.data;
+  }
+
+  static async placeHold(id: string, data: PlaceHoldRequest): Promise<APIResponse<CommandResult>> {
+    const response = await api.post(`/bank_accounts/${id}/holds`, data);
+    return response.data;
+  }
+
+  static async captureHold(id: string, holdId: string, data: CaptureHoldRequest): Promise<APIResponse<CommandResult>> {
+    const response = await api.post(`/bank_accounts/${id}/holds/${holdId}/capture`, data);
+    return response.data;
+  }
+
+  static async releaseHold(id: string, holdId: string, data: ReleaseHoldRequest = {}): Promise<APIResponse<CommandResult>> {
+    const response = await api.post(`/bank_accounts/${id}/holds/${holdId}/release`, data);
+    return response.data;
+  }
+
+  static async reverseTransaction(id: string, data: ReverseTransactionRequest): Promise<APIResponse<CommandResult>> {
+    const response = await api.post(`/bank_accounts/${id}/reversals`, data);
+    return response.data;
+  }
+
+  static async rollback(id: string, data: RollbackRequest): Promise<APIResponse<CommandResult>> {
+    const response = await api.post(`/bank_accounts/${id}/rollback`, data);
+    return response.data;
+  }
+
+  static async changeEmail(id: string, data: ChangeEmailRequest): Promise<APIResponse<CommandResult>> {
+    const response = await api.put(`/bank_accounts/${id}/email`, data);
+    return response.data;
+  }
+
+  static async updateProfile(id: string, data: UpdateProfileRequest): Promise<APIResponse<CommandResult>> {
+    const response = await api.put(`/bank_accounts/${id}/profile`, data);
+    return response.data;
+  }
+
+  static async changePassword(id: string, data: ChangePasswordRequest): Promise<APIResponse<CommandResult>> {
+    const response = await api.put(`/bank_accounts/${id}/password`, data);
+    return response.data;
+  }
+
+  static async freeze(id: string, data: AccountStatusRequest): Promise<APIResponse<CommandResult>> {
+    const response = await api.post(`/bank_accounts/${id}/freeze`, data);
+    return response.data;
+  }
+
+  static async unfreeze(id: string, data: AccountStatusRequest): Promise<APIResponse<CommandResult>> {
+    const response = await api.post(`/bank_accounts/${id}/unfreeze`, data);
+    return response.data;
+  }
+
+  static async close(id: string, data: CloseAccountRequest): Promise<APIResponse<CommandResult>> {
+    const response = await api.post(`/bank_accounts/${id}/close`, data);
+    return response.data;
+  }
+
   static async getEventsHistory(id: string): Promise<APIResponse<EventsHistoryResponse>> {
     const response = await api.get(`/bank_accounts/${id}/events`);
     return response.data;
   }
 
+  static async getTransactions(id: string, query: TransactionsQuery = {}): Promise<APIResponse<TransactionsPage>> {
+    const response = await api.get(`/bank_accounts/${id}/transactions`, { params: query });
+    return response.data;
+  }
+
+  static async getStatement(id: string, query: StatementQuery = {}): Promise<APIResponse<Statement>> {
+    const response = await api.get(`/bank_accounts/${id}/statements`, { params: { ...query, format: 'json' } });
+    return response.data;
+  }
+
+  static async downloadStatement(
+    id: string,
+    format: 'csv' | 'camt.053',
+    query: StatementQuery = {}
+  ): Promise<StatementFile> {
+    const response = await api.get(`/bank_accounts/${id}/statements`, {
+      params: { ...query, format },
+      responseType: 'blob',
+    });
+    const disposition: string = response.headers['content-disposition'] || '';
+    const match = disposition.match(/filename="?([^"]+)"?/);
+    const filename = match ? match[1] : `statement_${id}.${format === 'csv' ? 'csv' : 'xml'}`;
+    return { filename, blob: response.data };
+  }
+
   static async getAccountByVersion(id: string, version: number): Promise<APIResponse<BankAccount>> {
     const response = await api.get(`/bank_accounts/${id}/version/${version}`);
     return response.data;
   }
 }
 
+export class PayrollService {
+  // Submitting the same run id again returns the existing run
+  static async createRun(request: PayrollRunRequest): Promise<APIResponse<PayrollRun>> {
+    const response = await api.post('/payroll_runs', request);
+    return response.data;
+  }
+
+  // CSV with header row account_id,amount,reference and optional currency column, amounts in minor units
+  static async createRunFromCSV(id: string, csv: string): Promise<APIResponse<PayrollRun>> {
+    const response = await api.post('/payroll_runs', csv, {
+      params: { id },
+      headers: { 'Content-Type': 'text/csv' }
+    });
+    return response.data;
+  }
+
+  static async getRun(id: string): Promise<APIResponse<PayrollRun>> {
+    const response = await api.get(`/payroll_runs/${id}`);
+    return response.data;
+  }
+}
+
+export class StandingOrderService {
+  static async createOrder(request: StandingOrderRequest): Promise<APIResponse<StandingOrder>> {
+    const response = await api.post('/standing_orders', request);
+    return response.data;
+  }
+
+  static async getOrder(id: string): Promise<APIResponse<StandingOrder>> {
+    const response = await api.get(`/standing_orders/${id}`);
+    return response.data;
+  }
+
+  static async cancelOrder(id: string, reason?: string): Promise<APIResponse<StandingOrder>> {
+    const response = await api.post(`/standing_orders/${id}/cancel`, { reason });
+    return response.data;
+  }
+}
+
 export class ReplayService {
-  static async replayAllEvents(recreateIndex = false): Promise<APIResponse<ReplayResult>> {
+  static async replayAllEvents(recreateIndex = false, target: ReplayTarget = 'elasticsearch'): Promise<APIResponse<ReplayJob>> {
     const response = await api.post('/replay/events', {}, {
-      params: { recreate_index: recreateIndex }
+      params: { recreate_index: recreateIndex, target }
     });
     return response.data;
   }
 
+  static async replayStreams(request: SelectiveReplayRequest): Promise<APIResponse<ReplayJob>> {
+    const response = await api.post('/replay/streams', request);
+    return response.data;
+  }
+
+  static async listReplayJobs(): Promise<APIResponse<ReplayJob[]>> {
+    const response = await api.get('/replay/jobs');
+    return response.data;
+  }
+
+  static async getReplayJob(id: string): Promise<APIResponse<ReplayJob>> {
+    const response = await api.get(`/replay/jobs/${id}`);
+    return response.data;
+  }
+
+  static async cancelReplayJob(id: string): Promise<APIResponse<ReplayJob>> {
+    const response = await api.post(`/replay/jobs/${id}/cancel`);
+    return response.data;
+  }
+
+  static async resumeReplayJob(id: string): Promise<APIResponse<ReplayJob>> {
+    const response = await api.post(`/replay/jobs/${id}/resume`);
+    return response.data;
+  }
+
   static async getAccountFromElasticsearch(id: string): Promise<APIResponse<ElasticsearchAccount>> {
     const response = await api.get(`/replay/accounts/${id}`);
     return response.data;
@@ -162,6 +344,16 @@ export class ReplayService {
     return response.data;
   }
 
+  static async getIndexStatus(): Promise<APIResponse<IndexStatus>> {
+    const response = await api.get('/replay/index');
+    return response.data;
+  }
+
+  static async rollbackIndex(): Promise<APIResponse<IndexStatus>> {
+    const response = await api.post('/replay/index/rollback');
+    return response.data;
+  }
+
   static async deleteElasticsearchIndex(): Promise<APIResponse> {
     // This endpoint doesn't exist yet, but we can implement it
     const response = await api.delete('/replay/index');
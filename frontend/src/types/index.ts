@@ -1,3 +1,82 @@
+// Frozen accounts accept deposits but no withdrawals, closed accounts accept nothing
+export type AccountStatus = 'active' | 'frozen' | 'closed';
+
+// Limits in minor units of the account currency, zero means no limit and no overdraft
+export interface WithdrawalPolicy {
+  overdraft_limit: number;
+  max_per_transaction: number;
+  daily_limit: number;
+  monthly_limit: number;
+}
+
+export interface ChangeWithdrawalPolicyRequest extends WithdrawalPolicy {
+  reason: string;
+}
+
+// Annual rate in basis points, zero stops accrual
+export interface ChangeInterestRateRequest {
+  rate_bps: number;
+  reason: string;
+}
+
+// Funds reserved for a pending payment in minor units, expired holds no longer reserve funds
+export interface Hold {
+  id: string;
+  amount: number;
+  reference?: string;
+  placed_at: string;
+  expires_at: string;
+}
+
+// Omitted expires_at defaults to 7 days, holds last at most 30 days
+export interface PlaceHoldRequest {
+  hold_id: string;
+  amount: number;
+  currency?: string;
+  reference?: string;
+  expires_at?: string;
+}
+
+// Zero amount captures the whole hold, the rest of a partial capture is released
+export interface CaptureHoldRequest {
+  amount?: number;
+  payment_id: string;
+}
+
+export interface ReleaseHoldRequest {
+  reason?: string;
+}
+
+// Reverses the deposit or withdrawal recorded by the event, the reason is recorded for audit
+export interface ReverseTransactionRequest {
+  event_id: string;
+  reason: string;
+}
+
+// Reverses every deposit and withdrawal recorded after the version, the reason is recorded for audit
+export interface RollbackRequest {
+  version: number;
+  reason: string;
+}
+
+// The current password is verified before the change
+export interface ChangeEmailRequest {
+  email: string;
+  current_password: string;
+}
+
+// Omitted names are kept
+export interface UpdateProfileRequest {
+  first_name?: string;
+  last_name?: string;
+}
+
+// Tokens issued before the change are revoked, log in again afterwards
+export interface ChangePasswordRequest {
+  current_password: string;
+  new_password: string;
+}
+
 export interface BankAccount {
   aggregateID: string;
   email: string;
@@ -7,9 +86,27 @@ export interface BankAccount {
     amount: number;
     currency: string;
   };
+  // Ledger balance less funds reserved by holds
+  available_balance?: {
+    amount: number;
+    currency: string;
+  };
+  holds?: Hold[];
+  status?: AccountStatus;
+  withdrawal_policy?: WithdrawalPolicy;
+  interest_rate_bps?: number;
+  // Millionths of the minor unit, credited at the end of the month
+  accrued_interest?: number;
+  version: number;
   updated_at?: string;
 }
 
+// Returned by commands, pass version as min_version to read the account including the command
+export interface CommandResult {
+  aggregateID: string;
+  version: number;
+}
+
 export interface User {
   id: string;
   email: string;
@@ -21,7 +118,8 @@ export interface CreateBankAccountRequest {
   email: string;
   first_name: string;
   last_name: string;
-  balance: number;
+  balance: number; // minor units of currency
+  currency?: string;
   password: string;
 }
 
@@ -44,7 +142,8 @@ export interface RegisterRequest {
   password: string;
   first_name: string;    // Request uses first_name
   last_name: string;     // Request uses last_name
-  initial_balance: number; // Request uses initial_balance
+  initial_balance: number; // Request uses initial_balance, minor units of currency
+  currency?: string;
 }
 
 export interface RegisterResponse {
@@ -53,16 +152,29 @@ export interface RegisterResponse {
   message: string;
 }
 
+// Amounts are in minor units, currency defaults to the account currency
 export interface DepositRequest {
   amount: number;
+  currency?: string;
   payment_id: string;
 }
 
 export interface WithdrawRequest {
   amount: number;
+  currency?: string;
   payment_id: string;
 }
 
+export interface AccountStatusRequest {
+  reason: string;
+}
+
+// Remaining balance is paid out to payout_target, required unless the balance is zero
+export interface CloseAccountRequest {
+  reason: string;
+  payout_target?: string;
+}
+
 export interface APIResponse<T = any> {
   success: boolean;
   code: string;
@@ -84,6 +196,9 @@ export interface EventResponse {
   data: any;
   metadata?: any;
   timestamp: string;
+  // Event id of the transaction this reversal compensates, and of the reversal of this transaction
+  reverses?: string;
+  reversed_by?: string;
 }
 
 export interface EventsHistoryResponse {
@@ -121,23 +236,250 @@ export interface AccountSummary {
   lastActivity: string;
 }
 
-export interface ReplayResult {
-  totalEvents: number;
-  processedEvents: number;
-  createdAccounts: number;
-  updatedAccounts: number;
-  duration: number;
+export type ReplayJobStatus = 'pending' | 'running' | 'completed' | 'failed' | 'cancelled' | 'interrupted';
+
+export type ReplayTarget = 'elasticsearch' | 'mongo';
+
+export interface StreamFilter {
+  aggregateIds?: string[];
+  aggregateType?: string;
+  fromPosition?: number;
+  toPosition?: number;
+  from?: string;
+  to?: string;
+}
+
+export interface SelectiveReplayRequest {
+  target?: ReplayTarget;
+  aggregate_ids?: string[];
+  aggregate_type?: string;
+  from_position?: number;
+  to_position?: number;
+  from?: string;
+  to?: string;
+}
+
+export interface ReplayJob {
+  id: string;
+  projection: string;
+  status: ReplayJobStatus;
+  recreate: boolean;
+  targetIndex?: string;
+  filter?: StreamFilter;
+  cursor?: string;
+  position: number;
+  headPosition: number;
+  total: number;
+  processed: number;
+  skipped: number;
+  failed: number;
   errors?: string[];
-  accountSummaries: AccountSummary[];
+  error?: string;
+  createdAt: string;
+  updatedAt: string;
+  startedAt?: string;
+  finishedAt?: string;
 }
 
-export interface SystemSummary {
-  totalAccounts: number;
-  activeAccounts: number;
+export interface IndexVersion {
+  name: string;
+  version: number;
+  documents: number;
+  current: boolean;
+}
+
+export interface IndexStatus {
+  alias: string;
+  current: string[];
+  indices: IndexVersion[];
+}
+
+// Money totals per currency, amounts are in minor units
+export interface CurrencySummary {
+  currency: string;
+  accounts: number;
   totalBalance: number;
   totalDeposits: number;
   totalWithdrawals: number;
-  totalTransactions: number;
   netFlow: number;
   averageBalance: number;
-}
\ No newline at end of file
+}
+
+export interface SystemSummary {
+  totalAccounts: number;
+  activeAccounts: number;
+  totalTransactions: number;
+  currencies: CurrencySummary[];
+}
+export type TransactionType = 'opening' | 'deposit' | 'withdrawal' | 'closing_payout' | 'interest' | 'hold_capture'
+  | 'deposit_reversal' | 'withdrawal_reversal';
+
+// Ledger entry, amounts are in minor units of the currency
+export interface Transaction {
+  id: string;
+  event_id: string;
+  aggregate_id: string;
+  version: number;
+  type: TransactionType;
+  direction: 'credit' | 'debit';
+  amount: number;
+  currency: string;
+  payment_id?: string;
+  balance_after: number;
+  // Event of the transaction compensated by a reversal
+  reversed_event_id?: string;
+  timestamp: string;
+  metadata?: Record<string, string>;
+}
+
+export interface TransactionsPage {
+  transactions: Transaction[];
+  page: number;
+  size: number;
+  total: number;
+}
+
+export interface TransactionsQuery {
+  page?: number;
+  size?: number;
+  from?: string;
+  to?: string;
+}
+
+export type StatementFormat = 'json' | 'csv' | 'camt.053';
+
+// Period of the statement, UTC days YYYY-MM-DD inclusive
+export interface StatementQuery {
+  from?: string;
+  to?: string;
+}
+
+// Balance changing transaction of the statement, amounts are in minor units of the currency
+export interface StatementEntry {
+  event_id: string;
+  version: number;
+  type: TransactionType;
+  direction: 'credit' | 'debit';
+  amount: number;
+  payment_id?: string;
+  reference?: string;
+  balance_after: number;
+  booked_at: string;
+}
+
+export interface Statement {
+  id: string;
+  account_id: string;
+  holder_name: string;
+  currency: string;
+  from: string;
+  to: string;
+  opening_balance: number;
+  closing_balance: number;
+  total_credits: number;
+  total_debits: number;
+  version: number;
+  entries: StatementEntry[];
+  generated_at: string;
+}
+
+// Rendered statement file with the name suggested by the server
+export interface StatementFile {
+  filename: string;
+  blob: Blob;
+}
+
+export type PayrollLineStatus = 'pending' | 'succeeded' | 'failed';
+
+// Amount in minor units of currency, empty currency means the account currency
+export interface PayrollLineRequest {
+  account_id: string;
+  amount: number;
+  currency?: string;
+  reference: string;
+}
+
+export interface PayrollRunRequest {
+  id: string;
+  lines: PayrollLineRequest[];
+}
+
+export interface PayrollLine extends PayrollLineRequest {
+  line_no: number;
+  status: PayrollLineStatus;
+  error?: string;
+  account_version?: number;
+}
+
+export interface PayrollRun {
+  id: string;
+  source: 'json' | 'csv';
+  status: 'running' | 'completed';
+  total: number;
+  succeeded: number;
+  failed: number;
+  pending: number;
+  lines: PayrollLine[];
+  created_at: string;
+  completed_at?: string;
+}
+
+// Details of the rejected run, line_no is zero for errors of the whole batch
+export interface PayrollLineError {
+  line_no: number;
+  error: string;
+}
+
+export type StandingOrderKind = 'deposit' | 'withdrawal' | 'transfer';
+export type ScheduleFrequency = 'once' | 'daily' | 'weekly' | 'monthly' | 'cron';
+
+// Amount in minor units of currency, cron is a five field UTC expression used with cron frequency
+export interface StandingOrderRequest {
+  id: string;
+  kind: StandingOrderKind;
+  account_id: string;
+  target_account_id?: string;
+  amount: number;
+  currency?: string;
+  reference?: string;
+  frequency: ScheduleFrequency;
+  cron?: string;
+  start_at: string;
+  end_at?: string;
+  max_attempts?: number;
+}
+
+export interface StandingOrderExecution {
+  run_at: string;
+  attempt: number;
+  succeeded: boolean;
+  error?: string;
+  at: string;
+  retry_at?: string;
+}
+
+export interface StandingOrder {
+  id: string;
+  owner_id: string;
+  kind: StandingOrderKind;
+  account_id: string;
+  target_account_id?: string;
+  amount: number;
+  currency: string;
+  reference: string;
+  schedule: {
+    frequency: ScheduleFrequency;
+    cron?: string;
+    start_at: string;
+    end_at?: string;
+  };
+  max_attempts: number;
+  status: 'active' | 'completed' | 'cancelled';
+  next_run_at?: string;
+  retry_at?: string;
+  attempts: number;
+  executions: number;
+  failures: number;
+  history: StandingOrderExecution[];
+  created_at: string;
+}
@@ -0,0 +1,28 @@
+// Amounts are sent and received in minor units of the account currency (e.g. cents for USD)
+
+export const DEFAULT_CURRENCY = 'VND';
+
+export const SUPPORTED_CURRENCIES = ['VND', 'USD', 'EUR', 'JPY', 'GBP'];
+
+export const currencyFractionDigits = (currency: string): number => {
+  return new Intl.NumberFormat('en-US', {
+    style: 'currency',
+    currency: currency || DEFAULT_CURRENCY,
+  }).resolvedOptions().maximumFractionDigits ?? 0;
+};
+
+export const toMinorUnits = (amount: number, currency: string): number => {
+  return Math.round(amount * Math.pow(10, currencyFractionDigits(currency)));
+};
+
+export const fromMinorUnits = (amount: number, currency: string): number => {
+  return amount / Math.pow(10, currencyFractionDigits(currency));
+};
+
+export const formatMoney = (amount: number, currency: string, locale = 'vi-VN'): string => {
+  const code = currency || DEFAULT_CURRENCY;
+  return new Intl.NumberFormat(locale, {
+    style: 'currency',
+    currency: code,
+  }).format(fromMinorUnits(amount, code));
+};
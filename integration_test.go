@@ -187,7 +187,7 @@ func (it *IntegrationTest) testWithdrawMoney(t *testing.T) {
 	require.NoError(t, err)
 	defer resp2.Body.Close()
 
-	assert.Equal(t, http.StatusInternalServerError, resp2.StatusCode)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp2.StatusCode)
 
 	var apiResp2 APIResponse
 	err = json.NewDecoder(resp2.Body).Decode(&apiResp2)
@@ -2,58 +2,100 @@ package app
 
 import (
 	"context"
+	"os/signal"
 	"syscall"
 
 	"github.com/th1enq/es-demo/config"
 	"github.com/th1enq/es-demo/internal/delivery/http"
 	"github.com/th1enq/es-demo/internal/domain"
-	"github.com/th1enq/es-demo/internal/utils"
 	"github.com/th1enq/es-demo/pkg/es"
 	kafka_client "github.com/th1enq/es-demo/pkg/kafka"
+	"github.com/th1enq/es-demo/pkg/lifecycle"
 	"go.uber.org/zap"
 )
 
 type Application struct {
-	cfg               *config.Config
-	server            http.HTTPServer
-	mongoSubscription kafka_client.ConsumerGroup
-	logger            *zap.Logger
+	cfg                       *config.Config
+	server                    http.HTTPServer
+	mongoSubscription         kafka_client.ConsumerGroup
+	elasticsearchSubscription kafka_client.ConsumerGroup
+	transactionsSubscription  kafka_client.ConsumerGroup
+	mongoProjection           *es.ProjectionRunner
+	elasticsearchProjection   *es.ProjectionRunner
+	transactionsProjection    *es.ProjectionRunner
+	lifecycle                 *lifecycle.Manager
+	logger                    *zap.Logger
 }
 
 func NewApplication(
 	cfg *config.Config,
 	server http.HTTPServer,
 	mongoSubscription kafka_client.ConsumerGroup,
+	elasticsearchSubscription kafka_client.ConsumerGroup,
+	transactionsSubscription kafka_client.ConsumerGroup,
+	mongoProjection *es.ProjectionRunner,
+	elasticsearchProjection *es.ProjectionRunner,
+	transactionsProjection *es.ProjectionRunner,
+	lifecycle *lifecycle.Manager,
 	logger *zap.Logger,
 ) *Application {
 	return &Application{
-		cfg:               cfg,
-		server:            server,
-		mongoSubscription: mongoSubscription,
-		logger:            logger,
+		cfg:                       cfg,
+		server:                    server,
+		mongoSubscription:         mongoSubscription,
+		elasticsearchSubscription: elasticsearchSubscription,
+		transactionsSubscription:  transactionsSubscription,
+		mongoProjection:           mongoProjection,
+		elasticsearchProjection:   elasticsearchProjection,
+		transactionsProjection:    transactionsProjection,
+		lifecycle:                 lifecycle,
+		logger:                    logger,
 	}
 }
 
+// Start runs the application components until SIGINT/SIGTERM or a component failure,
+// then shuts them down gracefully within the configured shutdown timeout.
 func (app *Application) Start(ctx context.Context) error {
 	app.logger.Info("Starting application ...")
-	go func() {
-		if err := app.server.Start(ctx); err != nil {
-			app.logger.Fatal("Failed to start HTTP server", zap.Error(err))
-		}
-	}()
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	topics := []string{
 		es.GetTopicName(app.cfg.KafkaPublisherConfig.TopicPrefix, string(domain.BankAccountAggregateType)),
 	}
-	go func() {
-		if err := app.mongoSubscription.ConsumeTopicWithErrGroup(
+
+	// components are stopped in reverse order: HTTP server drains first so that
+	// no new events are published while consumers finish their current batches,
+	// stopping the projection releases events waiting while it is paused or rebuilding
+	app.lifecycle.Add("mongo_subscription", func(ctx context.Context) error {
+		return app.mongoSubscription.ConsumeTopicWithErrGroup(
 			ctx,
 			topics,
 			app.cfg.Projections.MongoSubscriptionPoolSize,
-		); err != nil {
-			app.logger.Fatal("Failed to start MongoDB subscription consumer group", zap.Error(err))
-		}
-	}()
-	utils.BlockUntilSignal(syscall.SIGINT, syscall.SIGTERM)
+		)
+	}, app.mongoProjection.Stop)
+	app.lifecycle.Add("elasticsearch_subscription", func(ctx context.Context) error {
+		return app.elasticsearchSubscription.ConsumeTopicWithErrGroup(
+			ctx,
+			topics,
+			app.cfg.Projections.ElasticsearchSubscriptionPoolSize,
+		)
+	}, app.elasticsearchProjection.Stop)
+	app.lifecycle.Add("transactions_subscription", func(ctx context.Context) error {
+		return app.transactionsSubscription.ConsumeTopicWithErrGroup(
+			ctx,
+			topics,
+			app.cfg.Projections.TransactionsSubscriptionPoolSize,
+		)
+	}, app.transactionsProjection.Stop)
+	app.lifecycle.Add("http_server", app.server.Start, app.server.Shutdown)
+
+	if err := app.lifecycle.Run(ctx); err != nil {
+		app.logger.Error("Application stopped with error", zap.Error(err))
+		return err
+	}
+
+	app.logger.Info("Application stopped")
 	return nil
 }
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+	"github.com/th1enq/es-demo/config"
+	"github.com/th1enq/es-demo/internal/service"
+	"github.com/th1enq/es-demo/pkg/health"
+	kafkaClient "github.com/th1enq/es-demo/pkg/kafka"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	featureReplay            = service.FeatureReplay
+	featureElasticsearchRead = "elasticsearch_search"
+	featureMongoProjection   = "mongo_projection"
+	featureTransactions      = "transactions"
+)
+
+// newHealthRegistry registers dependency checks used by the readiness endpoint.
+func newHealthRegistry(
+	cfg *config.Config,
+	pgxPool *pgxpool.Pool,
+	mongoClient *mongo.Client,
+	esClient *elasticsearch.Client,
+	mongoConsumerGroup kafkaClient.ConsumerGroup,
+	elasticsearchConsumerGroup kafkaClient.ConsumerGroup,
+	transactionsConsumerGroup kafkaClient.ConsumerGroup,
+) *health.Registry {
+	registry := health.NewRegistry(cfg.Health.CheckTimeout)
+
+	registry.Register(health.Check{
+		Name:     "postgres",
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			return pgxPool.Ping(ctx)
+		},
+	})
+
+	registry.Register(health.Check{
+		Name:     "mongodb",
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			return mongoClient.Ping(ctx, nil)
+		},
+	})
+
+	registry.Register(health.Check{
+		Name:     "kafka",
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			return pingKafka(ctx, cfg.Kafka.Brokers)
+		},
+	})
+
+	registry.Register(health.Check{
+		Name:     "elasticsearch",
+		Critical: false,
+		Features: []string{featureReplay, featureElasticsearchRead},
+		Check: func(ctx context.Context) error {
+			res, err := esClient.Ping(esClient.Ping.WithContext(ctx))
+			if err != nil {
+				return errors.Wrap(err, "esClient.Ping")
+			}
+			defer res.Body.Close()
+			if res.IsError() {
+				return errors.Errorf("elasticsearch ping: %s", res.Status())
+			}
+			return nil
+		},
+	})
+
+	registry.Register(health.Check{
+		Name:     "mongo_subscription",
+		Critical: true,
+		Features: []string{featureMongoProjection},
+		Check: func(ctx context.Context) error {
+			return checkConsumerGroup(mongoConsumerGroup.Status(), cfg.Health.ProjectionLagThreshold)
+		},
+	})
+
+	registry.Register(health.Check{
+		Name:     "elasticsearch_subscription",
+		Critical: false,
+		Features: []string{featureElasticsearchRead},
+		Check: func(ctx context.Context) error {
+			return checkConsumerGroup(elasticsearchConsumerGroup.Status(), cfg.Health.ProjectionLagThreshold)
+		},
+	})
+
+	registry.Register(health.Check{
+		Name:     "transactions_subscription",
+		Critical: false,
+		Features: []string{featureTransactions},
+		Check: func(ctx context.Context) error {
+			return checkConsumerGroup(transactionsConsumerGroup.Status(), cfg.Health.ProjectionLagThreshold)
+		},
+	})
+
+	return registry
+}
+
+func pingKafka(ctx context.Context, brokers []string) error {
+	if len(brokers) == 0 {
+		return errors.New("no kafka brokers configured")
+	}
+
+	var lastErr error
+	for _, broker := range brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			lastErr = errors.Wrapf(err, "kafka.DialContext broker: %s", broker)
+			continue
+		}
+
+		_, err = conn.Brokers()
+		conn.Close()
+		if err != nil {
+			lastErr = errors.Wrapf(err, "conn.Brokers broker: %s", broker)
+			continue
+		}
+		return nil
+	}
+	return lastErr
+}
+
+func checkConsumerGroup(status kafkaClient.ConsumerGroupStatus, lagThreshold int64) error {
+	if !status.Running {
+		if status.LastError != "" {
+			return errors.Errorf("consumer group %s is not running: %s", status.GroupID, status.LastError)
+		}
+		return errors.Errorf("consumer group %s is not running", status.GroupID)
+	}
+
+	if lagThreshold > 0 && status.Lag > lagThreshold {
+		return errors.Wrap(health.ErrDegraded, fmt.Sprintf("consumer group %s lag %d exceeds threshold %d", status.GroupID, status.Lag, lagThreshold))
+	}
+
+	return nil
+}
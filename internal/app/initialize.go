@@ -3,34 +3,33 @@ package app
 import (
 	"context"
 	"net"
+	"os"
 	"strconv"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	uuid "github.com/satori/go.uuid"
 	"github.com/segmentio/kafka-go"
 	"github.com/th1enq/es-demo/config"
 	"github.com/th1enq/es-demo/internal/delivery/http"
+	elasticsearch_subscription "github.com/th1enq/es-demo/internal/delivery/kafka/elasticsearch_subscription"
 	mongo_subscription "github.com/th1enq/es-demo/internal/delivery/kafka/mongo_subcription"
+	transactions_subscription "github.com/th1enq/es-demo/internal/delivery/kafka/transactions_subscription"
 	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/internal/events"
 	"github.com/th1enq/es-demo/internal/projection"
 	"github.com/th1enq/es-demo/internal/repository"
 	"github.com/th1enq/es-demo/internal/service"
-	"github.com/th1enq/es-demo/internal/utils"
-	serviceErrors "github.com/th1enq/es-demo/pkg/errors"
 	"github.com/th1enq/es-demo/pkg/es"
 	kafkaClient "github.com/th1enq/es-demo/pkg/kafka"
+	"github.com/th1enq/es-demo/pkg/lifecycle"
 	"github.com/th1enq/es-demo/pkg/logger"
 	"github.com/th1enq/es-demo/pkg/mongodb"
 	"github.com/th1enq/es-demo/pkg/postgres"
+	"github.com/th1enq/es-demo/pkg/tracing"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
 )
 
-const (
-	mongoBankAccountsCollection = "bank_accounts"
-)
-
 func Initialize(ctx context.Context) (*Application, error) {
 	cfg := config.Load()
 
@@ -39,12 +38,30 @@ func Initialize(ctx context.Context) (*Application, error) {
 		return nil, err
 	}
 
+	manager := lifecycle.NewManager(cfg.Lifecycle, logger)
+
+	// instanceID identifies this process as owner of background jobs shared with other instances
+	instanceID := newInstanceID()
+	logger.Info("Instance started", zap.String("instance_id", instanceID))
+
+	shutdownTracer, err := tracing.NewTracerProvider(ctx, cfg.Tracing)
+	if err != nil {
+		logger.Error("Failed to init tracer provider", zap.Error(err))
+		return nil, err
+	}
+	logger.Info("Tracing initialized", zap.String("exporter", cfg.Tracing.Exporter))
+	manager.AddCloser("tracer", lifecycle.StopFunc(shutdownTracer))
+
 	pgx, err := postgres.NewPgxConn(cfg.Postgres)
 	if err != nil {
 		logger.Error("Failed to connect to Postgres", zap.Error(err))
 		return nil, err
 	}
 	logger.Info("Success connect to Postgres", zap.String("host", cfg.Postgres.Host), zap.Int("port", cfg.Postgres.Port))
+	manager.AddCloser("postgres", func(ctx context.Context) error {
+		pgx.Close()
+		return nil
+	})
 
 	// Run event store migrations
 	err = postgres.RunMigrations(ctx, pgx, logger)
@@ -53,6 +70,20 @@ func Initialize(ctx context.Context) (*Application, error) {
 		return nil, err
 	}
 
+	schemaRegistry, err := events.NewSchemaRegistry()
+	if err != nil {
+		logger.Error("Failed to register event schemas", zap.Error(err))
+		return nil, err
+	}
+	// revisions changed after release break consumers, appending is refused only in strict mode
+	if err := schemaRegistry.Sync(ctx, es.NewPgSchemaStore(pgx, logger)); err != nil {
+		if cfg.PgStore.SchemaValidation == es.SchemaValidationStrict {
+			logger.Error("Failed to sync event schemas", zap.Error(err))
+			return nil, err
+		}
+		logger.Warn("Failed to sync event schemas", zap.Error(err))
+	}
+
 	mongodb, err := mongodb.NewMongoDBConn(
 		ctx,
 		&cfg.MongoDB,
@@ -62,51 +93,31 @@ func Initialize(ctx context.Context) (*Application, error) {
 		return nil, err
 	}
 	logger.Info("Success connect to MongoDB", zap.String("uri", cfg.MongoDB.URI))
+	manager.AddCloser("mongodb", mongodb.Disconnect)
 
-	// init mongodb collection
-	err = mongodb.Database(cfg.MongoDB.Db).CreateCollection(ctx, mongoBankAccountsCollection)
-	if err != nil {
-		if !utils.CheckErrForMessagesCaseInSensitive(err, serviceErrors.ErrMsgMongoCollectionAlreadyExists) {
-			logger.Warn("Create Collection Failed, Collection Already Exist", zap.Error(err))
-		}
-	}
-
-	aggregateIdIndexOptions := options.Index().
-		SetSparse(true).
-		SetUnique(true)
-
-	aggregateIdIndex, err := mongodb.Database(cfg.MongoDB.Db).
-		Collection(mongoBankAccountsCollection).
-		Indexes().
-		CreateOne(ctx, mongo.IndexModel{
-			Keys:    bson.D{{Key: "aggregate_id", Value: 1}},
-			Options: aggregateIdIndexOptions,
-		})
+	mongoRepository := repository.NewBankAccountMongoRepository(
+		cfg,
+		mongodb,
+		logger,
+	)
 
-	if err != nil && !utils.CheckErrForMessagesCaseInSensitive(err, serviceErrors.ErrMsgAlreadyExists) {
-		logger.Warn("Create Aggregate ID Index Failed, Index Already exist", zap.Error(err))
+	// init mongodb collection with aggregate id and email indexes
+	if err := mongoRepository.EnsureCollection(ctx); err != nil {
+		logger.Warn("Failed to init MongoDB collection", zap.Error(err))
 	}
-	logger.Info("Created Aggregate ID Index on MongoDB", zap.String("index", aggregateIdIndex))
 
-	// Create email index for authentication
-	emailIndexOptions := options.Index().
-		SetSparse(true).
-		SetUnique(true)
-
-	emailIndex, err := mongodb.Database(cfg.MongoDB.Db).
-		Collection(mongoBankAccountsCollection).
-		Indexes().
-		CreateOne(ctx, mongo.IndexModel{
-			Keys:    bson.D{{Key: "email", Value: 1}},
-			Options: emailIndexOptions,
-		})
+	transactionRepository := repository.NewTransactionMongoRepository(
+		cfg,
+		mongodb,
+		logger,
+	)
 
-	if err != nil && !utils.CheckErrForMessagesCaseInSensitive(err, serviceErrors.ErrMsgAlreadyExists) {
-		logger.Warn("Create Email Index Failed, Index Already exist", zap.Error(err))
+	// init transactions ledger collection with paging and date range indexes
+	if err := transactionRepository.EnsureCollection(ctx); err != nil {
+		logger.Warn("Failed to init MongoDB transactions collection", zap.Error(err))
 	}
-	logger.Info("Created Email Index on MongoDB", zap.String("index", emailIndex))
 
-	list, err := mongodb.Database(cfg.MongoDB.Db).Collection(mongoBankAccountsCollection).
+	list, err := mongodb.Database(cfg.MongoDB.Db).Collection(domain.BankAccountsCollection).
 		Indexes().
 		List(ctx)
 	if err != nil {
@@ -162,7 +173,11 @@ func Initialize(ctx context.Context) (*Application, error) {
 
 		bankAccountAggregateTopic := es.GetKafkaAggregateTypeTopic(cfg.KafkaPublisherConfig, string(domain.BankAccountAggregateType))
 
-		if err := conn.CreateTopics(bankAccountAggregateTopic); err != nil {
+		payrollRunAggregateTopic := es.GetKafkaAggregateTypeTopic(cfg.KafkaPublisherConfig, string(domain.PayrollRunAggregateType))
+
+		standingOrderAggregateTopic := es.GetKafkaAggregateTypeTopic(cfg.KafkaPublisherConfig, string(domain.StandingOrderAggregateType))
+
+		if err := conn.CreateTopics(bankAccountAggregateTopic, payrollRunAggregateTopic, standingOrderAggregateTopic); err != nil {
 			logger.Error("Failed to create Kafka topics", zap.Error(err))
 			return nil, err
 		}
@@ -176,7 +191,10 @@ func Initialize(ctx context.Context) (*Application, error) {
 		logger,
 		cfg.Kafka.Brokers,
 	)
-	// Don't close producer here, it will be used throughout the application lifecycle
+	// closed on shutdown after consumers are stopped, flushing pending messages
+	manager.AddCloser("kafka_producer", func(ctx context.Context) error {
+		return kafkaProducer.Close()
+	})
 
 	eventBus := es.NewKafkaEventsBus(
 		kafkaProducer,
@@ -189,19 +207,17 @@ func Initialize(ctx context.Context) (*Application, error) {
 		serializer,
 		logger,
 		eventBus,
-	)
-
-	mongoRepository := repository.NewBankAccountMongoRepository(
-		cfg,
-		mongodb,
-		logger,
+		schemaRegistry,
 	)
 
 	bankService := service.NewBankAccountService(
+		cfg.Query,
 		logger,
 		esStore,
 		serializer,
 		mongoRepository,
+		transactionRepository,
+		repository.NewEmailReservationRepository(pgx, logger),
 	)
 
 	// Initialize Elasticsearch client
@@ -233,19 +249,259 @@ func Initialize(ctx context.Context) (*Application, error) {
 	// Create Elasticsearch repository
 	esRepository := repository.NewElasticsearchRepository(esClient, logger)
 
+	checkpointStore := es.NewPgCheckpointStore(pgx, logger)
+
+	mongoProjectionRunner := es.NewProjectionRunner(
+		cfg.Projections.Runner,
+		projection.NewBankAccountMongoProjection(
+			projection.BankAccountMongoProjectionName,
+			serializer,
+			mongoRepository,
+			logger,
+		),
+		esStore,
+		checkpointStore,
+		logger,
+	)
+
+	elasticsearchProjectionRunner := es.NewProjectionRunner(
+		cfg.Projections.Runner,
+		projection.NewBankAccountElasticsearchProjection(
+			projection.BankAccountElasticsearchProjectionName,
+			service.BankAccountIndexName,
+			serializer,
+			esRepository,
+			logger,
+		),
+		esStore,
+		checkpointStore,
+		logger,
+	)
+
+	transactionProjectionRunner := es.NewProjectionRunner(
+		cfg.Projections.Runner,
+		projection.NewTransactionMongoProjection(
+			projection.TransactionMongoProjectionName,
+			serializer,
+			transactionRepository,
+			logger,
+		),
+		esStore,
+		checkpointStore,
+		logger,
+	)
+
+	projectionController := http.NewProjectionController(
+		es.NewProjectionRegistry(
+			mongoProjectionRunner,
+			elasticsearchProjectionRunner,
+			transactionProjectionRunner,
+		),
+		logger,
+	)
+
+	mongoSubscription := mongo_subscription.NewBankAccountMongoSubscription(
+		logger,
+		cfg,
+		bankService,
+		mongoProjectionRunner,
+		serializer,
+		eventBus,
+	)
+
+	mongoConsumerGroup := kafkaClient.NewConsumerGroup(
+		cfg.Kafka.Brokers,
+		"bank_account_mongo_subscription_group",
+		mongoSubscription.ProcessMessagesErrGroup,
+		logger,
+	)
+
+	elasticsearchSubscription := elasticsearch_subscription.NewBankAccountElasticsearchSubscription(
+		logger,
+		cfg,
+		elasticsearchProjectionRunner,
+	)
+
+	elasticsearchConsumerGroup := kafkaClient.NewConsumerGroup(
+		cfg.Kafka.Brokers,
+		"bank_account_elasticsearch_subscription_group",
+		elasticsearchSubscription.ProcessMessagesErrGroup,
+		logger,
+	)
+
+	transactionsSubscription := transactions_subscription.NewTransactionsSubscription(
+		logger,
+		cfg,
+		transactionProjectionRunner,
+	)
+
+	transactionsConsumerGroup := kafkaClient.NewConsumerGroup(
+		cfg.Kafka.Brokers,
+		"bank_account_transactions_subscription_group",
+		transactionsSubscription.ProcessMessagesErrGroup,
+		logger,
+	)
+
+	healthRegistry := newHealthRegistry(
+		cfg,
+		pgx,
+		mongodb,
+		esClient,
+		mongoConsumerGroup,
+		elasticsearchConsumerGroup,
+		transactionsConsumerGroup,
+	)
+
 	// Create replay service
 	replayService := service.NewReplayService(
+		elasticsearchProjectionRunner,
+		mongoProjectionRunner,
+		func(indexName string) *es.ProjectionRunner {
+			return es.NewProjectionRunner(
+				cfg.Projections.Runner,
+				projection.NewBankAccountElasticsearchProjection(
+					projection.BankAccountElasticsearchProjectionName+":"+indexName,
+					indexName,
+					serializer,
+					esRepository,
+					logger,
+				),
+				esStore,
+				checkpointStore,
+				logger,
+			)
+		},
+		func(collection string) *es.ProjectionRunner {
+			return es.NewProjectionRunner(
+				cfg.Projections.Runner,
+				projection.NewBankAccountMongoProjection(
+					projection.BankAccountMongoProjectionName+":"+collection,
+					serializer,
+					mongoRepository.WithCollection(collection),
+					logger,
+				),
+				esStore,
+				checkpointStore,
+				logger,
+			)
+		},
 		esStore,
+		checkpointStore,
+		es.NewPgReplayJobStore(pgx, logger),
 		esRepository,
+		mongoRepository,
+		healthRegistry,
+		instanceID,
+		logger,
+	)
+
+	// index is kept up to date by the live projection, create it with mapping before first event
+	if err := replayService.EnsureIndex(ctx); err != nil {
+		logger.Warn("Failed to create Elasticsearch index - live projection will retry on events", zap.Error(err))
+	}
+	manager.Add("replay_jobs", replayService.Run, nil)
+
+	consistencyService := service.NewConsistencyService(
+		esStore,
+		serializer,
+		mongoRepository,
+		esRepository,
+		mongoProjectionRunner,
+		elasticsearchProjectionRunner,
+		repository.NewConsistencyCheckRepository(pgx, logger),
+		instanceID,
+		logger,
+	)
+	manager.Add("consistency_checks", consistencyService.Run, nil)
+
+	consistencyController := http.NewConsistencyController(
+		consistencyService,
+		logger,
+	)
+
+	payrollService := service.NewPayrollService(
+		esStore,
+		serializer,
+		bankService.Commands.DepositeBalance,
+		repository.NewPayrollRunLeaseRepository(pgx, logger),
+		instanceID,
+		logger,
+	)
+	manager.Add("payroll_runs", payrollService.Run, nil)
+
+	payrollController := http.NewPayrollController(
+		payrollService,
+		logger,
+	)
+
+	standingOrderService := service.NewStandingOrderService(
+		cfg.Scheduler,
+		esStore,
+		serializer,
+		bankService.Commands,
+		repository.NewStandingOrderScheduleRepository(pgx, logger),
+		logger,
+	)
+	manager.Add("standing_orders", standingOrderService.Run, nil)
+
+	standingOrderController := http.NewStandingOrderController(
+		standingOrderService,
+		logger,
+	)
+
+	interestService := service.NewInterestService(
+		cfg.Interest,
+		esStore,
+		serializer,
+		logger,
+	)
+	manager.Add("interest_accrual", interestService.Run, nil)
+
+	holdExpiryService := service.NewHoldExpiryService(
+		cfg.Holds,
+		esStore,
+		mongoRepository,
+		logger,
+	)
+	manager.Add("hold_expiry", holdExpiryService.Run, nil)
+
+	interestController := http.NewInterestController(
+		interestService,
+		logger,
+	)
+
+	statementService := service.NewStatementService(
+		esStore,
 		serializer,
 		logger,
 	)
+	statementController := http.NewStatementController(
+		statementService,
+		logger,
+	)
+
+	eventArchiveService := service.NewEventArchiveService(
+		esStore,
+		eventBus,
+		logger,
+	)
+	eventArchiveController := http.NewEventArchiveController(
+		eventArchiveService,
+		logger,
+	)
+
+	eventCatalogController := http.NewEventCatalogController(
+		service.NewEventCatalogService(schemaRegistry, cfg.KafkaPublisherConfig),
+		logger,
+	)
 
 	// Create auth service
 	authService := service.NewAuthService(
 		bankService, // QueryService interface
 		bankService, // CommandBus interface
+		esStore,
 		cfg.JWT.SecretKey,
+		cfg.JWT.AdminAccountIDs,
 		logger,
 	)
 
@@ -264,35 +520,22 @@ func Initialize(ctx context.Context) (*Application, error) {
 		logger,
 	)
 
+	healthController := http.NewHealthController(healthRegistry)
+
 	httpServer := http.NewHTTPServer(
 		cfg.Server,
 		controller,
 		authController,
 		authMiddleware,
-		logger,
-	)
-
-	mongoProjection := projection.NewBankAccountMongoProjection(
-		serializer,
-		mongoRepository,
-		logger,
-	)
-
-	mongoSubscription := mongo_subscription.NewBankAccountMongoSubscription(
-		logger,
-		cfg,
-		bankService,
-		mongoProjection,
-		serializer,
-		mongoRepository,
-		esStore,
-		eventBus,
-	)
-
-	mongoConsumerGroup := kafkaClient.NewConsumerGroup(
-		cfg.Kafka.Brokers,
-		"bank_account_mongo_subscription_group",
-		mongoSubscription.ProcessMessagesErrGroup,
+		healthController,
+		projectionController,
+		consistencyController,
+		payrollController,
+		standingOrderController,
+		interestController,
+		statementController,
+		eventArchiveController,
+		eventCatalogController,
 		logger,
 	)
 
@@ -300,6 +543,22 @@ func Initialize(ctx context.Context) (*Application, error) {
 		cfg,
 		httpServer,
 		mongoConsumerGroup,
+		elasticsearchConsumerGroup,
+		transactionsConsumerGroup,
+		mongoProjectionRunner,
+		elasticsearchProjectionRunner,
+		transactionProjectionRunner,
+		manager,
 		logger,
 	), nil
 }
+
+// newInstanceID returns the host name with a random suffix, so restarted processes of the same host
+// do not take over jobs of their predecessor.
+func newInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "es-demo"
+	}
+	return hostname + "-" + uuid.NewV4().String()[:8]
+}
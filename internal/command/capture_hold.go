@@ -0,0 +1,64 @@
+package command
+
+import (
+	"context"
+
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+type CaptureHoldCommand struct {
+	AggregateID string `json:"aggregate_id" validate:"required,gte=0"`
+	HoldID      string `json:"hold_id" validate:"required"`
+	// Amount in minor units of the account currency, zero captures the whole hold
+	Amount    int64  `json:"amount" validate:"gte=0"`
+	PaymentID string `json:"payment_id" validate:"required,gte=0"`
+}
+
+type CaptureHold interface {
+	// Handle returns version of the aggregate after the hold was captured.
+	Handle(ctx context.Context, cmd CaptureHoldCommand) (uint64, error)
+}
+
+type captureHoldCmdHandler struct {
+	aggregateStore es.AggregateStore
+	logger         *zap.Logger
+}
+
+func NewCaptureHoldCmdHandler(
+	aggregateStore es.AggregateStore,
+	logger *zap.Logger,
+) CaptureHold {
+	return &captureHoldCmdHandler{
+		aggregateStore: aggregateStore,
+		logger:         logger,
+	}
+}
+
+func (h *captureHoldCmdHandler) Handle(ctx context.Context, cmd CaptureHoldCommand) (uint64, error) {
+	h.logger.Info("Handling CaptureHoldCommand", zap.String("id", cmd.AggregateID), zap.String("hold_id", cmd.HoldID))
+	ctx, span := tracing.StartSpan(ctx, "captureHoldCmdHandler.Handle")
+	span.SetAttributes(attribute.String("aggregate_id", cmd.AggregateID), attribute.String("hold_id", cmd.HoldID))
+	defer span.End()
+
+	bankAccountAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
+	if err := h.aggregateStore.Load(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if bankAccountAggregate.GetVersion() == 0 {
+		return 0, tracing.TraceErr(span, bankAccountErrors.ErrBankAccountNotFound)
+	}
+
+	if err := bankAccountAggregate.CaptureHold(ctx, cmd.HoldID, cmd.Amount, cmd.PaymentID); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+
+	if err := h.aggregateStore.Save(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	return bankAccountAggregate.GetVersion(), nil
+}
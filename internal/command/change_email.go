@@ -0,0 +1,82 @@
+package command
+
+import (
+	"context"
+
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// ChangeEmailCommand replaces the login email, the current password is verified before the change.
+type ChangeEmailCommand struct {
+	AggregateID     string `json:"aggregate_id" validate:"required,gte=0"`
+	Email           string `json:"email" validate:"required,email"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+type ChangeEmail interface {
+	// Handle returns version of the aggregate after the email was changed.
+	Handle(ctx context.Context, cmd ChangeEmailCommand) (uint64, error)
+}
+
+type changeEmailCmdHandler struct {
+	aggregateStore    es.AggregateStore
+	emailReservations domain.EmailReservationRepository
+	logger            *zap.Logger
+}
+
+func NewChangeEmailCmdHandler(
+	aggregateStore es.AggregateStore,
+	emailReservations domain.EmailReservationRepository,
+	logger *zap.Logger,
+) ChangeEmail {
+	return &changeEmailCmdHandler{
+		aggregateStore:    aggregateStore,
+		emailReservations: emailReservations,
+		logger:            logger,
+	}
+}
+
+func (h *changeEmailCmdHandler) Handle(ctx context.Context, cmd ChangeEmailCommand) (uint64, error) {
+	h.logger.Info("Handling ChangeEmailCommand", zap.String("id", cmd.AggregateID))
+	ctx, span := tracing.StartSpan(ctx, "changeEmailCmdHandler.Handle")
+	span.SetAttributes(attribute.String("aggregate_id", cmd.AggregateID))
+	defer span.End()
+
+	bankAccountAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
+	if err := h.aggregateStore.Load(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if bankAccountAggregate.GetVersion() == 0 {
+		return 0, tracing.TraceErr(span, bankAccountErrors.ErrBankAccountNotFound)
+	}
+
+	oldEmail := bankAccountAggregate.BankAccount.Email
+	if err := bankAccountAggregate.ChangeEmail(ctx, cmd.Email, cmd.CurrentPassword); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if len(bankAccountAggregate.GetChanges()) == 0 {
+		return bankAccountAggregate.GetVersion(), nil
+	}
+
+	// the email is reserved before the change is saved, concurrent changes to the same email fail on the reservation
+	if err := h.emailReservations.Reserve(ctx, cmd.Email, cmd.AggregateID); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if err := h.aggregateStore.Save(ctx, bankAccountAggregate); err != nil {
+		if releaseErr := h.emailReservations.Release(context.WithoutCancel(ctx), cmd.Email, cmd.AggregateID); releaseErr != nil {
+			h.logger.Error("Failed to release reserved email", zap.String("id", cmd.AggregateID), zap.Error(releaseErr))
+		}
+		return 0, tracing.TraceErr(span, err)
+	}
+
+	// the old email stays reserved when releasing fails, it is not usable by other accounts then
+	if err := h.emailReservations.Release(ctx, oldEmail, cmd.AggregateID); err != nil {
+		h.logger.Error("Failed to release old email", zap.String("id", cmd.AggregateID), zap.Error(err))
+	}
+	return bankAccountAggregate.GetVersion(), nil
+}
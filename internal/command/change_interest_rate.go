@@ -0,0 +1,63 @@
+package command
+
+import (
+	"context"
+
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+type ChangeInterestRateCommand struct {
+	AggregateID string `json:"aggregate_id" validate:"required,gte=0"`
+	// RateBps annual interest rate in basis points, zero stops accrual
+	RateBps int64  `json:"rate_bps" validate:"gte=0,lte=10000"`
+	Reason  string `json:"reason" validate:"required,max=500"`
+}
+
+type ChangeInterestRate interface {
+	// Handle returns version of the aggregate after the rate was changed.
+	Handle(ctx context.Context, cmd ChangeInterestRateCommand) (uint64, error)
+}
+
+type changeInterestRateCmdHandler struct {
+	aggregateStore es.AggregateStore
+	logger         *zap.Logger
+}
+
+func NewChangeInterestRateCmdHandler(
+	aggregateStore es.AggregateStore,
+	logger *zap.Logger,
+) ChangeInterestRate {
+	return &changeInterestRateCmdHandler{
+		aggregateStore: aggregateStore,
+		logger:         logger,
+	}
+}
+
+func (c *changeInterestRateCmdHandler) Handle(ctx context.Context, cmd ChangeInterestRateCommand) (uint64, error) {
+	c.logger.Info("Handling ChangeInterestRateCommand", zap.String("id", cmd.AggregateID), zap.Int64("rate_bps", cmd.RateBps))
+	ctx, span := tracing.StartSpan(ctx, "changeInterestRateCmdHandler.Handle")
+	span.SetAttributes(attribute.String("aggregate_id", cmd.AggregateID))
+	defer span.End()
+
+	bankAccountAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
+	if err := c.aggregateStore.Load(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if bankAccountAggregate.GetVersion() == 0 {
+		return 0, tracing.TraceErr(span, bankAccountErrors.ErrBankAccountNotFound)
+	}
+
+	if err := bankAccountAggregate.ChangeInterestRate(ctx, cmd.RateBps, cmd.Reason); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+
+	if err := c.aggregateStore.Save(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	return bankAccountAggregate.GetVersion(), nil
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"context"
+
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// ChangePasswordCommand replaces the password, tokens issued before the change are revoked.
+type ChangePasswordCommand struct {
+	AggregateID     string `json:"aggregate_id" validate:"required,gte=0"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6,nefield=CurrentPassword"`
+}
+
+type ChangePassword interface {
+	// Handle returns version of the aggregate after the password was changed.
+	Handle(ctx context.Context, cmd ChangePasswordCommand) (uint64, error)
+}
+
+type changePasswordCmdHandler struct {
+	aggregateStore es.AggregateStore
+	logger         *zap.Logger
+}
+
+func NewChangePasswordCmdHandler(
+	aggregateStore es.AggregateStore,
+	logger *zap.Logger,
+) ChangePassword {
+	return &changePasswordCmdHandler{
+		aggregateStore: aggregateStore,
+		logger:         logger,
+	}
+}
+
+func (h *changePasswordCmdHandler) Handle(ctx context.Context, cmd ChangePasswordCommand) (uint64, error) {
+	h.logger.Info("Handling ChangePasswordCommand", zap.String("id", cmd.AggregateID))
+	ctx, span := tracing.StartSpan(ctx, "changePasswordCmdHandler.Handle")
+	span.SetAttributes(attribute.String("aggregate_id", cmd.AggregateID))
+	defer span.End()
+
+	bankAccountAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
+	if err := h.aggregateStore.Load(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if bankAccountAggregate.GetVersion() == 0 {
+		return 0, tracing.TraceErr(span, bankAccountErrors.ErrBankAccountNotFound)
+	}
+
+	if err := bankAccountAggregate.ChangePassword(ctx, cmd.CurrentPassword, cmd.NewPassword); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+
+	if err := h.aggregateStore.Save(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	return bankAccountAggregate.GetVersion(), nil
+}
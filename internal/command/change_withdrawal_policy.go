@@ -0,0 +1,71 @@
+package command
+
+import (
+	"context"
+
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+type ChangeWithdrawalPolicyCommand struct {
+	AggregateID string `json:"aggregate_id" validate:"required,gte=0"`
+	// Limits in minor units of the account currency, zero means no limit and no overdraft
+	OverdraftLimit    int64  `json:"overdraft_limit" validate:"gte=0"`
+	MaxPerTransaction int64  `json:"max_per_transaction" validate:"gte=0"`
+	DailyLimit        int64  `json:"daily_limit" validate:"gte=0"`
+	MonthlyLimit      int64  `json:"monthly_limit" validate:"gte=0"`
+	Reason            string `json:"reason" validate:"required,max=500"`
+}
+
+type ChangeWithdrawalPolicy interface {
+	// Handle returns version of the aggregate after the policy was changed.
+	Handle(ctx context.Context, cmd ChangeWithdrawalPolicyCommand) (uint64, error)
+}
+
+type changeWithdrawalPolicyCmdHandler struct {
+	aggregateStore es.AggregateStore
+	logger         *zap.Logger
+}
+
+func NewChangeWithdrawalPolicyCmdHandler(
+	aggregateStore es.AggregateStore,
+	logger *zap.Logger,
+) ChangeWithdrawalPolicy {
+	return &changeWithdrawalPolicyCmdHandler{
+		aggregateStore: aggregateStore,
+		logger:         logger,
+	}
+}
+
+func (f *changeWithdrawalPolicyCmdHandler) Handle(ctx context.Context, cmd ChangeWithdrawalPolicyCommand) (uint64, error) {
+	f.logger.Info("Handling ChangeWithdrawalPolicyCommand", zap.String("id", cmd.AggregateID))
+	ctx, span := tracing.StartSpan(ctx, "changeWithdrawalPolicyCmdHandler.Handle")
+	span.SetAttributes(attribute.String("aggregate_id", cmd.AggregateID))
+	defer span.End()
+
+	bankAccountAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
+	if err := f.aggregateStore.Load(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if bankAccountAggregate.GetVersion() == 0 {
+		return 0, tracing.TraceErr(span, bankAccountErrors.ErrBankAccountNotFound)
+	}
+
+	if err := bankAccountAggregate.ChangeWithdrawalPolicy(ctx, domain.WithdrawalPolicy{
+		OverdraftLimit:    cmd.OverdraftLimit,
+		MaxPerTransaction: cmd.MaxPerTransaction,
+		DailyLimit:        cmd.DailyLimit,
+		MonthlyLimit:      cmd.MonthlyLimit,
+	}, cmd.Reason); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+
+	if err := f.aggregateStore.Save(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	return bankAccountAggregate.GetVersion(), nil
+}
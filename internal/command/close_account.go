@@ -0,0 +1,63 @@
+package command
+
+import (
+	"context"
+
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+type CloseAccountCommand struct {
+	AggregateID string `json:"aggregate_id" validate:"required,gte=0"`
+	Reason      string `json:"reason" validate:"required,max=500"`
+	// PayoutTarget where the remaining balance is paid out, required unless the balance is zero
+	PayoutTarget string `json:"payout_target" validate:"omitempty,max=200"`
+}
+
+type CloseAccount interface {
+	// Handle returns version of the aggregate after the account was closed.
+	Handle(ctx context.Context, cmd CloseAccountCommand) (uint64, error)
+}
+
+type closeAccountCmdHandler struct {
+	aggregateStore es.AggregateStore
+	logger         *zap.Logger
+}
+
+func NewCloseAccountCmdHandler(
+	aggregateStore es.AggregateStore,
+	logger *zap.Logger,
+) CloseAccount {
+	return &closeAccountCmdHandler{
+		aggregateStore: aggregateStore,
+		logger:         logger,
+	}
+}
+
+func (f *closeAccountCmdHandler) Handle(ctx context.Context, cmd CloseAccountCommand) (uint64, error) {
+	f.logger.Info("Handling CloseAccountCommand", zap.String("id", cmd.AggregateID))
+	ctx, span := tracing.StartSpan(ctx, "closeAccountCmdHandler.Handle")
+	span.SetAttributes(attribute.String("aggregate_id", cmd.AggregateID))
+	defer span.End()
+
+	bankAccountAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
+	if err := f.aggregateStore.Load(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if bankAccountAggregate.GetVersion() == 0 {
+		return 0, tracing.TraceErr(span, bankAccountErrors.ErrBankAccountNotFound)
+	}
+
+	if err := bankAccountAggregate.CloseAccount(ctx, cmd.Reason, cmd.PayoutTarget); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+
+	if err := f.aggregateStore.Save(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	return bankAccountAggregate.GetVersion(), nil
+}
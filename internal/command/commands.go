@@ -4,16 +4,52 @@ type BankAccountCommand struct {
 	CreateBankAccount
 	DepositeBalance
 	WithdrawBalance
+	FreezeAccount
+	UnfreezeAccount
+	CloseAccount
+	ChangeWithdrawalPolicy
+	ChangeInterestRate
+	PlaceHold
+	CaptureHold
+	ReleaseHold
+	ReverseTransaction
+	ChangeEmail
+	UpdateProfile
+	ChangePassword
 }
 
 func NewBankAccountCommand(
 	createBankAccount CreateBankAccount,
 	depositeBalance DepositeBalance,
 	withdrawBalance WithdrawBalance,
+	freezeAccount FreezeAccount,
+	unfreezeAccount UnfreezeAccount,
+	closeAccount CloseAccount,
+	changeWithdrawalPolicy ChangeWithdrawalPolicy,
+	changeInterestRate ChangeInterestRate,
+	placeHold PlaceHold,
+	captureHold CaptureHold,
+	releaseHold ReleaseHold,
+	reverseTransaction ReverseTransaction,
+	changeEmail ChangeEmail,
+	updateProfile UpdateProfile,
+	changePassword ChangePassword,
 ) *BankAccountCommand {
 	return &BankAccountCommand{
-		CreateBankAccount: createBankAccount,
-		DepositeBalance:   depositeBalance,
-		WithdrawBalance:   withdrawBalance,
+		CreateBankAccount:      createBankAccount,
+		DepositeBalance:        depositeBalance,
+		WithdrawBalance:        withdrawBalance,
+		FreezeAccount:          freezeAccount,
+		UnfreezeAccount:        unfreezeAccount,
+		CloseAccount:           closeAccount,
+		ChangeWithdrawalPolicy: changeWithdrawalPolicy,
+		ChangeInterestRate:     changeInterestRate,
+		PlaceHold:              placeHold,
+		CaptureHold:            captureHold,
+		ReleaseHold:            releaseHold,
+		ReverseTransaction:     reverseTransaction,
+		ChangeEmail:            changeEmail,
+		UpdateProfile:          updateProfile,
+		ChangePassword:         changePassword,
 	}
 }
@@ -6,6 +6,8 @@ import (
 	"github.com/th1enq/es-demo/internal/domain"
 	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
 	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -14,37 +16,48 @@ type CreateBankAccountCommand struct {
 	Email       string `json:"email" validate:"required,gte=0,email"`
 	FirstName   string `json:"first_name" validate:"required,gte=0"`
 	LastName    string `json:"last_name" validate:"required,gte=0"`
-	Balance     int64  `json:"balance" validate:"required,gte=0"`
-	Password    string `json:"password" validate:"required,min=6"` // Add password field
+	// Currency ISO 4217 code of the account, defaults to VND
+	Currency string `json:"currency" validate:"omitempty,iso4217"`
+	// Balance initial balance in minor units of Currency
+	Balance  int64  `json:"balance" validate:"required,gte=0"`
+	Password string `json:"password" validate:"required,min=6"` // Add password field
 }
 
 type CreateBankAccount interface {
-	Handle(ctx context.Context, cmd CreateBankAccountCommand) error
+	// Handle returns version of the created aggregate.
+	Handle(ctx context.Context, cmd CreateBankAccountCommand) (uint64, error)
 }
 
 type createBankAccount struct {
-	aggregateStore es.AggregateStore
-	logger         *zap.Logger
+	aggregateStore    es.AggregateStore
+	emailReservations domain.EmailReservationRepository
+	logger            *zap.Logger
 }
 
 func NewCreateBankAccountCmdHandler(
 	aggregateStore es.AggregateStore,
+	emailReservations domain.EmailReservationRepository,
 	logger *zap.Logger,
 ) CreateBankAccount {
 	return &createBankAccount{
-		aggregateStore: aggregateStore,
-		logger:         logger,
+		aggregateStore:    aggregateStore,
+		emailReservations: emailReservations,
+		logger:            logger,
 	}
 }
 
-func (c *createBankAccount) Handle(ctx context.Context, cmd CreateBankAccountCommand) error {
+func (c *createBankAccount) Handle(ctx context.Context, cmd CreateBankAccountCommand) (uint64, error) {
 	c.logger.Info("Handling CreateBankAccountCommand", zap.String("id", cmd.AggregateID))
+	ctx, span := tracing.StartSpan(ctx, "createBankAccount.Handle")
+	span.SetAttributes(attribute.String("aggregate_id", cmd.AggregateID))
+	defer span.End()
+
 	exists, err := c.aggregateStore.Exists(ctx, cmd.AggregateID)
 	if err != nil {
-		return err
+		return 0, tracing.TraceErr(span, err)
 	}
 	if exists {
-		return bankAccountErrors.ErrBankAccountAlreadyExists
+		return 0, tracing.TraceErr(span, bankAccountErrors.ErrBankAccountAlreadyExists)
 	}
 
 	bankAccountAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
@@ -53,11 +66,23 @@ func (c *createBankAccount) Handle(ctx context.Context, cmd CreateBankAccountCom
 		cmd.Email,
 		cmd.FirstName,
 		cmd.LastName,
+		cmd.Currency,
 		cmd.Balance,
 		cmd.Password, // Add password parameter
 	)
 	if err != nil {
-		return err
+		return 0, tracing.TraceErr(span, err)
+	}
+
+	// the email is reserved before the account is saved, registrations with the same email fail on the reservation
+	if err := c.emailReservations.Reserve(ctx, cmd.Email, cmd.AggregateID); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if err := c.aggregateStore.Save(ctx, bankAccountAggregate); err != nil {
+		if releaseErr := c.emailReservations.Release(context.WithoutCancel(ctx), cmd.Email, cmd.AggregateID); releaseErr != nil {
+			c.logger.Error("Failed to release reserved email", zap.String("id", cmd.AggregateID), zap.Error(releaseErr))
+		}
+		return 0, tracing.TraceErr(span, err)
 	}
-	return c.aggregateStore.Save(ctx, bankAccountAggregate)
+	return bankAccountAggregate.GetVersion(), nil
 }
@@ -5,17 +5,23 @@ import (
 
 	"github.com/th1enq/es-demo/internal/domain"
 	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
 type DepositeBalanceCommand struct {
 	AggregateID string `json:"aggregate_id" validate:"required,gte=0"`
-	Amount      int64  `json:"amount" validate:"required,gt=0"`
-	PaymentID   string `json:"payment_id" validate:"required,gte=0"`
+	// Amount in minor units of Currency
+	Amount int64 `json:"amount" validate:"required,gt=0"`
+	// Currency ISO 4217 code, must match the account currency, defaults to it
+	Currency  string `json:"currency" validate:"omitempty,iso4217"`
+	PaymentID string `json:"payment_id" validate:"required,gte=0"`
 }
 
 type DepositeBalance interface {
-	Handle(ctx context.Context, cmd DepositeBalanceCommand) error
+	// Handle returns version of the aggregate after the deposit.
+	Handle(ctx context.Context, cmd DepositeBalanceCommand) (uint64, error)
 }
 
 type depositeBalanceCmdHandler struct {
@@ -33,21 +39,29 @@ func NewDepositeBalanceCmdHandler(
 	}
 }
 
-func (d *depositeBalanceCmdHandler) Handle(ctx context.Context, cmd DepositeBalanceCommand) error {
+func (d *depositeBalanceCmdHandler) Handle(ctx context.Context, cmd DepositeBalanceCommand) (uint64, error) {
 	d.logger.Info("Handling DepositeBalanceCommand", zap.String("id", cmd.AggregateID))
+	ctx, span := tracing.StartSpan(ctx, "depositeBalanceCmdHandler.Handle")
+	span.SetAttributes(attribute.String("aggregate_id", cmd.AggregateID))
+	defer span.End()
+
 	bankAccoutAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
 	err := d.aggregateStore.Load(ctx, bankAccoutAggregate)
 	if err != nil {
-		return err
+		return 0, tracing.TraceErr(span, err)
 	}
 
 	if err := bankAccoutAggregate.DepositBalance(
 		ctx,
 		cmd.Amount,
+		cmd.Currency,
 		cmd.PaymentID,
 	); err != nil {
-		return err
+		return 0, tracing.TraceErr(span, err)
 	}
 
-	return d.aggregateStore.Save(ctx, bankAccoutAggregate)
+	if err := d.aggregateStore.Save(ctx, bankAccoutAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	return bankAccoutAggregate.GetVersion(), nil
 }
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"context"
+
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+type FreezeAccountCommand struct {
+	AggregateID string `json:"aggregate_id" validate:"required,gte=0"`
+	Reason      string `json:"reason" validate:"required,max=500"`
+}
+
+type FreezeAccount interface {
+	// Handle returns version of the aggregate after the account was frozen.
+	Handle(ctx context.Context, cmd FreezeAccountCommand) (uint64, error)
+}
+
+type freezeAccountCmdHandler struct {
+	aggregateStore es.AggregateStore
+	logger         *zap.Logger
+}
+
+func NewFreezeAccountCmdHandler(
+	aggregateStore es.AggregateStore,
+	logger *zap.Logger,
+) FreezeAccount {
+	return &freezeAccountCmdHandler{
+		aggregateStore: aggregateStore,
+		logger:         logger,
+	}
+}
+
+func (f *freezeAccountCmdHandler) Handle(ctx context.Context, cmd FreezeAccountCommand) (uint64, error) {
+	f.logger.Info("Handling FreezeAccountCommand", zap.String("id", cmd.AggregateID))
+	ctx, span := tracing.StartSpan(ctx, "freezeAccountCmdHandler.Handle")
+	span.SetAttributes(attribute.String("aggregate_id", cmd.AggregateID))
+	defer span.End()
+
+	bankAccountAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
+	if err := f.aggregateStore.Load(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if bankAccountAggregate.GetVersion() == 0 {
+		return 0, tracing.TraceErr(span, bankAccountErrors.ErrBankAccountNotFound)
+	}
+
+	if err := bankAccountAggregate.FreezeAccount(ctx, cmd.Reason); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+
+	if err := f.aggregateStore.Save(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	return bankAccountAggregate.GetVersion(), nil
+}
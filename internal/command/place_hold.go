@@ -0,0 +1,69 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+type PlaceHoldCommand struct {
+	AggregateID string `json:"aggregate_id" validate:"required,gte=0"`
+	HoldID      string `json:"hold_id" validate:"required,max=100"`
+	// Amount in minor units of Currency
+	Amount int64 `json:"amount" validate:"required,gt=0"`
+	// Currency ISO 4217 code, must match the account currency, defaults to it
+	Currency  string `json:"currency" validate:"omitempty,iso4217"`
+	Reference string `json:"reference" validate:"max=500"`
+	// ExpiresAt zero means the default hold duration
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type PlaceHold interface {
+	// Handle returns version of the aggregate after the hold was placed.
+	Handle(ctx context.Context, cmd PlaceHoldCommand) (uint64, error)
+}
+
+type placeHoldCmdHandler struct {
+	aggregateStore es.AggregateStore
+	logger         *zap.Logger
+}
+
+func NewPlaceHoldCmdHandler(
+	aggregateStore es.AggregateStore,
+	logger *zap.Logger,
+) PlaceHold {
+	return &placeHoldCmdHandler{
+		aggregateStore: aggregateStore,
+		logger:         logger,
+	}
+}
+
+func (h *placeHoldCmdHandler) Handle(ctx context.Context, cmd PlaceHoldCommand) (uint64, error) {
+	h.logger.Info("Handling PlaceHoldCommand", zap.String("id", cmd.AggregateID), zap.String("hold_id", cmd.HoldID))
+	ctx, span := tracing.StartSpan(ctx, "placeHoldCmdHandler.Handle")
+	span.SetAttributes(attribute.String("aggregate_id", cmd.AggregateID), attribute.String("hold_id", cmd.HoldID))
+	defer span.End()
+
+	bankAccountAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
+	if err := h.aggregateStore.Load(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if bankAccountAggregate.GetVersion() == 0 {
+		return 0, tracing.TraceErr(span, bankAccountErrors.ErrBankAccountNotFound)
+	}
+
+	if err := bankAccountAggregate.PlaceHold(ctx, cmd.HoldID, cmd.Amount, cmd.Currency, cmd.Reference, cmd.ExpiresAt); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+
+	if err := h.aggregateStore.Save(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	return bankAccountAggregate.GetVersion(), nil
+}
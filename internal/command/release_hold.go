@@ -0,0 +1,62 @@
+package command
+
+import (
+	"context"
+
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+type ReleaseHoldCommand struct {
+	AggregateID string `json:"aggregate_id" validate:"required,gte=0"`
+	HoldID      string `json:"hold_id" validate:"required"`
+	Reason      string `json:"reason" validate:"max=500"`
+}
+
+type ReleaseHold interface {
+	// Handle returns version of the aggregate after the hold was released.
+	Handle(ctx context.Context, cmd ReleaseHoldCommand) (uint64, error)
+}
+
+type releaseHoldCmdHandler struct {
+	aggregateStore es.AggregateStore
+	logger         *zap.Logger
+}
+
+func NewReleaseHoldCmdHandler(
+	aggregateStore es.AggregateStore,
+	logger *zap.Logger,
+) ReleaseHold {
+	return &releaseHoldCmdHandler{
+		aggregateStore: aggregateStore,
+		logger:         logger,
+	}
+}
+
+func (h *releaseHoldCmdHandler) Handle(ctx context.Context, cmd ReleaseHoldCommand) (uint64, error) {
+	h.logger.Info("Handling ReleaseHoldCommand", zap.String("id", cmd.AggregateID), zap.String("hold_id", cmd.HoldID))
+	ctx, span := tracing.StartSpan(ctx, "releaseHoldCmdHandler.Handle")
+	span.SetAttributes(attribute.String("aggregate_id", cmd.AggregateID), attribute.String("hold_id", cmd.HoldID))
+	defer span.End()
+
+	bankAccountAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
+	if err := h.aggregateStore.Load(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if bankAccountAggregate.GetVersion() == 0 {
+		return 0, tracing.TraceErr(span, bankAccountErrors.ErrBankAccountNotFound)
+	}
+
+	if err := bankAccountAggregate.ReleaseHold(ctx, cmd.HoldID, cmd.Reason); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+
+	if err := h.aggregateStore.Save(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	return bankAccountAggregate.GetVersion(), nil
+}
@@ -0,0 +1,169 @@
+package command
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// ReverseTransactionCommand reverses the deposit or withdrawal recorded by EventID, or rolls the account back to
+// Version reversing every deposit and withdrawal recorded after it, newest first. Exactly one of them is set.
+// History is never changed, reversals are recorded as compensating events referencing the originals.
+// Reason is required, it is recorded with every compensating event for audit.
+type ReverseTransactionCommand struct {
+	AggregateID string `json:"aggregate_id" validate:"required,gte=0"`
+	EventID     string `json:"event_id" validate:"required_without=Version,excluded_with=Version"`
+	Version     uint64 `json:"version" validate:"required_without=EventID,excluded_with=EventID"`
+	Reason      string `json:"reason" validate:"required,max=500"`
+}
+
+type ReverseTransaction interface {
+	// Handle returns version of the aggregate after the reversal.
+	Handle(ctx context.Context, cmd ReverseTransactionCommand) (uint64, error)
+}
+
+type reverseTransactionCmdHandler struct {
+	aggregateStore es.AggregateStore
+	serializer     es.Serializer
+	logger         *zap.Logger
+}
+
+func NewReverseTransactionCmdHandler(
+	aggregateStore es.AggregateStore,
+	serializer es.Serializer,
+	logger *zap.Logger,
+) ReverseTransaction {
+	return &reverseTransactionCmdHandler{
+		aggregateStore: aggregateStore,
+		serializer:     serializer,
+		logger:         logger,
+	}
+}
+
+func (h *reverseTransactionCmdHandler) Handle(ctx context.Context, cmd ReverseTransactionCommand) (uint64, error) {
+	h.logger.Info("Handling ReverseTransactionCommand",
+		zap.String("id", cmd.AggregateID),
+		zap.String("event_id", cmd.EventID),
+		zap.Uint64("version", cmd.Version))
+	ctx, span := tracing.StartSpan(ctx, "reverseTransactionCmdHandler.Handle")
+	span.SetAttributes(
+		attribute.String("aggregate_id", cmd.AggregateID),
+		attribute.String("event_id", cmd.EventID),
+		attribute.Int64("version", int64(cmd.Version)),
+	)
+	defer span.End()
+
+	if (cmd.EventID == "") == (cmd.Version == 0) {
+		return 0, tracing.TraceErr(span, errors.Wrap(bankAccountErrors.ErrInvalidReversal, "either event id or version is required"))
+	}
+	if strings.TrimSpace(cmd.Reason) == "" {
+		return 0, tracing.TraceErr(span, errors.Wrap(bankAccountErrors.ErrInvalidReversal, "reason is required"))
+	}
+
+	bankAccountAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
+	if err := h.aggregateStore.Load(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if bankAccountAggregate.GetVersion() == 0 {
+		return 0, tracing.TraceErr(span, bankAccountErrors.ErrBankAccountNotFound)
+	}
+	if cmd.Version >= bankAccountAggregate.GetVersion() {
+		return 0, tracing.TraceErr(span, errors.Wrapf(bankAccountErrors.ErrInvalidReversal, "version: %d, current version: %d", cmd.Version, bankAccountAggregate.GetVersion()))
+	}
+
+	accountEvents, err := h.aggregateStore.LoadEvents(ctx, cmd.AggregateID)
+	if err != nil {
+		return 0, tracing.TraceErr(span, errors.Wrapf(err, "aggregateStore.LoadEvents aggregateID: %s", cmd.AggregateID))
+	}
+	// events saved after the aggregate was loaded are not reversed, saving fails on the version conflict anyway
+	for len(accountEvents) > 0 && accountEvents[len(accountEvents)-1].GetVersion() > bankAccountAggregate.GetVersion() {
+		accountEvents = accountEvents[:len(accountEvents)-1]
+	}
+
+	if cmd.EventID != "" {
+		err = h.reverseEvent(ctx, bankAccountAggregate, accountEvents, cmd.EventID, cmd.Reason)
+	} else {
+		err = h.rollback(ctx, bankAccountAggregate, accountEvents, cmd.Version, cmd.Reason)
+	}
+	if err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+
+	// rolling back to a version without deposits and withdrawals after it changes nothing
+	if len(bankAccountAggregate.GetChanges()) == 0 {
+		return bankAccountAggregate.GetVersion(), nil
+	}
+	if err := h.aggregateStore.Save(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	return bankAccountAggregate.GetVersion(), nil
+}
+
+// reverseEvent reverses the deposit or withdrawal recorded by the event.
+func (h *reverseTransactionCmdHandler) reverseEvent(ctx context.Context, aggregate *domain.BankAccountAggregate, accountEvents []es.Event, eventID string, reason string) error {
+	for _, event := range accountEvents {
+		if event.GetEventID() != eventID {
+			continue
+		}
+
+		deserializedEvent, err := h.serializer.DeserializeEvent(event)
+		if err != nil {
+			return errors.Wrapf(err, "serializer.DeserializeEvent aggregateID: %s, version: %d", event.GetAggregateID(), event.GetVersion())
+		}
+		switch evt := deserializedEvent.(type) {
+		case *events.BalanceDepositedEventV1:
+			return aggregate.ReverseDeposit(ctx, eventID, event.GetVersion(), *evt, reason)
+		case *events.BalanceWithdrawedEventV1:
+			return aggregate.ReverseWithdrawal(ctx, eventID, event.GetVersion(), *evt, reason)
+		default:
+			return errors.Wrapf(bankAccountErrors.ErrTransactionNotReversible, "event id: %s, type: %s", eventID, event.GetEventType())
+		}
+	}
+	return errors.Wrapf(bankAccountErrors.ErrTransactionNotFound, "event id: %s", eventID)
+}
+
+// rollback reverses deposits and withdrawals recorded after version newest first. Changes moving no money stay as
+// they are, other money movements after version cannot be rolled back.
+func (h *reverseTransactionCmdHandler) rollback(ctx context.Context, aggregate *domain.BankAccountAggregate, accountEvents []es.Event, version uint64, reason string) error {
+	for i := len(accountEvents) - 1; i >= 0 && accountEvents[i].GetVersion() > version; i-- {
+		event := accountEvents[i]
+		deserializedEvent, err := h.serializer.DeserializeEvent(event)
+		if err != nil {
+			return errors.Wrapf(err, "serializer.DeserializeEvent aggregateID: %s, version: %d", event.GetAggregateID(), event.GetVersion())
+		}
+
+		// reversed already, either before or by a reversal after version which is kept
+		if aggregate.BankAccount.IsReversed(event.GetVersion()) {
+			continue
+		}
+
+		switch evt := deserializedEvent.(type) {
+		case *events.BalanceDepositedEventV1:
+			err = aggregate.ReverseDeposit(ctx, event.GetEventID(), event.GetVersion(), *evt, reason)
+		case *events.BalanceWithdrawedEventV1:
+			err = aggregate.ReverseWithdrawal(ctx, event.GetEventID(), event.GetVersion(), *evt, reason)
+		case *events.DepositReversedEventV1:
+			if evt.OriginalVersion <= version {
+				err = errors.Wrapf(bankAccountErrors.ErrTransactionNotReversible, "reversal of version %d at version: %d", evt.OriginalVersion, event.GetVersion())
+			}
+		case *events.WithdrawalReversedEventV1:
+			if evt.OriginalVersion <= version {
+				err = errors.Wrapf(bankAccountErrors.ErrTransactionNotReversible, "reversal of version %d at version: %d", evt.OriginalVersion, event.GetVersion())
+			}
+		case *events.InterestPostedEventV1, *events.HoldCapturedEventV1, *events.AccountClosedEventV1:
+			err = errors.Wrapf(bankAccountErrors.ErrTransactionNotReversible, "version: %d, type: %s", event.GetVersion(), event.GetEventType())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
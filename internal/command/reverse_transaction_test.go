@@ -0,0 +1,249 @@
+package command
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+const testAccountID = "account-1"
+
+// memoryAggregateStore keeps serialized events in memory, methods not used by the handler are left unimplemented.
+type memoryAggregateStore struct {
+	es.AggregateStore
+	serializer es.Serializer
+	events     []es.Event
+}
+
+func (s *memoryAggregateStore) Load(ctx context.Context, aggregate es.Aggregate) error {
+	for _, event := range s.events {
+		if event.GetAggregateID() != aggregate.GetID() {
+			continue
+		}
+		deserializedEvent, err := s.serializer.DeserializeEvent(event)
+		if err != nil {
+			return err
+		}
+		if err := aggregate.RaiseEvent(deserializedEvent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memoryAggregateStore) LoadEvents(ctx context.Context, aggregateID string) ([]es.Event, error) {
+	events := make([]es.Event, 0)
+	for _, event := range s.events {
+		if event.GetAggregateID() == aggregateID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *memoryAggregateStore) Save(ctx context.Context, aggregate es.Aggregate) error {
+	changes := aggregate.GetChanges()
+	firstVersion := aggregate.GetVersion() - uint64(len(changes)) + 1
+	for i, change := range changes {
+		event, err := s.serializer.SerializeEvent(aggregate, change)
+		if err != nil {
+			return err
+		}
+		event.SetVersion(firstVersion + uint64(i))
+		event.Position = uint64(len(s.events) + 1)
+		event.EventID = strconv.FormatUint(event.Position, 10)
+		s.events = append(s.events, event)
+	}
+	aggregate.ClearChanges()
+	return nil
+}
+
+// newTestAccountStore stores an account with deposit of 100 at version 2, withdrawal of 30 at version 3
+// and deposit of 50 at version 4, followed by the changes of setup.
+func newTestAccountStore(t *testing.T, setup func(aggregate *domain.BankAccountAggregate) error) *memoryAggregateStore {
+	t.Helper()
+	ctx := context.Background()
+	store := &memoryAggregateStore{serializer: domain.NewEventSerializer()}
+
+	aggregate := domain.NewBankAccountAggregate(testAccountID)
+	require.NoError(t, aggregate.Apply(&events.BankAccountCreatedEventV1{Email: "account@example.com", Balance: money.New(0, money.USD)}))
+	require.NoError(t, aggregate.DepositBalance(ctx, 100, "", "deposit-1"))
+	require.NoError(t, aggregate.WithdrawBalance(ctx, 30, "", "withdrawal-1"))
+	require.NoError(t, aggregate.DepositBalance(ctx, 50, "", "deposit-2"))
+	if setup != nil {
+		require.NoError(t, setup(aggregate))
+	}
+	require.NoError(t, store.Save(ctx, aggregate))
+	return store
+}
+
+// compensation of a reversal event, the original version and amount it reverses.
+type compensation struct {
+	eventType       es.EventType
+	originalVersion uint64
+	amount          int64
+}
+
+func TestReverseTransactionCompensatingEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(aggregate *domain.BankAccountAggregate) error
+		cmd      ReverseTransactionCommand
+		expected error
+		events   []compensation
+		balance  int64
+	}{
+		{
+			name:    "reverse deposit",
+			cmd:     ReverseTransactionCommand{EventID: "2", Reason: "chargeback"},
+			events:  []compensation{{events.DepositReversedEventTypeV1, 2, 100}},
+			balance: 20,
+		},
+		{
+			name:    "reverse withdrawal",
+			cmd:     ReverseTransactionCommand{EventID: "3", Reason: "duplicate"},
+			events:  []compensation{{events.WithdrawalReversedEventTypeV1, 3, 30}},
+			balance: 150,
+		},
+		{
+			name: "reverse deposit over available balance",
+			setup: func(aggregate *domain.BankAccountAggregate) error {
+				return aggregate.WithdrawBalance(context.Background(), 100, "", "withdrawal-2")
+			},
+			cmd:      ReverseTransactionCommand{EventID: "2", Reason: "chargeback"},
+			expected: bankAccountErrors.ErrNotEnoughBalance,
+		},
+		{
+			name: "reverse reversed transaction",
+			setup: func(aggregate *domain.BankAccountAggregate) error {
+				return aggregate.ReverseWithdrawal(context.Background(), "3", 3, events.BalanceWithdrawedEventV1{Amount: 30}, "duplicate")
+			},
+			cmd:      ReverseTransactionCommand{EventID: "3", Reason: "duplicate"},
+			expected: bankAccountErrors.ErrTransactionAlreadyReversed,
+		},
+		{
+			name:     "reverse event moving no money",
+			cmd:      ReverseTransactionCommand{EventID: "1", Reason: "mistake"},
+			expected: bankAccountErrors.ErrTransactionNotReversible,
+		},
+		{
+			name:     "reverse unknown event",
+			cmd:      ReverseTransactionCommand{EventID: "42", Reason: "mistake"},
+			expected: bankAccountErrors.ErrTransactionNotFound,
+		},
+		{
+			name:     "reverse without reason",
+			cmd:      ReverseTransactionCommand{EventID: "2", Reason: " "},
+			expected: bankAccountErrors.ErrInvalidReversal,
+		},
+		{
+			name: "rollback reverses transactions newest first",
+			cmd:  ReverseTransactionCommand{Version: 1, Reason: "fraud"},
+			events: []compensation{
+				{events.DepositReversedEventTypeV1, 4, 50},
+				{events.WithdrawalReversedEventTypeV1, 3, 30},
+				{events.DepositReversedEventTypeV1, 2, 100},
+			},
+			balance: 0,
+		},
+		{
+			name:    "rollback keeps transactions up to the version",
+			cmd:     ReverseTransactionCommand{Version: 3, Reason: "fraud"},
+			events:  []compensation{{events.DepositReversedEventTypeV1, 4, 50}},
+			balance: 70,
+		},
+		{
+			name: "rollback skips transactions reversed already",
+			setup: func(aggregate *domain.BankAccountAggregate) error {
+				return aggregate.ReverseWithdrawal(context.Background(), "3", 3, events.BalanceWithdrawedEventV1{Amount: 30}, "duplicate")
+			},
+			cmd: ReverseTransactionCommand{Version: 1, Reason: "fraud"},
+			events: []compensation{
+				{events.DepositReversedEventTypeV1, 4, 50},
+				{events.DepositReversedEventTypeV1, 2, 100},
+			},
+			balance: 0,
+		},
+		{
+			name: "rollback keeps changes moving no money",
+			setup: func(aggregate *domain.BankAccountAggregate) error {
+				return aggregate.FreezeAccount(context.Background(), "investigation")
+			},
+			cmd:     ReverseTransactionCommand{Version: 3, Reason: "fraud"},
+			events:  []compensation{{events.DepositReversedEventTypeV1, 4, 50}},
+			balance: 70,
+		},
+		{
+			name: "rollback over hold capture",
+			setup: func(aggregate *domain.BankAccountAggregate) error {
+				if err := aggregate.PlaceHold(context.Background(), "hold-1", 10, "", "", time.Time{}); err != nil {
+					return err
+				}
+				return aggregate.CaptureHold(context.Background(), "hold-1", 0, "payment-1")
+			},
+			cmd:      ReverseTransactionCommand{Version: 1, Reason: "fraud"},
+			expected: bankAccountErrors.ErrTransactionNotReversible,
+		},
+		{
+			name:     "rollback to the current version",
+			cmd:      ReverseTransactionCommand{Version: 4, Reason: "fraud"},
+			expected: bankAccountErrors.ErrInvalidReversal,
+		},
+		{
+			name:     "rollback without reason",
+			cmd:      ReverseTransactionCommand{Version: 1},
+			expected: bankAccountErrors.ErrInvalidReversal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestAccountStore(t, tt.setup)
+			saved := len(store.events)
+			handler := NewReverseTransactionCmdHandler(store, store.serializer, zap.NewNop())
+
+			tt.cmd.AggregateID = testAccountID
+			version, err := handler.Handle(context.Background(), tt.cmd)
+			if tt.expected != nil {
+				assert.ErrorIs(t, err, tt.expected)
+				assert.Len(t, store.events, saved, "nothing is saved")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, uint64(len(store.events)), version)
+
+			compensations := make([]compensation, 0, len(store.events)-saved)
+			for _, event := range store.events[saved:] {
+				deserializedEvent, err := store.serializer.DeserializeEvent(event)
+				require.NoError(t, err)
+				switch evt := deserializedEvent.(type) {
+				case *events.DepositReversedEventV1:
+					assert.Equal(t, tt.cmd.Reason, evt.Reason)
+					assert.Equal(t, strconv.FormatUint(store.events[evt.OriginalVersion-1].Position, 10), evt.OriginalEventID)
+					compensations = append(compensations, compensation{event.GetEventType(), evt.OriginalVersion, evt.Amount})
+				case *events.WithdrawalReversedEventV1:
+					assert.Equal(t, tt.cmd.Reason, evt.Reason)
+					assert.Equal(t, strconv.FormatUint(store.events[evt.OriginalVersion-1].Position, 10), evt.OriginalEventID)
+					compensations = append(compensations, compensation{event.GetEventType(), evt.OriginalVersion, evt.Amount})
+				default:
+					t.Fatalf("unexpected event %s", event.GetEventType())
+				}
+			}
+			assert.Equal(t, tt.events, compensations)
+
+			aggregate := domain.NewBankAccountAggregate(testAccountID)
+			require.NoError(t, store.Load(context.Background(), aggregate))
+			assert.Equal(t, tt.balance, aggregate.BankAccount.Balance.Amount())
+		})
+	}
+}
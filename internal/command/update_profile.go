@@ -0,0 +1,66 @@
+package command
+
+import (
+	"context"
+
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// UpdateProfileCommand replaces names of the account holder, empty names are kept.
+type UpdateProfileCommand struct {
+	AggregateID string `json:"aggregate_id" validate:"required,gte=0"`
+	FirstName   string `json:"first_name" validate:"required_without=LastName,max=100"`
+	LastName    string `json:"last_name" validate:"required_without=FirstName,max=100"`
+}
+
+type UpdateProfile interface {
+	// Handle returns version of the aggregate after the profile was updated.
+	Handle(ctx context.Context, cmd UpdateProfileCommand) (uint64, error)
+}
+
+type updateProfileCmdHandler struct {
+	aggregateStore es.AggregateStore
+	logger         *zap.Logger
+}
+
+func NewUpdateProfileCmdHandler(
+	aggregateStore es.AggregateStore,
+	logger *zap.Logger,
+) UpdateProfile {
+	return &updateProfileCmdHandler{
+		aggregateStore: aggregateStore,
+		logger:         logger,
+	}
+}
+
+func (h *updateProfileCmdHandler) Handle(ctx context.Context, cmd UpdateProfileCommand) (uint64, error) {
+	h.logger.Info("Handling UpdateProfileCommand", zap.String("id", cmd.AggregateID))
+	ctx, span := tracing.StartSpan(ctx, "updateProfileCmdHandler.Handle")
+	span.SetAttributes(attribute.String("aggregate_id", cmd.AggregateID))
+	defer span.End()
+
+	bankAccountAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
+	if err := h.aggregateStore.Load(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if bankAccountAggregate.GetVersion() == 0 {
+		return 0, tracing.TraceErr(span, bankAccountErrors.ErrBankAccountNotFound)
+	}
+
+	if err := bankAccountAggregate.UpdateProfile(ctx, cmd.FirstName, cmd.LastName); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	if len(bankAccountAggregate.GetChanges()) == 0 {
+		return bankAccountAggregate.GetVersion(), nil
+	}
+
+	if err := h.aggregateStore.Save(ctx, bankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	return bankAccountAggregate.GetVersion(), nil
+}
@@ -5,17 +5,23 @@ import (
 
 	"github.com/th1enq/es-demo/internal/domain"
 	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
 type WithdrawBalanceCommand struct {
 	AggregateID string `json:"aggregate_id" validate:"required,gte=0"`
-	Amount      int64  `json:"amount" validate:"required,gt=0"`
-	PaymentID   string `json:"payment_id" validate:"required,gte=0"`
+	// Amount in minor units of Currency
+	Amount int64 `json:"amount" validate:"required,gt=0"`
+	// Currency ISO 4217 code, must match the account currency, defaults to it
+	Currency  string `json:"currency" validate:"omitempty,iso4217"`
+	PaymentID string `json:"payment_id" validate:"required,gte=0"`
 }
 
 type WithdrawBalance interface {
-	Handle(ctx context.Context, cmd WithdrawBalanceCommand) error
+	// Handle returns version of the aggregate after the withdrawal.
+	Handle(ctx context.Context, cmd WithdrawBalanceCommand) (uint64, error)
 }
 
 type withdrawBalanceCmdHandler struct {
@@ -33,22 +39,30 @@ func NewWithdrawBalanceCmdHandler(
 	}
 }
 
-func (w *withdrawBalanceCmdHandler) Handle(ctx context.Context, cmd WithdrawBalanceCommand) error {
+func (w *withdrawBalanceCmdHandler) Handle(ctx context.Context, cmd WithdrawBalanceCommand) (uint64, error) {
 	w.logger.Info("Handling WithdrawBalanceCommand", zap.String("id", cmd.AggregateID))
+	ctx, span := tracing.StartSpan(ctx, "withdrawBalanceCmdHandler.Handle")
+	span.SetAttributes(attribute.String("aggregate_id", cmd.AggregateID))
+	defer span.End()
+
 	NewBankAccountAggregate := domain.NewBankAccountAggregate(cmd.AggregateID)
 	err := w.aggregateStore.Load(ctx, NewBankAccountAggregate)
 
 	if err != nil {
-		return err
+		return 0, tracing.TraceErr(span, err)
 	}
 
 	if err := NewBankAccountAggregate.WithdrawBalance(
 		ctx,
 		cmd.Amount,
+		cmd.Currency,
 		cmd.PaymentID,
 	); err != nil {
-		return err
+		return 0, tracing.TraceErr(span, err)
 	}
 
-	return w.aggregateStore.Save(ctx, NewBankAccountAggregate)
+	if err := w.aggregateStore.Save(ctx, NewBankAccountAggregate); err != nil {
+		return 0, tracing.TraceErr(span, err)
+	}
+	return NewBankAccountAggregate.GetVersion(), nil
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	"github.com/th1enq/es-demo/internal/dto"
 	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
@@ -104,6 +105,7 @@ func (a *AuthController) Login(c *gin.Context) {
 // @Success      201      {object}  dto.APIResponse{data=dto.RegisterResponse}
 // @Failure      400      {object}  dto.APIResponse
 // @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
 // @Failure      500      {object}  dto.APIResponse
 // @Router       /api/v1/auth/register [post]
 func (a *AuthController) Register(c *gin.Context) {
@@ -147,6 +149,15 @@ func (a *AuthController) Register(c *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, bankAccountErrors.ErrInvalidCurrency) {
+			c.JSON(http.StatusUnprocessableEntity, dto.NewErrorResponse(
+				dto.CodeUnprocessableEntity,
+				"registration failed",
+				err.Error(),
+			))
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
 			dto.CodeInternalServerError,
 			"registration failed",
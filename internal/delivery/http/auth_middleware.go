@@ -5,7 +5,9 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	"github.com/th1enq/es-demo/internal/dto"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
 	"github.com/th1enq/es-demo/internal/service"
 	"go.uber.org/zap"
 )
@@ -78,6 +80,28 @@ func (m *AuthMiddleware) JWTAuth() gin.HandlerFunc {
 			return
 		}
 
+		// Tokens are revoked by closing the account or changing the password
+		if err := m.authService.CheckSession(c, claims); err != nil {
+			if !errors.Is(err, bankAccountErrors.ErrInvalidToken) && !errors.Is(err, bankAccountErrors.ErrAccountInactive) {
+				m.logger.Error("Failed to check session", zap.String("user_id", claims.UserID), zap.Error(err))
+				c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
+					dto.CodeInternalServerError,
+					"failed to check session",
+					err.Error(),
+				))
+				c.Abort()
+				return
+			}
+			m.logger.Warn("Revoked token", zap.String("user_id", claims.UserID), zap.Error(err))
+			c.JSON(http.StatusUnauthorized, dto.NewErrorResponse(
+				dto.CodeInvalidToken,
+				"token is revoked",
+				err.Error(),
+			))
+			c.Abort()
+			return
+		}
+
 		// Set user context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
@@ -128,6 +152,29 @@ func (m *AuthMiddleware) RequireRole(requiredRole string) gin.HandlerFunc {
 	}
 }
 
+// RequireAccountOwner middleware checks the account of the :id path parameter belongs to the user,
+// admins may act on any account
+func (m *AuthMiddleware) RequireAccountOwner() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller := callerOf(c)
+		if !caller.CanAccess(c.Param("id")) {
+			m.logger.Warn("Access to another account denied",
+				zap.String("user_id", caller.UserID),
+				zap.String("account_id", c.Param("id")),
+			)
+			c.JSON(http.StatusForbidden, dto.NewErrorResponse(
+				dto.CodeForbidden,
+				"access denied",
+				"account does not belong to the user",
+			))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // OptionalAuth middleware validates JWT token if present but doesn't require it
 func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -168,3 +215,11 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// callerOf returns the user authenticated by JWTAuth.
+func callerOf(c *gin.Context) service.Caller {
+	return service.Caller{
+		UserID: c.GetString("user_id"),
+		Role:   c.GetString("user_role"),
+	}
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/dto"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/service"
+	"go.uber.org/zap"
+)
+
+type ConsistencyController struct {
+	consistencyService *service.ConsistencyService
+	validator          *validator.Validate
+	logger             *zap.Logger
+}
+
+func NewConsistencyController(consistencyService *service.ConsistencyService, logger *zap.Logger) *ConsistencyController {
+	return &ConsistencyController{
+		consistencyService: consistencyService,
+		validator:          validator.New(),
+		logger:             logger,
+	}
+}
+
+// StartConsistencyCheck godoc
+// @Summary      Start Consistency Check
+// @Description  Start background check replaying accounts from the event store and comparing balance, version, names and email with the MongoDB and Elasticsearch read models.
+// @Description  All accounts are checked when no aggregate ids are given. With repair drifted accounts are rebuilt in the drifted read model
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        repair   query     string                       false  "Whether to rebuild drifted accounts (true/false)"
+// @Param        request  body      dto.ConsistencyCheckRequest  false  "Accounts to check"
+// @Success      202      {object}  dto.APIResponse{data=domain.ConsistencyCheck}
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/admin/consistency/checks [post]
+func (cc *ConsistencyController) StartConsistencyCheck(c *gin.Context) {
+	repair := false
+	if repairStr := c.Query("repair"); repairStr != "" {
+		parsed, err := strconv.ParseBool(repairStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+				dto.CodeBadRequest,
+				"invalid repair query parameter",
+				err.Error(),
+			))
+			return
+		}
+		repair = parsed
+	}
+
+	var request dto.ConsistencyCheckRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindBodyWithJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+				dto.CodeBadRequest,
+				"invalid request body",
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	if err := cc.validator.StructCtx(c, request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	check, err := cc.consistencyService.StartCheck(c, request.AggregateIDs, repair)
+	if err != nil {
+		status, code := consistencyCheckErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to start consistency check",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.NewSuccessResponse(
+		dto.CodeCreated,
+		"consistency check started",
+		check,
+	))
+}
+
+// ListConsistencyChecks godoc
+// @Summary      List Consistency Checks
+// @Description  Latest consistency checks with their progress and drift counters
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  dto.APIResponse{data=[]domain.ConsistencyCheck}
+// @Failure      500  {object}  dto.APIResponse
+// @Router       /api/v1/admin/consistency/checks [get]
+func (cc *ConsistencyController) ListConsistencyChecks(c *gin.Context) {
+	checks, err := cc.consistencyService.ListChecks(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
+			dto.CodeInternalServerError,
+			"failed to list consistency checks",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"consistency checks retrieved successfully",
+		checks,
+	))
+}
+
+// GetConsistencyCheck godoc
+// @Summary      Get Consistency Check
+// @Description  Consistency check with drift reported per account and read model
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Consistency check ID"
+// @Success      200  {object}  dto.APIResponse{data=domain.ConsistencyCheck}
+// @Failure      404  {object}  dto.APIResponse
+// @Failure      500  {object}  dto.APIResponse
+// @Router       /api/v1/admin/consistency/checks/{id} [get]
+func (cc *ConsistencyController) GetConsistencyCheck(c *gin.Context) {
+	check, err := cc.consistencyService.GetCheck(c, c.Param("id"))
+	if err != nil {
+		status, code := consistencyCheckErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to get consistency check",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"consistency check retrieved successfully",
+		check,
+	))
+}
+
+func consistencyCheckErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, bankAccountErrors.ErrConsistencyCheckNotFound):
+		return http.StatusNotFound, dto.CodeNotFound
+	case errors.Is(err, bankAccountErrors.ErrConsistencyCheckRunning):
+		return http.StatusConflict, dto.CodeConflict
+	default:
+		return http.StatusInternalServerError, dto.CodeInternalServerError
+	}
+}
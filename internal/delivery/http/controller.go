@@ -3,16 +3,27 @@ package http
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	"github.com/th1enq/es-demo/internal/command"
 	"github.com/th1enq/es-demo/internal/dto"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
 	"github.com/th1enq/es-demo/internal/mappers"
 	"github.com/th1enq/es-demo/internal/query"
 	"github.com/th1enq/es-demo/internal/service"
 	"github.com/th1enq/es-demo/pkg/constants"
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfNoneMatch = "If-None-Match"
+
+	holdIDParam = "hold_id"
 )
 
 type Controller struct {
@@ -21,30 +32,851 @@ type Controller struct {
 	validator          *validator.Validate
 }
 
-func NewController(
-	bankAccountService *service.BankAccountService,
-	replayService *service.ReplayService,
-) *Controller {
-	return &Controller{
-		BankAccountService: bankAccountService,
-		ReplayService:      replayService,
-		validator:          validator.New(),
+func NewController(
+	bankAccountService *service.BankAccountService,
+	replayService *service.ReplayService,
+) *Controller {
+	return &Controller{
+		BankAccountService: bankAccountService,
+		ReplayService:      replayService,
+		validator:          validator.New(),
+	}
+}
+
+// CreateBankAccount godoc
+// @Summary      Create Bank Account
+// @Description  Create a new bank account
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Param        request  body      command.CreateBankAccountCommand  true  "Create Bank Account Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the created account"
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts [post]
+func (b *Controller) CreateBankAccount(c *gin.Context) {
+	var command command.CreateBankAccountCommand
+
+	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	command.AggregateID = uuid.NewV4().String()
+
+	if err := b.validator.StructCtx(c, command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	version, err := b.BankAccountService.Commands.CreateBankAccount.Handle(
+		c,
+		command,
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to create bank account",
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header(headerETag, versionETag(version))
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"bank account created successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
+	))
+}
+
+// DepositeBalance godoc
+// @Summary      Deposite Balance
+// @Description  Deposite balance to bank account
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                        true  "Bank Account ID"
+// @Param        request  body      command.DepositeBalanceCommand    true  "Deposite Balance Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the deposit"
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/deposite [post]
+func (b *Controller) DepositeBalance(c *gin.Context) {
+	var command command.DepositeBalanceCommand
+
+	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	command.AggregateID = c.Param(constants.ID)
+
+	if err := b.validator.StructCtx(c, command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	version, err := b.BankAccountService.Commands.DepositeBalance.Handle(
+		c,
+		command,
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to deposite balance",
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header(headerETag, versionETag(version))
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"balance deposited successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
+	))
+}
+
+// WithdrawBalance godoc
+// @Summary      Withdraw Balance
+// @Description  Withdraw balance from bank account, withdrawals exceeding the balance with overdraft limit or the
+// @Description  per transaction, daily or monthly limit of the withdrawal policy are rejected with 422
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                         true  "Bank Account ID"
+// @Param        request  body      command.WithdrawBalanceCommand     true  "Withdraw Balance Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the withdrawal"
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/withdraw [post]
+func (b *Controller) WithdrawBalance(c *gin.Context) {
+	var command command.WithdrawBalanceCommand
+
+	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	command.AggregateID = c.Param(constants.ID)
+
+	if err := b.validator.StructCtx(c, command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	version, err := b.BankAccountService.Commands.WithdrawBalance.Handle(
+		c,
+		command,
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to withdraw balance",
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header(headerETag, versionETag(version))
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"balance withdrawn successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
+	))
+}
+
+// ChangeWithdrawalPolicy godoc
+// @Summary      Change Withdrawal Policy
+// @Description  Replace overdraft limit, per transaction maximum and daily and monthly withdrawal limits of the account.
+// @Description  Limits are in minor units of the account currency, zero means no limit and no overdraft. Daily and monthly
+// @Description  limits count withdrawals in UTC days and months. Withdrawals breaking the policy are rejected with 422
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                      true  "Bank Account ID"
+// @Param        request  body      command.ChangeWithdrawalPolicyCommand  true  "Change Withdrawal Policy Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the policy change"
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/withdrawal_policy [put]
+func (b *Controller) ChangeWithdrawalPolicy(c *gin.Context) {
+	var command command.ChangeWithdrawalPolicyCommand
+
+	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	command.AggregateID = c.Param(constants.ID)
+
+	if err := b.validator.StructCtx(c, command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	version, err := b.BankAccountService.Commands.ChangeWithdrawalPolicy.Handle(
+		c,
+		command,
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to change withdrawal policy",
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header(headerETag, versionETag(version))
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeUpdated,
+		"withdrawal policy changed successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
+	))
+}
+
+// ChangeInterestRate godoc
+// @Summary      Change Interest Rate
+// @Description  Set the annual interest rate of the account in basis points, zero stops accrual. Interest accrues daily on the
+// @Description  closing balance of the UTC day at the rate in effect at its end and is credited at the end of every month
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                             true  "Bank Account ID"
+// @Param        request  body      command.ChangeInterestRateCommand  true  "Change Interest Rate Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the rate change"
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/interest_rate [put]
+func (b *Controller) ChangeInterestRate(c *gin.Context) {
+	var command command.ChangeInterestRateCommand
+
+	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	command.AggregateID = c.Param(constants.ID)
+
+	if err := b.validator.StructCtx(c, command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	version, err := b.BankAccountService.Commands.ChangeInterestRate.Handle(
+		c,
+		command,
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to change interest rate",
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header(headerETag, versionETag(version))
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeUpdated,
+		"interest rate changed successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
+	))
+}
+
+// PlaceHold godoc
+// @Summary      Place Hold
+// @Description  Reserve funds for a card style payment until it is captured or released. Holds are checked against the available
+// @Description  balance, the ledger balance less outstanding holds, and the withdrawal policy. Holds expire after expires_at, by default in 7 days and at most in 30 days
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                     true  "Bank Account ID"
+// @Param        request  body      command.PlaceHoldCommand  true  "Place Hold Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the hold"
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/holds [post]
+func (b *Controller) PlaceHold(c *gin.Context) {
+	var command command.PlaceHoldCommand
+
+	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	command.AggregateID = c.Param(constants.ID)
+
+	if err := b.validator.StructCtx(c, command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	version, err := b.BankAccountService.Commands.PlaceHold.Handle(
+		c,
+		command,
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to place hold",
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header(headerETag, versionETag(version))
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"hold placed successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
+	))
+}
+
+// CaptureHold godoc
+// @Summary      Capture Hold
+// @Description  Settle the hold debiting the captured amount from the ledger balance. Zero amount captures the whole hold,
+// @Description  capturing less is a partial capture releasing the rest. Expired holds cannot be captured
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                     true  "Bank Account ID"
+// @Param        hold_id  path      string                       true  "Hold ID"
+// @Param        request  body      command.CaptureHoldCommand  true  "Capture Hold Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the capture"
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/holds/{hold_id}/capture [post]
+func (b *Controller) CaptureHold(c *gin.Context) {
+	var command command.CaptureHoldCommand
+
+	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	command.AggregateID = c.Param(constants.ID)
+	command.HoldID = c.Param(holdIDParam)
+
+	if err := b.validator.StructCtx(c, command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	version, err := b.BankAccountService.Commands.CaptureHold.Handle(
+		c,
+		command,
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to capture hold",
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header(headerETag, versionETag(version))
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"hold captured successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
+	))
+}
+
+// ReleaseHold godoc
+// @Summary      Release Hold
+// @Description  Cancel the hold without moving money, held funds are available again
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                     true  "Bank Account ID"
+// @Param        hold_id  path      string                       true  "Hold ID"
+// @Param        request  body      command.ReleaseHoldCommand  true  "Release Hold Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the release"
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/holds/{hold_id}/release [post]
+func (b *Controller) ReleaseHold(c *gin.Context) {
+	var command command.ReleaseHoldCommand
+
+	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	command.AggregateID = c.Param(constants.ID)
+	command.HoldID = c.Param(holdIDParam)
+
+	if err := b.validator.StructCtx(c, command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	version, err := b.BankAccountService.Commands.ReleaseHold.Handle(
+		c,
+		command,
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to release hold",
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header(headerETag, versionETag(version))
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"hold released successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
+	))
+}
+
+// ReverseTransaction godoc
+// @Summary      Reverse Transaction
+// @Description  Reverse the deposit or withdrawal recorded by the event, history is not changed and the reversal is recorded
+// @Description  as a compensating event referencing the original. Reversing a deposit is rejected if it would drive the available balance negative
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                             true  "Bank Account ID"
+// @Param        request  body      command.ReverseTransactionCommand  true  "Reverse Transaction Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the reversal"
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/reversals [post]
+func (b *Controller) ReverseTransaction(c *gin.Context) {
+	var command command.ReverseTransactionCommand
+
+	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	command.AggregateID = c.Param(constants.ID)
+	// rolling back to a version has its own endpoint
+	command.Version = 0
+
+	if err := b.validator.StructCtx(c, command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	version, err := b.BankAccountService.Commands.ReverseTransaction.Handle(
+		c,
+		command,
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to reverse transaction",
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header(headerETag, versionETag(version))
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"transaction reversed successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
+	))
+}
+
+// RollbackBankAccount godoc
+// @Summary      Rollback Bank Account
+// @Description  Roll the account back to the version reversing every deposit and withdrawal recorded after it, newest first.
+// @Description  Changes moving no money are kept, rollback over interest postings, hold captures or reversals of earlier transactions is rejected
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string               true  "Bank Account ID"
+// @Param        request  body      dto.RollbackRequest  true  "Rollback Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the rollback"
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/rollback [post]
+func (b *Controller) RollbackBankAccount(c *gin.Context) {
+	var request dto.RollbackRequest
+
+	if err := c.ShouldBindBodyWithJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	if err := b.validator.StructCtx(c, request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	aggregateID := c.Param(constants.ID)
+	version, err := b.BankAccountService.Commands.ReverseTransaction.Handle(
+		c,
+		command.ReverseTransactionCommand{
+			AggregateID: aggregateID,
+			Version:     request.Version,
+			Reason:      request.Reason,
+		},
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to roll back bank account",
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header(headerETag, versionETag(version))
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"bank account rolled back successfully",
+		&dto.CommandResultResponse{AggregateID: aggregateID, Version: version},
+	))
+}
+
+// ChangeEmail godoc
+// @Summary      Change Email
+// @Description  Change the login email of the account after verifying the current password, the email must not be used by another account
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                     true  "Bank Account ID"
+// @Param        request  body      command.ChangeEmailCommand  true  "Change Email Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the change"
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/email [put]
+func (b *Controller) ChangeEmail(c *gin.Context) {
+	var command command.ChangeEmailCommand
+
+	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	command.AggregateID = c.Param(constants.ID)
+
+	if err := b.validator.StructCtx(c, command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	version, err := b.BankAccountService.Commands.ChangeEmail.Handle(
+		c,
+		command,
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to change email",
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header(headerETag, versionETag(version))
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeUpdated,
+		"email changed successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
+	))
+}
+
+// UpdateProfile godoc
+// @Summary      Update Profile
+// @Description  Correct names of the account holder, omitted names are kept
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                     true  "Bank Account ID"
+// @Param        request  body      command.UpdateProfileCommand  true  "Update Profile Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the update"
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/profile [put]
+func (b *Controller) UpdateProfile(c *gin.Context) {
+	var command command.UpdateProfileCommand
+
+	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	command.AggregateID = c.Param(constants.ID)
+
+	if err := b.validator.StructCtx(c, command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	version, err := b.BankAccountService.Commands.UpdateProfile.Handle(
+		c,
+		command,
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to update profile",
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header(headerETag, versionETag(version))
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeUpdated,
+		"profile updated successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
+	))
+}
+
+// ChangePassword godoc
+// @Summary      Change Password
+// @Description  Change the password after verifying the current one. Tokens issued before the change are revoked, the client logs in again
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                     true  "Bank Account ID"
+// @Param        request  body      command.ChangePasswordCommand  true  "Change Password Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the change"
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      403      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/password [put]
+func (b *Controller) ChangePassword(c *gin.Context) {
+	var command command.ChangePasswordCommand
+
+	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	command.AggregateID = c.Param(constants.ID)
+
+	if err := b.validator.StructCtx(c, command); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	version, err := b.BankAccountService.Commands.ChangePassword.Handle(
+		c,
+		command,
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to change password",
+			err.Error(),
+		))
+		return
 	}
+
+	c.Header(headerETag, versionETag(version))
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeUpdated,
+		"password changed successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
+	))
 }
 
-// CreateBankAccount godoc
-// @Summary      Create Bank Account
-// @Description  Create a new bank account
+// FreezeAccount godoc
+// @Summary      Freeze Account
+// @Description  Freeze bank account, withdrawals are rejected until the account is unfrozen while deposits are still accepted
 // @Tags         BankAccount
 // @Accept       json
 // @Produce      json
-// @Param        request  body      command.CreateBankAccountCommand  true  "Create Bank Account Request"
-// @Success      200      {object}  dto.APIResponse
+// @Security     BearerAuth
+// @Param        id       path      string                      true  "Bank Account ID"
+// @Param        request  body      command.FreezeAccountCommand  true  "Freeze Account Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the freeze"
 // @Failure      400      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
 // @Failure      500      {object}  dto.APIResponse
-// @Router       /api/v1/bank_accounts [post]
-func (b *Controller) CreateBankAccount(c *gin.Context) {
-	var command command.CreateBankAccountCommand
+// @Router       /api/v1/bank_accounts/{id}/freeze [post]
+func (b *Controller) FreezeAccount(c *gin.Context) {
+	var command command.FreezeAccountCommand
 
 	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
@@ -55,7 +887,7 @@ func (b *Controller) CreateBankAccount(c *gin.Context) {
 		return
 	}
 
-	command.AggregateID = uuid.NewV4().String()
+	command.AggregateID = c.Param(constants.ID)
 
 	if err := b.validator.StructCtx(c, command); err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
@@ -66,39 +898,46 @@ func (b *Controller) CreateBankAccount(c *gin.Context) {
 		return
 	}
 
-	if err := b.BankAccountService.Commands.CreateBankAccount.Handle(
+	version, err := b.BankAccountService.Commands.FreezeAccount.Handle(
 		c,
 		command,
-	); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
-			dto.CodeInternalServerError,
-			"failed to create bank account",
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to freeze account",
 			err.Error(),
 		))
 		return
 	}
 
+	c.Header(headerETag, versionETag(version))
 	c.JSON(http.StatusOK, dto.NewSuccessResponse(
-		dto.CodeSuccess,
-		"bank account created successfully",
-		nil,
+		dto.CodeUpdated,
+		"account frozen successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
 	))
 }
 
-// DepositeBalance godoc
-// @Summary      Deposite Balance
-// @Description  Deposite balance to bank account
+// UnfreezeAccount godoc
+// @Summary      Unfreeze Account
+// @Description  Unfreeze frozen bank account allowing withdrawals again
 // @Tags         BankAccount
 // @Accept       json
 // @Produce      json
-// @Param        id       path      string                        true  "Bank Account ID"
-// @Param        request  body      command.DepositeBalanceCommand    true  "Deposite Balance Request"
-// @Success      200      {object}  dto.APIResponse
+// @Security     BearerAuth
+// @Param        id       path      string                      true  "Bank Account ID"
+// @Param        request  body      command.UnfreezeAccountCommand  true  "Unfreeze Account Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the unfreeze"
 // @Failure      400      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
 // @Failure      500      {object}  dto.APIResponse
-// @Router       /api/v1/bank_accounts/{id}/deposite [post]
-func (b *Controller) DepositeBalance(c *gin.Context) {
-	var command command.DepositeBalanceCommand
+// @Router       /api/v1/bank_accounts/{id}/unfreeze [post]
+func (b *Controller) UnfreezeAccount(c *gin.Context) {
+	var command command.UnfreezeAccountCommand
 
 	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
@@ -120,39 +959,47 @@ func (b *Controller) DepositeBalance(c *gin.Context) {
 		return
 	}
 
-	if err := b.BankAccountService.Commands.DepositeBalance.Handle(
+	version, err := b.BankAccountService.Commands.UnfreezeAccount.Handle(
 		c,
 		command,
-	); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
-			dto.CodeInternalServerError,
-			"failed to deposite balance",
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to unfreeze account",
 			err.Error(),
 		))
 		return
 	}
 
+	c.Header(headerETag, versionETag(version))
 	c.JSON(http.StatusOK, dto.NewSuccessResponse(
-		dto.CodeSuccess,
-		"balance deposited successfully",
-		nil,
+		dto.CodeUpdated,
+		"account unfrozen successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
 	))
 }
 
-// WithdrawBalance godoc
-// @Summary      Withdraw Balance
-// @Description  Withdraw balance from bank account
+// CloseAccount godoc
+// @Summary      Close Account
+// @Description  Close bank account, remaining balance is paid out to the payout target which is required unless the balance is zero. Closed accounts accept no operations and cannot log in
 // @Tags         BankAccount
 // @Accept       json
 // @Produce      json
-// @Param        id       path      string                         true  "Bank Account ID"
-// @Param        request  body      command.WithdrawBalanceCommand     true  "Withdraw Balance Request"
-// @Success      200      {object}  dto.APIResponse
+// @Security     BearerAuth
+// @Param        id       path      string                      true  "Bank Account ID"
+// @Param        request  body      command.CloseAccountCommand  true  "Close Account Request"
+// @Success      200      {object}  dto.APIResponse{data=dto.CommandResultResponse}
+// @Header       200      {string}  ETag  "Version of the account after the closing"
 // @Failure      400      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
 // @Failure      500      {object}  dto.APIResponse
-// @Router       /api/v1/bank_accounts/{id}/withdraw [post]
-func (b *Controller) WithdrawBalance(c *gin.Context) {
-	var command command.WithdrawBalanceCommand
+// @Router       /api/v1/bank_accounts/{id}/close [post]
+func (b *Controller) CloseAccount(c *gin.Context) {
+	var command command.CloseAccountCommand
 
 	if err := c.ShouldBindBodyWithJSON(&command); err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
@@ -174,34 +1021,42 @@ func (b *Controller) WithdrawBalance(c *gin.Context) {
 		return
 	}
 
-	if err := b.BankAccountService.Commands.WithdrawBalance.Handle(
+	version, err := b.BankAccountService.Commands.CloseAccount.Handle(
 		c,
 		command,
-	); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
-			dto.CodeInternalServerError,
-			"failed to withdraw balance",
+	)
+	if err != nil {
+		status, code := commandErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to close account",
 			err.Error(),
 		))
 		return
 	}
 
+	c.Header(headerETag, versionETag(version))
 	c.JSON(http.StatusOK, dto.NewSuccessResponse(
-		dto.CodeSuccess,
-		"balance withdrawn successfully",
-		nil,
+		dto.CodeUpdated,
+		"account closed successfully",
+		&dto.CommandResultResponse{AggregateID: command.AggregateID, Version: version},
 	))
 }
 
 // GetBankAccountByID godoc
 // @Summary      Get Bank Account by ID
-// @Description  Retrieve bank account details by ID
+// @Description  Retrieve bank account details by ID. Pass the version returned by a command as min_version to read your own write:
+// @Description  the projection is awaited for a short time, then the account is loaded from the event store
 // @Tags         BankAccount
 // @Accept       json
 // @Produce      json
 // @Param        id               path      string  true  "Bank Account ID"
 // @Param        from_event_store query     string  false "Fetch data from event store (true/false)"
-// @Success      200              {object}  dto.APIResponse
+// @Param        min_version      query     int     false "Minimal account version the result must include"
+// @Param        If-None-Match    header    string  false "ETag of the cached account"
+// @Success      200              {object}  dto.APIResponse{data=dto.HttpBankAccountResponse}
+// @Header       200              {string}  ETag  "Version of the account"
+// @Success      304              "Account not modified since If-None-Match"
 // @Failure      400              {object}  dto.APIResponse
 // @Failure      500              {object}  dto.APIResponse
 // @Router       /api/v1/bank_accounts/{id} [get]
@@ -224,6 +1079,19 @@ func (b *Controller) GetBankAccountByID(c *gin.Context) {
 		query.FromEventStore = isFromStore
 	}
 
+	if minVersion := c.Query("min_version"); minVersion != "" {
+		parsed, err := strconv.ParseUint(minVersion, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+				dto.CodeBadRequest,
+				"invalid min_version query parameter",
+				err.Error(),
+			))
+			return
+		}
+		query.MinVersion = parsed
+	}
+
 	if err := b.validator.StructCtx(c, query); err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
 			dto.CodeBadRequest,
@@ -246,6 +1114,13 @@ func (b *Controller) GetBankAccountByID(c *gin.Context) {
 		return
 	}
 
+	etag := versionETag(result.Version)
+	c.Header(headerETag, etag)
+	if c.GetHeader(headerIfNoneMatch) == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
 	c.JSON(http.StatusOK, dto.NewSuccessResponse(
 		dto.CodeSuccess,
 		"bank account retrieved successfully",
@@ -353,16 +1228,109 @@ func (b *Controller) GetEventsHistory(c *gin.Context) {
 	))
 }
 
+// GetTransactions godoc
+// @Summary      Get Transactions
+// @Description  Ledger of deposits and withdrawals of the bank account with running balance, newest first. The first entry of every account is its opening balance
+// @Tags         BankAccount
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string  true   "Bank Account ID"
+// @Param        page  query     int     false  "Page number, starts at 1"
+// @Param        size  query     int     false  "Page size, at most 100"
+// @Param        from  query     string  false  "Include transactions at or after the time (RFC3339)"
+// @Param        to    query     string  false  "Include transactions before the time (RFC3339)"
+// @Success      200   {object}  dto.APIResponse{data=query.TransactionsPage}
+// @Failure      400   {object}  dto.APIResponse
+// @Failure      500   {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/transactions [get]
+func (b *Controller) GetTransactions(c *gin.Context) {
+	var query query.GetTransactionsQuery
+
+	query.AggregateID = c.Param(constants.ID)
+
+	for param, target := range map[string]*int{"page": &query.Page, "size": &query.Size} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+				dto.CodeBadRequest,
+				"invalid "+param+" query parameter",
+				err.Error(),
+			))
+			return
+		}
+		*target = parsed
+	}
+
+	for param, target := range map[string]**time.Time{"from": &query.From, "to": &query.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+				dto.CodeBadRequest,
+				"invalid "+param+" query parameter",
+				err.Error(),
+			))
+			return
+		}
+		*target = &parsed
+	}
+
+	if query.From != nil && query.To != nil && !query.To.After(*query.From) {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request",
+			"to must be after from",
+		))
+		return
+	}
+
+	if err := b.validator.StructCtx(c, query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request",
+			err.Error(),
+		))
+		return
+	}
+
+	result, err := b.BankAccountService.Query.GetTransactions.Handle(c, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
+			dto.CodeInternalServerError,
+			"failed to get transactions",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"transactions retrieved successfully",
+		result,
+	))
+}
+
 // ReplayAllEvents godoc
-// @Summary      Replay All Events to Elasticsearch
-// @Description  Replay all events from PostgreSQL event store to Elasticsearch for demonstration of Event Sourcing replay capability
+// @Summary      Start Replay Job
+// @Description  Start background job replaying all events from PostgreSQL event store to Elasticsearch. Poll the returned job for progress.
+// @Description  With recreate_index a new index version is built and the search alias is swapped to it once validated
 // @Tags         Replay
 // @Accept       json
 // @Produce      json
-// @Param        recreate_index query  string  false "Whether to recreate the Elasticsearch index (true/false)"
-// @Success      200            {object}  dto.APIResponse
+// @Param        recreate_index query  string  false "Whether to rebuild the read model from scratch into a new index or shadow collection (true/false)"
+// @Param        target         query  string  false "Read model to replay into: elasticsearch (default) or mongo"
+// @Success      202            {object}  dto.APIResponse{data=es.ReplayJob}
 // @Failure      400            {object}  dto.APIResponse
+// @Failure      409            {object}  dto.APIResponse
 // @Failure      500            {object}  dto.APIResponse
+// @Failure      503            {object}  dto.APIResponse
 // @Router       /api/v1/replay/events [post]
 func (b *Controller) ReplayAllEvents(c *gin.Context) {
 	recreateIndexStr := c.Query("recreate_index")
@@ -380,11 +1348,98 @@ func (b *Controller) ReplayAllEvents(c *gin.Context) {
 		recreateIndex = parsed
 	}
 
-	result, err := b.ReplayService.ReplayAllEvents(c, recreateIndex)
+	job, err := b.ReplayService.StartReplay(c, service.ReplayTarget(c.Query("target")), recreateIndex)
+	if err != nil {
+		status, code := replayJobErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to start replay job",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.NewSuccessResponse(
+		dto.CodeCreated,
+		"replay job started",
+		job,
+	))
+}
+
+// ReplayStreams godoc
+// @Summary      Start Selective Replay Job
+// @Description  Start background job rebuilding only selected accounts in Elasticsearch or MongoDB: by aggregate ids,
+// @Description  aggregate type or accounts having events within a position or time window. Matched accounts are rebuilt from their full event streams
+// @Tags         Replay
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.SelectiveReplayRequest  true  "Selective Replay Request"
+// @Success      202      {object}  dto.APIResponse{data=es.ReplayJob}
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Failure      503      {object}  dto.APIResponse
+// @Router       /api/v1/replay/streams [post]
+func (b *Controller) ReplayStreams(c *gin.Context) {
+	var request dto.SelectiveReplayRequest
+
+	if err := c.ShouldBindBodyWithJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	if err := b.validator.StructCtx(c, request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	job, err := b.ReplayService.StartSelectiveReplay(c, service.ReplayTarget(request.Target), es.StreamFilter{
+		AggregateIDs:  request.AggregateIDs,
+		AggregateType: es.AggregateType(request.AggregateType),
+		FromPosition:  request.FromPosition,
+		ToPosition:    request.ToPosition,
+		From:          request.From,
+		To:            request.To,
+	})
+	if err != nil {
+		status, code := replayJobErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to start selective replay job",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.NewSuccessResponse(
+		dto.CodeCreated,
+		"selective replay job started",
+		job,
+	))
+}
+
+// ListReplayJobs godoc
+// @Summary      List Replay Jobs
+// @Description  Latest replay jobs with their progress
+// @Tags         Replay
+// @Produce      json
+// @Success      200  {object}  dto.APIResponse{data=[]es.ReplayJob}
+// @Failure      500  {object}  dto.APIResponse
+// @Router       /api/v1/replay/jobs [get]
+func (b *Controller) ListReplayJobs(c *gin.Context) {
+	jobs, err := b.ReplayService.ListReplayJobs(c)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
 			dto.CodeInternalServerError,
-			"failed to replay events",
+			"failed to list replay jobs",
 			err.Error(),
 		))
 		return
@@ -392,11 +1447,166 @@ func (b *Controller) ReplayAllEvents(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dto.NewSuccessResponse(
 		dto.CodeSuccess,
-		"events replayed successfully to Elasticsearch",
-		result,
+		"replay jobs retrieved successfully",
+		jobs,
+	))
+}
+
+// GetReplayJob godoc
+// @Summary      Get Replay Job
+// @Description  Replay job status: position, events processed and errors
+// @Tags         Replay
+// @Produce      json
+// @Param        id   path      string  true  "Replay job ID"
+// @Success      200  {object}  dto.APIResponse{data=es.ReplayJob}
+// @Failure      404  {object}  dto.APIResponse
+// @Failure      500  {object}  dto.APIResponse
+// @Router       /api/v1/replay/jobs/{id} [get]
+func (b *Controller) GetReplayJob(c *gin.Context) {
+	job, err := b.ReplayService.GetReplayJob(c, c.Param(constants.ID))
+	if err != nil {
+		status, code := replayJobErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to get replay job",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"replay job retrieved successfully",
+		job,
+	))
+}
+
+// CancelReplayJob godoc
+// @Summary      Cancel Replay Job
+// @Description  Stop running replay job, progress is kept and the job can be resumed
+// @Tags         Replay
+// @Produce      json
+// @Param        id   path      string  true  "Replay job ID"
+// @Success      200  {object}  dto.APIResponse{data=es.ReplayJob}
+// @Failure      404  {object}  dto.APIResponse
+// @Failure      409  {object}  dto.APIResponse
+// @Router       /api/v1/replay/jobs/{id}/cancel [post]
+func (b *Controller) CancelReplayJob(c *gin.Context) {
+	job, err := b.ReplayService.CancelReplay(c, c.Param(constants.ID))
+	if err != nil {
+		status, code := replayJobErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to cancel replay job",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeUpdated,
+		"replay job cancel requested",
+		job,
+	))
+}
+
+// ResumeReplayJob godoc
+// @Summary      Resume Replay Job
+// @Description  Continue cancelled, interrupted or failed replay job from its last position
+// @Tags         Replay
+// @Produce      json
+// @Param        id   path      string  true  "Replay job ID"
+// @Success      202  {object}  dto.APIResponse{data=es.ReplayJob}
+// @Failure      404  {object}  dto.APIResponse
+// @Failure      409  {object}  dto.APIResponse
+// @Failure      503  {object}  dto.APIResponse
+// @Router       /api/v1/replay/jobs/{id}/resume [post]
+func (b *Controller) ResumeReplayJob(c *gin.Context) {
+	job, err := b.ReplayService.ResumeReplay(c, c.Param(constants.ID))
+	if err != nil {
+		status, code := replayJobErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to resume replay job",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.NewSuccessResponse(
+		dto.CodeUpdated,
+		"replay job resumed",
+		job,
 	))
 }
 
+// versionETag strong ETag of the aggregate version.
+func versionETag(version uint64) string {
+	return strconv.Quote(strconv.FormatUint(version, 10))
+}
+
+func commandErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, bankAccountErrors.ErrBankAccountNotFound),
+		errors.Is(err, bankAccountErrors.ErrHoldNotFound),
+		errors.Is(err, bankAccountErrors.ErrTransactionNotFound):
+		return http.StatusNotFound, dto.CodeNotFound
+	case errors.Is(err, bankAccountErrors.ErrAccountFrozen),
+		errors.Is(err, bankAccountErrors.ErrAccountNotFrozen),
+		errors.Is(err, bankAccountErrors.ErrAccountClosed),
+		errors.Is(err, bankAccountErrors.ErrHoldExists),
+		errors.Is(err, bankAccountErrors.ErrHoldExpired),
+		errors.Is(err, bankAccountErrors.ErrHoldsOutstanding),
+		errors.Is(err, bankAccountErrors.ErrTransactionAlreadyReversed),
+		errors.Is(err, bankAccountErrors.ErrTransactionNotReversible),
+		errors.Is(err, bankAccountErrors.ErrEmailAlreadyExists):
+		return http.StatusConflict, dto.CodeConflict
+	case errors.Is(err, bankAccountErrors.ErrInvalidCredentials):
+		return http.StatusForbidden, dto.CodeForbidden
+	case errors.Is(err, bankAccountErrors.ErrNotEnoughBalance):
+		return http.StatusUnprocessableEntity, dto.CodeInsufficientFunds
+	case errors.Is(err, bankAccountErrors.ErrTransactionLimitExceeded):
+		return http.StatusUnprocessableEntity, dto.CodeTransactionLimit
+	case errors.Is(err, bankAccountErrors.ErrDailyLimitExceeded):
+		return http.StatusUnprocessableEntity, dto.CodeDailyLimit
+	case errors.Is(err, bankAccountErrors.ErrMonthlyLimitExceeded):
+		return http.StatusUnprocessableEntity, dto.CodeMonthlyLimit
+	case errors.Is(err, bankAccountErrors.ErrInvalidCurrency),
+		errors.Is(err, bankAccountErrors.ErrCurrencyMismatch),
+		errors.Is(err, bankAccountErrors.ErrPayoutTargetRequired),
+		errors.Is(err, bankAccountErrors.ErrInvalidWithdrawalPolicy),
+		errors.Is(err, bankAccountErrors.ErrInvalidInterestRate),
+		errors.Is(err, bankAccountErrors.ErrInvalidHold),
+		errors.Is(err, bankAccountErrors.ErrHoldAmountExceeded),
+		errors.Is(err, bankAccountErrors.ErrInvalidReversal),
+		errors.Is(err, bankAccountErrors.ErrInvalidProfile):
+		return http.StatusUnprocessableEntity, dto.CodeUnprocessableEntity
+	default:
+		return http.StatusInternalServerError, dto.CodeInternalServerError
+	}
+}
+
+func replayJobErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidReplayTarget),
+		errors.Is(err, service.ErrInvalidReplayFilter):
+		return http.StatusBadRequest, dto.CodeBadRequest
+	case errors.Is(err, es.ErrReplayJobNotFound):
+		return http.StatusNotFound, dto.CodeNotFound
+	case errors.Is(err, es.ErrReplayJobRunning),
+		errors.Is(err, es.ErrReplayJobNotRunning),
+		errors.Is(err, es.ErrReplayJobFinished),
+		errors.Is(err, es.ErrProjectionRebuilding),
+		errors.Is(err, service.ErrNoPreviousIndex),
+		errors.Is(err, service.ErrReplayValidation):
+		return http.StatusConflict, dto.CodeConflict
+	case errors.Is(err, service.ErrReplayUnavailable):
+		return http.StatusServiceUnavailable, dto.CodeServiceUnavailable
+	default:
+		return http.StatusInternalServerError, dto.CodeInternalServerError
+	}
+}
+
 // GetAccountFromElasticsearch godoc
 // @Summary      Get Bank Account from Elasticsearch
 // @Description  Retrieve bank account details from Elasticsearch (replayed data)
@@ -553,21 +1763,77 @@ func (b *Controller) GetAccountSummary(c *gin.Context) {
 	))
 }
 
+// GetElasticsearchIndexStatus godoc
+// @Summary      Get Elasticsearch Index Status
+// @Description  Versioned bank accounts indices with document counts and the one the search alias points to
+// @Tags         Replay
+// @Produce      json
+// @Success      200  {object}  dto.APIResponse{data=service.IndexStatus}
+// @Failure      500  {object}  dto.APIResponse
+// @Router       /api/v1/replay/index [get]
+func (b *Controller) GetElasticsearchIndexStatus(c *gin.Context) {
+	status, err := b.ReplayService.GetIndexStatus(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
+			dto.CodeInternalServerError,
+			"failed to get Elasticsearch index status",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"Elasticsearch index status retrieved successfully",
+		status,
+	))
+}
+
+// RollbackElasticsearchIndex godoc
+// @Summary      Rollback Elasticsearch Index
+// @Description  Swap the search alias back to the previous index version, it is caught up with newer events first
+// @Tags         Replay
+// @Produce      json
+// @Success      200  {object}  dto.APIResponse{data=service.IndexStatus}
+// @Failure      409  {object}  dto.APIResponse
+// @Failure      500  {object}  dto.APIResponse
+// @Router       /api/v1/replay/index/rollback [post]
+func (b *Controller) RollbackElasticsearchIndex(c *gin.Context) {
+	status, err := b.ReplayService.RollbackIndex(c)
+	if err != nil {
+		httpStatus, code := replayJobErrorStatus(err)
+		c.JSON(httpStatus, dto.NewErrorResponse(
+			code,
+			"failed to rollback Elasticsearch index",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeUpdated,
+		"Elasticsearch index rolled back successfully",
+		status,
+	))
+}
+
 // DeleteElasticsearchIndex godoc
 // @Summary      Delete Elasticsearch Index
-// @Description  Delete the bank accounts index from Elasticsearch (for demo purposes)
+// @Description  Delete all bank accounts and previous index versions from Elasticsearch (for demo purposes)
 // @Tags         Replay
 // @Accept       json
 // @Produce      json
 // @Success      200  {object}  dto.APIResponse
+// @Failure      409  {object}  dto.APIResponse
 // @Failure      500  {object}  dto.APIResponse
 // @Router       /api/v1/replay/index [delete]
 func (b *Controller) DeleteElasticsearchIndex(c *gin.Context) {
 	// Delete the bank accounts index
 	err := b.ReplayService.DeleteElasticsearchIndex(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
-			dto.CodeInternalServerError,
+		status, code := replayJobErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
 			"failed to delete Elasticsearch index",
 			err.Error(),
 		))
@@ -0,0 +1,231 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/dto"
+	"github.com/th1enq/es-demo/internal/service"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+const (
+	eventArchiveContentType = "application/gzip"
+	eventArchiveFormField   = "archive"
+)
+
+type EventArchiveController struct {
+	eventArchiveService *service.EventArchiveService
+	validator           *validator.Validate
+	logger              *zap.Logger
+}
+
+func NewEventArchiveController(eventArchiveService *service.EventArchiveService, logger *zap.Logger) *EventArchiveController {
+	return &EventArchiveController{
+		eventArchiveService: eventArchiveService,
+		validator:           validator.New(),
+		logger:              logger,
+	}
+}
+
+// ExportEvents godoc
+// @Summary      Export Events
+// @Description  Export event streams, all or by aggregate type or ids, up to the current head position as gzip NDJSON archive. The first line is the header,
+// @Description  every event line carries its global position and checksum and the last line is the manifest with the archive checksum and position range
+// @Tags         Admin
+// @Accept       json
+// @Produce      application/gzip
+// @Security     BearerAuth
+// @Param        request  body      dto.EventExportRequest  false  "Streams to export"
+// @Success      200      {file}    file
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/admin/event-store/exports [post]
+func (ec *EventArchiveController) ExportEvents(c *gin.Context) {
+	var request dto.EventExportRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+				dto.CodeBadRequest,
+				"invalid request body",
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	if err := ec.validator.StructCtx(c, request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	filename := fmt.Sprintf("events-%s.ndjson.gz", time.Now().UTC().Format("20060102T150405Z"))
+	c.Header("Content-Type", eventArchiveContentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	manifest, err := ec.eventArchiveService.Export(c, c.Writer, service.EventExportRequest{
+		AggregateType:      es.AggregateType(request.AggregateType),
+		AggregateIDs:       request.AggregateIDs,
+		Anonymize:          request.Anonymize,
+		AnonymizedPassword: request.AnonymizedPassword,
+	})
+	if err != nil {
+		ec.logger.Error("Failed to export events", zap.Error(err))
+		// once the archive is streaming the status is sent, the missing manifest tells readers it is incomplete
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
+				dto.CodeInternalServerError,
+				"failed to export events",
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	ec.logger.Info("Events exported",
+		zap.String("filename", filename),
+		zap.Int64("events", manifest.Events),
+		zap.String("checksum", manifest.Checksum))
+}
+
+// ImportEvents godoc
+// @Summary      Import Events
+// @Description  Import a gzip NDJSON archive of ExportEvents, sent as request body or as multipart file "archive". The archive checksums are verified and every stream is
+// @Description  checked against the store before anything is written: stored events must match and new events must continue the stored stream. Events stored already are skipped,
+// @Description  so an import is completed by importing the archive again. Conflicts reject the archive unless skipped, which leaves the conflicting streams out
+// @Tags         Admin
+// @Accept       application/gzip
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        dry_run             query     string  false  "Only verify the archive and check it against the store (true/false)"
+// @Param        preserve_positions  query     string  false  "Keep the global positions of the archive, they must be free in the store (true/false)"
+// @Param        skip_conflicts      query     string  false  "Import the streams without conflicts instead of rejecting the archive (true/false)"
+// @Param        publish             query     string  false  "Publish imported events to the event bus for the projections (true/false)"
+// @Param        archive             formData  file    false  "Event archive"
+// @Success      200                 {object}  dto.APIResponse{data=service.EventImportResult}
+// @Failure      400                 {object}  dto.APIResponse
+// @Failure      409                 {object}  dto.APIResponse{error=dto.ErrorInfo{details=service.EventImportResult}}
+// @Failure      500                 {object}  dto.APIResponse
+// @Router       /api/v1/admin/event-store/imports [post]
+func (ec *EventArchiveController) ImportEvents(c *gin.Context) {
+	var options service.EventImportOptions
+	for param, target := range map[string]*bool{
+		"dry_run":            &options.DryRun,
+		"preserve_positions": &options.PreservePositions,
+		"skip_conflicts":     &options.SkipConflicts,
+		"publish":            &options.Publish,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+				dto.CodeBadRequest,
+				fmt.Sprintf("invalid %s query parameter", param),
+				err.Error(),
+			))
+			return
+		}
+		*target = parsed
+	}
+
+	archive, cleanup, err := ec.receiveArchive(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid event archive",
+			err.Error(),
+		))
+		return
+	}
+	defer cleanup()
+
+	result, err := ec.eventArchiveService.Import(c, archive, options)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrEventImportConflicts):
+			c.JSON(http.StatusConflict, dto.NewErrorResponse(
+				dto.CodeConflict,
+				"event archive conflicts with the event store",
+				result,
+			))
+		case errors.Is(err, es.ErrInvalidArchive), errors.Is(err, es.ErrArchiveChecksumMismatch):
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+				dto.CodeBadRequest,
+				"invalid event archive",
+				err.Error(),
+			))
+		default:
+			ec.logger.Error("Failed to import events", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
+				dto.CodeInternalServerError,
+				"failed to import events",
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	message := "events imported successfully"
+	if options.DryRun {
+		message = "event archive verified successfully"
+	}
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		message,
+		result,
+	))
+}
+
+// receiveArchive returns the uploaded archive, request bodies are spooled to a temporary file as the import reads
+// the archive twice.
+func (ec *EventArchiveController) receiveArchive(c *gin.Context) (io.ReadSeeker, func(), error) {
+	if c.ContentType() == binding.MIMEMultipartPOSTForm {
+		header, err := c.FormFile(eventArchiveFormField)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "c.FormFile")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "header.Open")
+		}
+		return file, func() { _ = file.Close() }, nil
+	}
+
+	file, err := os.CreateTemp("", "event-archive-*.ndjson.gz")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "os.CreateTemp")
+	}
+	cleanup := func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}
+	if _, err := io.Copy(file, c.Request.Body); err != nil {
+		cleanup()
+		return nil, nil, errors.Wrap(err, "io.Copy")
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, nil, errors.Wrap(err, "file.Seek")
+	}
+	return file, cleanup, nil
+}
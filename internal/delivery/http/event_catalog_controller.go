@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/dto"
+	"github.com/th1enq/es-demo/internal/service"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+type EventCatalogController struct {
+	eventCatalogService *service.EventCatalogService
+	logger              *zap.Logger
+}
+
+func NewEventCatalogController(eventCatalogService *service.EventCatalogService, logger *zap.Logger) *EventCatalogController {
+	return &EventCatalogController{
+		eventCatalogService: eventCatalogService,
+		logger:              logger,
+	}
+}
+
+// ListEventTypes godoc
+// @Summary      List Event Types
+// @Description  Contracts of the events published to Kafka: every event type with its aggregate type, topic and the JSON Schema (draft 2020-12)
+// @Description  of the latest payload revision. Revisions of an event type are backward compatible, breaking changes get a new event type version
+// @Tags         Event Catalog
+// @Produce      json
+// @Success      200  {object}  dto.APIResponse
+// @Router       /api/v1/event-catalog [get]
+func (ec *EventCatalogController) ListEventTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"event types retrieved successfully",
+		ec.eventCatalogService.ListEventTypes(),
+	))
+}
+
+// GetEventType godoc
+// @Summary      Get Event Type
+// @Description  Event type contract with all JSON Schema revisions of its payload, oldest first
+// @Tags         Event Catalog
+// @Produce      json
+// @Param        eventType  path      string  true  "Event type, e.g. BALANCE_DEPOSITED_V1"
+// @Success      200        {object}  dto.APIResponse
+// @Failure      404        {object}  dto.APIResponse
+// @Router       /api/v1/event-catalog/{eventType} [get]
+func (ec *EventCatalogController) GetEventType(c *gin.Context) {
+	entry, err := ec.eventCatalogService.GetEventType(es.EventType(c.Param("eventType")))
+	if err != nil {
+		if errors.Is(err, es.ErrEventSchemaNotFound) {
+			c.JSON(http.StatusNotFound, dto.NewErrorResponse(
+				dto.CodeNotFound,
+				"event type not found",
+				err.Error(),
+			))
+			return
+		}
+		ec.logger.Error("Failed to get event type", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
+			dto.CodeInternalServerError,
+			"failed to get event type",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"event type retrieved successfully",
+		entry,
+	))
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/es-demo/pkg/health"
+)
+
+type HealthController struct {
+	registry *health.Registry
+}
+
+func NewHealthController(registry *health.Registry) *HealthController {
+	return &HealthController{
+		registry: registry,
+	}
+}
+
+// Live godoc
+// @Summary      Liveness probe
+// @Description  Reports that the process is running and able to serve HTTP requests
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object}  map[string]string
+// @Router       /health/live [get]
+func (h *HealthController) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": health.StatusUp,
+	})
+}
+
+// Ready godoc
+// @Summary      Readiness probe
+// @Description  Checks Postgres, MongoDB, Kafka, Elasticsearch and projection consumers. Returns 503 when a critical dependency is down, 200 with degraded status when optional features are unavailable
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object}  health.Report
+// @Failure      503  {object}  health.Report
+// @Router       /health/ready [get]
+func (h *HealthController) Ready(c *gin.Context) {
+	report := h.registry.Run(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status == health.StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
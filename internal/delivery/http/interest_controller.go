@@ -0,0 +1,93 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/dto"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/service"
+	"go.uber.org/zap"
+)
+
+type InterestController struct {
+	interestService *service.InterestService
+	validator       *validator.Validate
+	logger          *zap.Logger
+}
+
+func NewInterestController(interestService *service.InterestService, logger *zap.Logger) *InterestController {
+	return &InterestController{
+		interestService: interestService,
+		validator:       validator.New(),
+		logger:          logger,
+	}
+}
+
+// AccrueInterest godoc
+// @Summary      Accrue Interest
+// @Description  Run the interest accrual job up to a finished UTC day, accruing the days every account has not accrued yet and
+// @Description  posting the months ending within them. The job runs in background as well, running it again for the same day is a no-op
+// @Tags         Interest
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.InterestAccrualRequest  true  "Last day to accrue"
+// @Success      200      {object}  dto.APIResponse{data=service.InterestRunResult}
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/interest/accruals [post]
+func (ic *InterestController) AccrueInterest(c *gin.Context) {
+	var request dto.InterestAccrualRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	if err := ic.validator.StructCtx(c, request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	through, err := time.Parse(time.DateOnly, request.Through)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid through day",
+			err.Error(),
+		))
+		return
+	}
+
+	result, err := ic.interestService.AccrueThrough(c, through)
+	if err != nil {
+		status, code := http.StatusInternalServerError, dto.CodeInternalServerError
+		if errors.Is(err, bankAccountErrors.ErrInterestPeriodNotOver) {
+			status, code = http.StatusUnprocessableEntity, dto.CodeUnprocessableEntity
+		}
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to accrue interest",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"interest accrued successfully",
+		result,
+	))
+}
@@ -0,0 +1,245 @@
+package http
+
+import (
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/internal/dto"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/service"
+	"go.uber.org/zap"
+)
+
+const (
+	csvContentType = "text/csv"
+
+	payrollCSVAccountID = "account_id"
+	payrollCSVAmount    = "amount"
+	payrollCSVCurrency  = "currency"
+	payrollCSVReference = "reference"
+)
+
+type PayrollController struct {
+	payrollService *service.PayrollService
+	validator      *validator.Validate
+	logger         *zap.Logger
+}
+
+func NewPayrollController(payrollService *service.PayrollService, logger *zap.Logger) *PayrollController {
+	return &PayrollController{
+		payrollService: payrollService,
+		validator:      validator.New(),
+		logger:         logger,
+	}
+}
+
+// CreatePayrollRun godoc
+// @Summary      Create Payroll Run
+// @Description  Submit a batch of deposits as JSON, or as CSV with text/csv content type, header row account_id,amount,reference and optional currency column and the run id in the id query parameter.
+// @Description  Every line is validated up front and the whole batch is rejected listing invalid lines. Lines are deposited in background with per line status.
+// @Description  Submitting the same run id again returns the existing run and resumes it when it was stopped
+// @Tags         Payroll
+// @Accept       json
+// @Accept       text/csv
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       query     string                 false  "Run ID, required for CSV"
+// @Param        request  body      dto.PayrollRunRequest  true   "Payroll lines"
+// @Success      200      {object}  dto.APIResponse{data=domain.PayrollRun}
+// @Success      202      {object}  dto.APIResponse{data=domain.PayrollRun}
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      403      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse{error=dto.ErrorInfo{details=[]domain.PayrollLineError}}
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/payroll_runs [post]
+func (pc *PayrollController) CreatePayrollRun(c *gin.Context) {
+	var (
+		request dto.PayrollRunRequest
+		source  = domain.PayrollRunSourceJSON
+	)
+
+	if c.ContentType() == csvContentType {
+		lines, err := parsePayrollCSV(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+				dto.CodeBadRequest,
+				"invalid CSV body",
+				err.Error(),
+			))
+			return
+		}
+		request = dto.PayrollRunRequest{ID: c.Query("id"), Lines: lines}
+		source = domain.PayrollRunSourceCSV
+	} else if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	if err := pc.validator.StructCtx(c, request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	lines := make([]domain.PayrollLine, 0, len(request.Lines))
+	for _, line := range request.Lines {
+		lines = append(lines, domain.PayrollLine{
+			AccountID: line.AccountID,
+			Amount:    line.Amount,
+			Currency:  line.Currency,
+			Reference: line.Reference,
+		})
+	}
+
+	run, created, err := pc.payrollService.CreateRun(c, request.ID, source, lines)
+	if err != nil {
+		var validationErr *domain.PayrollValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusUnprocessableEntity, dto.NewErrorResponse(
+				dto.CodeUnprocessableEntity,
+				"invalid payroll lines",
+				validationErr.Lines,
+			))
+			return
+		}
+
+		status, code := payrollRunErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to create payroll run",
+			err.Error(),
+		))
+		return
+	}
+
+	if !created {
+		c.JSON(http.StatusOK, dto.NewSuccessResponse(
+			dto.CodeSuccess,
+			"payroll run already exists",
+			run,
+		))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.NewSuccessResponse(
+		dto.CodeCreated,
+		"payroll run started",
+		run,
+	))
+}
+
+// GetPayrollRun godoc
+// @Summary      Get Payroll Run
+// @Description  Payroll run progress with status, error and account version of every line
+// @Tags         Payroll
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Run ID"
+// @Success      200  {object}  dto.APIResponse{data=domain.PayrollRun}
+// @Failure      403  {object}  dto.APIResponse
+// @Failure      404  {object}  dto.APIResponse
+// @Failure      500  {object}  dto.APIResponse
+// @Router       /api/v1/payroll_runs/{id} [get]
+func (pc *PayrollController) GetPayrollRun(c *gin.Context) {
+	run, err := pc.payrollService.GetRun(c, c.Param("id"))
+	if err != nil {
+		status, code := payrollRunErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to get payroll run",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"payroll run retrieved successfully",
+		run,
+	))
+}
+
+func payrollRunErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, bankAccountErrors.ErrPayrollRunNotFound):
+		return http.StatusNotFound, dto.CodeNotFound
+	case errors.Is(err, bankAccountErrors.ErrPayrollRunConflict):
+		return http.StatusConflict, dto.CodeConflict
+	case errors.Is(err, bankAccountErrors.ErrInvalidPayrollRun):
+		return http.StatusUnprocessableEntity, dto.CodeUnprocessableEntity
+	default:
+		return http.StatusInternalServerError, dto.CodeInternalServerError
+	}
+}
+
+// parsePayrollCSV reads lines from CSV with header row, columns are matched by name in any order.
+func parsePayrollCSV(body io.Reader) ([]dto.PayrollLineRequest, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "read header")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{payrollCSVAccountID, payrollCSVAmount, payrollCSVReference} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.Errorf("missing column: %s", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	lines := make([]dto.PayrollLineRequest, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// lines are numbered like the JSON batch, header row is not counted
+		lineNo := len(lines) + 1
+		amount, err := strconv.ParseInt(field(record, payrollCSVAmount), 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d: invalid amount in minor units", lineNo)
+		}
+
+		lines = append(lines, dto.PayrollLineRequest{
+			AccountID: field(record, payrollCSVAccountID),
+			Amount:    amount,
+			Currency:  strings.ToUpper(field(record, payrollCSVCurrency)),
+			Reference: field(record, payrollCSVReference),
+		})
+		if len(lines) > domain.MaxPayrollRunLines {
+			return nil, errors.Errorf("more than %d lines", domain.MaxPayrollRunLines)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/dto"
+	"github.com/th1enq/es-demo/pkg/constants"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+type ProjectionController struct {
+	projections *es.ProjectionRegistry
+	logger      *zap.Logger
+}
+
+func NewProjectionController(projections *es.ProjectionRegistry, logger *zap.Logger) *ProjectionController {
+	return &ProjectionController{
+		projections: projections,
+		logger:      logger,
+	}
+}
+
+// ListProjections godoc
+// @Summary      List Projections
+// @Description  Status of all projections: state, checkpoint position, lag behind the event store and last error
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  dto.APIResponse
+// @Failure      500  {object}  dto.APIResponse
+// @Router       /api/v1/admin/projections [get]
+func (p *ProjectionController) ListProjections(c *gin.Context) {
+	runners := p.projections.List()
+	statuses := make([]*es.ProjectionStatus, 0, len(runners))
+
+	for _, runner := range runners {
+		status, err := runner.Status(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
+				dto.CodeInternalServerError,
+				"failed to get projection status",
+				err.Error(),
+			))
+			return
+		}
+		statuses = append(statuses, status)
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"projections retrieved successfully",
+		statuses,
+	))
+}
+
+// GetProjection godoc
+// @Summary      Get Projection
+// @Description  Status of projection by name
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name  path      string  true  "Projection name"
+// @Success      200   {object}  dto.APIResponse
+// @Failure      404   {object}  dto.APIResponse
+// @Failure      500   {object}  dto.APIResponse
+// @Router       /api/v1/admin/projections/{name} [get]
+func (p *ProjectionController) GetProjection(c *gin.Context) {
+	runner, ok := p.getRunner(c)
+	if !ok {
+		return
+	}
+
+	status, err := runner.Status(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
+			dto.CodeInternalServerError,
+			"failed to get projection status",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"projection retrieved successfully",
+		status,
+	))
+}
+
+// PauseProjection godoc
+// @Summary      Pause Projection
+// @Description  Stop applying new events to projection, in-flight events are finished first
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name  path      string  true  "Projection name"
+// @Success      200   {object}  dto.APIResponse
+// @Failure      404   {object}  dto.APIResponse
+// @Failure      409   {object}  dto.APIResponse
+// @Router       /api/v1/admin/projections/{name}/pause [post]
+func (p *ProjectionController) PauseProjection(c *gin.Context) {
+	runner, ok := p.getRunner(c)
+	if !ok {
+		return
+	}
+
+	if err := runner.Pause(); err != nil {
+		c.JSON(http.StatusConflict, dto.NewErrorResponse(
+			dto.CodeConflict,
+			"failed to pause projection",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeUpdated,
+		"projection paused successfully",
+		gin.H{"name": runner.Name()},
+	))
+}
+
+// ResumeProjection godoc
+// @Summary      Resume Projection
+// @Description  Continue applying events to paused projection
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name  path      string  true  "Projection name"
+// @Success      200   {object}  dto.APIResponse
+// @Failure      404   {object}  dto.APIResponse
+// @Failure      409   {object}  dto.APIResponse
+// @Router       /api/v1/admin/projections/{name}/resume [post]
+func (p *ProjectionController) ResumeProjection(c *gin.Context) {
+	runner, ok := p.getRunner(c)
+	if !ok {
+		return
+	}
+
+	if err := runner.Resume(); err != nil {
+		c.JSON(http.StatusConflict, dto.NewErrorResponse(
+			dto.CodeConflict,
+			"failed to resume projection",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeUpdated,
+		"projection resumed successfully",
+		gin.H{"name": runner.Name()},
+	))
+}
+
+// RebuildProjection godoc
+// @Summary      Rebuild Projection
+// @Description  Truncate read model and checkpoints and replay all events from the event store in background. Progress is reported by projection status
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name  path      string  true  "Projection name"
+// @Success      202   {object}  dto.APIResponse
+// @Failure      404   {object}  dto.APIResponse
+// @Failure      409   {object}  dto.APIResponse
+// @Router       /api/v1/admin/projections/{name}/rebuild [post]
+func (p *ProjectionController) RebuildProjection(c *gin.Context) {
+	runner, ok := p.getRunner(c)
+	if !ok {
+		return
+	}
+
+	status, err := runner.Status(c.Request.Context())
+	if err == nil && status.State == es.ProjectionStateRebuilding {
+		c.JSON(http.StatusConflict, dto.NewErrorResponse(
+			dto.CodeConflict,
+			"failed to rebuild projection",
+			es.ErrProjectionRebuilding.Error(),
+		))
+		return
+	}
+
+	// rebuild outlives the request, status endpoint reports progress
+	go func() {
+		stats, err := runner.Rebuild(context.WithoutCancel(c.Request.Context()), nil)
+		if err != nil {
+			p.logger.Error("Projection rebuild failed", zap.String("projection", runner.Name()), zap.Error(err))
+			return
+		}
+		p.logger.Info("Projection rebuild finished", zap.String("projection", runner.Name()), zap.Any("stats", stats))
+	}()
+
+	c.JSON(http.StatusAccepted, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"projection rebuild started",
+		gin.H{"name": runner.Name()},
+	))
+}
+
+func (p *ProjectionController) getRunner(c *gin.Context) (*es.ProjectionRunner, bool) {
+	runner, err := p.projections.Get(c.Param(constants.Name))
+	if err != nil {
+		status, code := http.StatusInternalServerError, dto.CodeInternalServerError
+		if errors.Is(err, es.ErrProjectionNotFound) {
+			status, code = http.StatusNotFound, dto.CodeNotFound
+		}
+		c.JSON(status, dto.NewErrorResponse(code, "projection not found", err.Error()))
+		return nil, false
+	}
+	return runner, true
+}
@@ -6,26 +6,41 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/th1enq/es-demo/internal/service"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.uber.org/zap"
 )
 
 type HTTPServer interface {
 	Start(ctx context.Context) error
+	Shutdown(ctx context.Context) error
 }
 
 type Config struct {
-	Host string
-	Port int
+	Host        string
+	Port        int
+	ServiceName string
 }
 
 type httpServer struct {
-	cfg            Config
-	controller     *Controller
-	authController *AuthController
-	authMiddleware *AuthMiddleware
-	logger         *zap.Logger
+	cfg                     Config
+	controller              *Controller
+	authController          *AuthController
+	authMiddleware          *AuthMiddleware
+	healthController        *HealthController
+	projectionController    *ProjectionController
+	consistencyController   *ConsistencyController
+	payrollController       *PayrollController
+	standingOrderController *StandingOrderController
+	interestController      *InterestController
+	statementController     *StatementController
+	eventArchiveController  *EventArchiveController
+	eventCatalogController  *EventCatalogController
+	server                  *http.Server
+	logger                  *zap.Logger
 }
 
 func NewHTTPServer(
@@ -33,25 +48,50 @@ func NewHTTPServer(
 	controller *Controller,
 	authController *AuthController,
 	authMiddleware *AuthMiddleware,
+	healthController *HealthController,
+	projectionController *ProjectionController,
+	consistencyController *ConsistencyController,
+	payrollController *PayrollController,
+	standingOrderController *StandingOrderController,
+	interestController *InterestController,
+	statementController *StatementController,
+	eventArchiveController *EventArchiveController,
+	eventCatalogController *EventCatalogController,
 	logger *zap.Logger,
 ) HTTPServer {
-	return &httpServer{
-		cfg:            cfg,
-		controller:     controller,
-		authController: authController,
-		authMiddleware: authMiddleware,
-		logger:         logger,
+	s := &httpServer{
+		cfg:                     cfg,
+		controller:              controller,
+		authController:          authController,
+		authMiddleware:          authMiddleware,
+		healthController:        healthController,
+		projectionController:    projectionController,
+		consistencyController:   consistencyController,
+		payrollController:       payrollController,
+		standingOrderController: standingOrderController,
+		interestController:      interestController,
+		statementController:     statementController,
+		eventArchiveController:  eventArchiveController,
+		eventCatalogController:  eventCatalogController,
+		logger:                  logger,
 	}
+	s.server = &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Handler: s.RegisRouter(),
+	}
+	return s
 }
 
 func (s *httpServer) RegisRouter() *gin.Engine {
 	router := gin.Default()
+	router.Use(otelgin.Middleware(s.cfg.ServiceName))
 
 	// CORS middleware for frontend
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, traceparent, tracestate, If-None-Match")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, Content-Disposition")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -61,11 +101,9 @@ func (s *httpServer) RegisRouter() *gin.Engine {
 		c.Next()
 	})
 
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "ok",
-		})
-	})
+	router.GET("/health", s.healthController.Live)
+	router.GET("/health/live", s.healthController.Live)
+	router.GET("/health/ready", s.healthController.Ready)
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -87,25 +125,95 @@ func (s *httpServer) RegisRouter() *gin.Engine {
 			bankAccounts.GET("/:id", s.controller.GetBankAccountByID)
 			bankAccounts.GET("/:id/version/:version", s.controller.GetBankAccountByVersion)
 			bankAccounts.GET("/:id/events", s.controller.GetEventsHistory)
+			bankAccounts.GET("/:id/transactions", s.controller.GetTransactions)
 
-			// Protected routes (authentication required)
-			protected := bankAccounts.Group("", s.authMiddleware.JWTAuth())
+			// Owner routes, customers act on their own account only
+			owner := bankAccounts.Group("", s.authMiddleware.JWTAuth(), s.authMiddleware.RequireAccountOwner())
 			{
-				protected.POST("/:id/deposite", s.controller.DepositeBalance)
-				protected.POST("/:id/withdraw", s.controller.WithdrawBalance)
+				owner.POST("/:id/deposite", s.controller.DepositeBalance)
+				owner.POST("/:id/withdraw", s.controller.WithdrawBalance)
+				owner.POST("/:id/holds", s.controller.PlaceHold)
+				owner.POST("/:id/holds/:hold_id/capture", s.controller.CaptureHold)
+				owner.POST("/:id/holds/:hold_id/release", s.controller.ReleaseHold)
+				owner.GET("/:id/statements", s.statementController.GetStatement)
+				owner.PUT("/:id/email", s.controller.ChangeEmail)
+				owner.PUT("/:id/profile", s.controller.UpdateProfile)
+				owner.PUT("/:id/password", s.controller.ChangePassword)
+			}
+
+			// Account administration routes
+			accountAdmin := bankAccounts.Group("", s.authMiddleware.JWTAuth(), s.authMiddleware.RequireRole(service.RoleAdmin))
+			{
+				accountAdmin.POST("/:id/freeze", s.controller.FreezeAccount)
+				accountAdmin.POST("/:id/unfreeze", s.controller.UnfreezeAccount)
+				accountAdmin.POST("/:id/close", s.controller.CloseAccount)
+				accountAdmin.PUT("/:id/withdrawal_policy", s.controller.ChangeWithdrawalPolicy)
+				accountAdmin.PUT("/:id/interest_rate", s.controller.ChangeInterestRate)
+				accountAdmin.POST("/:id/reversals", s.controller.ReverseTransaction)
+				accountAdmin.POST("/:id/rollback", s.controller.RollbackBankAccount)
 			}
 		}
 
+		// Payroll run routes
+		payrollRuns := apiV1.Group("/payroll_runs", s.authMiddleware.JWTAuth(), s.authMiddleware.RequireRole(service.RoleAdmin))
+		{
+			payrollRuns.POST("", s.payrollController.CreatePayrollRun)
+			payrollRuns.GET("/:id", s.payrollController.GetPayrollRun)
+		}
+
+		// Standing order routes
+		standingOrders := apiV1.Group("/standing_orders", s.authMiddleware.JWTAuth())
+		{
+			standingOrders.POST("", s.standingOrderController.CreateStandingOrder)
+			standingOrders.GET("/:id", s.standingOrderController.GetStandingOrder)
+			standingOrders.POST("/:id/cancel", s.standingOrderController.CancelStandingOrder)
+		}
+
+		// Interest accrual routes
+		interest := apiV1.Group("/interest", s.authMiddleware.JWTAuth(), s.authMiddleware.RequireRole(service.RoleAdmin))
+		{
+			interest.POST("/accruals", s.interestController.AccrueInterest)
+		}
+
 		// Replay routes for Event Sourcing demonstration
 		replay := apiV1.Group("/replay")
 		{
 			// Public routes for demonstration purposes
 			replay.POST("/events", s.controller.ReplayAllEvents)
+			replay.POST("/streams", s.controller.ReplayStreams)
+			replay.GET("/jobs", s.controller.ListReplayJobs)
+			replay.GET("/jobs/:id", s.controller.GetReplayJob)
+			replay.POST("/jobs/:id/cancel", s.controller.CancelReplayJob)
+			replay.POST("/jobs/:id/resume", s.controller.ResumeReplayJob)
 			replay.GET("/accounts/:id", s.controller.GetAccountFromElasticsearch)
 			replay.GET("/accounts/search", s.controller.SearchAccountsInElasticsearch)
 			replay.GET("/summary", s.controller.GetAccountSummary)
+			replay.GET("/index", s.controller.GetElasticsearchIndexStatus)
+			replay.POST("/index/rollback", s.controller.RollbackElasticsearchIndex)
 			replay.DELETE("/index", s.controller.DeleteElasticsearchIndex)
 		}
+
+		// Event catalog routes (public) describing the events published to Kafka
+		eventCatalog := apiV1.Group("/event-catalog")
+		{
+			eventCatalog.GET("", s.eventCatalogController.ListEventTypes)
+			eventCatalog.GET("/:eventType", s.eventCatalogController.GetEventType)
+		}
+
+		// Admin routes for projection management
+		admin := apiV1.Group("/admin", s.authMiddleware.JWTAuth(), s.authMiddleware.RequireRole(service.RoleAdmin))
+		{
+			admin.GET("/projections", s.projectionController.ListProjections)
+			admin.GET("/projections/:name", s.projectionController.GetProjection)
+			admin.POST("/projections/:name/pause", s.projectionController.PauseProjection)
+			admin.POST("/projections/:name/resume", s.projectionController.ResumeProjection)
+			admin.POST("/projections/:name/rebuild", s.projectionController.RebuildProjection)
+			admin.POST("/consistency/checks", s.consistencyController.StartConsistencyCheck)
+			admin.GET("/consistency/checks", s.consistencyController.ListConsistencyChecks)
+			admin.GET("/consistency/checks/:id", s.consistencyController.GetConsistencyCheck)
+			admin.POST("/event-store/exports", s.eventArchiveController.ExportEvents)
+			admin.POST("/event-store/imports", s.eventArchiveController.ImportEvents)
+		}
 	}
 
 	return router
@@ -114,13 +222,18 @@ func (s *httpServer) RegisRouter() *gin.Engine {
 func (s *httpServer) Start(ctx context.Context) error {
 	s.logger.Info("Starting HTTP Server", zap.String("host", s.cfg.Host), zap.Int("port", s.cfg.Port))
 
-	server := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port),
-		Handler: s.RegisRouter(),
+	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return errors.Wrap(err, "HTTP Server failed")
 	}
+	return nil
+}
+
+// Shutdown stops accepting new connections and waits for in-flight requests until ctx deadline.
+func (s *httpServer) Shutdown(ctx context.Context) error {
+	s.logger.Info("Shutting down HTTP Server")
 
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		s.logger.Fatal("HTTP Server failed", zap.Error(err))
+	if err := s.server.Shutdown(ctx); err != nil {
+		return errors.Wrap(err, "HTTP Server shutdown")
 	}
 	return nil
 }
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/th1enq/es-demo/internal/service"
+	"go.uber.org/zap"
+)
+
+// roleAuthService accepts the role name as the token, methods not used by AuthMiddleware are left unimplemented.
+type roleAuthService struct {
+	service.AuthService
+}
+
+func (s *roleAuthService) ValidateToken(tokenString string) (*jwt.Token, error) {
+	claims := &service.CustomClaims{UserID: "account-" + tokenString, Role: tokenString}
+	return &jwt.Token{Claims: claims, Valid: true}, nil
+}
+
+func (s *roleAuthService) CheckSession(ctx context.Context, claims *service.CustomClaims) error {
+	return nil
+}
+
+func TestRouterAdminRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := &httpServer{
+		authMiddleware: NewAuthMiddleware(&roleAuthService{}, zap.NewNop()),
+		logger:         zap.NewNop(),
+	}
+	router := server.RegisRouter()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{name: "create payroll run", method: http.MethodPost, path: "/api/v1/payroll_runs"},
+		{name: "get payroll run", method: http.MethodGet, path: "/api/v1/payroll_runs/run-1"},
+		{name: "list projections", method: http.MethodGet, path: "/api/v1/admin/projections"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{}"))
+			request.Header.Set("Authorization", "Bearer "+service.RoleCustomer)
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+		})
+	}
+}
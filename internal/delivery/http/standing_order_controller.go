@@ -0,0 +1,198 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/internal/dto"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/service"
+	"go.uber.org/zap"
+)
+
+type StandingOrderController struct {
+	standingOrderService *service.StandingOrderService
+	validator            *validator.Validate
+	logger               *zap.Logger
+}
+
+func NewStandingOrderController(standingOrderService *service.StandingOrderService, logger *zap.Logger) *StandingOrderController {
+	return &StandingOrderController{
+		standingOrderService: standingOrderService,
+		validator:            validator.New(),
+		logger:               logger,
+	}
+}
+
+// CreateStandingOrder godoc
+// @Summary      Create Standing Order
+// @Description  Schedule a future dated or recurring deposit, withdrawal or transfer. Frequency is once, daily, weekly, monthly or cron with a five field UTC cron expression.
+// @Description  Occurrences are executed by a single instance, failed occurrences are retried with backoff up to max attempts and then skipped
+// @Tags         Standing Orders
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.StandingOrderRequest  true  "Standing order"
+// @Success      201      {object}  dto.APIResponse{data=domain.StandingOrder}
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      403      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      422      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/standing_orders [post]
+func (sc *StandingOrderController) CreateStandingOrder(c *gin.Context) {
+	var request dto.StandingOrderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	if err := sc.validator.StructCtx(c, request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	instruction := domain.StandingOrderInstruction{
+		Kind:            domain.StandingOrderKind(request.Kind),
+		AccountID:       request.AccountID,
+		TargetAccountID: request.TargetAccountID,
+		Amount:          request.Amount,
+		Currency:        request.Currency,
+		Reference:       request.Reference,
+	}
+	schedule := domain.Schedule{
+		Frequency: domain.ScheduleFrequency(request.Frequency),
+		Cron:      request.Cron,
+		StartAt:   request.StartAt,
+		EndAt:     request.EndAt,
+	}
+
+	order, err := sc.standingOrderService.CreateOrder(c, callerOf(c), request.ID, instruction, schedule, request.MaxAttempts)
+	if err != nil {
+		status, code := standingOrderErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to create standing order",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.NewSuccessResponse(
+		dto.CodeCreated,
+		"standing order created successfully",
+		order,
+	))
+}
+
+// GetStandingOrder godoc
+// @Summary      Get Standing Order
+// @Description  Standing order with the occurrence due next and the latest executions
+// @Tags         Standing Orders
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Standing order ID"
+// @Success      200  {object}  dto.APIResponse{data=domain.StandingOrder}
+// @Failure      403  {object}  dto.APIResponse
+// @Failure      404  {object}  dto.APIResponse
+// @Failure      500  {object}  dto.APIResponse
+// @Router       /api/v1/standing_orders/{id} [get]
+func (sc *StandingOrderController) GetStandingOrder(c *gin.Context) {
+	order, err := sc.standingOrderService.GetOrder(c, callerOf(c), c.Param("id"))
+	if err != nil {
+		status, code := standingOrderErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to get standing order",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"standing order retrieved successfully",
+		order,
+	))
+}
+
+// CancelStandingOrder godoc
+// @Summary      Cancel Standing Order
+// @Description  Stop further occurrences of an active standing order
+// @Tags         Standing Orders
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                          true   "Standing order ID"
+// @Param        request  body      dto.CancelStandingOrderRequest  false  "Cancellation reason"
+// @Success      200      {object}  dto.APIResponse{data=domain.StandingOrder}
+// @Failure      400      {object}  dto.APIResponse
+// @Failure      403      {object}  dto.APIResponse
+// @Failure      404      {object}  dto.APIResponse
+// @Failure      409      {object}  dto.APIResponse
+// @Failure      500      {object}  dto.APIResponse
+// @Router       /api/v1/standing_orders/{id}/cancel [post]
+func (sc *StandingOrderController) CancelStandingOrder(c *gin.Context) {
+	var request dto.CancelStandingOrderRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+				dto.CodeBadRequest,
+				"invalid request body",
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	order, err := sc.standingOrderService.CancelOrder(c, callerOf(c), c.Param("id"), request.Reason)
+	if err != nil {
+		status, code := standingOrderErrorStatus(err)
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to cancel standing order",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(
+		dto.CodeSuccess,
+		"standing order cancelled successfully",
+		order,
+	))
+}
+
+func standingOrderErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, bankAccountErrors.ErrForbidden):
+		return http.StatusForbidden, dto.CodeForbidden
+	case errors.Is(err, bankAccountErrors.ErrStandingOrderNotFound),
+		errors.Is(err, bankAccountErrors.ErrBankAccountNotFound):
+		return http.StatusNotFound, dto.CodeNotFound
+	case errors.Is(err, bankAccountErrors.ErrStandingOrderExists),
+		errors.Is(err, bankAccountErrors.ErrStandingOrderNotActive):
+		return http.StatusConflict, dto.CodeConflict
+	case errors.Is(err, bankAccountErrors.ErrInvalidStandingOrder),
+		errors.Is(err, bankAccountErrors.ErrInvalidSchedule),
+		errors.Is(err, bankAccountErrors.ErrInvalidBalanceAmount),
+		errors.Is(err, bankAccountErrors.ErrInvalidCurrency),
+		errors.Is(err, bankAccountErrors.ErrCurrencyMismatch),
+		errors.Is(err, bankAccountErrors.ErrAccountClosed):
+		return http.StatusUnprocessableEntity, dto.CodeUnprocessableEntity
+	default:
+		return http.StatusInternalServerError, dto.CodeInternalServerError
+	}
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/internal/dto"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/internal/service"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+const (
+	testOwnerID    = "account-1"
+	testOtherID    = "account-2"
+	testOrderID    = "7d1c2c8e-3f0a-4c55-9a4e-2f8c3b7f6d10"
+	testNewOrderID = "1b0f6a52-8f0e-4b7e-a3d5-6f1e9f7c2a44"
+)
+
+// memoryAggregateStore keeps serialized events in memory, methods not used by the controllers are left unimplemented.
+type memoryAggregateStore struct {
+	es.AggregateStore
+	serializer es.Serializer
+	events     []es.Event
+}
+
+func (s *memoryAggregateStore) Load(ctx context.Context, aggregate es.Aggregate) error {
+	for _, event := range s.events {
+		if event.GetAggregateID() != aggregate.GetID() {
+			continue
+		}
+		deserializedEvent, err := s.serializer.DeserializeEvent(event)
+		if err != nil {
+			return err
+		}
+		if err := aggregate.RaiseEvent(deserializedEvent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memoryAggregateStore) Exists(ctx context.Context, aggregateID string) (bool, error) {
+	for _, event := range s.events {
+		if event.GetAggregateID() == aggregateID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memoryAggregateStore) Save(ctx context.Context, aggregate es.Aggregate) error {
+	changes := aggregate.GetChanges()
+	firstVersion := aggregate.GetVersion() - uint64(len(changes)) + 1
+	for i, change := range changes {
+		event, err := s.serializer.SerializeEvent(aggregate, change)
+		if err != nil {
+			return err
+		}
+		event.SetVersion(firstVersion + uint64(i))
+		s.events = append(s.events, event)
+	}
+	aggregate.ClearChanges()
+	return nil
+}
+
+// memoryScheduleRepository ignores the due times, the scheduler is not run by the controller tests.
+type memoryScheduleRepository struct {
+	domain.StandingOrderScheduleRepository
+}
+
+func (r *memoryScheduleRepository) Save(ctx context.Context, id string, dueAt *time.Time) error {
+	return nil
+}
+
+// newTestStandingOrderRouter serves the standing order routes to a caller signed in as userID with role,
+// the store holds the accounts of testOwnerID and testOtherID and an order of testOwnerID.
+func newTestStandingOrderRouter(t *testing.T, userID string, role string) *gin.Engine {
+	t.Helper()
+	ctx := context.Background()
+	store := &memoryAggregateStore{serializer: domain.NewEventSerializer()}
+
+	for _, id := range []string{testOwnerID, testOtherID} {
+		account := domain.NewBankAccountAggregate(id)
+		require.NoError(t, account.Apply(&events.BankAccountCreatedEventV1{Email: id + "@example.com", Balance: money.New(0, money.USD)}))
+		require.NoError(t, store.Save(ctx, account))
+	}
+
+	order := domain.NewStandingOrderAggregate(testOrderID)
+	require.NoError(t, order.CreateStandingOrder(ctx, testOwnerID, domain.StandingOrderInstruction{
+		Kind:      domain.StandingOrderKindWithdrawal,
+		AccountID: testOwnerID,
+		Amount:    100,
+		Currency:  money.USD,
+	}, domain.Schedule{Frequency: domain.ScheduleFrequencyDaily, StartAt: time.Now().UTC().Add(time.Hour)}, 3))
+	require.NoError(t, store.Save(ctx, order))
+
+	standingOrderService := service.NewStandingOrderService(
+		service.SchedulerConfig{MaxAttempts: 3},
+		store,
+		domain.NewEventSerializer(),
+		nil,
+		&memoryScheduleRepository{},
+		zap.NewNop(),
+	)
+	controller := NewStandingOrderController(standingOrderService, zap.NewNop())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("user_role", role)
+	})
+	router.POST("/standing_orders", controller.CreateStandingOrder)
+	router.GET("/standing_orders/:id", controller.GetStandingOrder)
+	router.POST("/standing_orders/:id/cancel", controller.CancelStandingOrder)
+	return router
+}
+
+func TestStandingOrderControllerOwnership(t *testing.T) {
+	createBody, err := json.Marshal(dto.StandingOrderRequest{
+		ID:        testNewOrderID,
+		Kind:      string(domain.StandingOrderKindWithdrawal),
+		AccountID: testOwnerID,
+		Amount:    100,
+		Frequency: string(domain.ScheduleFrequencyDaily),
+		StartAt:   time.Now().UTC().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		userID string
+		role   string
+		method string
+		path   string
+		body   []byte
+		status int
+	}{
+		{name: "non owner create", userID: testOtherID, role: service.RoleCustomer, method: http.MethodPost, path: "/standing_orders", body: createBody, status: http.StatusForbidden},
+		{name: "non owner get", userID: testOtherID, role: service.RoleCustomer, method: http.MethodGet, path: "/standing_orders/" + testOrderID, status: http.StatusForbidden},
+		{name: "non owner cancel", userID: testOtherID, role: service.RoleCustomer, method: http.MethodPost, path: "/standing_orders/" + testOrderID + "/cancel", status: http.StatusForbidden},
+		{name: "owner create", userID: testOwnerID, role: service.RoleCustomer, method: http.MethodPost, path: "/standing_orders", body: createBody, status: http.StatusCreated},
+		{name: "owner get", userID: testOwnerID, role: service.RoleCustomer, method: http.MethodGet, path: "/standing_orders/" + testOrderID, status: http.StatusOK},
+		{name: "owner cancel", userID: testOwnerID, role: service.RoleCustomer, method: http.MethodPost, path: "/standing_orders/" + testOrderID + "/cancel", status: http.StatusOK},
+		{name: "admin create", userID: testOtherID, role: service.RoleAdmin, method: http.MethodPost, path: "/standing_orders", body: createBody, status: http.StatusCreated},
+		{name: "admin get", userID: testOtherID, role: service.RoleAdmin, method: http.MethodGet, path: "/standing_orders/" + testOrderID, status: http.StatusOK},
+		{name: "admin cancel", userID: testOtherID, role: service.RoleAdmin, method: http.MethodPost, path: "/standing_orders/" + testOrderID + "/cancel", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestStandingOrderRouter(t, tt.userID, tt.role)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tt.method, tt.path, bytes.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.status, recorder.Code, recorder.Body.String())
+		})
+	}
+}
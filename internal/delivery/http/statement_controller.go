@@ -0,0 +1,127 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/dto"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/service"
+	"github.com/th1enq/es-demo/pkg/constants"
+	"go.uber.org/zap"
+)
+
+type StatementController struct {
+	statementService *service.StatementService
+	logger           *zap.Logger
+}
+
+func NewStatementController(statementService *service.StatementService, logger *zap.Logger) *StatementController {
+	return &StatementController{
+		statementService: statementService,
+		logger:           logger,
+	}
+}
+
+// GetStatement godoc
+// @Summary      Get Statement
+// @Description  Statement of the bank account for the UTC days from to to inclusive, computed from the event store with opening balance,
+// @Description  every balance changing transaction and closing balance. Rendered as JSON, CSV or ISO 20022 camt.053.001.02 XML
+// @Tags         BankAccount
+// @Produce      json
+// @Produce      text/csv
+// @Produce      application/xml
+// @Security     BearerAuth
+// @Param        id      path      string  true   "Bank Account ID"
+// @Param        from    query     string  false  "First day of the period (YYYY-MM-DD), defaults to the first day of the current month"
+// @Param        to      query     string  false  "Last day of the period (YYYY-MM-DD), defaults to today"
+// @Param        format  query     string  false  "json, csv or camt.053, defaults to json"
+// @Success      200     {object}  dto.APIResponse{data=service.Statement}
+// @Failure      400     {object}  dto.APIResponse
+// @Failure      404     {object}  dto.APIResponse
+// @Failure      500     {object}  dto.APIResponse
+// @Router       /api/v1/bank_accounts/{id}/statements [get]
+func (sc *StatementController) GetStatement(c *gin.Context) {
+	today := time.Now().UTC()
+	from := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := today
+
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		day, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+				dto.CodeBadRequest,
+				fmt.Sprintf("invalid %s day", param),
+				err.Error(),
+			))
+			return
+		}
+		*target = day
+	}
+
+	format, err := service.ParseStatementFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(
+			dto.CodeBadRequest,
+			"invalid statement format",
+			err.Error(),
+		))
+		return
+	}
+
+	statement, err := sc.statementService.Generate(c, c.Param(constants.ID), from, to)
+	if err != nil {
+		status, code := http.StatusInternalServerError, dto.CodeInternalServerError
+		switch {
+		case errors.Is(err, service.ErrInvalidStatementPeriod):
+			status, code = http.StatusBadRequest, dto.CodeBadRequest
+		case errors.Is(err, bankAccountErrors.ErrBankAccountNotFound):
+			status, code = http.StatusNotFound, dto.CodeNotFound
+		}
+		c.JSON(status, dto.NewErrorResponse(
+			code,
+			"failed to generate statement",
+			err.Error(),
+		))
+		return
+	}
+
+	var (
+		buffer      bytes.Buffer
+		contentType string
+		extension   string
+	)
+	switch format {
+	case service.StatementFormatCSV:
+		err, contentType, extension = service.RenderStatementCSV(&buffer, statement), "text/csv; charset=utf-8", "csv"
+	case service.StatementFormatCamt053:
+		err, contentType, extension = service.RenderStatementCamt053(&buffer, statement), "application/xml; charset=utf-8", "xml"
+	default:
+		c.JSON(http.StatusOK, dto.NewSuccessResponse(
+			dto.CodeSuccess,
+			"statement generated successfully",
+			statement,
+		))
+		return
+	}
+	if err != nil {
+		sc.logger.Error("Failed to render statement", zap.String("statement_id", statement.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(
+			dto.CodeInternalServerError,
+			"failed to render statement",
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", statement.ID+"."+extension))
+	c.Data(http.StatusOK, contentType, buffer.Bytes())
+}
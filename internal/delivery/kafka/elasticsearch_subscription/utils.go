@@ -0,0 +1,14 @@
+package elasticsearch_subscription
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+func (s *ElasticsearchSubscription) commitMessage(ctx context.Context, r *kafka.Reader, m kafka.Message) {
+	if err := r.CommitMessages(ctx, m); err != nil {
+		s.log.Error("(elasticsearchSubscription) [CommitMessages] err", zap.Error(err))
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"github.com/segmentio/kafka-go"
 	"github.com/th1enq/es-demo/config"
 	"github.com/th1enq/es-demo/internal/domain"
-	"github.com/th1enq/es-demo/internal/mappers"
 	"github.com/th1enq/es-demo/internal/service"
 	"github.com/th1enq/es-demo/pkg/es"
 	"github.com/th1enq/es-demo/pkg/es/serializer"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -18,10 +20,8 @@ type MongoSubscription struct {
 	log                *zap.Logger
 	cfg                *config.Config
 	bankAccountService *service.BankAccountService
-	projection         es.Projection
+	runner             *es.ProjectionRunner
 	serializer         es.Serializer
-	mongoRepository    domain.MongoRepository
-	aggregateStore     es.AggregateStore
 	eventBus           es.EventsBus
 }
 
@@ -29,20 +29,16 @@ func NewBankAccountMongoSubscription(
 	log *zap.Logger,
 	cfg *config.Config,
 	bankAccountService *service.BankAccountService,
-	projection es.Projection,
+	runner *es.ProjectionRunner,
 	serializer es.Serializer,
-	mongoRepository domain.MongoRepository,
-	aggregateStore es.AggregateStore,
 	eventBus es.EventsBus,
 ) *MongoSubscription {
 	return &MongoSubscription{
 		log:                log,
 		cfg:                cfg,
 		bankAccountService: bankAccountService,
-		projection:         projection,
+		runner:             runner,
 		serializer:         serializer,
-		mongoRepository:    mongoRepository,
-		aggregateStore:     aggregateStore,
 		eventBus:           eventBus,
 	}
 }
@@ -56,26 +52,51 @@ func (s *MongoSubscription) ProcessMessagesErrGroup(ctx context.Context, r *kafk
 		default:
 		}
 
+		if err := s.runner.WaitRunning(ctx); err != nil {
+			if errors.Is(err, es.ErrProjectionStopped) {
+				return nil
+			}
+			return err
+		}
+
 		m, err := r.FetchMessage(ctx)
 		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			s.log.Warn("mongoSubscription.FetchMessage: %v", zap.Error(err))
 			continue
 		}
 
 		// s.logProcessMessage(m, workerID)
 
+		// fetched message is processed and committed even if shutdown starts meanwhile
+		processCtx := context.WithoutCancel(ctx)
+
 		switch m.Topic {
 		case es.GetTopicName(s.cfg.KafkaPublisherConfig.TopicPrefix, string(domain.BankAccountAggregateType)):
-			s.handleBankAccountEvents(ctx, r, m)
+			s.handleBankAccountEvents(processCtx, r, m)
 		}
 	}
 }
 
 func (s *MongoSubscription) handleBankAccountEvents(ctx context.Context, r *kafka.Reader, m kafka.Message) {
+	ctx, span := tracing.StartSpan(
+		tracing.ExtractKafkaHeaders(ctx, m.Headers),
+		"MongoSubscription.handleBankAccountEvents",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+	)
+	span.SetAttributes(
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.destination.name", m.Topic),
+		attribute.Int("messaging.kafka.partition", m.Partition),
+		attribute.Int64("messaging.kafka.offset", m.Offset),
+	)
+	defer span.End()
 
 	var events []es.Event
 	if err := serializer.Unmarshal(m.Value, &events); err != nil {
-		s.log.Error("serializer.Unmarshal", zap.Error(err))
+		s.log.Error("serializer.Unmarshal", zap.Error(tracing.TraceErr(span, err)))
 		// s.commitErrMessage(ctx, r, m)
 		return
 	}
@@ -89,45 +110,23 @@ func (s *MongoSubscription) handleBankAccountEvents(ctx context.Context, r *kafk
 }
 
 func (s *MongoSubscription) handle(ctx context.Context, r *kafka.Reader, m kafka.Message, event es.Event) error {
-	err := s.projection.When(ctx, event)
+	ctx, span := tracing.StartSpan(ctx, "MongoSubscription.handle", trace.WithLinks(
+		trace.LinkFromContext(tracing.ExtractMetadata(context.Background(), event.GetMetadata())),
+	))
+	span.SetAttributes(
+		attribute.String("aggregate_id", event.GetAggregateID()),
+		attribute.String("event_type", string(event.GetEventType())),
+		attribute.Int64("version", int64(event.GetVersion())),
+	)
+	defer span.End()
+
+	// runner skips already applied versions and fills stream gaps from the event store
+	err := s.runner.Handle(ctx, event)
 	if err != nil {
-		s.log.Error("MongoSubscription When err", zap.Error(err))
-
-		recreateErr := s.recreateProjection(ctx, event)
-		if recreateErr != nil {
-			return errors.Wrapf(recreateErr, "recreateProjection err: %v", err)
-		}
-
-		s.commitErrMessage(ctx, r, m)
-		return errors.Wrapf(err, "When type: %s, aggregateID: %s", event.GetEventType(), event.GetAggregateID())
+		s.log.Error("MongoSubscription runner.Handle err", zap.Error(tracing.TraceErr(span, err)))
+		return errors.Wrapf(err, "Handle type: %s, aggregateID: %s", event.GetEventType(), event.GetAggregateID())
 	}
 
 	s.log.Info("MongoSubscription <<<commit>>> event: %s", zap.String("event", event.String()))
 	return nil
 }
-
-func (s *MongoSubscription) recreateProjection(ctx context.Context, event es.Event) error {
-	s.log.Warn("MongoSubscription recreating projection", zap.String("aggregateID", event.GetAggregateID()), zap.Uint64("version", event.GetVersion()), zap.Any("type", event.GetEventType()))
-
-	err := s.mongoRepository.DeleteByAggregateID(ctx, event.GetAggregateID())
-	if err != nil {
-		s.log.Error("MongoSubscription DeleteByAggregateID", zap.Error(err))
-		return errors.Wrapf(err, "When DeleteByAggregateID type: %s, aggregateID: %s", event.GetEventType(), event.GetAggregateID())
-	}
-
-	bankAccountAggregate := domain.NewBankAccountAggregate(event.GetAggregateID())
-	err = s.aggregateStore.Load(ctx, bankAccountAggregate)
-	if err != nil {
-		s.log.Error("MongoSubscription aggregateStore.Load", zap.Error(err))
-		return errors.Wrapf(err, "When aggregateStore.Load type: %s, aggregateID: %s", event.GetEventType(), event.GetAggregateID())
-	}
-
-	err = s.mongoRepository.Insert(ctx, mappers.BankAccountToMongoProjection(bankAccountAggregate))
-	if err != nil {
-		s.log.Error("MongoSubscription mongoRepository.Insert", zap.Error(err))
-		return errors.Wrapf(err, "When mongoRepository.Insert type: %s, aggregateID: %s", event.GetEventType(), event.GetAggregateID())
-	}
-
-	s.log.Info("MongoSubscription <<<projection recreated commit>>> aggregateID: %s, version: %d", zap.String("aggregateID", event.GetAggregateID()), zap.Uint64("version", bankAccountAggregate.GetVersion()))
-	return nil
-}
@@ -0,0 +1,120 @@
+package transactions_subscription
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+	"github.com/th1enq/es-demo/config"
+	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/es/serializer"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// TransactionsSubscription keeps the transactions ledger up to date from the aggregate topic.
+type TransactionsSubscription struct {
+	log    *zap.Logger
+	cfg    *config.Config
+	runner *es.ProjectionRunner
+}
+
+func NewTransactionsSubscription(
+	log *zap.Logger,
+	cfg *config.Config,
+	runner *es.ProjectionRunner,
+) *TransactionsSubscription {
+	return &TransactionsSubscription{
+		log:    log,
+		cfg:    cfg,
+		runner: runner,
+	}
+}
+
+func (s *TransactionsSubscription) ProcessMessagesErrGroup(ctx context.Context, r *kafka.Reader, workerID int) error {
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if err := s.runner.WaitRunning(ctx); err != nil {
+			if errors.Is(err, es.ErrProjectionStopped) {
+				return nil
+			}
+			return err
+		}
+
+		m, err := r.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.log.Warn("transactionsSubscription.FetchMessage", zap.Error(err))
+			continue
+		}
+
+		// fetched message is processed and committed even if shutdown starts meanwhile
+		processCtx := context.WithoutCancel(ctx)
+
+		switch m.Topic {
+		case es.GetTopicName(s.cfg.KafkaPublisherConfig.TopicPrefix, string(domain.BankAccountAggregateType)):
+			s.handleBankAccountEvents(processCtx, r, m)
+		}
+	}
+}
+
+func (s *TransactionsSubscription) handleBankAccountEvents(ctx context.Context, r *kafka.Reader, m kafka.Message) {
+	ctx, span := tracing.StartSpan(
+		tracing.ExtractKafkaHeaders(ctx, m.Headers),
+		"TransactionsSubscription.handleBankAccountEvents",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+	)
+	span.SetAttributes(
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.destination.name", m.Topic),
+		attribute.Int("messaging.kafka.partition", m.Partition),
+		attribute.Int64("messaging.kafka.offset", m.Offset),
+	)
+	defer span.End()
+
+	var events []es.Event
+	if err := serializer.Unmarshal(m.Value, &events); err != nil {
+		s.log.Error("serializer.Unmarshal", zap.Error(tracing.TraceErr(span, err)))
+		s.commitMessage(ctx, r, m)
+		return
+	}
+
+	for _, event := range events {
+		if err := s.handle(ctx, event); err != nil {
+			return
+		}
+	}
+	s.commitMessage(ctx, r, m)
+}
+
+func (s *TransactionsSubscription) handle(ctx context.Context, event es.Event) error {
+	ctx, span := tracing.StartSpan(ctx, "TransactionsSubscription.handle", trace.WithLinks(
+		trace.LinkFromContext(tracing.ExtractMetadata(context.Background(), event.GetMetadata())),
+	))
+	span.SetAttributes(
+		attribute.String("aggregate_id", event.GetAggregateID()),
+		attribute.String("event_type", string(event.GetEventType())),
+		attribute.Int64("version", int64(event.GetVersion())),
+	)
+	defer span.End()
+
+	// runner skips duplicates and fills out of order gaps from the event store
+	if err := s.runner.Handle(ctx, event); err != nil {
+		s.log.Error("TransactionsSubscription runner.Handle err", zap.Error(tracing.TraceErr(span, err)))
+		return errors.Wrapf(err, "Handle type: %s, aggregateID: %s", event.GetEventType(), event.GetAggregateID())
+	}
+
+	s.log.Debug("TransactionsSubscription <<<commit>>> event", zap.String("event", event.String()))
+	return nil
+}
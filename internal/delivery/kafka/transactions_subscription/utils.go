@@ -0,0 +1,14 @@
+package transactions_subscription
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+func (s *TransactionsSubscription) commitMessage(ctx context.Context, r *kafka.Reader, m kafka.Message) {
+	if err := r.CommitMessages(ctx, m); err != nil {
+		s.log.Error("(transactionsSubscription) [CommitMessages] err", zap.Error(err))
+	}
+}
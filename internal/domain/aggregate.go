@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/Rhymond/go-money"
 	"github.com/pkg/errors"
@@ -42,14 +43,125 @@ func (a *BankAccountAggregate) When(event any) error {
 		a.BankAccount.FirstName = evt.FirstName
 		a.BankAccount.LastName = evt.LastName
 		a.BankAccount.PasswordHash = evt.PasswordHash
+		a.BankAccount.Status = AccountStatusActive
 		return nil
 
 	case *events.BalanceDepositedEventV1:
+		if err := a.checkCurrency(evt.Currency); err != nil {
+			return err
+		}
 		return a.BankAccount.Deposit(evt.Amount)
 
 	case *events.BalanceWithdrawedEventV1:
+		if err := a.checkCurrency(evt.Currency); err != nil {
+			return err
+		}
+		if !evt.WithdrawnAt.IsZero() {
+			a.BankAccount.WithdrawalTotals.Add(evt.Amount, evt.WithdrawnAt)
+		}
 		return a.BankAccount.Withdraw(evt.Amount)
 
+	case *events.WithdrawalPolicyChangedEventV1:
+		a.BankAccount.WithdrawalPolicy = WithdrawalPolicy{
+			OverdraftLimit:    evt.OverdraftLimit,
+			MaxPerTransaction: evt.MaxPerTransaction,
+			DailyLimit:        evt.DailyLimit,
+			MonthlyLimit:      evt.MonthlyLimit,
+		}
+		return nil
+
+	case *events.InterestRateChangedEventV1:
+		a.BankAccount.Interest.RateBps = evt.RateBps
+		a.BankAccount.Interest.RateChangedAt = evt.ChangedAt
+		if a.BankAccount.Interest.AccruingSince.IsZero() && evt.RateBps > 0 {
+			a.BankAccount.Interest.AccruingSince = evt.ChangedAt
+		}
+		return nil
+
+	case *events.InterestAccruedEventV1:
+		a.BankAccount.Interest.Accrued += evt.Amount
+		a.BankAccount.Interest.LastAccruedDay = evt.Day
+		return nil
+
+	case *events.InterestPostedEventV1:
+		if err := a.checkCurrency(evt.Currency); err != nil {
+			return err
+		}
+		a.BankAccount.Interest.Accrued -= evt.Accrued
+		a.BankAccount.Interest.LastPostedMonth = evt.Month
+		return a.BankAccount.Deposit(evt.Amount)
+
+	case *events.HoldPlacedEventV1:
+		if err := a.checkCurrency(evt.Currency); err != nil {
+			return err
+		}
+		if a.BankAccount.Holds == nil {
+			a.BankAccount.Holds = make(Holds)
+		}
+		a.BankAccount.Holds[evt.HoldID] = Hold{
+			ID:        evt.HoldID,
+			Amount:    evt.Amount,
+			Reference: evt.Reference,
+			PlacedAt:  evt.PlacedAt,
+			ExpiresAt: evt.ExpiresAt,
+		}
+		return nil
+
+	case *events.HoldCapturedEventV1:
+		if err := a.checkCurrency(evt.Currency); err != nil {
+			return err
+		}
+		delete(a.BankAccount.Holds, evt.HoldID)
+		a.BankAccount.WithdrawalTotals.Add(evt.Amount, evt.CapturedAt)
+		return a.BankAccount.Withdraw(evt.Amount)
+
+	case *events.HoldReleasedEventV1:
+		delete(a.BankAccount.Holds, evt.HoldID)
+		return nil
+
+	case *events.DepositReversedEventV1:
+		if err := a.checkCurrency(evt.Currency); err != nil {
+			return err
+		}
+		a.BankAccount.MarkReversed(evt.OriginalVersion)
+		return a.BankAccount.Withdraw(evt.Amount)
+
+	case *events.WithdrawalReversedEventV1:
+		if err := a.checkCurrency(evt.Currency); err != nil {
+			return err
+		}
+		a.BankAccount.MarkReversed(evt.OriginalVersion)
+		return a.BankAccount.Deposit(evt.Amount)
+
+	case *events.EmailChangedEventV1:
+		a.BankAccount.Email = evt.Email
+		return nil
+
+	case *events.ProfileUpdatedEventV1:
+		a.BankAccount.FirstName = evt.FirstName
+		a.BankAccount.LastName = evt.LastName
+		return nil
+
+	case *events.PasswordChangedEventV1:
+		a.BankAccount.PasswordHash = evt.PasswordHash
+		a.BankAccount.CredentialsChangedAt = evt.ChangedAt
+		return nil
+
+	case *events.AccountFrozenEventV1:
+		a.BankAccount.Status = AccountStatusFrozen
+		return nil
+
+	case *events.AccountUnfrozenEventV1:
+		a.BankAccount.Status = AccountStatusActive
+		return nil
+
+	case *events.AccountClosedEventV1:
+		if err := a.BankAccount.Withdraw(evt.PayoutAmount); err != nil {
+			return err
+		}
+		a.BankAccount.Status = AccountStatusClosed
+		return nil
+
 	default:
 		return errors.Wrapf(bankAccountErrors.ErrUnknownEventType, "event: %#v", event)
 	}
@@ -58,12 +170,19 @@ func (a *BankAccountAggregate) When(event any) error {
 func (a *BankAccountAggregate) CreateBankAccount(
 	ctx context.Context,
 	email, firstName, lastName string,
+	currency string,
 	amount int64,
 	password string, // Add password parameter
 ) error {
 	if amount < 0 {
 		return errors.Wrapf(bankAccountErrors.ErrInvalidBalanceAmount, "amount: %d", amount)
 	}
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if money.GetCurrency(currency) == nil {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidCurrency, "currency: %s", currency)
+	}
 
 	// Create a temporary bank account to hash the password
 	tempAccount := NewBankAccount(a.GetID())
@@ -75,43 +194,505 @@ func (a *BankAccountAggregate) CreateBankAccount(
 		Email:        email,
 		FirstName:    firstName,
 		LastName:     lastName,
-		Balance:      money.New(amount, money.VND),
+		Balance:      money.New(amount, currency),
 		PasswordHash: tempAccount.PasswordHash,
 	}
 
 	return a.Apply(event)
 }
 
-func (a *BankAccountAggregate) DepositBalance(ctx context.Context, amount int64, paymentID string) error {
+// DepositBalance deposits amount in minor units of currency, empty currency means the account currency.
+func (a *BankAccountAggregate) DepositBalance(ctx context.Context, amount int64, currency string, paymentID string) error {
 	if amount <= 0 {
 		return errors.Wrapf(bankAccountErrors.ErrInvalidBalanceAmount, "amount: %d", amount)
 	}
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+	if currency == "" {
+		currency = a.BankAccount.Currency()
+	}
+	if err := a.checkCurrency(currency); err != nil {
+		return err
+	}
+
 	event := &events.BalanceDepositedEventV1{
 		Amount:    amount,
+		Currency:  currency,
 		PaymentID: paymentID,
 	}
 
 	return a.Apply(event)
 }
 
-func (a *BankAccountAggregate) WithdrawBalance(ctx context.Context, amount int64, paymentID string) error {
+// WithdrawBalance withdraws amount in minor units of currency, empty currency means the account currency.
+func (a *BankAccountAggregate) WithdrawBalance(ctx context.Context, amount int64, currency string, paymentID string) error {
 	if amount <= 0 {
 		return errors.Wrapf(bankAccountErrors.ErrInvalidBalanceAmount, "amount: %d", amount)
 	}
-
-	balance, err := money.New(a.BankAccount.Balance.Amount(), money.VND).Subtract(money.New(amount, money.VND))
-	if err != nil {
-		return errors.Wrapf(err, "Balance.Subtract amount: %d", amount)
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+	if a.BankAccount.IsFrozen() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountFrozen, "aggregateID: %s", a.GetID())
+	}
+	if currency == "" {
+		currency = a.BankAccount.Currency()
+	}
+	if err := a.checkCurrency(currency); err != nil {
+		return err
 	}
 
-	if balance.IsNegative() {
-		return errors.Wrapf(bankAccountErrors.ErrNotEnoughBalance, "amount: %d", amount)
+	now := time.Now().UTC()
+	account := a.BankAccount
+	// held funds are not available for withdrawals and count towards the limits
+	if err := account.WithdrawalPolicy.CheckWithdrawal(account.AvailableBalance(now), amount, account.CommittedWithdrawals(now), now); err != nil {
+		return err
 	}
 
 	event := &events.BalanceWithdrawedEventV1{
+		Amount:      amount,
+		Currency:    currency,
+		PaymentID:   paymentID,
+		WithdrawnAt: now,
+	}
+
+	return a.Apply(event)
+}
+
+// PlaceHold reserves amount in minor units of currency until expiresAt, empty currency means the account currency
+// and zero expiresAt means DefaultHoldDuration. The hold is checked against the available balance and the withdrawal policy.
+func (a *BankAccountAggregate) PlaceHold(ctx context.Context, holdID string, amount int64, currency string, reference string, expiresAt time.Time) error {
+	if holdID == "" {
+		return errors.Wrap(bankAccountErrors.ErrInvalidHold, "empty hold id")
+	}
+	if amount <= 0 {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidBalanceAmount, "amount: %d", amount)
+	}
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+	if a.BankAccount.IsFrozen() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountFrozen, "aggregateID: %s", a.GetID())
+	}
+	if _, ok := a.BankAccount.Holds[holdID]; ok {
+		return errors.Wrapf(bankAccountErrors.ErrHoldExists, "holdID: %s", holdID)
+	}
+	if currency == "" {
+		currency = a.BankAccount.Currency()
+	}
+	if err := a.checkCurrency(currency); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(DefaultHoldDuration)
+	}
+	if !expiresAt.After(now) || expiresAt.After(now.Add(MaxHoldDuration)) {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidHold, "expires at: %s, max duration: %s", expiresAt.Format(time.RFC3339), MaxHoldDuration)
+	}
+
+	account := a.BankAccount
+	if err := account.WithdrawalPolicy.CheckWithdrawal(account.AvailableBalance(now), amount, account.CommittedWithdrawals(now), now); err != nil {
+		return err
+	}
+
+	event := &events.HoldPlacedEventV1{
+		HoldID:    holdID,
 		Amount:    amount,
-		PaymentID: paymentID,
+		Currency:  currency,
+		Reference: reference,
+		PlacedAt:  now,
+		ExpiresAt: expiresAt.UTC(),
+	}
+
+	return a.Apply(event)
+}
+
+// CaptureHold debits amount of the hold from the ledger balance, zero amount captures the whole hold.
+// Capturing less than held is a partial capture, the rest of the hold is released. Limits are checked when the
+// hold is placed, captures are rejected while the account is frozen.
+func (a *BankAccountAggregate) CaptureHold(ctx context.Context, holdID string, amount int64, paymentID string) error {
+	hold, ok := a.BankAccount.Holds[holdID]
+	if !ok {
+		return errors.Wrapf(bankAccountErrors.ErrHoldNotFound, "holdID: %s", holdID)
+	}
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+	if a.BankAccount.IsFrozen() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountFrozen, "aggregateID: %s", a.GetID())
+	}
+
+	now := time.Now().UTC()
+	if hold.IsExpired(now) {
+		return errors.Wrapf(bankAccountErrors.ErrHoldExpired, "holdID: %s, expired at: %s", holdID, hold.ExpiresAt.Format(time.RFC3339))
+	}
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if amount < 0 {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidBalanceAmount, "amount: %d", amount)
+	}
+	if amount > hold.Amount {
+		return errors.Wrapf(bankAccountErrors.ErrHoldAmountExceeded, "amount: %d, held: %d", amount, hold.Amount)
+	}
+
+	event := &events.HoldCapturedEventV1{
+		HoldID:     holdID,
+		Amount:     amount,
+		Released:   hold.Amount - amount,
+		Currency:   a.BankAccount.Currency(),
+		PaymentID:  paymentID,
+		CapturedAt: now,
+	}
+
+	return a.Apply(event)
+}
+
+// ReleaseHold cancels the hold, held funds are available again.
+func (a *BankAccountAggregate) ReleaseHold(ctx context.Context, holdID string, reason string) error {
+	hold, ok := a.BankAccount.Holds[holdID]
+	if !ok {
+		return errors.Wrapf(bankAccountErrors.ErrHoldNotFound, "holdID: %s", holdID)
+	}
+
+	event := &events.HoldReleasedEventV1{
+		HoldID:     holdID,
+		Amount:     hold.Amount,
+		Reason:     reason,
+		ReleasedAt: time.Now().UTC(),
+	}
+
+	return a.Apply(event)
+}
+
+// ReleaseExpiredHolds releases holds expired at now, releasing no holds is a no-op.
+func (a *BankAccountAggregate) ReleaseExpiredHolds(ctx context.Context, now time.Time) error {
+	for _, hold := range a.BankAccount.Holds.Expired(now) {
+		event := &events.HoldReleasedEventV1{
+			HoldID:     hold.ID,
+			Amount:     hold.Amount,
+			Reason:     holdExpiredReason,
+			Expired:    true,
+			ReleasedAt: now.UTC(),
+		}
+		if err := a.Apply(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReverseDeposit debits the deposit recorded by the event with originalEventID at originalVersion. The reversal
+// is rejected if the deposit was reversed already or if it would drive the available balance negative.
+func (a *BankAccountAggregate) ReverseDeposit(ctx context.Context, originalEventID string, originalVersion uint64, deposit events.BalanceDepositedEventV1, reason string) error {
+	if err := a.checkReversal(originalVersion); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if available := a.BankAccount.AvailableBalance(now); available < deposit.Amount {
+		return errors.Wrapf(bankAccountErrors.ErrNotEnoughBalance, "available: %d, reversal: %d", available, deposit.Amount)
+	}
+
+	event := &events.DepositReversedEventV1{
+		OriginalEventID: originalEventID,
+		OriginalVersion: originalVersion,
+		Amount:          deposit.Amount,
+		Currency:        a.BankAccount.Currency(),
+		PaymentID:       deposit.PaymentID,
+		Reason:          reason,
+		ReversedAt:      now,
+	}
+
+	return a.Apply(event)
+}
+
+// ReverseWithdrawal credits back the withdrawal recorded by the event with originalEventID at originalVersion.
+// The withdrawal still counts towards daily and monthly limits.
+func (a *BankAccountAggregate) ReverseWithdrawal(ctx context.Context, originalEventID string, originalVersion uint64, withdrawal events.BalanceWithdrawedEventV1, reason string) error {
+	if err := a.checkReversal(originalVersion); err != nil {
+		return err
+	}
+
+	event := &events.WithdrawalReversedEventV1{
+		OriginalEventID: originalEventID,
+		OriginalVersion: originalVersion,
+		Amount:          withdrawal.Amount,
+		Currency:        a.BankAccount.Currency(),
+		PaymentID:       withdrawal.PaymentID,
+		Reason:          reason,
+		ReversedAt:      time.Now().UTC(),
+	}
+
+	return a.Apply(event)
+}
+
+func (a *BankAccountAggregate) checkReversal(originalVersion uint64) error {
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+	if originalVersion == 0 || originalVersion > a.GetVersion() {
+		return errors.Wrapf(bankAccountErrors.ErrTransactionNotFound, "version: %d", originalVersion)
+	}
+	if a.BankAccount.IsReversed(originalVersion) {
+		return errors.Wrapf(bankAccountErrors.ErrTransactionAlreadyReversed, "version: %d", originalVersion)
+	}
+	return nil
+}
+
+// ChangeWithdrawalPolicy replaces overdraft and velocity limits of the account.
+func (a *BankAccountAggregate) ChangeWithdrawalPolicy(ctx context.Context, policy WithdrawalPolicy, reason string) error {
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	event := &events.WithdrawalPolicyChangedEventV1{
+		OverdraftLimit:    policy.OverdraftLimit,
+		MaxPerTransaction: policy.MaxPerTransaction,
+		DailyLimit:        policy.DailyLimit,
+		MonthlyLimit:      policy.MonthlyLimit,
+		Reason:            reason,
+	}
+
+	return a.Apply(event)
+}
+
+// ChangeInterestRate sets the annual interest rate in basis points, zero stops accrual.
+func (a *BankAccountAggregate) ChangeInterestRate(ctx context.Context, rateBps int64, reason string) error {
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+	if rateBps < 0 || rateBps > MaxInterestRateBps {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidInterestRate, "rate: %d bps, max: %d bps", rateBps, MaxInterestRateBps)
+	}
+
+	event := &events.InterestRateChangedEventV1{
+		RateBps:   rateBps,
+		Reason:    reason,
+		ChangedAt: time.Now().UTC(),
+	}
+
+	return a.Apply(event)
+}
+
+// AccrueInterest accrues interest of the finished UTC day on the closing balance of the day at the rate in effect
+// at its end. Days up to the last accrued day are already accrued and accruing them again is a no-op.
+func (a *BankAccountAggregate) AccrueInterest(ctx context.Context, day time.Time, balance int64, rateBps int64) error {
+	day = InterestDay(day)
+	key := day.Format(dayPeriodLayout)
+	if key <= a.BankAccount.Interest.LastAccruedDay {
+		return nil
+	}
+	if day.AddDate(0, 0, 1).After(time.Now().UTC()) {
+		return errors.Wrapf(bankAccountErrors.ErrInterestPeriodNotOver, "day: %s", key)
+	}
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+	if rateBps < 0 || rateBps > MaxInterestRateBps {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidInterestRate, "rate: %d bps, max: %d bps", rateBps, MaxInterestRateBps)
+	}
+
+	event := &events.InterestAccruedEventV1{
+		Day:      key,
+		Balance:  balance,
+		RateBps:  rateBps,
+		Amount:   DailyInterest(balance, rateBps, day),
+		Currency: a.BankAccount.Currency(),
+	}
+
+	return a.Apply(event)
+}
+
+// PostInterest credits interest accrued up to the end of the finished UTC month of day. Posting a month
+// up to the last posted month, or without accrued interest, is a no-op.
+func (a *BankAccountAggregate) PostInterest(ctx context.Context, day time.Time) error {
+	day = InterestDay(day)
+	month := day.Format(monthPeriodLayout)
+	if month <= a.BankAccount.Interest.LastPostedMonth || a.BankAccount.Interest.Accrued <= 0 {
+		return nil
+	}
+	if time.Date(day.Year(), day.Month()+1, 1, 0, 0, 0, 0, time.UTC).After(time.Now().UTC()) {
+		return errors.Wrapf(bankAccountErrors.ErrInterestPeriodNotOver, "month: %s", month)
+	}
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+
+	accrued := a.BankAccount.Interest.Accrued
+	event := &events.InterestPostedEventV1{
+		Month:    month,
+		Accrued:  accrued,
+		Amount:   PostedInterest(accrued),
+		Currency: a.BankAccount.Currency(),
+	}
+
+	return a.Apply(event)
+}
+
+// ChangeEmail replaces the login email after verifying the current password, uniqueness of the email
+// is checked by the caller. Changing to the current email is a no-op.
+func (a *BankAccountAggregate) ChangeEmail(ctx context.Context, email string, currentPassword string) error {
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+	if err := a.BankAccount.CheckPassword(currentPassword); err != nil {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidCredentials, "aggregateID: %s", a.GetID())
+	}
+	if email == "" {
+		return errors.Wrap(bankAccountErrors.ErrInvalidProfile, "empty email")
+	}
+	if email == a.BankAccount.Email {
+		return nil
+	}
+
+	event := &events.EmailChangedEventV1{
+		OldEmail:  a.BankAccount.Email,
+		Email:     email,
+		ChangedAt: time.Now().UTC(),
 	}
 
 	return a.Apply(event)
 }
+
+// UpdateProfile replaces names of the account holder, empty names are kept. Updating to the current
+// names is a no-op.
+func (a *BankAccountAggregate) UpdateProfile(ctx context.Context, firstName, lastName string) error {
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+	if firstName == "" {
+		firstName = a.BankAccount.FirstName
+	}
+	if lastName == "" {
+		lastName = a.BankAccount.LastName
+	}
+	if firstName == a.BankAccount.FirstName && lastName == a.BankAccount.LastName {
+		return nil
+	}
+
+	event := &events.ProfileUpdatedEventV1{
+		FirstName: firstName,
+		LastName:  lastName,
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	return a.Apply(event)
+}
+
+// ChangePassword replaces the password after verifying the current one, tokens issued before
+// the change are revoked.
+func (a *BankAccountAggregate) ChangePassword(ctx context.Context, currentPassword, newPassword string) error {
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+	if err := a.BankAccount.CheckPassword(currentPassword); err != nil {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidCredentials, "aggregateID: %s", a.GetID())
+	}
+
+	// hash on a copy, the account changes when the event is applied
+	tempAccount := NewBankAccount(a.GetID())
+	if err := tempAccount.HashPassword(newPassword); err != nil {
+		return errors.Wrap(err, "failed to hash password")
+	}
+
+	event := &events.PasswordChangedEventV1{
+		PasswordHash: tempAccount.PasswordHash,
+		ChangedAt:    time.Now().UTC(),
+	}
+
+	return a.Apply(event)
+}
+
+// FreezeAccount blocks withdrawals from the account until it is unfrozen.
+func (a *BankAccountAggregate) FreezeAccount(ctx context.Context, reason string) error {
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+	if a.BankAccount.IsFrozen() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountFrozen, "aggregateID: %s", a.GetID())
+	}
+
+	event := &events.AccountFrozenEventV1{
+		Reason: reason,
+	}
+
+	return a.Apply(event)
+}
+
+// UnfreezeAccount allows withdrawals from the frozen account again.
+func (a *BankAccountAggregate) UnfreezeAccount(ctx context.Context, reason string) error {
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+	if !a.BankAccount.IsFrozen() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountNotFrozen, "aggregateID: %s", a.GetID())
+	}
+
+	event := &events.AccountUnfrozenEventV1{
+		Reason: reason,
+	}
+
+	return a.Apply(event)
+}
+
+// CloseAccount closes the account, remaining balance is paid out to payoutTarget which is
+// required unless the balance is zero. Paying out of the frozen account is not allowed.
+func (a *BankAccountAggregate) CloseAccount(ctx context.Context, reason string, payoutTarget string) error {
+	if a.BankAccount.IsClosed() {
+		return errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", a.GetID())
+	}
+
+	// expired holds are released with the closing, outstanding holds have to be captured or released first
+	if err := a.ReleaseExpiredHolds(ctx, time.Now().UTC()); err != nil {
+		return err
+	}
+	if len(a.BankAccount.Holds) > 0 {
+		return errors.Wrapf(bankAccountErrors.ErrHoldsOutstanding, "holds: %d", len(a.BankAccount.Holds))
+	}
+
+	balance := a.BankAccount.Balance
+	if balance.IsNegative() {
+		return errors.Wrapf(bankAccountErrors.ErrNotEnoughBalance, "balance: %d", balance.Amount())
+	}
+	if balance.IsPositive() {
+		if a.BankAccount.IsFrozen() {
+			return errors.Wrapf(bankAccountErrors.ErrAccountFrozen, "aggregateID: %s", a.GetID())
+		}
+		if payoutTarget == "" {
+			return errors.Wrapf(bankAccountErrors.ErrPayoutTargetRequired, "balance: %d", balance.Amount())
+		}
+	}
+
+	event := &events.AccountClosedEventV1{
+		Reason:       reason,
+		PayoutAmount: balance.Amount(),
+		Currency:     a.BankAccount.Currency(),
+	}
+	if balance.IsPositive() {
+		event.PayoutTarget = payoutTarget
+	}
+
+	return a.Apply(event)
+}
+
+// checkCurrency rejects money in other currency than the account has, empty currency
+// of events recorded before accounts had explicit currency is the account currency.
+func (a *BankAccountAggregate) checkCurrency(currency string) error {
+	if currency == "" {
+		return nil
+	}
+	if money.GetCurrency(currency) == nil {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidCurrency, "currency: %s", currency)
+	}
+	if currency != a.BankAccount.Currency() {
+		return errors.Wrapf(bankAccountErrors.ErrCurrencyMismatch, "currency: %s, account currency: %s", currency, a.BankAccount.Currency())
+	}
+	return nil
+}
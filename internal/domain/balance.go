@@ -1,6 +1,7 @@
 package domain
 
+// Balance amount in minor units of the currency, e.g. cents for USD.
 type Balance struct {
-	Amount   float64 `json:"amount" bson:"amount"`
-	Currency string  `json:"currency" bson:"currency"`
+	Amount   int64  `json:"amount" bson:"amount"`
+	Currency string `json:"currency" bson:"currency"`
 }
@@ -7,16 +7,44 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	// DefaultCurrency currency of accounts created without explicit currency
+	DefaultCurrency = money.VND
+)
+
+// AccountStatus lifecycle state of the account.
+type AccountStatus string
+
+const (
+	AccountStatusActive AccountStatus = "active"
+	// AccountStatusFrozen account accepts deposits but no withdrawals
+	AccountStatusFrozen AccountStatus = "frozen"
+	// AccountStatusClosed account accepts no operations and cannot log in
+	AccountStatusClosed AccountStatus = "closed"
+)
+
 type BankAccount struct {
 	AggregateID string       `json:"aggregate_id"`
 	Email       string       `json:"email"`
 	FirstName   string       `json:"first_name"`
 	LastName    string       `json:"last_name"`
 	Balance     *money.Money `json:"balance"`
-	// Authentication fields
-	PasswordHash string    `json:"password_hash"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	// Status empty in snapshots taken before accounts had a lifecycle, which are active
+	Status AccountStatus `json:"status,omitempty"`
+	// WithdrawalPolicy limits withdrawals, WithdrawalTotals counts withdrawals towards them
+	WithdrawalPolicy WithdrawalPolicy `json:"withdrawal_policy"`
+	WithdrawalTotals WithdrawalTotals `json:"withdrawal_totals"`
+	// Interest rate and interest accrued but not posted yet
+	Interest InterestAccrual `json:"interest"`
+	// Holds reserve funds of the ledger balance, see AvailableBalance
+	Holds Holds `json:"holds,omitempty"`
+	// Reversed versions of deposits and withdrawals compensated by reversal events
+	Reversed map[uint64]bool `json:"reversed,omitempty"`
+	// Authentication fields, tokens issued before CredentialsChangedAt are not accepted
+	PasswordHash         string    `json:"password_hash"`
+	CredentialsChangedAt time.Time `json:"credentials_changed_at,omitempty"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
 }
 
 func NewBankAccount(
@@ -24,7 +52,7 @@ func NewBankAccount(
 ) *BankAccount {
 	return &BankAccount{
 		AggregateID: id,
-		Balance:     money.New(0, "VND"),
+		Balance:     money.New(0, DefaultCurrency),
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -46,8 +74,59 @@ func (b *BankAccount) CheckPassword(password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(b.PasswordHash), []byte(password))
 }
 
+// IsTokenRevoked whether the token issued at issuedAt was revoked by a later credentials change. Tokens carry
+// milliseconds, a token issued within the millisecond of the change is revoked as well.
+func (b *BankAccount) IsTokenRevoked(issuedAt time.Time) bool {
+	if b.CredentialsChangedAt.IsZero() {
+		return false
+	}
+	return !issuedAt.After(b.CredentialsChangedAt.Truncate(time.Millisecond))
+}
+
+// Currency ISO 4217 code of the account.
+func (b *BankAccount) Currency() string {
+	return b.Balance.Currency().Code
+}
+
+// IsFrozen whether withdrawals from the account are blocked.
+func (b *BankAccount) IsFrozen() bool {
+	return b.Status == AccountStatusFrozen
+}
+
+// IsClosed whether the account was closed.
+func (b *BankAccount) IsClosed() bool {
+	return b.Status == AccountStatusClosed
+}
+
+// AvailableBalance ledger balance less funds held by holds not expired at t, in minor units.
+func (b *BankAccount) AvailableBalance(at time.Time) int64 {
+	return b.Balance.Amount() - b.Holds.Held(at)
+}
+
+// CommittedWithdrawals withdrawal totals counting funds held by holds not expired at t as withdrawn at t,
+// holds are debited without a limit check when captured, so they count towards the limits when placed.
+func (b *BankAccount) CommittedWithdrawals(at time.Time) WithdrawalTotals {
+	totals := b.WithdrawalTotals
+	totals.Add(b.Holds.Held(at), at)
+	return totals
+}
+
+// IsReversed whether the deposit or withdrawal at version was reversed.
+func (b *BankAccount) IsReversed(version uint64) bool {
+	return b.Reversed[version]
+}
+
+// MarkReversed records the deposit or withdrawal at version as reversed.
+func (b *BankAccount) MarkReversed(version uint64) {
+	if b.Reversed == nil {
+		b.Reversed = make(map[uint64]bool)
+	}
+	b.Reversed[version] = true
+}
+
+// Deposit adds amount in minor units of the account currency.
 func (b *BankAccount) Deposit(amount int64) error {
-	result, err := b.Balance.Add(money.New(amount, money.VND))
+	result, err := b.Balance.Add(money.New(amount, b.Currency()))
 	if err != nil {
 		return err
 	}
@@ -55,8 +134,9 @@ func (b *BankAccount) Deposit(amount int64) error {
 	return nil
 }
 
+// Withdraw subtracts amount in minor units of the account currency.
 func (b *BankAccount) Withdraw(amount int64) error {
-	result, err := b.Balance.Subtract(money.New(amount, money.VND))
+	result, err := b.Balance.Subtract(money.New(amount, b.Currency()))
 	if err != nil {
 		return err
 	}
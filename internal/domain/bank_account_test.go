@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBankAccountIsTokenRevoked(t *testing.T) {
+	changedAt := time.Date(2024, 3, 15, 12, 0, 0, 500_300_000, time.UTC)
+
+	tests := []struct {
+		name      string
+		changedAt time.Time
+		issuedAt  time.Time
+		expected  bool
+	}{
+		{name: "credentials never changed", issuedAt: changedAt},
+		{name: "issued in an earlier second", changedAt: changedAt, issuedAt: changedAt.Add(-time.Second), expected: true},
+		{name: "issued earlier within the second", changedAt: changedAt, issuedAt: changedAt.Add(-100 * time.Millisecond), expected: true},
+		{name: "issued within the millisecond", changedAt: changedAt, issuedAt: changedAt.Truncate(time.Millisecond), expected: true},
+		{name: "token carrying seconds only", changedAt: changedAt, issuedAt: changedAt.Truncate(time.Second), expected: true},
+		{name: "issued in the next millisecond", changedAt: changedAt, issuedAt: changedAt.Truncate(time.Millisecond).Add(time.Millisecond)},
+		{name: "issued later", changedAt: changedAt, issuedAt: changedAt.Add(time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := &BankAccount{CredentialsChangedAt: tt.changedAt}
+			assert.Equal(t, tt.expected, account.IsTokenRevoked(tt.issuedAt))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ConsistencyCheckStatus status of the consistency check run.
+type ConsistencyCheckStatus string
+
+const (
+	ConsistencyCheckStatusRunning     ConsistencyCheckStatus = "running"
+	ConsistencyCheckStatusCompleted   ConsistencyCheckStatus = "completed"
+	ConsistencyCheckStatusFailed      ConsistencyCheckStatus = "failed"
+	ConsistencyCheckStatusInterrupted ConsistencyCheckStatus = "interrupted"
+)
+
+const (
+	ReadModelMongo         = "mongo"
+	ReadModelElasticsearch = "elasticsearch"
+)
+
+// FieldDrift read model field value differing from the state replayed from the event store.
+type FieldDrift struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// AccountDrift differences of one account in one read model.
+type AccountDrift struct {
+	AggregateID string       `json:"aggregateId"`
+	ReadModel   string       `json:"readModel"`
+	Missing     bool         `json:"missing,omitempty"`
+	Fields      []FieldDrift `json:"fields,omitempty"`
+	Repaired    bool         `json:"repaired"`
+	RepairError string       `json:"repairError,omitempty"`
+}
+
+// ConsistencyCheck compares every account replayed from the event store with MongoDB and Elasticsearch projections.
+type ConsistencyCheck struct {
+	ID           string                 `json:"id"`
+	Status       ConsistencyCheckStatus `json:"status"`
+	Repair       bool                   `json:"repair"`
+	AggregateIDs []string               `json:"aggregateIds,omitempty"`
+	Checked      int                    `json:"checked"`
+	Drifted      int                    `json:"drifted"`
+	Repaired     int                    `json:"repaired"`
+	Drifts       []*AccountDrift        `json:"drifts"`
+	Error        string                 `json:"error,omitempty"`
+	CreatedAt    time.Time              `json:"createdAt"`
+	UpdatedAt    time.Time              `json:"updatedAt"`
+	FinishedAt   *time.Time             `json:"finishedAt,omitempty"`
+	Owner        string                 `json:"owner,omitempty"`
+	HeartbeatAt  *time.Time             `json:"heartbeatAt,omitempty"`
+}
+
+type ConsistencyCheckRepository interface {
+	Create(ctx context.Context, check *ConsistencyCheck) error
+	Update(ctx context.Context, check *ConsistencyCheck) error
+	Get(ctx context.Context, id string) (*ConsistencyCheck, error)
+	List(ctx context.Context, limit int) ([]*ConsistencyCheck, error)
+
+	// Heartbeat refreshes the heartbeat of the running check of owner.
+	Heartbeat(ctx context.Context, owner string, id string, heartbeatAt time.Time) error
+
+	// MarkInterrupted marks running checks with heartbeat older than staleBefore as interrupted,
+	// their owner stopped without persisting them.
+	MarkInterrupted(ctx context.Context, staleBefore time.Time) (int64, error)
+}
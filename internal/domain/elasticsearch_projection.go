@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/Rhymond/go-money"
+	"github.com/pkg/errors"
 	"github.com/th1enq/es-demo/internal/events"
 )
 
@@ -15,18 +16,23 @@ type BankAccountElasticsearchProjection struct {
 	FirstName   string             `json:"firstName"`
 	LastName    string             `json:"lastName"`
 	Balance     *BalanceProjection `json:"balance"`
-	CreatedAt   time.Time          `json:"createdAt"`
-	UpdatedAt   time.Time          `json:"updatedAt"`
-	Version     uint64             `json:"version"`
+	// AvailableBalance ledger balance less HeldAmount reserved by holds
+	AvailableBalance *BalanceProjection `json:"availableBalance"`
+	HeldAmount       int64              `json:"heldAmount"`
+	CreatedAt        time.Time          `json:"createdAt"`
+	UpdatedAt        time.Time          `json:"updatedAt"`
+	Version          uint64             `json:"version"`
 	// Additional fields for Elasticsearch analytics
 	TotalDeposits    int64     `json:"totalDeposits"`
 	TotalWithdrawals int64     `json:"totalWithdrawals"`
+	TotalInterest    int64     `json:"totalInterest"`
 	TransactionCount int       `json:"transactionCount"`
-	Status           string    `json:"status"` // active, inactive, frozen
+	Status           string    `json:"status"` // active, frozen, closed
+	StatusReason     string    `json:"statusReason,omitempty"`
 	LastActivity     time.Time `json:"lastActivity"`
 }
 
-// BalanceProjection for Elasticsearch
+// BalanceProjection for Elasticsearch, amount in minor units of the currency
 type BalanceProjection struct {
 	Amount   int64  `json:"amount"`
 	Currency string `json:"currency"`
@@ -36,14 +42,14 @@ type BalanceProjection struct {
 func NewBankAccountElasticsearchProjection(aggregateID string) *BankAccountElasticsearchProjection {
 	return &BankAccountElasticsearchProjection{
 		AggregateID:      aggregateID,
-		Balance:          &BalanceProjection{Amount: 0, Currency: "USD"},
+		Balance:          &BalanceProjection{Amount: 0, Currency: DefaultCurrency},
 		CreatedAt:        time.Now(),
 		UpdatedAt:        time.Now(),
 		Version:          0,
 		TotalDeposits:    0,
 		TotalWithdrawals: 0,
 		TransactionCount: 0,
-		Status:           "active",
+		Status:           string(AccountStatusActive),
 		LastActivity:     time.Now(),
 	}
 }
@@ -65,7 +71,7 @@ func (p *BankAccountElasticsearchProjection) FromJSON(jsonData string) error {
 // GetBalance returns the balance as money.Money
 func (p *BankAccountElasticsearchProjection) GetBalance() *money.Money {
 	if p.Balance == nil {
-		p.Balance = &BalanceProjection{Amount: 0, Currency: "USD"}
+		p.Balance = &BalanceProjection{Amount: 0, Currency: DefaultCurrency}
 	}
 	return money.New(p.Balance.Amount, p.Balance.Currency)
 }
@@ -73,13 +79,14 @@ func (p *BankAccountElasticsearchProjection) GetBalance() *money.Money {
 // SetBalance sets the balance from money.Money
 func (p *BankAccountElasticsearchProjection) SetBalance(balance *money.Money) {
 	if balance == nil {
-		balance = money.New(0, "USD")
+		balance = money.New(0, DefaultCurrency)
 	}
 	if p.Balance == nil {
 		p.Balance = &BalanceProjection{}
 	}
 	p.Balance.Amount = balance.Amount()
 	p.Balance.Currency = balance.Currency().Code
+	p.AvailableBalance = &BalanceProjection{Amount: p.Balance.Amount - p.HeldAmount, Currency: p.Balance.Currency}
 	p.UpdatedAt = time.Now()
 	p.LastActivity = time.Now()
 }
@@ -96,34 +103,198 @@ func (p *BankAccountElasticsearchProjection) WhenBankAccountCreated(event events
 	p.CreatedAt = timestamp
 	p.UpdatedAt = timestamp
 	p.Version = version
-	p.Status = "active"
+	p.Status = string(AccountStatusActive)
 	p.LastActivity = timestamp
 }
 
-// When BalanceDepositedEventV1 is applied
-func (p *BankAccountElasticsearchProjection) WhenBalanceDeposited(event events.BalanceDepositedEventV1, version uint64, timestamp time.Time) {
+// When BalanceDepositedEventV1 is applied, money of the event is in the account currency
+func (p *BankAccountElasticsearchProjection) WhenBalanceDeposited(event events.BalanceDepositedEventV1, version uint64, timestamp time.Time) error {
 	currentBalance := p.GetBalance()
-	depositAmount := money.New(event.Amount, "USD")
-	newBalance, _ := currentBalance.Add(depositAmount)
+	newBalance, err := currentBalance.Add(money.New(event.Amount, eventCurrency(event.Currency, currentBalance)))
+	if err != nil {
+		return errors.Wrapf(err, "Balance.Add amount: %d %s", event.Amount, event.Currency)
+	}
 	p.SetBalance(newBalance)
 	p.TotalDeposits += event.Amount
 	p.TransactionCount++
 	p.Version = version
 	p.UpdatedAt = timestamp
 	p.LastActivity = timestamp
+	return nil
+}
+
+// When BalanceWithdrawedEventV1 is applied, money of the event is in the account currency
+func (p *BankAccountElasticsearchProjection) WhenBalanceWithdrawn(event events.BalanceWithdrawedEventV1, version uint64, timestamp time.Time) error {
+	currentBalance := p.GetBalance()
+	newBalance, err := currentBalance.Subtract(money.New(event.Amount, eventCurrency(event.Currency, currentBalance)))
+	if err != nil {
+		return errors.Wrapf(err, "Balance.Subtract amount: %d %s", event.Amount, event.Currency)
+	}
+	p.SetBalance(newBalance)
+	p.TotalWithdrawals += event.Amount
+	p.TransactionCount++
+	p.Version = version
+	p.UpdatedAt = timestamp
+	p.LastActivity = timestamp
+	return nil
+}
+
+// When AccountFrozenEventV1 is applied
+func (p *BankAccountElasticsearchProjection) WhenAccountFrozen(event events.AccountFrozenEventV1, version uint64, timestamp time.Time) {
+	p.setStatus(AccountStatusFrozen, event.Reason, version, timestamp)
+}
+
+// When AccountUnfrozenEventV1 is applied
+func (p *BankAccountElasticsearchProjection) WhenAccountUnfrozen(event events.AccountUnfrozenEventV1, version uint64, timestamp time.Time) {
+	p.setStatus(AccountStatusActive, event.Reason, version, timestamp)
+}
+
+// When AccountClosedEventV1 is applied, remaining balance is paid out and counted as withdrawal
+func (p *BankAccountElasticsearchProjection) WhenAccountClosed(event events.AccountClosedEventV1, version uint64, timestamp time.Time) error {
+	if event.PayoutAmount != 0 {
+		currentBalance := p.GetBalance()
+		newBalance, err := currentBalance.Subtract(money.New(event.PayoutAmount, eventCurrency(event.Currency, currentBalance)))
+		if err != nil {
+			return errors.Wrapf(err, "Balance.Subtract payout: %d %s", event.PayoutAmount, event.Currency)
+		}
+		p.SetBalance(newBalance)
+		p.TotalWithdrawals += event.PayoutAmount
+		p.TransactionCount++
+	}
+	p.setStatus(AccountStatusClosed, event.Reason, version, timestamp)
+	return nil
+}
+
+// When WithdrawalPolicyChangedEventV1 is applied, the policy is not indexed
+func (p *BankAccountElasticsearchProjection) WhenWithdrawalPolicyChanged(event events.WithdrawalPolicyChangedEventV1, version uint64, timestamp time.Time) {
+	p.Version = version
+	p.UpdatedAt = timestamp
+}
+
+// When InterestRateChangedEventV1 or InterestAccruedEventV1 is applied, interest is indexed when posted
+func (p *BankAccountElasticsearchProjection) WhenInterestChanged(version uint64, timestamp time.Time) {
+	p.Version = version
+	p.UpdatedAt = timestamp
+}
+
+// When InterestPostedEventV1 is applied, posted interest is counted apart from deposits
+func (p *BankAccountElasticsearchProjection) WhenInterestPosted(event events.InterestPostedEventV1, version uint64, timestamp time.Time) error {
+	currentBalance := p.GetBalance()
+	newBalance, err := currentBalance.Add(money.New(event.Amount, eventCurrency(event.Currency, currentBalance)))
+	if err != nil {
+		return errors.Wrapf(err, "Balance.Add interest: %d %s", event.Amount, event.Currency)
+	}
+	p.SetBalance(newBalance)
+	p.TotalInterest += event.Amount
+	p.TransactionCount++
+	p.Version = version
+	p.UpdatedAt = timestamp
+	p.LastActivity = timestamp
+	return nil
 }
 
-// When BalanceWithdrawedEventV1 is applied
-func (p *BankAccountElasticsearchProjection) WhenBalanceWithdrawn(event events.BalanceWithdrawedEventV1, version uint64, timestamp time.Time) {
+// When HoldPlacedEventV1 is applied, held funds are not available
+func (p *BankAccountElasticsearchProjection) WhenHoldPlaced(event events.HoldPlacedEventV1, version uint64, timestamp time.Time) {
+	p.setHeldAmount(p.HeldAmount+event.Amount, version, timestamp)
+}
+
+// When HoldCapturedEventV1 is applied, captured amount is withdrawn and the rest of the hold is available again
+func (p *BankAccountElasticsearchProjection) WhenHoldCaptured(event events.HoldCapturedEventV1, version uint64, timestamp time.Time) error {
+	p.HeldAmount -= event.Amount + event.Released
 	currentBalance := p.GetBalance()
-	withdrawAmount := money.New(event.Amount, "USD")
-	newBalance, _ := currentBalance.Subtract(withdrawAmount)
+	newBalance, err := currentBalance.Subtract(money.New(event.Amount, eventCurrency(event.Currency, currentBalance)))
+	if err != nil {
+		return errors.Wrapf(err, "Balance.Subtract capture: %d %s", event.Amount, event.Currency)
+	}
 	p.SetBalance(newBalance)
 	p.TotalWithdrawals += event.Amount
 	p.TransactionCount++
 	p.Version = version
 	p.UpdatedAt = timestamp
 	p.LastActivity = timestamp
+	return nil
+}
+
+// When HoldReleasedEventV1 is applied, held funds are available again
+func (p *BankAccountElasticsearchProjection) WhenHoldReleased(event events.HoldReleasedEventV1, version uint64, timestamp time.Time) {
+	p.setHeldAmount(p.HeldAmount-event.Amount, version, timestamp)
+}
+
+// When DepositReversedEventV1 is applied, the reversed deposit no longer counts towards deposits
+func (p *BankAccountElasticsearchProjection) WhenDepositReversed(event events.DepositReversedEventV1, version uint64, timestamp time.Time) error {
+	currentBalance := p.GetBalance()
+	newBalance, err := currentBalance.Subtract(money.New(event.Amount, eventCurrency(event.Currency, currentBalance)))
+	if err != nil {
+		return errors.Wrapf(err, "Balance.Subtract reversal: %d %s", event.Amount, event.Currency)
+	}
+	p.SetBalance(newBalance)
+	p.TotalDeposits -= event.Amount
+	p.TransactionCount++
+	p.Version = version
+	p.UpdatedAt = timestamp
+	p.LastActivity = timestamp
+	return nil
+}
+
+// When WithdrawalReversedEventV1 is applied, the reversed withdrawal no longer counts towards withdrawals
+func (p *BankAccountElasticsearchProjection) WhenWithdrawalReversed(event events.WithdrawalReversedEventV1, version uint64, timestamp time.Time) error {
+	currentBalance := p.GetBalance()
+	newBalance, err := currentBalance.Add(money.New(event.Amount, eventCurrency(event.Currency, currentBalance)))
+	if err != nil {
+		return errors.Wrapf(err, "Balance.Add reversal: %d %s", event.Amount, event.Currency)
+	}
+	p.SetBalance(newBalance)
+	p.TotalWithdrawals -= event.Amount
+	p.TransactionCount++
+	p.Version = version
+	p.UpdatedAt = timestamp
+	p.LastActivity = timestamp
+	return nil
+}
+
+// When EmailChangedEventV1 is applied
+func (p *BankAccountElasticsearchProjection) WhenEmailChanged(event events.EmailChangedEventV1, version uint64, timestamp time.Time) {
+	p.Email = event.Email
+	p.Version = version
+	p.UpdatedAt = timestamp
+}
+
+// When ProfileUpdatedEventV1 is applied
+func (p *BankAccountElasticsearchProjection) WhenProfileUpdated(event events.ProfileUpdatedEventV1, version uint64, timestamp time.Time) {
+	p.FirstName = event.FirstName
+	p.LastName = event.LastName
+	p.Version = version
+	p.UpdatedAt = timestamp
+}
+
+// When PasswordChangedEventV1 is applied, credentials are not indexed
+func (p *BankAccountElasticsearchProjection) WhenPasswordChanged(version uint64, timestamp time.Time) {
+	p.Version = version
+	p.UpdatedAt = timestamp
+}
+
+func (p *BankAccountElasticsearchProjection) setHeldAmount(heldAmount int64, version uint64, timestamp time.Time) {
+	p.HeldAmount = heldAmount
+	p.SetBalance(p.GetBalance())
+	p.Version = version
+	p.UpdatedAt = timestamp
+	p.LastActivity = timestamp
+}
+
+func (p *BankAccountElasticsearchProjection) setStatus(status AccountStatus, reason string, version uint64, timestamp time.Time) {
+	p.Status = string(status)
+	p.StatusReason = reason
+	p.Version = version
+	p.UpdatedAt = timestamp
+	p.LastActivity = timestamp
+}
+
+// eventCurrency currency of event money, events recorded before accounts had explicit currency are in the balance currency.
+func eventCurrency(currency string, balance *money.Money) string {
+	if currency == "" {
+		return balance.Currency().Code
+	}
+	return currency
 }
 
 // GetFullName returns the full name
@@ -133,12 +304,12 @@ func (p *BankAccountElasticsearchProjection) GetFullName() string {
 
 // IsActive checks if the account is active
 func (p *BankAccountElasticsearchProjection) IsActive() bool {
-	return p.Status == "active"
+	return p.Status == string(AccountStatusActive)
 }
 
-// GetNetFlow returns the net flow (deposits - withdrawals)
+// GetNetFlow returns the net flow (deposits + interest - withdrawals)
 func (p *BankAccountElasticsearchProjection) GetNetFlow() int64 {
-	return p.TotalDeposits - p.TotalWithdrawals
+	return p.TotalDeposits + p.TotalInterest - p.TotalWithdrawals
 }
 
 // UpdateStatus updates the account status
@@ -158,4 +329,10 @@ type ElasticsearchRepository interface {
 	Search(indexName string, query map[string]interface{}) ([]*BankAccountElasticsearchProjection, error)
 	DeleteIndex(indexName string) error
 	BulkIndex(indexName string, documents map[string]interface{}) error
+	DeleteAllDocuments(indexName string) error
+	CountDocuments(indexName string) (int64, error)
+	IndexExists(indexName string) (bool, error)
+	ListIndices(pattern string) ([]string, error)
+	GetAliasIndices(alias string) ([]string, error)
+	SwapAlias(alias, indexName string) error
 }
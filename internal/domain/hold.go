@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultHoldDuration expiry of holds placed without explicit expiry
+	DefaultHoldDuration = 7 * 24 * time.Hour
+	// MaxHoldDuration longest time funds can be held
+	MaxHoldDuration = 30 * 24 * time.Hour
+
+	holdExpiredReason = "hold expired"
+)
+
+// Hold funds reserved for a pending card style payment in minor units of the account currency.
+type Hold struct {
+	ID        string    `json:"id" bson:"id"`
+	Amount    int64     `json:"amount" bson:"amount"`
+	Reference string    `json:"reference,omitempty" bson:"reference,omitempty"`
+	PlacedAt  time.Time `json:"placed_at" bson:"placed_at"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// IsExpired whether the hold no longer reserves funds at t.
+func (h Hold) IsExpired(at time.Time) bool {
+	return !at.Before(h.ExpiresAt)
+}
+
+// Holds outstanding holds of the account by id.
+type Holds map[string]Hold
+
+// Held amount reserved by holds not expired at t.
+func (h Holds) Held(at time.Time) int64 {
+	var held int64
+	for _, hold := range h {
+		if !hold.IsExpired(at) {
+			held += hold.Amount
+		}
+	}
+	return held
+}
+
+// Expired holds expired at t ordered by id, so that releasing them records events in the same order.
+func (h Holds) Expired(at time.Time) []Hold {
+	expired := make([]Hold, 0)
+	for _, hold := range h {
+		if hold.IsExpired(at) {
+			expired = append(expired, hold)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].ID < expired[j].ID })
+	return expired
+}
+
+// List holds ordered by expiry.
+func (h Holds) List() []Hold {
+	list := make([]Hold, 0, len(h))
+	for _, hold := range h {
+		list = append(list, hold)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].ExpiresAt.Equal(list[j].ExpiresAt) {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].ExpiresAt.Before(list[j].ExpiresAt)
+	})
+	return list
+}
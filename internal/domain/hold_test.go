@@ -0,0 +1,283 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/events"
+)
+
+// newTestBankAccount returns an active account with balance in USD, setup events are not changes of the aggregate.
+func newTestBankAccount(t *testing.T, balance int64, setup ...any) *BankAccountAggregate {
+	t.Helper()
+
+	aggregate := NewBankAccountAggregate("account-1")
+	require.NoError(t, aggregate.RaiseEvent(&events.BankAccountCreatedEventV1{
+		Email:   "account@example.com",
+		Balance: money.New(balance, money.USD),
+	}))
+	for _, event := range setup {
+		require.NoError(t, aggregate.RaiseEvent(event))
+	}
+	return aggregate
+}
+
+func testHold(id string, amount int64, expiresAt time.Time) *events.HoldPlacedEventV1 {
+	return &events.HoldPlacedEventV1{
+		HoldID:    id,
+		Amount:    amount,
+		Currency:  money.USD,
+		PlacedAt:  expiresAt.Add(-time.Hour),
+		ExpiresAt: expiresAt,
+	}
+}
+
+func TestPlaceHold(t *testing.T) {
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name      string
+		setup     []any
+		holdID    string
+		amount    int64
+		currency  string
+		expiresAt time.Time
+		expected  error
+		available int64
+	}{
+		{name: "hold with default expiry", holdID: "hold-1", amount: 60, available: 40},
+		{name: "hold with expiry", holdID: "hold-1", amount: 60, expiresAt: now.Add(time.Hour), available: 40},
+		{name: "hold of the whole available balance", setup: []any{testHold("hold-0", 40, now.Add(time.Hour))}, holdID: "hold-1", amount: 60, available: 0},
+		{name: "held funds are not available", setup: []any{testHold("hold-0", 60, now.Add(time.Hour))}, holdID: "hold-1", amount: 50, expected: bankAccountErrors.ErrNotEnoughBalance},
+		{name: "expired holds do not reserve funds", setup: []any{testHold("hold-0", 60, now.Add(-time.Minute))}, holdID: "hold-1", amount: 100, available: 0},
+		{name: "existing hold id", setup: []any{testHold("hold-1", 10, now.Add(time.Hour))}, holdID: "hold-1", amount: 10, expected: bankAccountErrors.ErrHoldExists},
+		{name: "empty hold id", amount: 10, expected: bankAccountErrors.ErrInvalidHold},
+		{name: "zero amount", holdID: "hold-1", expected: bankAccountErrors.ErrInvalidBalanceAmount},
+		{name: "other currency", holdID: "hold-1", amount: 10, currency: money.EUR, expected: bankAccountErrors.ErrCurrencyMismatch},
+		{name: "expiry in the past", holdID: "hold-1", amount: 10, expiresAt: now.Add(-time.Minute), expected: bankAccountErrors.ErrInvalidHold},
+		{name: "expiry after max duration", holdID: "hold-1", amount: 10, expiresAt: now.Add(MaxHoldDuration + time.Hour), expected: bankAccountErrors.ErrInvalidHold},
+		{name: "frozen account", setup: []any{&events.AccountFrozenEventV1{}}, holdID: "hold-1", amount: 10, expected: bankAccountErrors.ErrAccountFrozen},
+		{name: "withdrawal policy applies", setup: []any{&events.WithdrawalPolicyChangedEventV1{MaxPerTransaction: 50}}, holdID: "hold-1", amount: 60, expected: bankAccountErrors.ErrTransactionLimitExceeded},
+		{
+			name:   "outstanding holds count towards the daily limit",
+			setup:  []any{&events.WithdrawalPolicyChangedEventV1{DailyLimit: 50}, testHold("hold-0", 30, now.Add(time.Hour))},
+			holdID: "hold-1", amount: 30, expected: bankAccountErrors.ErrDailyLimitExceeded,
+		},
+		{
+			name:   "outstanding holds count towards the monthly limit",
+			setup:  []any{&events.WithdrawalPolicyChangedEventV1{MonthlyLimit: 50}, testHold("hold-0", 30, now.Add(time.Hour))},
+			holdID: "hold-1", amount: 30, expected: bankAccountErrors.ErrMonthlyLimitExceeded,
+		},
+		{
+			name:   "holds within the daily limit",
+			setup:  []any{&events.WithdrawalPolicyChangedEventV1{DailyLimit: 60}, testHold("hold-0", 30, now.Add(time.Hour))},
+			holdID: "hold-1", amount: 30, available: 40,
+		},
+		{
+			name:   "expired holds do not count towards the limits",
+			setup:  []any{&events.WithdrawalPolicyChangedEventV1{DailyLimit: 50}, testHold("hold-0", 30, now.Add(-time.Minute))},
+			holdID: "hold-1", amount: 30, available: 70,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregate := newTestBankAccount(t, 100, tt.setup...)
+
+			err := aggregate.PlaceHold(context.Background(), tt.holdID, tt.amount, tt.currency, "", tt.expiresAt)
+			if tt.expected != nil {
+				assert.ErrorIs(t, err, tt.expected)
+				assert.Empty(t, aggregate.GetChanges())
+				return
+			}
+			require.NoError(t, err)
+
+			hold, ok := aggregate.BankAccount.Holds[tt.holdID]
+			require.True(t, ok)
+			assert.Equal(t, tt.amount, hold.Amount)
+			assert.Equal(t, int64(100), aggregate.BankAccount.Balance.Amount())
+			assert.Equal(t, tt.available, aggregate.BankAccount.AvailableBalance(time.Now().UTC()))
+			if tt.expiresAt.IsZero() {
+				assert.WithinDuration(t, hold.PlacedAt.Add(DefaultHoldDuration), hold.ExpiresAt, time.Second)
+			}
+		})
+	}
+}
+
+func TestCaptureHold(t *testing.T) {
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name      string
+		setup     []any
+		holdID    string
+		amount    int64
+		expected  error
+		captured  int64
+		released  int64
+		balance   int64
+		available int64
+	}{
+		{name: "whole hold", holdID: "hold-1", captured: 60, balance: 40, available: 40},
+		{name: "whole hold by amount", holdID: "hold-1", amount: 60, captured: 60, balance: 40, available: 40},
+		{name: "partial capture releases the rest", holdID: "hold-1", amount: 20, captured: 20, released: 40, balance: 80, available: 80},
+		{
+			name:   "other holds stay",
+			setup:  []any{testHold("hold-2", 30, now.Add(time.Hour))},
+			holdID: "hold-1", captured: 60, balance: 40, available: 10,
+		},
+		{name: "amount over the hold", holdID: "hold-1", amount: 61, expected: bankAccountErrors.ErrHoldAmountExceeded},
+		{name: "negative amount", holdID: "hold-1", amount: -1, expected: bankAccountErrors.ErrInvalidBalanceAmount},
+		{name: "unknown hold", holdID: "hold-2", expected: bankAccountErrors.ErrHoldNotFound},
+		{name: "expired hold", setup: []any{testHold("hold-3", 10, now.Add(-time.Minute))}, holdID: "hold-3", expected: bankAccountErrors.ErrHoldExpired},
+		{name: "frozen account", setup: []any{&events.AccountFrozenEventV1{}}, holdID: "hold-1", expected: bankAccountErrors.ErrAccountFrozen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup := append([]any{testHold("hold-1", 60, now.Add(time.Hour))}, tt.setup...)
+			aggregate := newTestBankAccount(t, 100, setup...)
+
+			err := aggregate.CaptureHold(context.Background(), tt.holdID, tt.amount, "payment-1")
+			if tt.expected != nil {
+				assert.ErrorIs(t, err, tt.expected)
+				assert.Empty(t, aggregate.GetChanges())
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, aggregate.GetChanges(), 1)
+			event, ok := aggregate.GetChanges()[0].(*events.HoldCapturedEventV1)
+			require.True(t, ok)
+			assert.Equal(t, tt.captured, event.Amount)
+			assert.Equal(t, tt.released, event.Released)
+			assert.Equal(t, "payment-1", event.PaymentID)
+
+			assert.NotContains(t, aggregate.BankAccount.Holds, tt.holdID)
+			assert.Equal(t, tt.balance, aggregate.BankAccount.Balance.Amount())
+			assert.Equal(t, tt.available, aggregate.BankAccount.AvailableBalance(time.Now().UTC()))
+			daily, _ := aggregate.BankAccount.WithdrawalTotals.Withdrawn(time.Now().UTC())
+			assert.Equal(t, tt.captured, daily, "captured amount counts towards withdrawal limits")
+		})
+	}
+}
+
+func TestReleaseHold(t *testing.T) {
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name      string
+		holdID    string
+		expected  error
+		available int64
+	}{
+		{name: "held funds are available again", holdID: "hold-1", available: 70},
+		{name: "expired hold can be released", holdID: "hold-2", available: 40},
+		{name: "unknown hold", holdID: "hold-3", expected: bankAccountErrors.ErrHoldNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregate := newTestBankAccount(t, 100,
+				testHold("hold-1", 30, now.Add(time.Hour)),
+				testHold("hold-2", 30, now.Add(-time.Minute)),
+				testHold("hold-4", 30, now.Add(time.Hour)),
+			)
+
+			err := aggregate.ReleaseHold(context.Background(), tt.holdID, "cancelled")
+			if tt.expected != nil {
+				assert.ErrorIs(t, err, tt.expected)
+				assert.Empty(t, aggregate.GetChanges())
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, aggregate.GetChanges(), 1)
+			event, ok := aggregate.GetChanges()[0].(*events.HoldReleasedEventV1)
+			require.True(t, ok)
+			assert.Equal(t, "cancelled", event.Reason)
+			assert.False(t, event.Expired)
+
+			assert.NotContains(t, aggregate.BankAccount.Holds, tt.holdID)
+			assert.Equal(t, int64(100), aggregate.BankAccount.Balance.Amount())
+			assert.Equal(t, tt.available, aggregate.BankAccount.AvailableBalance(time.Now().UTC()))
+		})
+	}
+}
+
+func TestReleaseExpiredHolds(t *testing.T) {
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name     string
+		setup    []any
+		released []string
+		holds    []string
+	}{
+		{name: "no holds", released: []string{}, holds: []string{}},
+		{
+			name:     "no expired holds",
+			setup:    []any{testHold("hold-1", 10, now.Add(time.Hour))},
+			released: []string{},
+			holds:    []string{"hold-1"},
+		},
+		{
+			name: "expired holds are released in id order",
+			setup: []any{
+				testHold("hold-c", 10, now.Add(-time.Minute)),
+				testHold("hold-b", 10, now.Add(time.Hour)),
+				testHold("hold-a", 10, now),
+			},
+			released: []string{"hold-a", "hold-c"},
+			holds:    []string{"hold-b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregate := newTestBankAccount(t, 100, tt.setup...)
+
+			require.NoError(t, aggregate.ReleaseExpiredHolds(context.Background(), now))
+
+			released := make([]string, 0)
+			for _, change := range aggregate.GetChanges() {
+				event, ok := change.(*events.HoldReleasedEventV1)
+				require.True(t, ok)
+				assert.True(t, event.Expired)
+				assert.Equal(t, holdExpiredReason, event.Reason)
+				released = append(released, event.HoldID)
+			}
+			assert.Equal(t, tt.released, released)
+
+			holds := make([]string, 0)
+			for _, hold := range aggregate.BankAccount.Holds.List() {
+				holds = append(holds, hold.ID)
+			}
+			assert.Equal(t, tt.holds, holds)
+			assert.Equal(t, int64(100), aggregate.BankAccount.Balance.Amount())
+		})
+	}
+}
+
+func TestCapturedHoldsStayWithinLimits(t *testing.T) {
+	ctx := context.Background()
+	aggregate := newTestBankAccount(t, 100, &events.WithdrawalPolicyChangedEventV1{DailyLimit: 50, MonthlyLimit: 50})
+
+	require.NoError(t, aggregate.PlaceHold(ctx, "hold-1", 30, "", "", time.Time{}))
+	assert.ErrorIs(t, aggregate.PlaceHold(ctx, "hold-2", 30, "", "", time.Time{}), bankAccountErrors.ErrDailyLimitExceeded)
+	assert.ErrorIs(t, aggregate.WithdrawBalance(ctx, 30, "", "withdrawal-1"), bankAccountErrors.ErrDailyLimitExceeded)
+
+	require.NoError(t, aggregate.CaptureHold(ctx, "hold-1", 0, "payment-1"))
+	require.NoError(t, aggregate.PlaceHold(ctx, "hold-3", 20, "", "", time.Time{}))
+	require.NoError(t, aggregate.CaptureHold(ctx, "hold-3", 0, "payment-2"))
+
+	daily, monthly := aggregate.BankAccount.WithdrawalTotals.Withdrawn(time.Now().UTC())
+	assert.Equal(t, int64(50), daily)
+	assert.Equal(t, int64(50), monthly)
+	assert.Equal(t, int64(50), aggregate.BankAccount.Balance.Amount())
+}
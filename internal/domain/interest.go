@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"math/big"
+	"time"
+)
+
+const (
+	// MaxInterestRateBps highest annual interest rate in basis points
+	MaxInterestRateBps = 10000
+	// InterestAccrualScale accrued interest is kept in millionths of the minor unit and rounded when posted
+	InterestAccrualScale = 1_000_000
+
+	basisPointsScale = 10000
+)
+
+// InterestAccrual interest state of the account, Accrued in millionths of the minor unit is not posted yet.
+// Days and months are UTC periods in the day and month layouts of withdrawal totals.
+type InterestAccrual struct {
+	RateBps       int64     `json:"rate_bps"`
+	RateChangedAt time.Time `json:"rate_changed_at"`
+	// AccruingSince when a non-zero rate was set first, accrual starts on its day
+	AccruingSince   time.Time `json:"accruing_since"`
+	Accrued         int64     `json:"accrued"`
+	LastAccruedDay  string    `json:"last_accrued_day,omitempty"`
+	LastPostedMonth string    `json:"last_posted_month,omitempty"`
+}
+
+// DailyInterest interest of one day on the balance in millionths of the minor unit, actual/actual day count
+// with 365 or 366 days in the year of the day. Only positive balances earn interest, the result is rounded down.
+func DailyInterest(balance int64, rateBps int64, day time.Time) int64 {
+	if balance <= 0 || rateBps <= 0 {
+		return 0
+	}
+
+	daysInYear := time.Date(day.UTC().Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+
+	// big integers keep the product of large balances exact
+	numerator := new(big.Int).Mul(big.NewInt(balance), big.NewInt(rateBps))
+	numerator.Mul(numerator, big.NewInt(InterestAccrualScale))
+	denominator := big.NewInt(basisPointsScale * int64(daysInYear))
+	return numerator.Quo(numerator, denominator).Int64()
+}
+
+// PostedInterest rounds accrued interest in millionths of the minor unit half up to minor units.
+func PostedInterest(accrued int64) int64 {
+	if accrued <= 0 {
+		return 0
+	}
+	return (accrued + InterestAccrualScale/2) / InterestAccrualScale
+}
+
+// InterestDay UTC day of t.
+func InterestDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// IsLastDayOfMonth whether day is the last UTC day of its month, when the month interest is posted.
+func IsLastDayOfMonth(day time.Time) bool {
+	return InterestDay(day).AddDate(0, 0, 1).Day() == 1
+}
+
+// InterestPaymentID payment id of the interest posted for the month.
+func InterestPaymentID(month string) string {
+	return "interest:" + month
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyInterest(t *testing.T) {
+	tests := []struct {
+		name     string
+		balance  int64
+		rateBps  int64
+		day      time.Time
+		expected int64
+	}{
+		{name: "day of a common year", balance: 100_000, rateBps: 500, day: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), expected: 13_698_630},
+		{name: "day of a leap year", balance: 100_000, rateBps: 500, day: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), expected: 13_661_202},
+		{name: "year of the UTC day", balance: 100_000, rateBps: 500, day: time.Date(2024, 1, 1, 1, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)), expected: 13_698_630},
+		{name: "fraction of millionth is rounded down", balance: 1, rateBps: 1, day: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), expected: 0},
+		{name: "large balance does not overflow", balance: 1_000_000_000_000, rateBps: MaxInterestRateBps, day: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), expected: 2_739_726_027_397_260},
+		{name: "zero balance", balance: 0, rateBps: 500, day: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "negative balance", balance: -100_000, rateBps: 500, day: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "zero rate", balance: 100_000, rateBps: 0, day: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DailyInterest(tt.balance, tt.rateBps, tt.day))
+		})
+	}
+}
+
+func TestPostedInterest(t *testing.T) {
+	tests := []struct {
+		name     string
+		accrued  int64
+		expected int64
+	}{
+		{name: "nothing accrued", accrued: 0},
+		{name: "negative accrual", accrued: -500_000},
+		{name: "below half is rounded down", accrued: 499_999},
+		{name: "half is rounded up", accrued: 500_000, expected: 1},
+		{name: "above half of whole units", accrued: 1_500_000, expected: 2},
+		{name: "below half of whole units", accrued: 1_499_999, expected: 1},
+		{name: "month of daily accruals", accrued: 31 * 13_698_630, expected: 425},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PostedInterest(tt.accrued))
+		})
+	}
+}
+
+func TestIsLastDayOfMonth(t *testing.T) {
+	tests := []struct {
+		name     string
+		day      time.Time
+		expected bool
+	}{
+		{name: "middle of month", day: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{name: "end of long month", day: time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC), expected: true},
+		{name: "end of february in common year", day: time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC), expected: true},
+		{name: "february 28 in leap year", day: time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{name: "end of february in leap year", day: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), expected: true},
+		{name: "UTC day of local time", day: time.Date(2023, 2, 1, 1, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsLastDayOfMonth(tt.day))
+		})
+	}
+}
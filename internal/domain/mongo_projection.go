@@ -3,16 +3,52 @@ package domain
 import "time"
 
 type BankAccountMongoProjection struct {
-	ID           string    `json:"id" bson:"_id,omitempty"`
-	AggregateID  string    `json:"aggregate_id" bson:"aggregate_id,omitempty"`
-	Version      uint64    `json:"version" bson:"version,omitempty"`
-	Email        string    `json:"email" bson:"email,omitempty"`
-	FirstName    string    `json:"first_name" bson:"first_name,omitempty"`
-	LastName     string    `json:"last_name" bson:"last_name,omitempty"`
-	Balance      Balance   `json:"balance" bson:"balance,omitempty"`
-	PasswordHash string    `json:"-" bson:"password_hash,omitempty"` // Don't expose in JSON
-	CreatedAt    time.Time `json:"created_at" bson:"created_at,omitempty"`
-	UpdatedAt    time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+	ID          string  `json:"id" bson:"_id,omitempty"`
+	AggregateID string  `json:"aggregate_id" bson:"aggregate_id,omitempty"`
+	Version     uint64  `json:"version" bson:"version,omitempty"`
+	Email       string  `json:"email" bson:"email,omitempty"`
+	FirstName   string  `json:"first_name" bson:"first_name,omitempty"`
+	LastName    string  `json:"last_name" bson:"last_name,omitempty"`
+	Balance     Balance `json:"balance" bson:"balance,omitempty"`
+	// Status empty in documents projected before accounts had a lifecycle, which are active
+	Status           AccountStatus    `json:"status" bson:"status,omitempty"`
+	WithdrawalPolicy WithdrawalPolicy `json:"withdrawal_policy" bson:"withdrawal_policy"`
+	// InterestRateBps annual interest rate, AccruedInterest in millionths of the minor unit is not posted yet
+	InterestRateBps int64 `json:"interest_rate_bps" bson:"interest_rate_bps"`
+	AccruedInterest int64 `json:"accrued_interest" bson:"accrued_interest"`
+	// Holds outstanding holds reserving funds of the ledger balance
+	Holds        []Hold `json:"holds" bson:"holds"`
+	PasswordHash string `json:"-" bson:"password_hash,omitempty"` // Don't expose in JSON
+	// CredentialsChangedAt tokens issued before are revoked
+	CredentialsChangedAt time.Time `json:"-" bson:"credentials_changed_at,omitempty"`
+	CreatedAt            time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt            time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+}
+
+// AvailableBalance ledger balance less funds reserved by outstanding holds. Expired holds count until
+// the release of the expired hold is projected.
+func (p *BankAccountMongoProjection) AvailableBalance() Balance {
+	available := p.Balance
+	for _, hold := range p.Holds {
+		available.Amount -= hold.Amount
+	}
+	return available
+}
+
+// RemoveHold removes the hold from outstanding holds.
+func (p *BankAccountMongoProjection) RemoveHold(holdID string) {
+	holds := make([]Hold, 0, len(p.Holds))
+	for _, hold := range p.Holds {
+		if hold.ID != holdID {
+			holds = append(holds, hold)
+		}
+	}
+	p.Holds = holds
+}
+
+// IsClosed whether the account was closed.
+func (p *BankAccountMongoProjection) IsClosed() bool {
+	return p.Status == AccountStatusClosed
 }
 
 // CheckPassword verifies the password against the stored hash
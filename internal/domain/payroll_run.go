@@ -0,0 +1,337 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/pkg/errors"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	PayrollRunAggregateType es.AggregateType = "PayrollRun"
+
+	// MaxPayrollRunLines bounds the batch size, the whole run is a single event stream
+	MaxPayrollRunLines = 10000
+)
+
+// PayrollRunSource format of the batch the run was created from.
+const (
+	PayrollRunSourceJSON = "json"
+	PayrollRunSourceCSV  = "csv"
+)
+
+// PayrollRunStatus status of the payroll run.
+type PayrollRunStatus string
+
+const (
+	PayrollRunStatusRunning   PayrollRunStatus = "running"
+	PayrollRunStatusCompleted PayrollRunStatus = "completed"
+)
+
+// PayrollLineStatus status of the single deposit of the payroll run.
+type PayrollLineStatus string
+
+const (
+	PayrollLineStatusPending   PayrollLineStatus = "pending"
+	PayrollLineStatusSucceeded PayrollLineStatus = "succeeded"
+	PayrollLineStatusFailed    PayrollLineStatus = "failed"
+)
+
+// PayrollLine deposit of amount in minor units of currency to the account, empty currency means the account currency.
+type PayrollLine struct {
+	LineNo    int    `json:"line_no"`
+	AccountID string `json:"account_id"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency,omitempty"`
+	Reference string `json:"reference"`
+
+	Status PayrollLineStatus `json:"status"`
+	Error  string            `json:"error,omitempty"`
+	// AccountVersion account version after the deposit, passed as min_version to account queries
+	AccountVersion uint64 `json:"account_version,omitempty"`
+}
+
+// PaymentID of the line deposit, deterministic so a deposit saved before a crash is found on resume.
+func (l *PayrollLine) PaymentID(runID string) string {
+	return fmt.Sprintf("payroll:%s:%d", runID, l.LineNo)
+}
+
+// PayrollRun batch of deposits executed line by line, progress is recorded in the run event stream.
+type PayrollRun struct {
+	ID          string           `json:"id"`
+	Source      string           `json:"source"`
+	Status      PayrollRunStatus `json:"status"`
+	Total       int              `json:"total"`
+	Succeeded   int              `json:"succeeded"`
+	Failed      int              `json:"failed"`
+	Pending     int              `json:"pending"`
+	Lines       []*PayrollLine   `json:"lines"`
+	CreatedAt   time.Time        `json:"created_at"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+}
+
+// IsCompleted every line has succeeded or failed.
+func (r *PayrollRun) IsCompleted() bool {
+	return r.Status == PayrollRunStatusCompleted
+}
+
+// NextPendingLine returns the first line not executed yet, nil when all lines are done.
+func (r *PayrollRun) NextPendingLine() *PayrollLine {
+	for _, line := range r.Lines {
+		if line.Status == PayrollLineStatusPending {
+			return line
+		}
+	}
+	return nil
+}
+
+// SameLines reports whether the run was created from the same batch, used to make run creation idempotent.
+func (r *PayrollRun) SameLines(lines []PayrollLine) bool {
+	if len(lines) != len(r.Lines) {
+		return false
+	}
+	for i, line := range lines {
+		existing := r.Lines[i]
+		if existing.AccountID != line.AccountID ||
+			existing.Amount != line.Amount ||
+			existing.Currency != line.Currency ||
+			existing.Reference != line.Reference {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *PayrollRun) line(lineNo int) *PayrollLine {
+	if lineNo < 1 || lineNo > len(r.Lines) {
+		return nil
+	}
+	return r.Lines[lineNo-1]
+}
+
+// PayrollLineError validation error of the batch line.
+type PayrollLineError struct {
+	LineNo int    `json:"line_no"`
+	Error  string `json:"error"`
+}
+
+// PayrollValidationError lists every invalid line of the batch, the run is rejected as a whole.
+type PayrollValidationError struct {
+	Lines []PayrollLineError
+}
+
+func (e *PayrollValidationError) Error() string {
+	messages := make([]string, 0, len(e.Lines))
+	for _, line := range e.Lines {
+		messages = append(messages, fmt.Sprintf("line %d: %s", line.LineNo, line.Error))
+	}
+	return fmt.Sprintf("%s: %s", bankAccountErrors.ErrInvalidPayrollRun, strings.Join(messages, "; "))
+}
+
+func (e *PayrollValidationError) Unwrap() error {
+	return bankAccountErrors.ErrInvalidPayrollRun
+}
+
+// ValidatePayrollLines checks lines without looking at the accounts: the batch size, amounts, currencies,
+// required fields and duplicated references of the same account. Lines are numbered in batch order.
+func ValidatePayrollLines(lines []PayrollLine) []PayrollLineError {
+	if len(lines) == 0 {
+		return []PayrollLineError{{Error: "payroll run has no lines"}}
+	}
+	if len(lines) > MaxPayrollRunLines {
+		return []PayrollLineError{{Error: fmt.Sprintf("payroll run has %d lines, limit: %d", len(lines), MaxPayrollRunLines)}}
+	}
+
+	lineErrors := make([]PayrollLineError, 0)
+	references := make(map[string]int, len(lines))
+	for i, line := range lines {
+		lineNo := i + 1
+		switch {
+		case line.AccountID == "":
+			lineErrors = append(lineErrors, PayrollLineError{LineNo: lineNo, Error: "account id is required"})
+		case line.Amount <= 0:
+			lineErrors = append(lineErrors, PayrollLineError{LineNo: lineNo, Error: fmt.Sprintf("amount must be positive: %d", line.Amount)})
+		case line.Currency != "" && money.GetCurrency(line.Currency) == nil:
+			lineErrors = append(lineErrors, PayrollLineError{LineNo: lineNo, Error: fmt.Sprintf("invalid currency: %s", line.Currency)})
+		case line.Reference == "":
+			lineErrors = append(lineErrors, PayrollLineError{LineNo: lineNo, Error: "reference is required"})
+		default:
+			key := line.AccountID + "\x00" + line.Reference
+			if first, ok := references[key]; ok {
+				lineErrors = append(lineErrors, PayrollLineError{LineNo: lineNo, Error: fmt.Sprintf("duplicates line %d", first)})
+				continue
+			}
+			references[key] = lineNo
+		}
+	}
+	return lineErrors
+}
+
+type PayrollRunAggregate struct {
+	*es.AggregateBase
+	PayrollRun *PayrollRun
+}
+
+func NewPayrollRunAggregate(id string) *PayrollRunAggregate {
+	if id == "" {
+		return nil
+	}
+
+	payrollRunAggregate := &PayrollRunAggregate{PayrollRun: &PayrollRun{ID: id, Lines: make([]*PayrollLine, 0)}}
+	aggregateBase := es.NewAggregateBase(payrollRunAggregate.When)
+	aggregateBase.SetType(PayrollRunAggregateType)
+	aggregateBase.SetID(id)
+	payrollRunAggregate.AggregateBase = aggregateBase
+	return payrollRunAggregate
+}
+
+func (a *PayrollRunAggregate) When(event any) error {
+	run := a.PayrollRun
+
+	switch evt := event.(type) {
+
+	case *events.PayrollRunCreatedEventV1:
+		run.Source = evt.Source
+		run.Status = PayrollRunStatusRunning
+		run.CreatedAt = evt.CreatedAt
+		run.Lines = make([]*PayrollLine, 0, len(evt.Lines))
+		for _, line := range evt.Lines {
+			run.Lines = append(run.Lines, &PayrollLine{
+				LineNo:    line.LineNo,
+				AccountID: line.AccountID,
+				Amount:    line.Amount,
+				Currency:  line.Currency,
+				Reference: line.Reference,
+				Status:    PayrollLineStatusPending,
+			})
+		}
+		run.Total = len(run.Lines)
+		run.Pending = len(run.Lines)
+		return nil
+
+	case *events.PayrollLineSucceededEventV1:
+		line := run.line(evt.LineNo)
+		if line == nil {
+			return errors.Wrapf(bankAccountErrors.ErrPayrollLineNotPending, "line: %d", evt.LineNo)
+		}
+		line.Status = PayrollLineStatusSucceeded
+		line.AccountVersion = evt.AccountVersion
+		run.Succeeded++
+		run.Pending--
+		return nil
+
+	case *events.PayrollLineFailedEventV1:
+		line := run.line(evt.LineNo)
+		if line == nil {
+			return errors.Wrapf(bankAccountErrors.ErrPayrollLineNotPending, "line: %d", evt.LineNo)
+		}
+		line.Status = PayrollLineStatusFailed
+		line.Error = evt.Error
+		run.Failed++
+		run.Pending--
+		return nil
+
+	case *events.PayrollRunCompletedEventV1:
+		completedAt := evt.CompletedAt
+		run.Status = PayrollRunStatusCompleted
+		run.CompletedAt = &completedAt
+		return nil
+
+	default:
+		return errors.Wrapf(bankAccountErrors.ErrUnknownEventType, "event: %#v", event)
+	}
+}
+
+// CreatePayrollRun accepts the batch of lines, every line is validated up front and the run is rejected
+// with PayrollValidationError listing all invalid lines.
+func (a *PayrollRunAggregate) CreatePayrollRun(ctx context.Context, source string, lines []PayrollLine) error {
+	if lineErrors := ValidatePayrollLines(lines); len(lineErrors) > 0 {
+		return &PayrollValidationError{Lines: lineErrors}
+	}
+
+	event := &events.PayrollRunCreatedEventV1{
+		Source:    source,
+		Lines:     make([]events.PayrollLineV1, 0, len(lines)),
+		CreatedAt: time.Now().UTC(),
+	}
+	for i, line := range lines {
+		event.Lines = append(event.Lines, events.PayrollLineV1{
+			LineNo:    i + 1,
+			AccountID: line.AccountID,
+			Amount:    line.Amount,
+			Currency:  line.Currency,
+			Reference: line.Reference,
+		})
+	}
+
+	return a.Apply(event)
+}
+
+// RecordLineSucceeded records the saved deposit of the pending line.
+func (a *PayrollRunAggregate) RecordLineSucceeded(ctx context.Context, lineNo int, accountVersion uint64) error {
+	if err := a.checkPending(lineNo); err != nil {
+		return err
+	}
+
+	event := &events.PayrollLineSucceededEventV1{
+		LineNo:         lineNo,
+		AccountVersion: accountVersion,
+	}
+
+	return a.Apply(event)
+}
+
+// RecordLineFailed records the deposit of the pending line rejected by the account.
+func (a *PayrollRunAggregate) RecordLineFailed(ctx context.Context, lineNo int, reason string) error {
+	if err := a.checkPending(lineNo); err != nil {
+		return err
+	}
+
+	event := &events.PayrollLineFailedEventV1{
+		LineNo: lineNo,
+		Error:  reason,
+	}
+
+	return a.Apply(event)
+}
+
+// CompletePayrollRun completes the run after every line has succeeded or failed.
+func (a *PayrollRunAggregate) CompletePayrollRun(ctx context.Context) error {
+	if a.PayrollRun.IsCompleted() {
+		return nil
+	}
+	if a.PayrollRun.Pending > 0 {
+		return errors.Wrapf(bankAccountErrors.ErrPayrollRunNotCompletable, "pending: %d", a.PayrollRun.Pending)
+	}
+
+	event := &events.PayrollRunCompletedEventV1{
+		CompletedAt: time.Now().UTC(),
+	}
+
+	return a.Apply(event)
+}
+
+func (a *PayrollRunAggregate) checkPending(lineNo int) error {
+	line := a.PayrollRun.line(lineNo)
+	if line == nil || line.Status != PayrollLineStatusPending {
+		return errors.Wrapf(bankAccountErrors.ErrPayrollLineNotPending, "runID: %s, line: %d", a.GetID(), lineNo)
+	}
+	return nil
+}
+
+// PayrollRunLeaseRepository leases payroll runs to a single instance, so a run is executed by one
+// instance at a time. Leases of stopped instances expire and are taken over.
+type PayrollRunLeaseRepository interface {
+	// Acquire leases the run to owner until expiresAt, false when other owner holds a lease not expired at now.
+	Acquire(ctx context.Context, runID string, owner string, now time.Time, expiresAt time.Time) (bool, error)
+	// Renew extends the lease of owner until expiresAt, false when the lease is lost.
+	Renew(ctx context.Context, runID string, owner string, expiresAt time.Time) (bool, error)
+	// Release removes the lease of owner.
+	Release(ctx context.Context, runID string, owner string) error
+}
@@ -1,6 +1,13 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
+
+const (
+	BankAccountsCollection = "bank_accounts"
+)
 
 type UpdateProjectionCallback func(projection *BankAccountMongoProjection) *BankAccountMongoProjection
 
@@ -10,7 +17,28 @@ type MongoRepository interface {
 	Upsert(ctx context.Context, projection *BankAccountMongoProjection) error
 
 	DeleteByAggregateID(ctx context.Context, aggregateID string) error
+	DeleteAll(ctx context.Context) error
 	UpdateConcurrently(ctx context.Context, aggregateID string, updateCb UpdateProjectionCallback, expectedVersion uint64) error
 	GetByAggregateID(ctx context.Context, aggregateID string) (*BankAccountMongoProjection, error)
 	GetByEmail(ctx context.Context, email string) (*BankAccountMongoProjection, error)
+	// GetIDsWithExpiredHolds returns ids after the cursor of accounts with holds expired at the time, ordered by aggregate id.
+	GetIDsWithExpiredHolds(ctx context.Context, at time.Time, cursor string, limit int) ([]string, error)
+
+	// WithCollection returns repository over another collection, used to rebuild into a shadow collection.
+	WithCollection(collection string) MongoRepository
+	EnsureCollection(ctx context.Context) error
+	Exists(ctx context.Context) (bool, error)
+	Count(ctx context.Context) (int64, error)
+	Drop(ctx context.Context) error
+	// RenameTo renames the collection replacing the target collection if it exists.
+	RenameTo(ctx context.Context, collection string) error
+}
+
+// EmailReservationRepository keeps login emails unique across accounts, an email is reserved by one account at a time.
+type EmailReservationRepository interface {
+	// Reserve reserves the email for the account, ErrEmailAlreadyExists when other account reserved it.
+	// Reserving an email the account reserved already succeeds.
+	Reserve(ctx context.Context, email string, aggregateID string) error
+	// Release releases the email reserved by the account.
+	Release(ctx context.Context, email string, aggregateID string) error
 }
@@ -31,6 +31,52 @@ func (s *eventSerializer) SerializeEvent(aggregate es.Aggregate, event any) (es.
 		return es.NewEvent(aggregate, events.BalancedDepositedEventTypeV1, eventsBytes, evt.Metadata), nil
 	case *events.BalanceWithdrawedEventV1:
 		return es.NewEvent(aggregate, events.BalanceWithdrawedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.AccountFrozenEventV1:
+		return es.NewEvent(aggregate, events.AccountFrozenEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.AccountUnfrozenEventV1:
+		return es.NewEvent(aggregate, events.AccountUnfrozenEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.AccountClosedEventV1:
+		return es.NewEvent(aggregate, events.AccountClosedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.WithdrawalPolicyChangedEventV1:
+		return es.NewEvent(aggregate, events.WithdrawalPolicyChangedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.InterestRateChangedEventV1:
+		return es.NewEvent(aggregate, events.InterestRateChangedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.InterestAccruedEventV1:
+		return es.NewEvent(aggregate, events.InterestAccruedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.InterestPostedEventV1:
+		return es.NewEvent(aggregate, events.InterestPostedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.HoldPlacedEventV1:
+		return es.NewEvent(aggregate, events.HoldPlacedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.HoldCapturedEventV1:
+		return es.NewEvent(aggregate, events.HoldCapturedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.HoldReleasedEventV1:
+		return es.NewEvent(aggregate, events.HoldReleasedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.DepositReversedEventV1:
+		return es.NewEvent(aggregate, events.DepositReversedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.WithdrawalReversedEventV1:
+		return es.NewEvent(aggregate, events.WithdrawalReversedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.EmailChangedEventV1:
+		return es.NewEvent(aggregate, events.EmailChangedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.ProfileUpdatedEventV1:
+		return es.NewEvent(aggregate, events.ProfileUpdatedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.PasswordChangedEventV1:
+		return es.NewEvent(aggregate, events.PasswordChangedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.PayrollRunCreatedEventV1:
+		return es.NewEvent(aggregate, events.PayrollRunCreatedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.PayrollLineSucceededEventV1:
+		return es.NewEvent(aggregate, events.PayrollLineSucceededEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.PayrollLineFailedEventV1:
+		return es.NewEvent(aggregate, events.PayrollLineFailedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.PayrollRunCompletedEventV1:
+		return es.NewEvent(aggregate, events.PayrollRunCompletedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.StandingOrderCreatedEventV1:
+		return es.NewEvent(aggregate, events.StandingOrderCreatedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.StandingOrderExecutedEventV1:
+		return es.NewEvent(aggregate, events.StandingOrderExecutedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.StandingOrderExecutionFailedEventV1:
+		return es.NewEvent(aggregate, events.StandingOrderExecutionFailedEventTypeV1, eventsBytes, evt.Metadata), nil
+	case *events.StandingOrderCancelledEventV1:
+		return es.NewEvent(aggregate, events.StandingOrderCancelledEventTypeV1, eventsBytes, evt.Metadata), nil
 	default:
 		return es.Event{}, errors.Wrapf(ErrInvalidEvent, "aggregateID: %s, type: %T", aggregate.GetID(), event)
 	}
@@ -44,6 +90,52 @@ func (s *eventSerializer) DeserializeEvent(event es.Event) (any, error) {
 		return deserializeEvent(event, new(events.BalanceDepositedEventV1))
 	case events.BalanceWithdrawedEventTypeV1:
 		return deserializeEvent(event, new(events.BalanceWithdrawedEventV1))
+	case events.AccountFrozenEventTypeV1:
+		return deserializeEvent(event, new(events.AccountFrozenEventV1))
+	case events.AccountUnfrozenEventTypeV1:
+		return deserializeEvent(event, new(events.AccountUnfrozenEventV1))
+	case events.AccountClosedEventTypeV1:
+		return deserializeEvent(event, new(events.AccountClosedEventV1))
+	case events.WithdrawalPolicyChangedEventTypeV1:
+		return deserializeEvent(event, new(events.WithdrawalPolicyChangedEventV1))
+	case events.InterestRateChangedEventTypeV1:
+		return deserializeEvent(event, new(events.InterestRateChangedEventV1))
+	case events.InterestAccruedEventTypeV1:
+		return deserializeEvent(event, new(events.InterestAccruedEventV1))
+	case events.InterestPostedEventTypeV1:
+		return deserializeEvent(event, new(events.InterestPostedEventV1))
+	case events.HoldPlacedEventTypeV1:
+		return deserializeEvent(event, new(events.HoldPlacedEventV1))
+	case events.HoldCapturedEventTypeV1:
+		return deserializeEvent(event, new(events.HoldCapturedEventV1))
+	case events.HoldReleasedEventTypeV1:
+		return deserializeEvent(event, new(events.HoldReleasedEventV1))
+	case events.DepositReversedEventTypeV1:
+		return deserializeEvent(event, new(events.DepositReversedEventV1))
+	case events.WithdrawalReversedEventTypeV1:
+		return deserializeEvent(event, new(events.WithdrawalReversedEventV1))
+	case events.EmailChangedEventTypeV1:
+		return deserializeEvent(event, new(events.EmailChangedEventV1))
+	case events.ProfileUpdatedEventTypeV1:
+		return deserializeEvent(event, new(events.ProfileUpdatedEventV1))
+	case events.PasswordChangedEventTypeV1:
+		return deserializeEvent(event, new(events.PasswordChangedEventV1))
+	case events.PayrollRunCreatedEventTypeV1:
+		return deserializeEvent(event, new(events.PayrollRunCreatedEventV1))
+	case events.PayrollLineSucceededEventTypeV1:
+		return deserializeEvent(event, new(events.PayrollLineSucceededEventV1))
+	case events.PayrollLineFailedEventTypeV1:
+		return deserializeEvent(event, new(events.PayrollLineFailedEventV1))
+	case events.PayrollRunCompletedEventTypeV1:
+		return deserializeEvent(event, new(events.PayrollRunCompletedEventV1))
+	case events.StandingOrderCreatedEventTypeV1:
+		return deserializeEvent(event, new(events.StandingOrderCreatedEventV1))
+	case events.StandingOrderExecutedEventTypeV1:
+		return deserializeEvent(event, new(events.StandingOrderExecutedEventV1))
+	case events.StandingOrderExecutionFailedEventTypeV1:
+		return deserializeEvent(event, new(events.StandingOrderExecutionFailedEventV1))
+	case events.StandingOrderCancelledEventTypeV1:
+		return deserializeEvent(event, new(events.StandingOrderCancelledEventV1))
 	default:
 		return nil, errors.Wrapf(ErrInvalidEvent, "type: %s", event.GetEventType())
 	}
@@ -0,0 +1,455 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/pkg/errors"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/pkg/cron"
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	StandingOrderAggregateType es.AggregateType = "StandingOrder"
+
+	// maxStandingOrderHistory bounds executions kept in the order state, the event stream keeps all of them
+	maxStandingOrderHistory = 50
+)
+
+// StandingOrderKind command dispatched by the standing order.
+type StandingOrderKind string
+
+const (
+	StandingOrderKindDeposit    StandingOrderKind = "deposit"
+	StandingOrderKindWithdrawal StandingOrderKind = "withdrawal"
+	StandingOrderKindTransfer   StandingOrderKind = "transfer"
+)
+
+// ScheduleFrequency how the standing order repeats.
+type ScheduleFrequency string
+
+const (
+	ScheduleFrequencyOnce    ScheduleFrequency = "once"
+	ScheduleFrequencyDaily   ScheduleFrequency = "daily"
+	ScheduleFrequencyWeekly  ScheduleFrequency = "weekly"
+	ScheduleFrequencyMonthly ScheduleFrequency = "monthly"
+	ScheduleFrequencyCron    ScheduleFrequency = "cron"
+)
+
+// StandingOrderStatus status of the standing order.
+type StandingOrderStatus string
+
+const (
+	StandingOrderStatusActive    StandingOrderStatus = "active"
+	StandingOrderStatusCompleted StandingOrderStatus = "completed"
+	StandingOrderStatusCancelled StandingOrderStatus = "cancelled"
+)
+
+// Schedule occurrences of the standing order in UTC. Daily, weekly and monthly occurrences repeat StartAt,
+// monthly occurrences fall on the last day of shorter months. Cron occurrences are activations of the
+// five field cron expression not before StartAt. No occurrence is after EndAt.
+type Schedule struct {
+	Frequency ScheduleFrequency `json:"frequency"`
+	Cron      string            `json:"cron,omitempty"`
+	StartAt   time.Time         `json:"start_at"`
+	EndAt     *time.Time        `json:"end_at,omitempty"`
+}
+
+// Validate checks the frequency, cron expression and the schedule window.
+func (s Schedule) Validate() error {
+	switch s.Frequency {
+	case ScheduleFrequencyOnce, ScheduleFrequencyDaily, ScheduleFrequencyWeekly, ScheduleFrequencyMonthly:
+	case ScheduleFrequencyCron:
+		if _, err := cron.Parse(s.Cron); err != nil {
+			return errors.Wrap(bankAccountErrors.ErrInvalidSchedule, err.Error())
+		}
+	default:
+		return errors.Wrapf(bankAccountErrors.ErrInvalidSchedule, "frequency: %s", s.Frequency)
+	}
+	if s.StartAt.IsZero() {
+		return errors.Wrap(bankAccountErrors.ErrInvalidSchedule, "start_at is required")
+	}
+	if s.EndAt != nil && s.EndAt.Before(s.StartAt) {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidSchedule, "end_at: %s is before start_at: %s", s.EndAt, s.StartAt)
+	}
+	return nil
+}
+
+// Next returns the first occurrence after t, false when the schedule has no more occurrences.
+func (s Schedule) Next(t time.Time) (time.Time, bool) {
+	start := s.StartAt.UTC()
+	t = t.UTC()
+
+	var next time.Time
+	switch s.Frequency {
+	case ScheduleFrequencyOnce:
+		if !start.After(t) {
+			return time.Time{}, false
+		}
+		next = start
+	case ScheduleFrequencyDaily, ScheduleFrequencyWeekly:
+		period := 24 * time.Hour
+		if s.Frequency == ScheduleFrequencyWeekly {
+			period *= 7
+		}
+		next = start
+		if !start.After(t) {
+			next = start.Add((t.Sub(start)/period + 1) * period)
+		}
+	case ScheduleFrequencyMonthly:
+		months := (t.Year()-start.Year())*12 + int(t.Month()-start.Month())
+		for m := max(months, 0); ; m++ {
+			if next = addMonths(start, m); next.After(t) {
+				break
+			}
+		}
+	case ScheduleFrequencyCron:
+		expr, err := cron.Parse(s.Cron)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if t.Before(start) {
+			t = start.Add(-time.Nanosecond)
+		}
+		if next = expr.Next(t); next.IsZero() {
+			return time.Time{}, false
+		}
+	default:
+		return time.Time{}, false
+	}
+
+	if s.EndAt != nil && next.After(s.EndAt.UTC()) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// addMonths adds months to t keeping the day, clamped to the last day of the month.
+func addMonths(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	return firstOfMonth.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
+
+// StandingOrderInstruction command dispatched on every occurrence, amount in minor units of currency.
+// Transfers withdraw from the account and deposit to the target account.
+type StandingOrderInstruction struct {
+	Kind            StandingOrderKind `json:"kind"`
+	AccountID       string            `json:"account_id"`
+	TargetAccountID string            `json:"target_account_id,omitempty"`
+	Amount          int64             `json:"amount"`
+	Currency        string            `json:"currency"`
+	Reference       string            `json:"reference"`
+}
+
+// Validate checks the instruction without looking at the accounts.
+func (i StandingOrderInstruction) Validate() error {
+	switch i.Kind {
+	case StandingOrderKindDeposit, StandingOrderKindWithdrawal:
+	case StandingOrderKindTransfer:
+		if i.TargetAccountID == "" || i.TargetAccountID == i.AccountID {
+			return errors.Wrapf(bankAccountErrors.ErrInvalidStandingOrder, "transfer target account: %q", i.TargetAccountID)
+		}
+	default:
+		return errors.Wrapf(bankAccountErrors.ErrInvalidStandingOrder, "kind: %s", i.Kind)
+	}
+	if i.AccountID == "" {
+		return errors.Wrap(bankAccountErrors.ErrInvalidStandingOrder, "account id is required")
+	}
+	if i.Amount <= 0 {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidBalanceAmount, "amount: %d", i.Amount)
+	}
+	if money.GetCurrency(i.Currency) == nil {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidCurrency, "currency: %s", i.Currency)
+	}
+	return nil
+}
+
+// StandingOrderExecution result of an attempt to execute the occurrence scheduled at RunAt.
+type StandingOrderExecution struct {
+	RunAt     time.Time  `json:"run_at"`
+	Attempt   int        `json:"attempt"`
+	Succeeded bool       `json:"succeeded"`
+	Error     string     `json:"error,omitempty"`
+	At        time.Time  `json:"at"`
+	RetryAt   *time.Time `json:"retry_at,omitempty"`
+}
+
+// StandingOrder scheduled instruction with the occurrence due next and the latest executions.
+type StandingOrder struct {
+	ID string `json:"id"`
+	// OwnerID user who created the order, only the owner and admins may read or cancel it
+	OwnerID string `json:"owner_id"`
+	StandingOrderInstruction
+	Schedule    Schedule            `json:"schedule"`
+	MaxAttempts int                 `json:"max_attempts"`
+	Status      StandingOrderStatus `json:"status"`
+	// NextRunAt occurrence executed next, RetryAt is set while its failed attempt waits for retry
+	NextRunAt  *time.Time               `json:"next_run_at,omitempty"`
+	RetryAt    *time.Time               `json:"retry_at,omitempty"`
+	Attempts   int                      `json:"attempts"`
+	Executions int                      `json:"executions"`
+	Failures   int                      `json:"failures"`
+	History    []StandingOrderExecution `json:"history"`
+	CreatedAt  time.Time                `json:"created_at"`
+}
+
+// IsActive order has occurrences to execute.
+func (o *StandingOrder) IsActive() bool {
+	return o.Status == StandingOrderStatusActive
+}
+
+// DueAt when the order is executed next, nil when it is not active.
+func (o *StandingOrder) DueAt() *time.Time {
+	if !o.IsActive() {
+		return nil
+	}
+	if o.RetryAt != nil {
+		return o.RetryAt
+	}
+	return o.NextRunAt
+}
+
+// PaymentID of the occurrence, deterministic so an execution saved before a crash is found on retry.
+func (o *StandingOrder) PaymentID(runAt time.Time) string {
+	return fmt.Sprintf("standing-order:%s:%d", o.ID, runAt.Unix())
+}
+
+func (o *StandingOrder) record(execution StandingOrderExecution) {
+	o.History = append(o.History, execution)
+	if len(o.History) > maxStandingOrderHistory {
+		o.History = o.History[len(o.History)-maxStandingOrderHistory:]
+	}
+}
+
+func (o *StandingOrder) moveTo(nextRunAt *time.Time) {
+	o.NextRunAt = nextRunAt
+	o.RetryAt = nil
+	o.Attempts = 0
+	if nextRunAt == nil {
+		o.Status = StandingOrderStatusCompleted
+	}
+}
+
+type StandingOrderAggregate struct {
+	*es.AggregateBase
+	StandingOrder *StandingOrder
+}
+
+func NewStandingOrderAggregate(id string) *StandingOrderAggregate {
+	if id == "" {
+		return nil
+	}
+
+	standingOrderAggregate := &StandingOrderAggregate{StandingOrder: &StandingOrder{ID: id, History: make([]StandingOrderExecution, 0)}}
+	aggregateBase := es.NewAggregateBase(standingOrderAggregate.When)
+	aggregateBase.SetType(StandingOrderAggregateType)
+	aggregateBase.SetID(id)
+	standingOrderAggregate.AggregateBase = aggregateBase
+	return standingOrderAggregate
+}
+
+func (a *StandingOrderAggregate) When(event any) error {
+	order := a.StandingOrder
+
+	switch evt := event.(type) {
+
+	case *events.StandingOrderCreatedEventV1:
+		order.StandingOrderInstruction = StandingOrderInstruction{
+			Kind:            StandingOrderKind(evt.Kind),
+			AccountID:       evt.AccountID,
+			TargetAccountID: evt.TargetAccountID,
+			Amount:          evt.Amount,
+			Currency:        evt.Currency,
+			Reference:       evt.Reference,
+		}
+		// orders created before owners were recorded belong to the owner of the account
+		order.OwnerID = evt.OwnerID
+		if order.OwnerID == "" {
+			order.OwnerID = evt.AccountID
+		}
+		order.Schedule = Schedule{
+			Frequency: ScheduleFrequency(evt.Frequency),
+			Cron:      evt.Cron,
+			StartAt:   evt.StartAt,
+			EndAt:     evt.EndAt,
+		}
+		order.MaxAttempts = evt.MaxAttempts
+		order.Status = StandingOrderStatusActive
+		order.CreatedAt = evt.CreatedAt
+		nextRunAt := evt.NextRunAt
+		order.NextRunAt = &nextRunAt
+		return nil
+
+	case *events.StandingOrderExecutedEventV1:
+		order.Executions++
+		order.record(StandingOrderExecution{
+			RunAt:     evt.RunAt,
+			Attempt:   order.Attempts + 1,
+			Succeeded: true,
+			At:        evt.ExecutedAt,
+		})
+		order.moveTo(evt.NextRunAt)
+		return nil
+
+	case *events.StandingOrderExecutionFailedEventV1:
+		order.Failures++
+		order.record(StandingOrderExecution{
+			RunAt:   evt.RunAt,
+			Attempt: evt.Attempt,
+			Error:   evt.Error,
+			At:      evt.FailedAt,
+			RetryAt: evt.RetryAt,
+		})
+		if evt.RetryAt != nil {
+			order.Attempts = evt.Attempt
+			order.RetryAt = evt.RetryAt
+			return nil
+		}
+		order.moveTo(evt.NextRunAt)
+		return nil
+
+	case *events.StandingOrderCancelledEventV1:
+		order.Status = StandingOrderStatusCancelled
+		order.NextRunAt = nil
+		order.RetryAt = nil
+		return nil
+
+	default:
+		return errors.Wrapf(bankAccountErrors.ErrUnknownEventType, "event: %#v", event)
+	}
+}
+
+// CreateStandingOrder schedules the instruction for the owner, occurrences failing maxAttempts times are skipped.
+func (a *StandingOrderAggregate) CreateStandingOrder(ctx context.Context, ownerID string, instruction StandingOrderInstruction, schedule Schedule, maxAttempts int) error {
+	if ownerID == "" {
+		return errors.Wrap(bankAccountErrors.ErrInvalidStandingOrder, "owner id is required")
+	}
+	if err := instruction.Validate(); err != nil {
+		return err
+	}
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	if maxAttempts <= 0 {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidStandingOrder, "max attempts: %d", maxAttempts)
+	}
+
+	// occurrences before now are not executed
+	now := time.Now().UTC()
+	nextRunAt, ok := schedule.Next(now.Add(-time.Nanosecond))
+	if !ok {
+		return errors.Wrap(bankAccountErrors.ErrInvalidSchedule, "schedule has no future occurrences")
+	}
+
+	event := &events.StandingOrderCreatedEventV1{
+		Kind:            string(instruction.Kind),
+		AccountID:       instruction.AccountID,
+		OwnerID:         ownerID,
+		TargetAccountID: instruction.TargetAccountID,
+		Amount:          instruction.Amount,
+		Currency:        instruction.Currency,
+		Reference:       instruction.Reference,
+		Frequency:       string(schedule.Frequency),
+		Cron:            schedule.Cron,
+		StartAt:         schedule.StartAt.UTC(),
+		EndAt:           schedule.EndAt,
+		MaxAttempts:     maxAttempts,
+		NextRunAt:       nextRunAt,
+		CreatedAt:       now,
+	}
+
+	return a.Apply(event)
+}
+
+// RecordExecuted records execution of the due occurrence and moves the order to the next one.
+func (a *StandingOrderAggregate) RecordExecuted(ctx context.Context, runAt time.Time, paymentID string) error {
+	if err := a.checkDue(runAt); err != nil {
+		return err
+	}
+
+	event := &events.StandingOrderExecutedEventV1{
+		RunAt:      runAt,
+		ExecutedAt: time.Now().UTC(),
+		PaymentID:  paymentID,
+		NextRunAt:  a.nextRunAt(runAt),
+	}
+
+	return a.Apply(event)
+}
+
+// RecordFailed records failed attempt of the due occurrence. Retryable attempts are retried after
+// retryDelay doubled with every attempt until max attempts, then the occurrence is skipped.
+func (a *StandingOrderAggregate) RecordFailed(ctx context.Context, runAt time.Time, reason string, retryable bool, retryDelay time.Duration) error {
+	if err := a.checkDue(runAt); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	attempt := a.StandingOrder.Attempts + 1
+	event := &events.StandingOrderExecutionFailedEventV1{
+		RunAt:    runAt,
+		Attempt:  attempt,
+		Error:    reason,
+		FailedAt: now,
+	}
+	if retryable && attempt < a.StandingOrder.MaxAttempts {
+		retryAt := now.Add(retryDelay << (attempt - 1))
+		event.RetryAt = &retryAt
+	} else {
+		event.NextRunAt = a.nextRunAt(runAt)
+	}
+
+	return a.Apply(event)
+}
+
+// CancelStandingOrder stops further occurrences.
+func (a *StandingOrderAggregate) CancelStandingOrder(ctx context.Context, reason string) error {
+	if !a.StandingOrder.IsActive() {
+		return errors.Wrapf(bankAccountErrors.ErrStandingOrderNotActive, "id: %s, status: %s", a.GetID(), a.StandingOrder.Status)
+	}
+
+	event := &events.StandingOrderCancelledEventV1{
+		Reason: reason,
+	}
+
+	return a.Apply(event)
+}
+
+func (a *StandingOrderAggregate) checkDue(runAt time.Time) error {
+	order := a.StandingOrder
+	if !order.IsActive() {
+		return errors.Wrapf(bankAccountErrors.ErrStandingOrderNotActive, "id: %s, status: %s", a.GetID(), order.Status)
+	}
+	if order.NextRunAt == nil || !order.NextRunAt.Equal(runAt) {
+		return errors.Wrapf(bankAccountErrors.ErrStandingOrderNotDue, "id: %s, run at: %s", a.GetID(), runAt)
+	}
+	return nil
+}
+
+func (a *StandingOrderAggregate) nextRunAt(runAt time.Time) *time.Time {
+	next, ok := a.StandingOrder.Schedule.Next(runAt)
+	if !ok {
+		return nil
+	}
+	return &next
+}
+
+// ClaimFunc executes the claimed standing order and returns when it is due next, nil when it is not active.
+type ClaimFunc func(ctx context.Context, id string) (*time.Time, error)
+
+// StandingOrderScheduleRepository due times of active standing orders, the index the scheduler polls.
+// Standing order aggregates are the source of truth, the schedule is corrected from them on every claim.
+type StandingOrderScheduleRepository interface {
+	// Save stores when the order is due, order without due time is removed.
+	Save(ctx context.Context, id string, dueAt *time.Time) error
+	// Ensure adds the order when it is missing, existing due time is kept.
+	Ensure(ctx context.Context, id string, dueAt time.Time) error
+	// ClaimDue locks up to limit orders due at now, skipping orders locked by other instances, and executes
+	// them holding the locks. Due times returned by fn are saved before the locks are released.
+	ClaimDue(ctx context.Context, now time.Time, limit int, fn ClaimFunc) (int, error)
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	TransactionsCollection = "transactions"
+)
+
+// TransactionType operation recorded in the ledger.
+type TransactionType string
+
+const (
+	// TransactionTypeOpening initial balance of the account, starts the running balance.
+	TransactionTypeOpening    TransactionType = "opening"
+	TransactionTypeDeposit    TransactionType = "deposit"
+	TransactionTypeWithdrawal TransactionType = "withdrawal"
+	// TransactionTypeClosingPayout remaining balance paid out when the account was closed.
+	TransactionTypeClosingPayout TransactionType = "closing_payout"
+	// TransactionTypeInterest monthly posting of accrued interest.
+	TransactionTypeInterest TransactionType = "interest"
+	// TransactionTypeHoldCapture captured amount of a funds hold.
+	TransactionTypeHoldCapture TransactionType = "hold_capture"
+	// TransactionTypeDepositReversal and TransactionTypeWithdrawalReversal compensate the entry of
+	// the reversed event, ReversedEventID references it.
+	TransactionTypeDepositReversal    TransactionType = "deposit_reversal"
+	TransactionTypeWithdrawalReversal TransactionType = "withdrawal_reversal"
+)
+
+// TransactionDirection whether the transaction credits or debits the account.
+type TransactionDirection string
+
+const (
+	TransactionDirectionCredit TransactionDirection = "credit"
+	TransactionDirectionDebit  TransactionDirection = "debit"
+)
+
+// TransactionMongoProjection ledger entry of a single balance changing event, amounts are in minor units.
+type TransactionMongoProjection struct {
+	ID           string               `json:"id" bson:"_id,omitempty"`
+	EventID      string               `json:"event_id" bson:"event_id"`
+	AggregateID  string               `json:"aggregate_id" bson:"aggregate_id"`
+	Version      uint64               `json:"version" bson:"version"`
+	Type         TransactionType      `json:"type" bson:"type"`
+	Direction    TransactionDirection `json:"direction" bson:"direction"`
+	Amount       int64                `json:"amount" bson:"amount"`
+	Currency     string               `json:"currency" bson:"currency"`
+	PaymentID    string               `json:"payment_id,omitempty" bson:"payment_id,omitempty"`
+	BalanceAfter int64                `json:"balance_after" bson:"balance_after"`
+	// ReversedEventID event of the entry compensated by a reversal
+	ReversedEventID string    `json:"reversed_event_id,omitempty" bson:"reversed_event_id,omitempty"`
+	Timestamp       time.Time `json:"timestamp" bson:"timestamp"`
+	// Metadata correlation metadata of the event such as trace context
+	Metadata map[string]string `json:"metadata,omitempty" bson:"metadata,omitempty"`
+}
+
+// TransactionFilter selects ledger entries of the account, newest first.
+type TransactionFilter struct {
+	AggregateID string
+	From        *time.Time
+	To          *time.Time
+	Page        int
+	Size        int
+}
+
+type TransactionRepository interface {
+	EnsureCollection(ctx context.Context) error
+
+	// Upsert writes the entry keyed by aggregate id and version, replayed events overwrite their entries.
+	Upsert(ctx context.Context, transaction *TransactionMongoProjection) error
+	// GetLastBefore returns the entry of the account with the highest version below version.
+	GetLastBefore(ctx context.Context, aggregateID string, version uint64) (*TransactionMongoProjection, error)
+	// List returns a page of entries matching the filter and the number of all matching entries.
+	List(ctx context.Context, filter TransactionFilter) ([]*TransactionMongoProjection, int64, error)
+
+	DeleteByAggregateID(ctx context.Context, aggregateID string) error
+	DeleteAll(ctx context.Context) error
+}
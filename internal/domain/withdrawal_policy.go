@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+)
+
+const (
+	dayPeriodLayout   = "2006-01-02"
+	monthPeriodLayout = "2006-01"
+)
+
+// WithdrawalPolicy limits of withdrawals from the account in minor units of the account currency,
+// zero limit means no limit and zero overdraft limit means the balance cannot go negative.
+type WithdrawalPolicy struct {
+	OverdraftLimit    int64 `json:"overdraft_limit" bson:"overdraft_limit"`
+	MaxPerTransaction int64 `json:"max_per_transaction" bson:"max_per_transaction"`
+	DailyLimit        int64 `json:"daily_limit" bson:"daily_limit"`
+	MonthlyLimit      int64 `json:"monthly_limit" bson:"monthly_limit"`
+}
+
+// Validate rejects negative limits.
+func (p WithdrawalPolicy) Validate() error {
+	if p.OverdraftLimit < 0 || p.MaxPerTransaction < 0 || p.DailyLimit < 0 || p.MonthlyLimit < 0 {
+		return errors.Wrapf(bankAccountErrors.ErrInvalidWithdrawalPolicy, "policy: %+v", p)
+	}
+	return nil
+}
+
+// WithdrawalTotals amounts withdrawn within the UTC day and month of the latest withdrawal,
+// built from the withdrawal events of the account.
+type WithdrawalTotals struct {
+	Day         string `json:"day,omitempty"`
+	DayAmount   int64  `json:"day_amount"`
+	Month       string `json:"month,omitempty"`
+	MonthAmount int64  `json:"month_amount"`
+}
+
+// Add counts amount withdrawn at, totals of previous periods are dropped.
+func (t *WithdrawalTotals) Add(amount int64, at time.Time) {
+	day, month := withdrawalPeriods(at)
+	if t.Day != day {
+		t.Day, t.DayAmount = day, 0
+	}
+	if t.Month != month {
+		t.Month, t.MonthAmount = month, 0
+	}
+	t.DayAmount += amount
+	t.MonthAmount += amount
+}
+
+// Withdrawn returns amounts already withdrawn within the day and month of at.
+func (t WithdrawalTotals) Withdrawn(at time.Time) (daily int64, monthly int64) {
+	day, month := withdrawalPeriods(at)
+	if t.Day == day {
+		daily = t.DayAmount
+	}
+	if t.Month == month {
+		monthly = t.MonthAmount
+	}
+	return daily, monthly
+}
+
+// CheckWithdrawal verifies withdrawing amount at from the balance against the policy.
+func (p WithdrawalPolicy) CheckWithdrawal(balance int64, amount int64, totals WithdrawalTotals, at time.Time) error {
+	if p.MaxPerTransaction > 0 && amount > p.MaxPerTransaction {
+		return errors.Wrapf(bankAccountErrors.ErrTransactionLimitExceeded, "amount: %d, limit: %d", amount, p.MaxPerTransaction)
+	}
+
+	daily, monthly := totals.Withdrawn(at)
+	if p.DailyLimit > 0 && daily+amount > p.DailyLimit {
+		return errors.Wrapf(bankAccountErrors.ErrDailyLimitExceeded, "amount: %d, withdrawn: %d, limit: %d", amount, daily, p.DailyLimit)
+	}
+	if p.MonthlyLimit > 0 && monthly+amount > p.MonthlyLimit {
+		return errors.Wrapf(bankAccountErrors.ErrMonthlyLimitExceeded, "amount: %d, withdrawn: %d, limit: %d", amount, monthly, p.MonthlyLimit)
+	}
+
+	if balance-amount < -p.OverdraftLimit {
+		return errors.Wrapf(bankAccountErrors.ErrNotEnoughBalance, "amount: %d, balance: %d, overdraft limit: %d", amount, balance, p.OverdraftLimit)
+	}
+	return nil
+}
+
+func withdrawalPeriods(at time.Time) (string, string) {
+	at = at.UTC()
+	return at.Format(dayPeriodLayout), at.Format(monthPeriodLayout)
+}
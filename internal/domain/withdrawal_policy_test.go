@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+)
+
+func TestWithdrawalPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   WithdrawalPolicy
+		expected error
+	}{
+		{name: "no limits", policy: WithdrawalPolicy{}},
+		{name: "all limits", policy: WithdrawalPolicy{OverdraftLimit: 100, MaxPerTransaction: 50, DailyLimit: 200, MonthlyLimit: 1000}},
+		{name: "negative overdraft limit", policy: WithdrawalPolicy{OverdraftLimit: -1}, expected: bankAccountErrors.ErrInvalidWithdrawalPolicy},
+		{name: "negative max per transaction", policy: WithdrawalPolicy{MaxPerTransaction: -1}, expected: bankAccountErrors.ErrInvalidWithdrawalPolicy},
+		{name: "negative daily limit", policy: WithdrawalPolicy{DailyLimit: -1}, expected: bankAccountErrors.ErrInvalidWithdrawalPolicy},
+		{name: "negative monthly limit", policy: WithdrawalPolicy{MonthlyLimit: -1}, expected: bankAccountErrors.ErrInvalidWithdrawalPolicy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestWithdrawalPolicyCheckWithdrawal(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		policy   WithdrawalPolicy
+		balance  int64
+		amount   int64
+		totals   WithdrawalTotals
+		expected error
+	}{
+		{name: "within balance", balance: 100, amount: 100},
+		{name: "balance cannot go negative without overdraft", balance: 100, amount: 101, expected: bankAccountErrors.ErrNotEnoughBalance},
+		{name: "within overdraft limit", policy: WithdrawalPolicy{OverdraftLimit: 50}, balance: 100, amount: 150},
+		{name: "over overdraft limit", policy: WithdrawalPolicy{OverdraftLimit: 50}, balance: 100, amount: 151, expected: bankAccountErrors.ErrNotEnoughBalance},
+		{name: "at max per transaction", policy: WithdrawalPolicy{MaxPerTransaction: 40}, balance: 100, amount: 40},
+		{name: "over max per transaction", policy: WithdrawalPolicy{MaxPerTransaction: 40}, balance: 100, amount: 41, expected: bankAccountErrors.ErrTransactionLimitExceeded},
+		{
+			name:    "at daily limit with withdrawals of the day",
+			policy:  WithdrawalPolicy{DailyLimit: 100},
+			balance: 1000, amount: 30,
+			totals: WithdrawalTotals{Day: "2024-03-15", DayAmount: 70, Month: "2024-03", MonthAmount: 70},
+		},
+		{
+			name:    "over daily limit with withdrawals of the day",
+			policy:  WithdrawalPolicy{DailyLimit: 100},
+			balance: 1000, amount: 31,
+			totals:   WithdrawalTotals{Day: "2024-03-15", DayAmount: 70, Month: "2024-03", MonthAmount: 70},
+			expected: bankAccountErrors.ErrDailyLimitExceeded,
+		},
+		{
+			name:    "withdrawals of a previous day do not count to the daily limit",
+			policy:  WithdrawalPolicy{DailyLimit: 100},
+			balance: 1000, amount: 100,
+			totals: WithdrawalTotals{Day: "2024-03-14", DayAmount: 70, Month: "2024-03", MonthAmount: 70},
+		},
+		{
+			name:    "over monthly limit with withdrawals of previous days",
+			policy:  WithdrawalPolicy{DailyLimit: 100, MonthlyLimit: 500},
+			balance: 1000, amount: 50,
+			totals:   WithdrawalTotals{Day: "2024-03-14", DayAmount: 70, Month: "2024-03", MonthAmount: 460},
+			expected: bankAccountErrors.ErrMonthlyLimitExceeded,
+		},
+		{
+			name:    "withdrawals of a previous month do not count to the monthly limit",
+			policy:  WithdrawalPolicy{MonthlyLimit: 500},
+			balance: 1000, amount: 500,
+			totals: WithdrawalTotals{Day: "2024-02-29", DayAmount: 460, Month: "2024-02", MonthAmount: 460},
+		},
+		{
+			name:    "transaction limit is checked before the balance",
+			policy:  WithdrawalPolicy{MaxPerTransaction: 40},
+			balance: 10, amount: 50,
+			expected: bankAccountErrors.ErrTransactionLimitExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.CheckWithdrawal(tt.balance, tt.amount, tt.totals, now)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestWithdrawalTotalsAdd(t *testing.T) {
+	tests := []struct {
+		name     string
+		totals   WithdrawalTotals
+		at       time.Time
+		expected WithdrawalTotals
+	}{
+		{
+			name:     "first withdrawal",
+			at:       time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC),
+			expected: WithdrawalTotals{Day: "2024-03-15", DayAmount: 10, Month: "2024-03", MonthAmount: 10},
+		},
+		{
+			name:     "same day",
+			totals:   WithdrawalTotals{Day: "2024-03-15", DayAmount: 5, Month: "2024-03", MonthAmount: 20},
+			at:       time.Date(2024, 3, 15, 23, 59, 0, 0, time.UTC),
+			expected: WithdrawalTotals{Day: "2024-03-15", DayAmount: 15, Month: "2024-03", MonthAmount: 30},
+		},
+		{
+			name:     "next day of the month",
+			totals:   WithdrawalTotals{Day: "2024-03-15", DayAmount: 5, Month: "2024-03", MonthAmount: 20},
+			at:       time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC),
+			expected: WithdrawalTotals{Day: "2024-03-16", DayAmount: 10, Month: "2024-03", MonthAmount: 30},
+		},
+		{
+			name:     "next month",
+			totals:   WithdrawalTotals{Day: "2024-03-31", DayAmount: 5, Month: "2024-03", MonthAmount: 20},
+			at:       time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			expected: WithdrawalTotals{Day: "2024-04-01", DayAmount: 10, Month: "2024-04", MonthAmount: 10},
+		},
+		{
+			name:     "periods are UTC",
+			totals:   WithdrawalTotals{Day: "2024-03-15", DayAmount: 5, Month: "2024-03", MonthAmount: 20},
+			at:       time.Date(2024, 3, 16, 1, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)),
+			expected: WithdrawalTotals{Day: "2024-03-15", DayAmount: 15, Month: "2024-03", MonthAmount: 30},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			totals := tt.totals
+			totals.Add(10, tt.at)
+			assert.Equal(t, tt.expected, totals)
+		})
+	}
+}
@@ -11,6 +11,7 @@ type CreateBankAccountRequest struct {
 	Email       string `json:"email" validate:"required,email"`
 	FirstName   string `json:"first_name" validate:"required,gte=0"`
 	LastName    string `json:"last_name" validate:"required,gte=0"`
+	Currency    string `json:"currency" validate:"omitempty,iso4217"`
 	Balance     int64  `json:"balance" validate:"required,gte=0"`
 	Status      string `json:"status"`
 	Password    string `json:"password" validate:"required,min=6"` // Add password field
@@ -36,6 +37,7 @@ type RegisterRequest struct {
 	FirstName      string `json:"first_name" validate:"required"`
 	LastName       string `json:"last_name" validate:"required"`
 	Password       string `json:"password" validate:"required,min=6"`
+	Currency       string `json:"currency" validate:"omitempty,iso4217"`
 	InitialBalance int64  `json:"initial_balance" validate:"gte=0"`
 }
 
@@ -63,11 +65,23 @@ type HttpBankAccountResponse struct {
 	FirstName   string         `json:"firstName" bson:"firstName,omitempty"`
 	LastName    string         `json:"lastName" bson:"lastName,omitempty"`
 	Balance     domain.Balance `json:"balance" bson:"balance"`
-	Status      string         `json:"status" bson:"status,omitempty"`
+	// AvailableBalance ledger balance less funds reserved by holds
+	AvailableBalance domain.Balance `json:"available_balance" bson:"available_balance"`
+	Holds            []domain.Hold  `json:"holds" bson:"holds"`
+	Status           string         `json:"status" bson:"status,omitempty"`
+	// WithdrawalPolicy limits in minor units of the balance currency
+	WithdrawalPolicy domain.WithdrawalPolicy `json:"withdrawal_policy" bson:"withdrawal_policy"`
+	// InterestRateBps annual rate, AccruedInterest in millionths of the minor unit is credited at the end of the month
+	InterestRateBps int64  `json:"interest_rate_bps" bson:"interest_rate_bps"`
+	AccruedInterest int64  `json:"accrued_interest" bson:"accrued_interest"`
+	Version         uint64 `json:"version" bson:"version"`
 }
 
+// RollbackRequest rolls the account back to the version reversing deposits and withdrawals recorded after it,
+// the reason is recorded with every reversal for audit.
 type RollbackRequest struct {
 	Version uint64 `json:"version" validate:"required,gte=1"`
+	Reason  string `json:"reason" validate:"required,max=500"`
 }
 
 type EventResponse struct {
@@ -79,6 +93,9 @@ type EventResponse struct {
 	Data          interface{} `json:"data"`
 	Metadata      interface{} `json:"metadata,omitempty"`
 	Timestamp     time.Time   `json:"timestamp"`
+	// Reverses event id of the transaction compensated by this reversal, ReversedBy id of the reversal event
+	Reverses   string `json:"reverses,omitempty"`
+	ReversedBy string `json:"reversed_by,omitempty"`
 }
 
 type EventsHistoryResponse struct {
@@ -86,3 +103,77 @@ type EventsHistoryResponse struct {
 	TotalEvents int             `json:"total_events"`
 	Events      []EventResponse `json:"events"`
 }
+
+// SelectiveReplayRequest selects accounts to rebuild in the target read model,
+// accounts having events within the position and time window are rebuilt entirely.
+type SelectiveReplayRequest struct {
+	Target        string     `json:"target" validate:"omitempty,oneof=elasticsearch mongo"`
+	AggregateIDs  []string   `json:"aggregate_ids"`
+	AggregateType string     `json:"aggregate_type"`
+	FromPosition  uint64     `json:"from_position"`
+	ToPosition    uint64     `json:"to_position"`
+	From          *time.Time `json:"from"`
+	To            *time.Time `json:"to"`
+}
+
+// ConsistencyCheckRequest selects accounts to verify, all accounts are checked when empty.
+type ConsistencyCheckRequest struct {
+	AggregateIDs []string `json:"aggregate_ids" validate:"omitempty,dive,uuid"`
+}
+
+// EventExportRequest selects the streams to export, the whole event store is exported when empty. Anonymized
+// accounts get the anonymized password, a random one when empty.
+type EventExportRequest struct {
+	AggregateType      string   `json:"aggregate_type"`
+	AggregateIDs       []string `json:"aggregate_ids" validate:"omitempty,dive,uuid"`
+	Anonymize          bool     `json:"anonymize"`
+	AnonymizedPassword string   `json:"anonymized_password" validate:"omitempty,min=6"`
+}
+
+// PayrollRunRequest batch of payroll deposits, the run id makes submitting the batch idempotent.
+type PayrollRunRequest struct {
+	ID    string               `json:"id" validate:"required,uuid"`
+	Lines []PayrollLineRequest `json:"lines"`
+}
+
+// PayrollLineRequest deposit of amount in minor units of currency, empty currency means the account currency.
+type PayrollLineRequest struct {
+	AccountID string `json:"account_id"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Reference string `json:"reference"`
+}
+
+// StandingOrderRequest scheduled deposit, withdrawal or transfer of amount in minor units. Empty currency means
+// the account currency, zero max attempts means the configured default.
+type StandingOrderRequest struct {
+	ID              string     `json:"id" validate:"required,uuid"`
+	Kind            string     `json:"kind" validate:"required,oneof=deposit withdrawal transfer"`
+	AccountID       string     `json:"account_id" validate:"required"`
+	TargetAccountID string     `json:"target_account_id"`
+	Amount          int64      `json:"amount" validate:"gt=0"`
+	Currency        string     `json:"currency"`
+	Reference       string     `json:"reference"`
+	Frequency       string     `json:"frequency" validate:"required,oneof=once daily weekly monthly cron"`
+	Cron            string     `json:"cron"`
+	StartAt         time.Time  `json:"start_at" validate:"required"`
+	EndAt           *time.Time `json:"end_at"`
+	MaxAttempts     int        `json:"max_attempts" validate:"gte=0,lte=10"`
+}
+
+// CancelStandingOrderRequest reason recorded with the cancellation.
+type CancelStandingOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
+// InterestAccrualRequest finished UTC day up to which interest is accrued, in YYYY-MM-DD format.
+type InterestAccrualRequest struct {
+	Through string `json:"through" validate:"required,datetime=2006-01-02"`
+}
+
+// CommandResultResponse version of the aggregate after the command, passed as min_version
+// to queries so they return the state including the command.
+type CommandResultResponse struct {
+	AggregateID string `json:"aggregateID"`
+	Version     uint64 `json:"version"`
+}
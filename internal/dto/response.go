@@ -30,12 +30,18 @@ const (
 	CodeForbidden           = "FORBIDDEN"
 	CodeNotFound            = "NOT_FOUND"
 	CodeConflict            = "CONFLICT"
+	CodeUnprocessableEntity = "UNPROCESSABLE_ENTITY"
+	CodeInsufficientFunds   = "INSUFFICIENT_FUNDS"
+	CodeTransactionLimit    = "TRANSACTION_LIMIT_EXCEEDED"
+	CodeDailyLimit          = "DAILY_LIMIT_EXCEEDED"
+	CodeMonthlyLimit        = "MONTHLY_LIMIT_EXCEEDED"
 	CodeInternalServerError = "INTERNAL_SERVER_ERROR"
 	CodeValidationError     = "VALIDATION_ERROR"
 	CodeDatabaseError       = "DATABASE_ERROR"
 	CodeAuthError           = "AUTH_ERROR"
 	CodeTokenExpired        = "TOKEN_EXPIRED"
 	CodeInvalidToken        = "INVALID_TOKEN"
+	CodeServiceUnavailable  = "SERVICE_UNAVAILABLE"
 )
 
 // NewSuccessResponse creates a new success response
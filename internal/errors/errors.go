@@ -8,6 +8,59 @@ var (
 	ErrNotEnoughBalance         = errors.New("balance has not enough balance")
 	ErrBankAccountNotFound      = errors.New("bank account not found")
 	ErrBankAccountAlreadyExists = errors.New("bank account with given id already exists")
+	ErrDocumentNotFound         = errors.New("document not found")
+	ErrInvalidCurrency          = errors.New("invalid currency")
+	ErrCurrencyMismatch         = errors.New("currency does not match account currency")
+
+	// Account lifecycle errors
+	ErrAccountFrozen    = errors.New("account is frozen")
+	ErrAccountNotFrozen = errors.New("account is not frozen")
+	ErrAccountClosed    = errors.New("account is closed")
+	// Withdrawal policy errors
+	ErrInvalidWithdrawalPolicy  = errors.New("invalid withdrawal policy")
+	ErrTransactionLimitExceeded = errors.New("withdrawal exceeds per transaction limit")
+	ErrDailyLimitExceeded       = errors.New("withdrawal exceeds daily limit")
+	ErrMonthlyLimitExceeded     = errors.New("withdrawal exceeds monthly limit")
+
+	// Interest errors
+	ErrInvalidInterestRate   = errors.New("invalid interest rate")
+	ErrInterestPeriodNotOver = errors.New("interest period is not over")
+
+	// Funds hold errors
+	ErrInvalidHold        = errors.New("invalid hold")
+	ErrHoldNotFound       = errors.New("hold not found")
+	ErrHoldExists         = errors.New("hold with given id already exists")
+	ErrHoldExpired        = errors.New("hold is expired")
+	ErrHoldAmountExceeded = errors.New("capture exceeds held amount")
+	ErrHoldsOutstanding   = errors.New("account has outstanding holds")
+
+	// Reversal errors
+	ErrInvalidReversal            = errors.New("invalid reversal")
+	ErrTransactionNotFound        = errors.New("transaction not found")
+	ErrTransactionNotReversible   = errors.New("transaction cannot be reversed")
+	ErrTransactionAlreadyReversed = errors.New("transaction is already reversed")
+
+	ErrPayoutTargetRequired = errors.New("payout target is required to close account with non-zero balance")
+
+	// Payroll run errors
+	ErrPayrollRunNotFound       = errors.New("payroll run not found")
+	ErrInvalidPayrollRun        = errors.New("invalid payroll run")
+	ErrPayrollRunConflict       = errors.New("payroll run with given id has different lines")
+	ErrPayrollLineNotPending    = errors.New("payroll line is not pending")
+	ErrPayrollRunNotCompletable = errors.New("payroll run has pending lines")
+	ErrPayrollRunLeaseLost      = errors.New("payroll run lease is held by other instance")
+
+	// Standing order errors
+	ErrStandingOrderNotFound  = errors.New("standing order not found")
+	ErrStandingOrderExists    = errors.New("standing order with given id already exists")
+	ErrInvalidStandingOrder   = errors.New("invalid standing order")
+	ErrInvalidSchedule        = errors.New("invalid schedule")
+	ErrStandingOrderNotActive = errors.New("standing order is not active")
+	ErrStandingOrderNotDue    = errors.New("standing order occurrence is not due")
+
+	// Consistency check errors
+	ErrConsistencyCheckNotFound = errors.New("consistency check not found")
+	ErrConsistencyCheckRunning  = errors.New("consistency check already running")
 
 	// Authentication errors
 	ErrInvalidCredentials = errors.New("invalid email or password")
@@ -16,4 +69,5 @@ var (
 	ErrInvalidToken       = errors.New("invalid or expired token")
 	ErrUnauthorized       = errors.New("unauthorized access")
 	ErrForbidden          = errors.New("forbidden access")
+	ErrInvalidProfile     = errors.New("invalid profile")
 )
@@ -0,0 +1,17 @@
+package events
+
+import "github.com/th1enq/es-demo/pkg/es"
+
+const (
+	AccountClosedEventTypeV1 es.EventType = "ACCOUNT_CLOSED_V1"
+)
+
+type AccountClosedEventV1 struct {
+	Reason string `json:"reason"`
+	// PayoutTarget where the remaining balance was paid out, empty when the balance was zero
+	PayoutTarget string `json:"payout_target,omitempty"`
+	// PayoutAmount remaining balance paid out on closing, in minor units of Currency
+	PayoutAmount int64  `json:"payout_amount"`
+	Currency     string `json:"currency"`
+	Metadata     []byte `json:"-"`
+}
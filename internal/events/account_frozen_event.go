@@ -0,0 +1,12 @@
+package events
+
+import "github.com/th1enq/es-demo/pkg/es"
+
+const (
+	AccountFrozenEventTypeV1 es.EventType = "ACCOUNT_FROZEN_V1"
+)
+
+type AccountFrozenEventV1 struct {
+	Reason   string `json:"reason"`
+	Metadata []byte `json:"-"`
+}
@@ -0,0 +1,12 @@
+package events
+
+import "github.com/th1enq/es-demo/pkg/es"
+
+const (
+	AccountUnfrozenEventTypeV1 es.EventType = "ACCOUNT_UNFROZEN_V1"
+)
+
+type AccountUnfrozenEventV1 struct {
+	Reason   string `json:"reason"`
+	Metadata []byte `json:"-"`
+}
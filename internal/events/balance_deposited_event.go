@@ -7,7 +7,11 @@ const (
 )
 
 type BalanceDepositedEventV1 struct {
-	Amount    int64  `json:"amount"`
+	// Amount in minor units of Currency
+	Amount int64 `json:"amount"`
+	// Currency ISO 4217 code, empty in events recorded before accounts had explicit currency
+	// which are in the account currency
+	Currency  string `json:"currency,omitempty"`
 	PaymentID string `json:"payment_id"`
 	Metadata  []byte `json:"-"`
 }
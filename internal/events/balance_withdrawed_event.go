@@ -1,13 +1,24 @@
 package events
 
-import "github.com/th1enq/es-demo/pkg/es"
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
 
 const (
 	BalanceWithdrawedEventTypeV1 es.EventType = "BALANCE_WITHDRAWED_V1"
 )
 
 type BalanceWithdrawedEventV1 struct {
-	Amount    int64  `json:"amount"`
+	// Amount in minor units of Currency
+	Amount int64 `json:"amount"`
+	// Currency ISO 4217 code, empty in events recorded before accounts had explicit currency
+	// which are in the account currency
+	Currency  string `json:"currency,omitempty"`
 	PaymentID string `json:"payment_id"`
-	Metadata  []byte `json:"-"`
+	// WithdrawnAt counts the withdrawal towards daily and monthly limits, zero in events
+	// recorded before withdrawal limits which count towards no period
+	WithdrawnAt time.Time `json:"withdrawn_at,omitempty"`
+	Metadata    []byte    `json:"-"`
 }
@@ -0,0 +1,24 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	DepositReversedEventTypeV1 es.EventType = "DEPOSIT_REVERSED_V1"
+)
+
+// DepositReversedEventV1 compensates the deposit recorded by the original event, debiting its amount.
+type DepositReversedEventV1 struct {
+	OriginalEventID string `json:"original_event_id"`
+	OriginalVersion uint64 `json:"original_version"`
+	// Amount in minor units of Currency
+	Amount     int64     `json:"amount"`
+	Currency   string    `json:"currency"`
+	PaymentID  string    `json:"payment_id"`
+	Reason     string    `json:"reason"`
+	ReversedAt time.Time `json:"reversed_at"`
+	Metadata   []byte    `json:"-"`
+}
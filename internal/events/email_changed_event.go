@@ -0,0 +1,19 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	EmailChangedEventTypeV1 es.EventType = "EMAIL_CHANGED_V1"
+)
+
+// EmailChangedEventV1 replaces the login email of the account, OldEmail is released for other accounts.
+type EmailChangedEventV1 struct {
+	OldEmail  string    `json:"old_email"`
+	Email     string    `json:"email"`
+	ChangedAt time.Time `json:"changed_at"`
+	Metadata  []byte    `json:"-"`
+}
@@ -0,0 +1,23 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	HoldCapturedEventTypeV1 es.EventType = "HOLD_CAPTURED_V1"
+)
+
+// HoldCapturedEventV1 settles the hold, Amount is debited from the ledger balance and the rest
+// of the held amount, Released, is available again.
+type HoldCapturedEventV1 struct {
+	HoldID     string    `json:"hold_id"`
+	Amount     int64     `json:"amount"`
+	Released   int64     `json:"released"`
+	Currency   string    `json:"currency"`
+	PaymentID  string    `json:"payment_id"`
+	CapturedAt time.Time `json:"captured_at"`
+	Metadata   []byte    `json:"-"`
+}
@@ -0,0 +1,23 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	HoldPlacedEventTypeV1 es.EventType = "HOLD_PLACED_V1"
+)
+
+// HoldPlacedEventV1 reserves amount in minor units of the account currency until the hold is captured,
+// released or expires. Held funds are not available for withdrawals but stay in the ledger balance.
+type HoldPlacedEventV1 struct {
+	HoldID    string    `json:"hold_id"`
+	Amount    int64     `json:"amount"`
+	Currency  string    `json:"currency"`
+	Reference string    `json:"reference"`
+	PlacedAt  time.Time `json:"placed_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Metadata  []byte    `json:"-"`
+}
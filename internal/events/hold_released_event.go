@@ -0,0 +1,22 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	HoldReleasedEventTypeV1 es.EventType = "HOLD_RELEASED_V1"
+)
+
+// HoldReleasedEventV1 cancels the hold without moving money, Expired is set when the hold was
+// released because it expired.
+type HoldReleasedEventV1 struct {
+	HoldID     string    `json:"hold_id"`
+	Amount     int64     `json:"amount"`
+	Reason     string    `json:"reason"`
+	Expired    bool      `json:"expired"`
+	ReleasedAt time.Time `json:"released_at"`
+	Metadata   []byte    `json:"-"`
+}
@@ -0,0 +1,18 @@
+package events
+
+import "github.com/th1enq/es-demo/pkg/es"
+
+const (
+	InterestAccruedEventTypeV1 es.EventType = "INTEREST_ACCRUED_V1"
+)
+
+// InterestAccruedEventV1 interest of one UTC day computed from the closing balance of the day and the rate
+// in effect at its end. Amount is in millionths of the minor unit and is credited by the monthly posting.
+type InterestAccruedEventV1 struct {
+	Day      string `json:"day"`
+	Balance  int64  `json:"balance"`
+	RateBps  int64  `json:"rate_bps"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Metadata []byte `json:"-"`
+}
@@ -0,0 +1,17 @@
+package events
+
+import "github.com/th1enq/es-demo/pkg/es"
+
+const (
+	InterestPostedEventTypeV1 es.EventType = "INTEREST_POSTED_V1"
+)
+
+// InterestPostedEventV1 credits interest accrued within the month, Accrued in millionths of the minor unit
+// is rounded to Amount in minor units of Currency.
+type InterestPostedEventV1 struct {
+	Month    string `json:"month"`
+	Accrued  int64  `json:"accrued"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Metadata []byte `json:"-"`
+}
@@ -0,0 +1,19 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	InterestRateChangedEventTypeV1 es.EventType = "INTEREST_RATE_CHANGED_V1"
+)
+
+// InterestRateChangedEventV1 sets the annual interest rate of the account in basis points, zero stops accrual.
+type InterestRateChangedEventV1 struct {
+	RateBps   int64     `json:"rate_bps"`
+	Reason    string    `json:"reason"`
+	ChangedAt time.Time `json:"changed_at"`
+	Metadata  []byte    `json:"-"`
+}
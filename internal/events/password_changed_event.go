@@ -0,0 +1,18 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	PasswordChangedEventTypeV1 es.EventType = "PASSWORD_CHANGED_V1"
+)
+
+// PasswordChangedEventV1 replaces the password hash, tokens issued before ChangedAt are no longer accepted.
+type PasswordChangedEventV1 struct {
+	PasswordHash string    `json:"password_hash"`
+	ChangedAt    time.Time `json:"changed_at"`
+	Metadata     []byte    `json:"-"`
+}
@@ -0,0 +1,14 @@
+package events
+
+import "github.com/th1enq/es-demo/pkg/es"
+
+const (
+	PayrollLineFailedEventTypeV1 es.EventType = "PAYROLL_LINE_FAILED_V1"
+)
+
+// PayrollLineFailedEventV1 line deposit was rejected by the account.
+type PayrollLineFailedEventV1 struct {
+	LineNo   int    `json:"line_no"`
+	Error    string `json:"error"`
+	Metadata []byte `json:"-"`
+}
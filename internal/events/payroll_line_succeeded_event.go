@@ -0,0 +1,14 @@
+package events
+
+import "github.com/th1enq/es-demo/pkg/es"
+
+const (
+	PayrollLineSucceededEventTypeV1 es.EventType = "PAYROLL_LINE_SUCCEEDED_V1"
+)
+
+// PayrollLineSucceededEventV1 line deposit was saved, AccountVersion is the account version after the deposit.
+type PayrollLineSucceededEventV1 struct {
+	LineNo         int    `json:"line_no"`
+	AccountVersion uint64 `json:"account_version"`
+	Metadata       []byte `json:"-"`
+}
@@ -0,0 +1,17 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	PayrollRunCompletedEventTypeV1 es.EventType = "PAYROLL_RUN_COMPLETED_V1"
+)
+
+// PayrollRunCompletedEventV1 every line of the run has succeeded or failed.
+type PayrollRunCompletedEventV1 struct {
+	CompletedAt time.Time `json:"completed_at"`
+	Metadata    []byte    `json:"-"`
+}
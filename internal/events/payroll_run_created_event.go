@@ -0,0 +1,30 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	PayrollRunCreatedEventTypeV1 es.EventType = "PAYROLL_RUN_CREATED_V1"
+)
+
+// PayrollLineV1 deposit of the payroll run, lines are numbered from 1 in the order of the batch.
+type PayrollLineV1 struct {
+	LineNo    int    `json:"line_no"`
+	AccountID string `json:"account_id"`
+	// Amount in minor units of Currency
+	Amount int64 `json:"amount"`
+	// Currency ISO 4217 code, empty means the account currency
+	Currency  string `json:"currency,omitempty"`
+	Reference string `json:"reference"`
+}
+
+// PayrollRunCreatedEventV1 accepts validated batch of payroll lines, source is the batch format.
+type PayrollRunCreatedEventV1 struct {
+	Source    string          `json:"source"`
+	Lines     []PayrollLineV1 `json:"lines"`
+	CreatedAt time.Time       `json:"created_at"`
+	Metadata  []byte          `json:"-"`
+}
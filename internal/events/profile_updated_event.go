@@ -0,0 +1,19 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	ProfileUpdatedEventTypeV1 es.EventType = "PROFILE_UPDATED_V1"
+)
+
+// ProfileUpdatedEventV1 replaces the names of the account holder.
+type ProfileUpdatedEventV1 struct {
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Metadata  []byte    `json:"-"`
+}
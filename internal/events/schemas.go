@@ -0,0 +1,23 @@
+package events
+
+import (
+	"embed"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+// schemaFiles JSON Schema definitions of the event payloads, one file per event type. Registered revisions are
+// immutable, a payload change is added as the next revision of the file and must be compatible with the previous.
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// NewSchemaRegistry registry of the event payload schemas published to consumers in the event catalog.
+func NewSchemaRegistry() (*es.SchemaRegistry, error) {
+	registry := es.NewSchemaRegistry()
+	if err := registry.RegisterFS(schemaFiles, "schemas"); err != nil {
+		return nil, errors.Wrap(err, "registry.RegisterFS")
+	}
+	return registry, nil
+}
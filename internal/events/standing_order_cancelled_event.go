@@ -0,0 +1,12 @@
+package events
+
+import "github.com/th1enq/es-demo/pkg/es"
+
+const (
+	StandingOrderCancelledEventTypeV1 es.EventType = "STANDING_ORDER_CANCELLED_V1"
+)
+
+type StandingOrderCancelledEventV1 struct {
+	Reason   string `json:"reason"`
+	Metadata []byte `json:"-"`
+}
@@ -0,0 +1,33 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	StandingOrderCreatedEventTypeV1 es.EventType = "STANDING_ORDER_CREATED_V1"
+)
+
+// StandingOrderCreatedEventV1 schedules deposits, withdrawals or transfers of the account,
+// NextRunAt is the first occurrence of the schedule.
+type StandingOrderCreatedEventV1 struct {
+	Kind      string `json:"kind"`
+	AccountID string `json:"account_id"`
+	// OwnerID user who created the order, empty on orders created before owners were recorded
+	OwnerID         string `json:"owner_id,omitempty"`
+	TargetAccountID string `json:"target_account_id,omitempty"`
+	// Amount in minor units of Currency
+	Amount      int64      `json:"amount"`
+	Currency    string     `json:"currency"`
+	Reference   string     `json:"reference"`
+	Frequency   string     `json:"frequency"`
+	Cron        string     `json:"cron,omitempty"`
+	StartAt     time.Time  `json:"start_at"`
+	EndAt       *time.Time `json:"end_at,omitempty"`
+	MaxAttempts int        `json:"max_attempts"`
+	NextRunAt   time.Time  `json:"next_run_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Metadata    []byte     `json:"-"`
+}
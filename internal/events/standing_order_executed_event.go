@@ -0,0 +1,21 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	StandingOrderExecutedEventTypeV1 es.EventType = "STANDING_ORDER_EXECUTED_V1"
+)
+
+// StandingOrderExecutedEventV1 occurrence scheduled at RunAt was executed, NextRunAt is empty when
+// the schedule has no more occurrences.
+type StandingOrderExecutedEventV1 struct {
+	RunAt      time.Time  `json:"run_at"`
+	ExecutedAt time.Time  `json:"executed_at"`
+	PaymentID  string     `json:"payment_id"`
+	NextRunAt  *time.Time `json:"next_run_at,omitempty"`
+	Metadata   []byte     `json:"-"`
+}
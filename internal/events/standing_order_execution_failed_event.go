@@ -0,0 +1,23 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	StandingOrderExecutionFailedEventTypeV1 es.EventType = "STANDING_ORDER_EXECUTION_FAILED_V1"
+)
+
+// StandingOrderExecutionFailedEventV1 attempt to execute occurrence scheduled at RunAt failed. The occurrence
+// is retried at RetryAt, without RetryAt it is skipped and the order continues with NextRunAt.
+type StandingOrderExecutionFailedEventV1 struct {
+	RunAt     time.Time  `json:"run_at"`
+	Attempt   int        `json:"attempt"`
+	Error     string     `json:"error"`
+	FailedAt  time.Time  `json:"failed_at"`
+	RetryAt   *time.Time `json:"retry_at,omitempty"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	Metadata  []byte     `json:"-"`
+}
@@ -0,0 +1,18 @@
+package events
+
+import "github.com/th1enq/es-demo/pkg/es"
+
+const (
+	WithdrawalPolicyChangedEventTypeV1 es.EventType = "WITHDRAWAL_POLICY_CHANGED_V1"
+)
+
+// WithdrawalPolicyChangedEventV1 replaces the withdrawal policy of the account, limits are in minor
+// units of the account currency and zero means no limit.
+type WithdrawalPolicyChangedEventV1 struct {
+	OverdraftLimit    int64  `json:"overdraft_limit"`
+	MaxPerTransaction int64  `json:"max_per_transaction"`
+	DailyLimit        int64  `json:"daily_limit"`
+	MonthlyLimit      int64  `json:"monthly_limit"`
+	Reason            string `json:"reason"`
+	Metadata          []byte `json:"-"`
+}
@@ -0,0 +1,24 @@
+package events
+
+import (
+	"time"
+
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+const (
+	WithdrawalReversedEventTypeV1 es.EventType = "WITHDRAWAL_REVERSED_V1"
+)
+
+// WithdrawalReversedEventV1 compensates the withdrawal recorded by the original event, crediting its amount.
+type WithdrawalReversedEventV1 struct {
+	OriginalEventID string `json:"original_event_id"`
+	OriginalVersion uint64 `json:"original_version"`
+	// Amount in minor units of Currency
+	Amount     int64     `json:"amount"`
+	Currency   string    `json:"currency"`
+	PaymentID  string    `json:"payment_id"`
+	Reason     string    `json:"reason"`
+	ReversedAt time.Time `json:"reversed_at"`
+	Metadata   []byte    `json:"-"`
+}
@@ -7,7 +7,7 @@ import (
 
 func BalanceFromMoney(money *money.Money) domain.Balance {
 	return domain.Balance{
-		Amount:   money.AsMajorUnits(),
+		Amount:   money.Amount(),
 		Currency: money.Currency().Code,
 	}
 }
@@ -13,21 +13,34 @@ func BankAccountToMongoProjection(bankAccount *domain.BankAccountAggregate) *dom
 		FirstName:   bankAccount.BankAccount.FirstName,
 		LastName:    bankAccount.BankAccount.LastName,
 		Balance: domain.Balance{
-			Amount:   bankAccount.BankAccount.Balance.AsMajorUnits(),
+			Amount:   bankAccount.BankAccount.Balance.Amount(),
 			Currency: bankAccount.BankAccount.Balance.Currency().Code,
 		},
-		PasswordHash: bankAccount.BankAccount.PasswordHash,
-		CreatedAt:    bankAccount.BankAccount.CreatedAt,
-		UpdatedAt:    bankAccount.BankAccount.UpdatedAt,
+		Status:               bankAccount.BankAccount.Status,
+		WithdrawalPolicy:     bankAccount.BankAccount.WithdrawalPolicy,
+		InterestRateBps:      bankAccount.BankAccount.Interest.RateBps,
+		AccruedInterest:      bankAccount.BankAccount.Interest.Accrued,
+		Holds:                bankAccount.BankAccount.Holds.List(),
+		PasswordHash:         bankAccount.BankAccount.PasswordHash,
+		CredentialsChangedAt: bankAccount.BankAccount.CredentialsChangedAt,
+		CreatedAt:            bankAccount.BankAccount.CreatedAt,
+		UpdatedAt:            bankAccount.BankAccount.UpdatedAt,
 	}
 }
 
 func BankAccountMongoProjectionToHttp(bankAccount *domain.BankAccountMongoProjection) *dto.HttpBankAccountResponse {
 	return &dto.HttpBankAccountResponse{
-		AggregateID: bankAccount.AggregateID,
-		Email:       bankAccount.Email,
-		FirstName:   bankAccount.FirstName,
-		LastName:    bankAccount.LastName,
-		Balance:     bankAccount.Balance,
+		AggregateID:      bankAccount.AggregateID,
+		Email:            bankAccount.Email,
+		FirstName:        bankAccount.FirstName,
+		LastName:         bankAccount.LastName,
+		Balance:          bankAccount.Balance,
+		AvailableBalance: bankAccount.AvailableBalance(),
+		Holds:            bankAccount.Holds,
+		Status:           string(bankAccount.Status),
+		WithdrawalPolicy: bankAccount.WithdrawalPolicy,
+		InterestRateBps:  bankAccount.InterestRateBps,
+		AccruedInterest:  bankAccount.AccruedInterest,
+		Version:          bankAccount.Version,
 	}
 }
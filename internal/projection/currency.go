@@ -0,0 +1,15 @@
+package projection
+
+import (
+	"github.com/pkg/errors"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+)
+
+// checkEventCurrency verifies that money of the event is in the account currency, events recorded
+// before accounts had explicit currency have no currency and are in the account currency.
+func checkEventCurrency(eventCurrency, accountCurrency string) error {
+	if eventCurrency != "" && eventCurrency != accountCurrency {
+		return errors.Wrapf(bankAccountErrors.ErrCurrencyMismatch, "event currency: %s, account currency: %s", eventCurrency, accountCurrency)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package projection
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+const (
+	BankAccountElasticsearchProjectionName = "bank_accounts_elasticsearch"
+)
+
+type bankAccountElasticsearchProjection struct {
+	name         string
+	indexName    string
+	serializer   es.Serializer
+	esRepository domain.ElasticsearchRepository
+	logger       *zap.Logger
+}
+
+// NewBankAccountElasticsearchProjection creates projection writing to the index, name identifies its checkpoints
+// so that every versioned index built during reindexing tracks its own progress.
+func NewBankAccountElasticsearchProjection(
+	name string,
+	indexName string,
+	serializer es.Serializer,
+	esRepository domain.ElasticsearchRepository,
+	logger *zap.Logger,
+) *bankAccountElasticsearchProjection {
+	return &bankAccountElasticsearchProjection{
+		name:         name,
+		indexName:    indexName,
+		serializer:   serializer,
+		esRepository: esRepository,
+		logger:       logger,
+	}
+}
+
+func (b *bankAccountElasticsearchProjection) Name() string {
+	return b.name
+}
+
+func (b *bankAccountElasticsearchProjection) Reset(ctx context.Context) error {
+	// index may be an alias, documents are deleted so the index behind it is kept
+	if err := b.esRepository.DeleteAllDocuments(b.indexName); err != nil {
+		return errors.Wrap(err, "esRepository.DeleteAllDocuments")
+	}
+	return nil
+}
+
+func (b *bankAccountElasticsearchProjection) ResetStream(ctx context.Context, aggregateID string) error {
+	if err := b.esRepository.DeleteDocument(b.indexName, aggregateID); err != nil {
+		return errors.Wrap(err, "esRepository.DeleteDocument")
+	}
+	return nil
+}
+
+func (b *bankAccountElasticsearchProjection) When(ctx context.Context, esEvent es.Event) error {
+	// events of every aggregate type are replayed from the event store
+	if esEvent.GetAggregateType() != domain.BankAccountAggregateType {
+		return nil
+	}
+
+	deserializedEvent, err := b.serializer.DeserializeEvent(esEvent)
+	if err != nil {
+		return errors.Wrapf(err, "serializer.DeserializeEvent aggregateID: %s, type: %s", esEvent.GetAggregateID(), esEvent.GetEventType())
+	}
+
+	switch event := deserializedEvent.(type) {
+	case *events.BankAccountCreatedEventV1:
+		return b.onBankAccountCreated(ctx, esEvent, event)
+	case *events.BalanceDepositedEventV1:
+		return b.onBankAccountBalanceDeposited(ctx, esEvent, event)
+	case *events.BalanceWithdrawedEventV1:
+		return b.onBankAccountBalanceWithdrawed(ctx, esEvent, event)
+	case *events.AccountFrozenEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			projection.WhenAccountFrozen(*event, esEvent.GetVersion(), esEvent.GetTimeStamp())
+			return nil
+		})
+	case *events.AccountUnfrozenEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			projection.WhenAccountUnfrozen(*event, esEvent.GetVersion(), esEvent.GetTimeStamp())
+			return nil
+		})
+	case *events.AccountClosedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			return projection.WhenAccountClosed(*event, esEvent.GetVersion(), esEvent.GetTimeStamp())
+		})
+	case *events.WithdrawalPolicyChangedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			projection.WhenWithdrawalPolicyChanged(*event, esEvent.GetVersion(), esEvent.GetTimeStamp())
+			return nil
+		})
+	case *events.InterestRateChangedEventV1, *events.InterestAccruedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			projection.WhenInterestChanged(esEvent.GetVersion(), esEvent.GetTimeStamp())
+			return nil
+		})
+	case *events.InterestPostedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			return projection.WhenInterestPosted(*event, esEvent.GetVersion(), esEvent.GetTimeStamp())
+		})
+	case *events.HoldPlacedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			projection.WhenHoldPlaced(*event, esEvent.GetVersion(), esEvent.GetTimeStamp())
+			return nil
+		})
+	case *events.HoldCapturedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			return projection.WhenHoldCaptured(*event, esEvent.GetVersion(), esEvent.GetTimeStamp())
+		})
+	case *events.HoldReleasedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			projection.WhenHoldReleased(*event, esEvent.GetVersion(), esEvent.GetTimeStamp())
+			return nil
+		})
+	case *events.DepositReversedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			return projection.WhenDepositReversed(*event, esEvent.GetVersion(), esEvent.GetTimeStamp())
+		})
+	case *events.WithdrawalReversedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			return projection.WhenWithdrawalReversed(*event, esEvent.GetVersion(), esEvent.GetTimeStamp())
+		})
+	case *events.EmailChangedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			projection.WhenEmailChanged(*event, esEvent.GetVersion(), esEvent.GetTimeStamp())
+			return nil
+		})
+	case *events.ProfileUpdatedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			projection.WhenProfileUpdated(*event, esEvent.GetVersion(), esEvent.GetTimeStamp())
+			return nil
+		})
+	case *events.PasswordChangedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountElasticsearchProjection) error {
+			projection.WhenPasswordChanged(esEvent.GetVersion(), esEvent.GetTimeStamp())
+			return nil
+		})
+	default:
+		// search index is not interested in every event type
+		b.logger.Warn("Skip unknown event type", zap.String("event_type", string(esEvent.GetEventType())), zap.String("aggregate_id", esEvent.GetAggregateID()))
+		return nil
+	}
+}
+
+func (b *bankAccountElasticsearchProjection) onBankAccountCreated(ctx context.Context, esEvent es.Event, event *events.BankAccountCreatedEventV1) error {
+	projection := domain.NewBankAccountElasticsearchProjection(esEvent.GetAggregateID())
+	projection.WhenBankAccountCreated(*event, esEvent.GetAggregateID(), esEvent.GetVersion(), esEvent.GetTimeStamp())
+
+	if err := b.esRepository.IndexDocument(b.indexName, esEvent.GetAggregateID(), projection); err != nil {
+		return errors.Wrapf(err, "[onBankAccountCreated] esRepository.IndexDocument aggregateID: %s", esEvent.GetAggregateID())
+	}
+	return nil
+}
+
+func (b *bankAccountElasticsearchProjection) onBankAccountBalanceDeposited(ctx context.Context, esEvent es.Event, event *events.BalanceDepositedEventV1) error {
+	projection, err := b.esRepository.GetDocument(b.indexName, esEvent.GetAggregateID())
+	if err != nil {
+		return errors.Wrapf(err, "[onBalanceDeposited] esRepository.GetDocument aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	if applied, err := b.checkVersion(projection, esEvent); err != nil || applied {
+		return err
+	}
+
+	if err := projection.WhenBalanceDeposited(*event, esEvent.GetVersion(), esEvent.GetTimeStamp()); err != nil {
+		return errors.Wrapf(err, "[onBalanceDeposited] aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	if err := b.esRepository.UpdateDocument(b.indexName, esEvent.GetAggregateID(), projection); err != nil {
+		return errors.Wrapf(err, "[onBalanceDeposited] esRepository.UpdateDocument aggregateID: %s", esEvent.GetAggregateID())
+	}
+	return nil
+}
+
+func (b *bankAccountElasticsearchProjection) onBankAccountBalanceWithdrawed(ctx context.Context, esEvent es.Event, event *events.BalanceWithdrawedEventV1) error {
+	projection, err := b.esRepository.GetDocument(b.indexName, esEvent.GetAggregateID())
+	if err != nil {
+		return errors.Wrapf(err, "[onBalanceWithdrawed] esRepository.GetDocument aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	if applied, err := b.checkVersion(projection, esEvent); err != nil || applied {
+		return err
+	}
+
+	if err := projection.WhenBalanceWithdrawn(*event, esEvent.GetVersion(), esEvent.GetTimeStamp()); err != nil {
+		return errors.Wrapf(err, "[onBalanceWithdrawed] aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	if err := b.esRepository.UpdateDocument(b.indexName, esEvent.GetAggregateID(), projection); err != nil {
+		return errors.Wrapf(err, "[onBalanceWithdrawed] esRepository.UpdateDocument aggregateID: %s", esEvent.GetAggregateID())
+	}
+	return nil
+}
+
+func (b *bankAccountElasticsearchProjection) onAccountChanged(ctx context.Context, esEvent es.Event, apply func(projection *domain.BankAccountElasticsearchProjection) error) error {
+	projection, err := b.esRepository.GetDocument(b.indexName, esEvent.GetAggregateID())
+	if err != nil {
+		return errors.Wrapf(err, "[onAccountChanged] esRepository.GetDocument aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	if applied, err := b.checkVersion(projection, esEvent); err != nil || applied {
+		return err
+	}
+
+	if err := apply(projection); err != nil {
+		return errors.Wrapf(err, "[onAccountChanged] aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	if err := b.esRepository.UpdateDocument(b.indexName, esEvent.GetAggregateID(), projection); err != nil {
+		return errors.Wrapf(err, "[onAccountChanged] esRepository.UpdateDocument aggregateID: %s", esEvent.GetAggregateID())
+	}
+	return nil
+}
+
+// checkVersion guards the document against duplicate and out of order events, returns true
+// if the event is already reflected in the document.
+func (b *bankAccountElasticsearchProjection) checkVersion(projection *domain.BankAccountElasticsearchProjection, esEvent es.Event) (bool, error) {
+	if projection.Version >= esEvent.GetVersion() {
+		b.logger.Debug("Skip already indexed event", zap.String("aggregate_id", esEvent.GetAggregateID()), zap.Uint64("version", esEvent.GetVersion()), zap.Uint64("document_version", projection.Version))
+		return true, nil
+	}
+	if projection.Version+1 != esEvent.GetVersion() {
+		return false, errors.Wrapf(es.ErrInvalidEventVersion, "aggregateID: %s, document version: %d, event version: %d", esEvent.GetAggregateID(), projection.Version, esEvent.GetVersion())
+	}
+	return false, nil
+}
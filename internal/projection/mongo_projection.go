@@ -4,7 +4,6 @@ import (
 	"context"
 	"time"
 
-	"github.com/Rhymond/go-money"
 	"github.com/pkg/errors"
 	"github.com/th1enq/es-demo/internal/domain"
 	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
@@ -13,25 +12,57 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	BankAccountMongoProjectionName = "bank_accounts_mongo"
+)
+
 type bankAccountMongoProjection struct {
+	name            string
 	serializer      es.Serializer
 	mongoRepository domain.MongoRepository
 	logger          *zap.Logger
 }
 
+// NewBankAccountMongoProjection creates projection writing to the repository collection, name identifies
+// its checkpoints so that a shadow collection rebuilt next to the live one tracks its own progress.
 func NewBankAccountMongoProjection(
+	name string,
 	serializer es.Serializer,
 	mongoRepository domain.MongoRepository,
 	logger *zap.Logger,
 ) *bankAccountMongoProjection {
 	return &bankAccountMongoProjection{
+		name:            name,
 		serializer:      serializer,
 		mongoRepository: mongoRepository,
 		logger:          logger,
 	}
 }
 
+func (b *bankAccountMongoProjection) Name() string {
+	return b.name
+}
+
+func (b *bankAccountMongoProjection) Reset(ctx context.Context) error {
+	if err := b.mongoRepository.DeleteAll(ctx); err != nil {
+		return errors.Wrap(err, "mongoRepository.DeleteAll")
+	}
+	return nil
+}
+
+func (b *bankAccountMongoProjection) ResetStream(ctx context.Context, aggregateID string) error {
+	if err := b.mongoRepository.DeleteByAggregateID(ctx, aggregateID); err != nil {
+		return errors.Wrap(err, "mongoRepository.DeleteByAggregateID")
+	}
+	return nil
+}
+
 func (b *bankAccountMongoProjection) When(ctx context.Context, esEvent es.Event) error {
+	// events of every aggregate type are replayed from the event store
+	if esEvent.GetAggregateType() != domain.BankAccountAggregateType {
+		return nil
+	}
+
 	deserializedEvent, err := b.serializer.DeserializeEvent(esEvent)
 
 	if err != nil {
@@ -45,6 +76,96 @@ func (b *bankAccountMongoProjection) When(ctx context.Context, esEvent es.Event)
 		return b.onBankAccountBalanceDeposited(ctx, esEvent, event)
 	case *events.BankAccountCreatedEventV1:
 		return b.onBankAccountCreated(ctx, esEvent, event)
+	case *events.AccountFrozenEventV1:
+		return b.onAccountStatusChanged(ctx, esEvent, domain.AccountStatusFrozen, 0)
+	case *events.AccountUnfrozenEventV1:
+		return b.onAccountStatusChanged(ctx, esEvent, domain.AccountStatusActive, 0)
+	case *events.AccountClosedEventV1:
+		return b.onAccountStatusChanged(ctx, esEvent, domain.AccountStatusClosed, event.PayoutAmount)
+	case *events.WithdrawalPolicyChangedEventV1:
+		return b.onWithdrawalPolicyChanged(ctx, esEvent, event)
+	case *events.InterestRateChangedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountMongoProjection) error {
+			projection.InterestRateBps = event.RateBps
+			return nil
+		})
+	case *events.InterestAccruedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountMongoProjection) error {
+			projection.AccruedInterest += event.Amount
+			return nil
+		})
+	case *events.InterestPostedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountMongoProjection) error {
+			if err := checkEventCurrency(event.Currency, projection.Balance.Currency); err != nil {
+				return err
+			}
+			projection.AccruedInterest -= event.Accrued
+			projection.Balance.Amount += event.Amount
+			return nil
+		})
+	case *events.HoldPlacedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountMongoProjection) error {
+			if err := checkEventCurrency(event.Currency, projection.Balance.Currency); err != nil {
+				return err
+			}
+			projection.RemoveHold(event.HoldID)
+			projection.Holds = append(projection.Holds, domain.Hold{
+				ID:        event.HoldID,
+				Amount:    event.Amount,
+				Reference: event.Reference,
+				PlacedAt:  event.PlacedAt,
+				ExpiresAt: event.ExpiresAt,
+			})
+			return nil
+		})
+	case *events.HoldCapturedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountMongoProjection) error {
+			if err := checkEventCurrency(event.Currency, projection.Balance.Currency); err != nil {
+				return err
+			}
+			projection.RemoveHold(event.HoldID)
+			projection.Balance.Amount -= event.Amount
+			return nil
+		})
+	case *events.HoldReleasedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountMongoProjection) error {
+			projection.RemoveHold(event.HoldID)
+			return nil
+		})
+	case *events.DepositReversedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountMongoProjection) error {
+			if err := checkEventCurrency(event.Currency, projection.Balance.Currency); err != nil {
+				return err
+			}
+			projection.Balance.Amount -= event.Amount
+			return nil
+		})
+	case *events.WithdrawalReversedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountMongoProjection) error {
+			if err := checkEventCurrency(event.Currency, projection.Balance.Currency); err != nil {
+				return err
+			}
+			projection.Balance.Amount += event.Amount
+			return nil
+		})
+	case *events.EmailChangedEventV1:
+		// the email index releases the old email with the update
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountMongoProjection) error {
+			projection.Email = event.Email
+			return nil
+		})
+	case *events.ProfileUpdatedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountMongoProjection) error {
+			projection.FirstName = event.FirstName
+			projection.LastName = event.LastName
+			return nil
+		})
+	case *events.PasswordChangedEventV1:
+		return b.onAccountChanged(ctx, esEvent, func(projection *domain.BankAccountMongoProjection) error {
+			projection.PasswordHash = event.PasswordHash
+			projection.CredentialsChangedAt = event.ChangedAt
+			return nil
+		})
 	default:
 		return errors.Wrapf(bankAccountErrors.ErrUnknownEventType, "esEvent: %s", esEvent.String())
 	}
@@ -64,17 +185,19 @@ func (b *bankAccountMongoProjection) onBankAccountCreated(ctx context.Context, e
 		FirstName:   event.FirstName,
 		LastName:    event.LastName,
 		Balance: domain.Balance{
-			Amount:   event.Balance.AsMajorUnits(),
+			Amount:   event.Balance.Amount(),
 			Currency: event.Balance.Currency().Code,
 		},
+		Status:       domain.AccountStatusActive,
 		PasswordHash: event.PasswordHash,
 		UpdatedAt:    time.Now().UTC(),
 		CreatedAt:    time.Now().UTC(),
 	}
 
-	err := b.mongoRepository.Insert(ctx, projection)
+	// upsert so that replaying a stream from the beginning resets the document
+	err := b.mongoRepository.Upsert(ctx, projection)
 	if err != nil {
-		return errors.Wrapf(err, "[onBankAccountCreated] mongoRepository.Insert aggregateID: %s", esEvent.GetAggregateID())
+		return errors.Wrapf(err, "[onBankAccountCreated] mongoRepository.Upsert aggregateID: %s", esEvent.GetAggregateID())
 	}
 	b.logger.Info("Bank Account Created projection", zap.Any("projection", projection))
 	return nil
@@ -82,17 +205,20 @@ func (b *bankAccountMongoProjection) onBankAccountCreated(ctx context.Context, e
 
 func (b *bankAccountMongoProjection) onBankAccountBalanceDeposited(ctx context.Context, esEvent es.Event, event *events.BalanceDepositedEventV1) error {
 	b.logger.Info("Bank Account Deposit", zap.String("aggregate ID", esEvent.EventID))
-	if err := b.mongoRepository.UpdateConcurrently(
-		ctx,
-		esEvent.GetAggregateID(),
-		func(projection *domain.BankAccountMongoProjection) *domain.BankAccountMongoProjection {
-			projection.Balance.Amount += float64(money.New(event.Amount, money.VND).Amount())
-			projection.Version = esEvent.Version
-			return projection
-		},
-		esEvent.GetVersion()-1,
-	); err != nil {
-		return errors.Wrapf(err, "[onBalanceDeposited] mongoRepository.UpdateConcurrently aggregateID: %s", esEvent.GetAggregateID())
+	projection, err := b.mongoRepository.GetByAggregateID(ctx, esEvent.GetAggregateID())
+	if err != nil {
+		return errors.Wrapf(err, "[onBalanceDeposited] mongoRepository.GetByAggregateID aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	if err := checkEventCurrency(event.Currency, projection.Balance.Currency); err != nil {
+		return errors.Wrapf(err, "[onBalanceDeposited] aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	projection.Balance.Amount += event.Amount
+	projection.Version = esEvent.Version
+
+	if err := b.mongoRepository.Update(ctx, projection); err != nil {
+		return errors.Wrapf(err, "[onBalanceDeposited] mongoRepository.Update aggregateID: %s", esEvent.GetAggregateID())
 	}
 	b.logger.Info("Balance Deposited", zap.Any("event type", esEvent.GetEventType()), zap.String("aggregate id", esEvent.GetAggregateID()), zap.Uint64("version", esEvent.GetVersion()))
 	return nil
@@ -100,18 +226,79 @@ func (b *bankAccountMongoProjection) onBankAccountBalanceDeposited(ctx context.C
 
 func (b *bankAccountMongoProjection) onBankAccountBalanceWithdrawed(ctx context.Context, esEvent es.Event, event *events.BalanceWithdrawedEventV1) error {
 	b.logger.Info("Bank Account Withdraw", zap.String("aggregate ID", esEvent.EventID))
-	if err := b.mongoRepository.UpdateConcurrently(
-		ctx,
-		esEvent.GetAggregateID(),
-		func(projection *domain.BankAccountMongoProjection) *domain.BankAccountMongoProjection {
-			projection.Balance.Amount -= float64(money.New(event.Amount, money.VND).Amount())
-			projection.Version = esEvent.Version
-			return projection
-		},
-		esEvent.GetVersion()-1,
-	); err != nil {
-		return errors.Wrapf(err, "[onBalanceWithdrawed] mongoRepository.UpdateConcurrently aggregateID: %s", esEvent.GetAggregateID())
+	projection, err := b.mongoRepository.GetByAggregateID(ctx, esEvent.GetAggregateID())
+	if err != nil {
+		return errors.Wrapf(err, "[onBalanceWithdrawed] mongoRepository.GetByAggregateID aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	if err := checkEventCurrency(event.Currency, projection.Balance.Currency); err != nil {
+		return errors.Wrapf(err, "[onBalanceWithdrawed] aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	projection.Balance.Amount -= event.Amount
+	projection.Version = esEvent.Version
+
+	if err := b.mongoRepository.Update(ctx, projection); err != nil {
+		return errors.Wrapf(err, "[onBalanceWithdrawed] mongoRepository.Update aggregateID: %s", esEvent.GetAggregateID())
 	}
 	b.logger.Info("Balance Withdrawed", zap.Any("event type", esEvent.GetEventType()), zap.String("aggregate id", esEvent.GetAggregateID()), zap.Uint64("version", esEvent.GetVersion()))
 	return nil
 }
+
+// onAccountStatusChanged applies lifecycle events, payout is the balance paid out on closing.
+func (b *bankAccountMongoProjection) onAccountStatusChanged(ctx context.Context, esEvent es.Event, status domain.AccountStatus, payout int64) error {
+	b.logger.Info("Bank Account Status", zap.String("aggregate ID", esEvent.EventID), zap.String("status", string(status)))
+	projection, err := b.mongoRepository.GetByAggregateID(ctx, esEvent.GetAggregateID())
+	if err != nil {
+		return errors.Wrapf(err, "[onAccountStatusChanged] mongoRepository.GetByAggregateID aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	projection.Status = status
+	projection.Balance.Amount -= payout
+	projection.Version = esEvent.Version
+
+	if err := b.mongoRepository.Update(ctx, projection); err != nil {
+		return errors.Wrapf(err, "[onAccountStatusChanged] mongoRepository.Update aggregateID: %s", esEvent.GetAggregateID())
+	}
+	return nil
+}
+
+func (b *bankAccountMongoProjection) onWithdrawalPolicyChanged(ctx context.Context, esEvent es.Event, event *events.WithdrawalPolicyChangedEventV1) error {
+	b.logger.Info("Bank Account Withdrawal Policy", zap.String("aggregate ID", esEvent.EventID))
+	projection, err := b.mongoRepository.GetByAggregateID(ctx, esEvent.GetAggregateID())
+	if err != nil {
+		return errors.Wrapf(err, "[onWithdrawalPolicyChanged] mongoRepository.GetByAggregateID aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	projection.WithdrawalPolicy = domain.WithdrawalPolicy{
+		OverdraftLimit:    event.OverdraftLimit,
+		MaxPerTransaction: event.MaxPerTransaction,
+		DailyLimit:        event.DailyLimit,
+		MonthlyLimit:      event.MonthlyLimit,
+	}
+	projection.Version = esEvent.Version
+
+	if err := b.mongoRepository.Update(ctx, projection); err != nil {
+		return errors.Wrapf(err, "[onWithdrawalPolicyChanged] mongoRepository.Update aggregateID: %s", esEvent.GetAggregateID())
+	}
+	return nil
+}
+
+// onAccountChanged applies the event to the document with apply.
+func (b *bankAccountMongoProjection) onAccountChanged(ctx context.Context, esEvent es.Event, apply func(projection *domain.BankAccountMongoProjection) error) error {
+	b.logger.Info("Bank Account Changed", zap.String("aggregate ID", esEvent.EventID), zap.String("event type", string(esEvent.GetEventType())))
+	projection, err := b.mongoRepository.GetByAggregateID(ctx, esEvent.GetAggregateID())
+	if err != nil {
+		return errors.Wrapf(err, "[onAccountChanged] mongoRepository.GetByAggregateID aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	if err := apply(projection); err != nil {
+		return errors.Wrapf(err, "[onAccountChanged] aggregateID: %s", esEvent.GetAggregateID())
+	}
+	projection.Version = esEvent.Version
+
+	if err := b.mongoRepository.Update(ctx, projection); err != nil {
+		return errors.Wrapf(err, "[onAccountChanged] mongoRepository.Update aggregateID: %s", esEvent.GetAggregateID())
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package projection
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+const (
+	TransactionMongoProjectionName = "transactions_mongo"
+)
+
+type transactionMongoProjection struct {
+	name                  string
+	serializer            es.Serializer
+	transactionRepository domain.TransactionRepository
+	logger                *zap.Logger
+}
+
+// NewTransactionMongoProjection creates projection writing a ledger entry per balance changing event.
+// Runner applies events of a stream in version order, so the running balance continues from the previous entry.
+func NewTransactionMongoProjection(
+	name string,
+	serializer es.Serializer,
+	transactionRepository domain.TransactionRepository,
+	logger *zap.Logger,
+) *transactionMongoProjection {
+	return &transactionMongoProjection{
+		name:                  name,
+		serializer:            serializer,
+		transactionRepository: transactionRepository,
+		logger:                logger,
+	}
+}
+
+func (t *transactionMongoProjection) Name() string {
+	return t.name
+}
+
+func (t *transactionMongoProjection) Reset(ctx context.Context) error {
+	if err := t.transactionRepository.DeleteAll(ctx); err != nil {
+		return errors.Wrap(err, "transactionRepository.DeleteAll")
+	}
+	return nil
+}
+
+func (t *transactionMongoProjection) ResetStream(ctx context.Context, aggregateID string) error {
+	if err := t.transactionRepository.DeleteByAggregateID(ctx, aggregateID); err != nil {
+		return errors.Wrap(err, "transactionRepository.DeleteByAggregateID")
+	}
+	return nil
+}
+
+func (t *transactionMongoProjection) When(ctx context.Context, esEvent es.Event) error {
+	// events of every aggregate type are replayed from the event store
+	if esEvent.GetAggregateType() != domain.BankAccountAggregateType {
+		return nil
+	}
+
+	deserializedEvent, err := t.serializer.DeserializeEvent(esEvent)
+	if err != nil {
+		return errors.Wrapf(err, "serializer.DeserializeEvent aggregateID: %s, type: %s", esEvent.GetAggregateID(), esEvent.GetEventType())
+	}
+
+	switch event := deserializedEvent.(type) {
+	case *events.BankAccountCreatedEventV1:
+		return t.onBankAccountCreated(ctx, esEvent, event)
+	case *events.BalanceDepositedEventV1:
+		return t.onBalanceChanged(ctx, esEvent, domain.TransactionTypeDeposit, event.Amount, event.Currency, event.PaymentID)
+	case *events.BalanceWithdrawedEventV1:
+		return t.onBalanceChanged(ctx, esEvent, domain.TransactionTypeWithdrawal, event.Amount, event.Currency, event.PaymentID)
+	case *events.AccountClosedEventV1:
+		return t.onAccountClosed(ctx, esEvent, event)
+	case *events.InterestPostedEventV1:
+		return t.onInterestPosted(ctx, esEvent, event)
+	case *events.HoldCapturedEventV1:
+		return t.onBalanceChanged(ctx, esEvent, domain.TransactionTypeHoldCapture, event.Amount, event.Currency, event.PaymentID)
+	case *events.DepositReversedEventV1:
+		return t.onReversal(ctx, esEvent, domain.TransactionTypeDepositReversal, event.Amount, event.Currency, event.PaymentID, event.OriginalEventID)
+	case *events.WithdrawalReversedEventV1:
+		return t.onReversal(ctx, esEvent, domain.TransactionTypeWithdrawalReversal, event.Amount, event.Currency, event.PaymentID, event.OriginalEventID)
+	case *events.AccountFrozenEventV1, *events.AccountUnfrozenEventV1, *events.WithdrawalPolicyChangedEventV1,
+		*events.InterestRateChangedEventV1, *events.InterestAccruedEventV1,
+		*events.HoldPlacedEventV1, *events.HoldReleasedEventV1,
+		*events.EmailChangedEventV1, *events.ProfileUpdatedEventV1, *events.PasswordChangedEventV1:
+		// status, policy, rate and profile changes, accruals and holds move no money
+		return nil
+	default:
+		return errors.Wrapf(bankAccountErrors.ErrUnknownEventType, "esEvent: %s", esEvent.String())
+	}
+}
+
+func (t *transactionMongoProjection) onBankAccountCreated(ctx context.Context, esEvent es.Event, event *events.BankAccountCreatedEventV1) error {
+	if esEvent.GetVersion() != 1 {
+		return errors.Wrapf(es.ErrInvalidEventVersion, "type: %s, version: %d", esEvent.GetEventType(), esEvent.GetVersion())
+	}
+
+	transaction := t.newTransaction(esEvent, domain.TransactionTypeOpening)
+	transaction.Direction = domain.TransactionDirectionCredit
+	transaction.Amount = event.Balance.Amount()
+	transaction.Currency = event.Balance.Currency().Code
+	transaction.BalanceAfter = event.Balance.Amount()
+
+	if err := t.transactionRepository.Upsert(ctx, transaction); err != nil {
+		return errors.Wrapf(err, "[onBankAccountCreated] transactionRepository.Upsert aggregateID: %s", esEvent.GetAggregateID())
+	}
+	return nil
+}
+
+func (t *transactionMongoProjection) onBalanceChanged(ctx context.Context, esEvent es.Event, transactionType domain.TransactionType, amount int64, currency string, paymentID string) error {
+	return t.recordTransaction(ctx, esEvent, transactionType, amount, currency, paymentID, "")
+}
+
+// onReversal records the reversal with the payment id of the reversed transaction, referencing its event.
+func (t *transactionMongoProjection) onReversal(ctx context.Context, esEvent es.Event, transactionType domain.TransactionType, amount int64, currency string, paymentID string, reversedEventID string) error {
+	return t.recordTransaction(ctx, esEvent, transactionType, amount, currency, paymentID, reversedEventID)
+}
+
+func (t *transactionMongoProjection) recordTransaction(ctx context.Context, esEvent es.Event, transactionType domain.TransactionType, amount int64, currency string, paymentID string, reversedEventID string) error {
+	previous, err := t.transactionRepository.GetLastBefore(ctx, esEvent.GetAggregateID(), esEvent.GetVersion())
+	if err != nil {
+		return errors.Wrapf(err, "[recordTransaction] transactionRepository.GetLastBefore aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	if err := checkEventCurrency(currency, previous.Currency); err != nil {
+		return errors.Wrapf(err, "[recordTransaction] aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	transaction := t.newTransaction(esEvent, transactionType)
+	transaction.Amount = amount
+	transaction.Currency = previous.Currency
+	transaction.PaymentID = paymentID
+	transaction.ReversedEventID = reversedEventID
+
+	switch transactionType {
+	case domain.TransactionTypeWithdrawal, domain.TransactionTypeClosingPayout, domain.TransactionTypeHoldCapture,
+		domain.TransactionTypeDepositReversal:
+		transaction.Direction = domain.TransactionDirectionDebit
+		transaction.BalanceAfter = previous.BalanceAfter - amount
+	default:
+		transaction.Direction = domain.TransactionDirectionCredit
+		transaction.BalanceAfter = previous.BalanceAfter + amount
+	}
+
+	if err := t.transactionRepository.Upsert(ctx, transaction); err != nil {
+		return errors.Wrapf(err, "[recordTransaction] transactionRepository.Upsert aggregateID: %s", esEvent.GetAggregateID())
+	}
+
+	t.logger.Debug("Transaction recorded",
+		zap.String("aggregate_id", esEvent.GetAggregateID()),
+		zap.String("type", string(transactionType)),
+		zap.Uint64("version", esEvent.GetVersion()))
+	return nil
+}
+
+// onAccountClosed records the payout of the remaining balance with the payout target as payment id,
+// closing an empty account moves no money.
+func (t *transactionMongoProjection) onAccountClosed(ctx context.Context, esEvent es.Event, event *events.AccountClosedEventV1) error {
+	if event.PayoutAmount == 0 {
+		return nil
+	}
+	return t.onBalanceChanged(ctx, esEvent, domain.TransactionTypeClosingPayout, event.PayoutAmount, event.Currency, event.PayoutTarget)
+}
+
+// onInterestPosted records posted interest with the interest payment id of the month, interest rounded
+// to zero moves no money.
+func (t *transactionMongoProjection) onInterestPosted(ctx context.Context, esEvent es.Event, event *events.InterestPostedEventV1) error {
+	if event.Amount == 0 {
+		return nil
+	}
+	return t.onBalanceChanged(ctx, esEvent, domain.TransactionTypeInterest, event.Amount, event.Currency, domain.InterestPaymentID(event.Month))
+}
+
+// newTransaction keys the ledger entry on the event store position, so live and replayed events
+// of the same change upsert the same entry.
+func (t *transactionMongoProjection) newTransaction(esEvent es.Event, transactionType domain.TransactionType) *domain.TransactionMongoProjection {
+	return &domain.TransactionMongoProjection{
+		EventID:     strconv.FormatUint(esEvent.GetPosition(), 10),
+		AggregateID: esEvent.GetAggregateID(),
+		Version:     esEvent.GetVersion(),
+		Type:        transactionType,
+		Timestamp:   esEvent.GetTimeStamp().UTC(),
+		Metadata:    eventMetadata(esEvent),
+	}
+}
+
+// eventMetadata returns string values of the json event metadata, such as the trace context.
+func eventMetadata(esEvent es.Event) map[string]string {
+	if len(esEvent.GetMetadata()) == 0 {
+		return nil
+	}
+
+	values := make(map[string]any)
+	if err := esEvent.GetJsonMetadata(&values); err != nil {
+		return nil
+	}
+
+	metadata := make(map[string]string, len(values))
+	for key, value := range values {
+		if s, ok := value.(string); ok {
+			metadata[key] = s
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,137 @@
+package projection
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Rhymond/go-money"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+// memoryEventStore serves stream events at their global positions, methods not used by the runner are left unimplemented.
+type memoryEventStore struct {
+	es.EventStore
+	events []es.Event
+}
+
+func (s *memoryEventStore) LoadEvents(ctx context.Context, aggregateID string) ([]es.Event, error) {
+	events := make([]es.Event, 0)
+	for _, event := range s.events {
+		if event.GetAggregateID() == aggregateID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// memoryCheckpointStore keeps stream versions and projection positions, copying checkpoints is not supported.
+type memoryCheckpointStore struct {
+	streams   map[string]uint64
+	positions map[string]uint64
+}
+
+func (s *memoryCheckpointStore) GetStreamCheckpoint(ctx context.Context, projection string, aggregateID string) (uint64, error) {
+	return s.streams[projection+"/"+aggregateID], nil
+}
+
+func (s *memoryCheckpointStore) SaveCheckpoint(ctx context.Context, projection string, event es.Event) error {
+	s.streams[projection+"/"+event.GetAggregateID()] = event.GetVersion()
+	s.positions[projection] = max(s.positions[projection], event.GetPosition())
+	return nil
+}
+
+func (s *memoryCheckpointStore) GetPosition(ctx context.Context, projection string) (uint64, error) {
+	return s.positions[projection], nil
+}
+
+func (s *memoryCheckpointStore) ResetCheckpoints(ctx context.Context, projection string) error {
+	s.streams = make(map[string]uint64)
+	delete(s.positions, projection)
+	return nil
+}
+
+func (s *memoryCheckpointStore) ResetStreamCheckpoint(ctx context.Context, projection string, aggregateID string) error {
+	delete(s.streams, projection+"/"+aggregateID)
+	return nil
+}
+
+func (s *memoryCheckpointStore) CopyCheckpoints(ctx context.Context, from, to string) error {
+	return errors.New("not implemented")
+}
+
+// memoryTransactionRepository keeps ledger entries by aggregate id in version order.
+type memoryTransactionRepository struct {
+	domain.TransactionRepository
+	entries map[string][]*domain.TransactionMongoProjection
+}
+
+func (r *memoryTransactionRepository) Upsert(ctx context.Context, transaction *domain.TransactionMongoProjection) error {
+	r.entries[transaction.AggregateID] = append(r.entries[transaction.AggregateID], transaction)
+	return nil
+}
+
+func (r *memoryTransactionRepository) GetLastBefore(ctx context.Context, aggregateID string, version uint64) (*domain.TransactionMongoProjection, error) {
+	var last *domain.TransactionMongoProjection
+	for _, entry := range r.entries[aggregateID] {
+		if entry.Version < version {
+			last = entry
+		}
+	}
+	if last == nil {
+		return nil, errors.Errorf("no entry of %s before version %d", aggregateID, version)
+	}
+	return last, nil
+}
+
+func (r *memoryTransactionRepository) DeleteByAggregateID(ctx context.Context, aggregateID string) error {
+	delete(r.entries, aggregateID)
+	return nil
+}
+
+func TestTransactionProjectionRebuildStreamKeepsPositions(t *testing.T) {
+	ctx := context.Background()
+	serializer := domain.NewEventSerializer()
+
+	aggregate := domain.NewBankAccountAggregate("account-1")
+	require.NoError(t, aggregate.Apply(&events.BankAccountCreatedEventV1{Email: "account@example.com", Balance: money.New(0, money.USD)}))
+	require.NoError(t, aggregate.DepositBalance(ctx, 100, "", "deposit-1"))
+	require.NoError(t, aggregate.WithdrawBalance(ctx, 30, "", "withdrawal-1"))
+
+	// streams interleave in the store, so positions differ from versions
+	positions := []uint64{40, 41, 45}
+	store := &memoryEventStore{}
+	for i, change := range aggregate.GetChanges() {
+		event, err := serializer.SerializeEvent(aggregate, change)
+		require.NoError(t, err)
+		event.SetVersion(uint64(i + 1))
+		event.Position = positions[i]
+		event.EventID = strconv.FormatUint(positions[i], 10)
+		store.events = append(store.events, event)
+	}
+
+	transactions := &memoryTransactionRepository{entries: make(map[string][]*domain.TransactionMongoProjection)}
+	checkpoints := &memoryCheckpointStore{streams: make(map[string]uint64), positions: make(map[string]uint64)}
+	projector := NewTransactionMongoProjection("transactions", serializer, transactions, zap.NewNop())
+	runner := es.NewProjectionRunner(es.ProjectionConfig{ReplayBatchSize: 10}, projector, store, checkpoints, zap.NewNop())
+
+	stats, err := runner.RebuildStreams(ctx, []string{"account-1"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Processed)
+
+	eventIDs := make([]string, 0)
+	for _, entry := range transactions.entries["account-1"] {
+		eventIDs = append(eventIDs, entry.EventID)
+	}
+	assert.Equal(t, []string{"40", "41", "45"}, eventIDs)
+
+	position, err := checkpoints.GetPosition(ctx, "transactions")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(45), position)
+}
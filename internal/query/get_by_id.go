@@ -2,6 +2,7 @@ package query
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/th1enq/es-demo/internal/domain"
@@ -15,6 +16,9 @@ import (
 type GetBankAccountByIDQuery struct {
 	AggregateID    string `json:"aggregate_id" validate:"required,gte=0"`
 	FromEventStore bool   `json:"from_event_store"`
+	// MinVersion version the result must include, usually returned by the preceding command.
+	// The projection is awaited up to the configured timeout, then the event store is read.
+	MinVersion uint64 `json:"min_version"`
 }
 
 type GetBankAccountByID interface {
@@ -22,17 +26,20 @@ type GetBankAccountByID interface {
 }
 
 type getBankAccountByIDQuery struct {
+	cfg             Config
 	aggregateStore  es.AggregateStore
 	mongoRepository domain.MongoRepository
 	logger          *zap.Logger
 }
 
 func NewGetBankAccountByIDQuery(
+	cfg Config,
 	bankAccountRepo domain.MongoRepository,
 	aggregateStore es.AggregateStore,
 	logger *zap.Logger,
 ) GetBankAccountByID {
 	return &getBankAccountByIDQuery{
+		cfg:             cfg,
 		mongoRepository: bankAccountRepo,
 		aggregateStore:  aggregateStore,
 		logger:          logger,
@@ -67,9 +74,42 @@ func (q *getBankAccountByIDQuery) Handle(ctx context.Context, query GetBankAccou
 		return nil, err
 	}
 
+	if projection.Version < query.MinVersion {
+		return q.waitForVersion(ctx, query)
+	}
+
 	return projection, nil
 }
 
+// waitForVersion polls the projection until it includes query.MinVersion, when it does not catch up
+// within the timeout the account is loaded from the event store which always has the latest version.
+func (q *getBankAccountByIDQuery) waitForVersion(ctx context.Context, query GetBankAccountByIDQuery) (*domain.BankAccountMongoProjection, error) {
+	timer := time.NewTimer(q.cfg.MinVersionTimeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(q.cfg.MinVersionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			q.logger.Debug("Projection behind min version, loading from event store",
+				zap.String("aggregate_id", query.AggregateID),
+				zap.Uint64("min_version", query.MinVersion))
+			return q.loadFromAggregateStore(ctx, query)
+		case <-ticker.C:
+			projection, err := q.mongoRepository.GetByAggregateID(ctx, query.AggregateID)
+			if err != nil {
+				return nil, err
+			}
+			if projection.Version >= query.MinVersion {
+				return projection, nil
+			}
+		}
+	}
+}
+
 func (q *getBankAccountByIDQuery) loadFromAggregateStore(ctx context.Context, query GetBankAccountByIDQuery) (*domain.BankAccountMongoProjection, error) {
 
 	bankAccountAggregate := domain.NewBankAccountAggregate(query.AggregateID)
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/th1enq/es-demo/internal/dto"
+	"github.com/th1enq/es-demo/internal/events"
 	"github.com/th1enq/es-demo/pkg/es"
 	"go.uber.org/zap"
 )
@@ -74,6 +75,8 @@ func (h *GetEventsHistoryQueryHandler) Handle(
 		})
 	}
 
+	linkReversals(eventResponses)
+
 	response := &dto.EventsHistoryResponse{
 		AggregateID: query.AggregateID,
 		TotalEvents: len(eventResponses),
@@ -87,3 +90,33 @@ func (h *GetEventsHistoryQueryHandler) Handle(
 
 	return response, nil
 }
+
+// linkReversals links reversal events and the transactions they reverse in both directions.
+func linkReversals(eventResponses []dto.EventResponse) {
+	byID := make(map[string]int, len(eventResponses))
+	for i, event := range eventResponses {
+		byID[event.EventID] = i
+	}
+
+	for i, event := range eventResponses {
+		switch es.EventType(event.EventType) {
+		case events.DepositReversedEventTypeV1, events.WithdrawalReversedEventTypeV1:
+		default:
+			continue
+		}
+
+		data, ok := event.Data.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		originalID, _ := data["original_event_id"].(string)
+		if originalID == "" {
+			continue
+		}
+
+		eventResponses[i].Reverses = originalID
+		if j, ok := byID[originalID]; ok {
+			eventResponses[j].ReversedBy = event.EventID
+		}
+	}
+}
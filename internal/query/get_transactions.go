@@ -0,0 +1,79 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTransactionsPageSize = 20
+)
+
+type GetTransactionsQuery struct {
+	AggregateID string     `json:"aggregate_id" validate:"required,gte=0"`
+	From        *time.Time `json:"from"`
+	To          *time.Time `json:"to"`
+	Page        int        `json:"page" validate:"gte=0"`
+	Size        int        `json:"size" validate:"gte=0,lte=100"`
+}
+
+// TransactionsPage page of account ledger entries, newest first.
+type TransactionsPage struct {
+	Transactions []*domain.TransactionMongoProjection `json:"transactions"`
+	Page         int                                  `json:"page"`
+	Size         int                                  `json:"size"`
+	Total        int64                                `json:"total"`
+}
+
+type GetTransactions interface {
+	Handle(ctx context.Context, query GetTransactionsQuery) (*TransactionsPage, error)
+}
+
+type getTransactionsQuery struct {
+	transactionRepository domain.TransactionRepository
+	logger                *zap.Logger
+}
+
+func NewGetTransactionsQuery(
+	transactionRepository domain.TransactionRepository,
+	logger *zap.Logger,
+) GetTransactions {
+	return &getTransactionsQuery{
+		transactionRepository: transactionRepository,
+		logger:                logger,
+	}
+}
+
+func (q *getTransactionsQuery) Handle(ctx context.Context, query GetTransactionsQuery) (*TransactionsPage, error) {
+	q.logger.Info("GetTransactions query", zap.Any("query", query))
+
+	filter := domain.TransactionFilter{
+		AggregateID: query.AggregateID,
+		From:        query.From,
+		To:          query.To,
+		Page:        query.Page,
+		Size:        query.Size,
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.Size <= 0 {
+		filter.Size = defaultTransactionsPageSize
+	}
+
+	transactions, total, err := q.transactionRepository.List(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "transactionRepository.List")
+	}
+
+	return &TransactionsPage{
+		Transactions: transactions,
+		Page:         filter.Page,
+		Size:         filter.Size,
+		Total:        total,
+	}, nil
+}
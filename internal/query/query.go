@@ -1,21 +1,34 @@
 package query
 
 import (
+	"time"
+
 	"github.com/th1enq/es-demo/pkg/es"
 	"go.uber.org/zap"
 )
 
+// Config read-your-writes settings of queries accepting min_version.
+type Config struct {
+	// MinVersionTimeout how long a query waits for the projection to reach min_version
+	// before the account is loaded from the event store
+	MinVersionTimeout time.Duration
+	// MinVersionPollInterval delay between projection reads while waiting
+	MinVersionPollInterval time.Duration
+}
+
 type BankAccountQuery struct {
 	GetBankAccountByID      GetBankAccountByID
 	GetBankAccountByEmail   GetBankAccountByEmail
 	GetBankAccountByVersion GetBankAccountByVersion
 	GetEventsHistory        *GetEventsHistoryQueryHandler
+	GetTransactions         GetTransactions
 }
 
 func NewBankAccountQuery(
 	getBankAccountByID GetBankAccountByID,
 	getBankAccountByEmail GetBankAccountByEmail,
 	getBankAccountByVersion GetBankAccountByVersion,
+	getTransactions GetTransactions,
 	aggregateStore es.AggregateStore,
 	log *zap.Logger,
 ) *BankAccountQuery {
@@ -24,5 +37,6 @@ func NewBankAccountQuery(
 		GetBankAccountByEmail:   getBankAccountByEmail,
 		GetBankAccountByVersion: getBankAccountByVersion,
 		GetEventsHistory:        NewGetEventsHistoryQueryHandler(aggregateStore, log),
+		GetTransactions:         getTransactions,
 	}
 }
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	consistencyCheckColumns = `id, status, repair, aggregate_ids, checked, drifted, repaired, drifts, error, created_at, updated_at, finished_at, owner, heartbeat_at`
+
+	createConsistencyCheckQuery = `INSERT INTO microservices.consistency_checks (` + consistencyCheckColumns + `)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	updateConsistencyCheckQuery = `UPDATE microservices.consistency_checks SET status = $2, checked = $3, drifted = $4, repaired = $5,
+	drifts = $6, error = $7, updated_at = $8, finished_at = $9, heartbeat_at = GREATEST(heartbeat_at, $10) WHERE id = $1`
+
+	getConsistencyCheckQuery = `SELECT ` + consistencyCheckColumns + ` FROM microservices.consistency_checks WHERE id = $1`
+
+	listConsistencyChecksQuery = `SELECT ` + consistencyCheckColumns + ` FROM microservices.consistency_checks ORDER BY created_at DESC LIMIT $1`
+
+	heartbeatConsistencyCheckQuery = `UPDATE microservices.consistency_checks SET heartbeat_at = $3 WHERE status = 'running' AND owner = $1 AND id = $2`
+
+	markConsistencyChecksInterruptedQuery = `UPDATE microservices.consistency_checks SET status = 'interrupted', updated_at = now()
+	WHERE status = 'running' AND (heartbeat_at IS NULL OR heartbeat_at < $1)`
+)
+
+type consistencyCheckRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewConsistencyCheckRepository creates postgres repository of consistency check runs
+func NewConsistencyCheckRepository(db *pgxpool.Pool, logger *zap.Logger) domain.ConsistencyCheckRepository {
+	return &consistencyCheckRepository{db: db, logger: logger}
+}
+
+// Create implements domain.ConsistencyCheckRepository.
+func (r *consistencyCheckRepository) Create(ctx context.Context, check *domain.ConsistencyCheck) error {
+	drifts, err := json.Marshal(check.Drifts)
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal")
+	}
+
+	_, err = r.db.Exec(
+		ctx,
+		createConsistencyCheckQuery,
+		check.ID,
+		check.Status,
+		check.Repair,
+		check.AggregateIDs,
+		check.Checked,
+		check.Drifted,
+		check.Repaired,
+		drifts,
+		check.Error,
+		check.CreatedAt,
+		check.UpdatedAt,
+		check.FinishedAt,
+		check.Owner,
+		check.HeartbeatAt,
+	)
+	if err != nil {
+		r.logger.Error("(Create Consistency Check) db.Exec error", zap.String("id", check.ID), zap.Error(err))
+		return errors.Wrap(err, "db.Exec")
+	}
+	return nil
+}
+
+// Update implements domain.ConsistencyCheckRepository.
+func (r *consistencyCheckRepository) Update(ctx context.Context, check *domain.ConsistencyCheck) error {
+	drifts, err := json.Marshal(check.Drifts)
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal")
+	}
+
+	result, err := r.db.Exec(
+		ctx,
+		updateConsistencyCheckQuery,
+		check.ID,
+		check.Status,
+		check.Checked,
+		check.Drifted,
+		check.Repaired,
+		drifts,
+		check.Error,
+		check.UpdatedAt,
+		check.FinishedAt,
+		check.HeartbeatAt,
+	)
+	if err != nil {
+		r.logger.Error("(Update Consistency Check) db.Exec error", zap.String("id", check.ID), zap.Error(err))
+		return errors.Wrap(err, "db.Exec")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.Wrapf(bankAccountErrors.ErrConsistencyCheckNotFound, "id: %s", check.ID)
+	}
+	return nil
+}
+
+// Get implements domain.ConsistencyCheckRepository.
+func (r *consistencyCheckRepository) Get(ctx context.Context, id string) (*domain.ConsistencyCheck, error) {
+	check, err := scanConsistencyCheck(r.db.QueryRow(ctx, getConsistencyCheckQuery, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(bankAccountErrors.ErrConsistencyCheckNotFound, "id: %s", id)
+		}
+		r.logger.Error("(Get Consistency Check) db.QueryRow error", zap.String("id", id), zap.Error(err))
+		return nil, errors.Wrap(err, "db.QueryRow")
+	}
+	return check, nil
+}
+
+// List implements domain.ConsistencyCheckRepository.
+func (r *consistencyCheckRepository) List(ctx context.Context, limit int) ([]*domain.ConsistencyCheck, error) {
+	rows, err := r.db.Query(ctx, listConsistencyChecksQuery, limit)
+	if err != nil {
+		r.logger.Error("(List Consistency Checks) db.Query error", zap.Error(err))
+		return nil, errors.Wrap(err, "db.Query")
+	}
+	defer rows.Close()
+
+	checks := make([]*domain.ConsistencyCheck, 0, limit)
+	for rows.Next() {
+		check, err := scanConsistencyCheck(rows)
+		if err != nil {
+			r.logger.Error("(List Consistency Checks) rows.Scan error", zap.Error(err))
+			return nil, errors.Wrap(err, "rows.Scan")
+		}
+		checks = append(checks, check)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+	return checks, nil
+}
+
+// Heartbeat implements domain.ConsistencyCheckRepository.
+func (r *consistencyCheckRepository) Heartbeat(ctx context.Context, owner string, id string, heartbeatAt time.Time) error {
+	if _, err := r.db.Exec(ctx, heartbeatConsistencyCheckQuery, owner, id, heartbeatAt); err != nil {
+		r.logger.Error("(Heartbeat Consistency Check) db.Exec error", zap.String("id", id), zap.Error(err))
+		return errors.Wrap(err, "db.Exec")
+	}
+	return nil
+}
+
+// MarkInterrupted implements domain.ConsistencyCheckRepository.
+func (r *consistencyCheckRepository) MarkInterrupted(ctx context.Context, staleBefore time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, markConsistencyChecksInterruptedQuery, staleBefore)
+	if err != nil {
+		r.logger.Error("(Mark Consistency Checks Interrupted) db.Exec error", zap.Error(err))
+		return 0, errors.Wrap(err, "db.Exec")
+	}
+	return result.RowsAffected(), nil
+}
+
+func scanConsistencyCheck(row pgx.Row) (*domain.ConsistencyCheck, error) {
+	var check domain.ConsistencyCheck
+	var drifts []byte
+	if err := row.Scan(
+		&check.ID,
+		&check.Status,
+		&check.Repair,
+		&check.AggregateIDs,
+		&check.Checked,
+		&check.Drifted,
+		&check.Repaired,
+		&drifts,
+		&check.Error,
+		&check.CreatedAt,
+		&check.UpdatedAt,
+		&check.FinishedAt,
+		&check.Owner,
+		&check.HeartbeatAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(drifts, &check.Drifts); err != nil {
+		return nil, errors.Wrap(err, "json.Unmarshal")
+	}
+	return &check, nil
+}
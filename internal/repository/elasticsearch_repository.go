@@ -5,12 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/elastic/go-elasticsearch/v8/esapi"
 	"github.com/pkg/errors"
 	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
 	"go.uber.org/zap"
 )
 
@@ -65,6 +67,19 @@ func (r *elasticsearchRepository) CreateIndex(indexName string) error {
 						},
 					},
 				},
+				"availableBalance": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"amount": map[string]interface{}{
+							"type": "long",
+						},
+						"currency": map[string]interface{}{
+							"type": "keyword",
+						},
+					},
+				},
+				"heldAmount": map[string]interface{}{
+					"type": "long",
+				},
 				"createdAt": map[string]interface{}{
 					"type": "date",
 				},
@@ -80,12 +95,18 @@ func (r *elasticsearchRepository) CreateIndex(indexName string) error {
 				"totalWithdrawals": map[string]interface{}{
 					"type": "long",
 				},
+				"totalInterest": map[string]interface{}{
+					"type": "long",
+				},
 				"transactionCount": map[string]interface{}{
 					"type": "integer",
 				},
 				"status": map[string]interface{}{
 					"type": "keyword",
 				},
+				"statusReason": map[string]interface{}{
+					"type": "text",
+				},
 				"lastActivity": map[string]interface{}{
 					"type": "date",
 				},
@@ -176,7 +197,7 @@ func (r *elasticsearchRepository) GetDocument(indexName, documentID string) (*do
 
 	if res.IsError() {
 		if res.StatusCode == 404 {
-			return nil, errors.New("document not found")
+			return nil, errors.Wrapf(bankAccountErrors.ErrDocumentNotFound, "index: %s, id: %s", indexName, documentID)
 		}
 		r.logger.Error("Elasticsearch get document error", zap.String("response", res.String()))
 		return nil, errors.New(fmt.Sprintf("elasticsearch get document error: %s", res.String()))
@@ -418,3 +439,225 @@ func (r *elasticsearchRepository) BulkIndex(indexName string, documents map[stri
 	r.logger.Info("Bulk index completed successfully", zap.String("index", indexName), zap.Int("documents", len(documents)))
 	return nil
 }
+
+// DeleteAllDocuments deletes all documents from index or alias keeping its mapping
+func (r *elasticsearchRepository) DeleteAllDocuments(indexName string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query": map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		},
+	})
+	if err != nil {
+		r.logger.Error("Failed to marshal delete by query", zap.Error(err))
+		return errors.Wrap(err, "json.Marshal")
+	}
+
+	refresh := true
+	req := esapi.DeleteByQueryRequest{
+		Index:     []string{indexName},
+		Body:      bytes.NewReader(body),
+		Conflicts: "proceed",
+		Refresh:   &refresh,
+	}
+
+	res, err := req.Do(context.Background(), r.client)
+	if err != nil {
+		r.logger.Error("Failed to delete documents", zap.Error(err), zap.String("index", indexName))
+		return errors.Wrap(err, "esapi.DeleteByQueryRequest.Do")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return nil // Nothing to delete if index doesn't exist
+		}
+		r.logger.Error("Elasticsearch delete by query error", zap.String("response", res.String()))
+		return errors.New(fmt.Sprintf("elasticsearch delete by query error: %s", res.String()))
+	}
+
+	r.logger.Info("Documents deleted successfully", zap.String("index", indexName))
+	return nil
+}
+
+// CountDocuments returns number of documents in index or alias
+func (r *elasticsearchRepository) CountDocuments(indexName string) (int64, error) {
+	req := esapi.CountRequest{
+		Index: []string{indexName},
+	}
+
+	res, err := req.Do(context.Background(), r.client)
+	if err != nil {
+		r.logger.Error("Failed to count documents", zap.Error(err), zap.String("index", indexName))
+		return 0, errors.Wrap(err, "esapi.CountRequest.Do")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		r.logger.Error("Elasticsearch count error", zap.String("response", res.String()))
+		return 0, errors.New(fmt.Sprintf("elasticsearch count error: %s", res.String()))
+	}
+
+	var result struct {
+		Count int64 `json:"count"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		r.logger.Error("Failed to decode count response", zap.Error(err))
+		return 0, errors.Wrap(err, "json.Decode")
+	}
+
+	return result.Count, nil
+}
+
+// IndexExists checks whether concrete index or alias exists
+func (r *elasticsearchRepository) IndexExists(indexName string) (bool, error) {
+	req := esapi.IndicesExistsRequest{
+		Index: []string{indexName},
+	}
+
+	res, err := req.Do(context.Background(), r.client)
+	if err != nil {
+		r.logger.Error("Failed to check index exists", zap.Error(err), zap.String("index", indexName))
+		return false, errors.Wrap(err, "esapi.IndicesExistsRequest.Do")
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 200:
+		return true, nil
+	case 404:
+		return false, nil
+	default:
+		r.logger.Error("Elasticsearch index exists error", zap.String("response", res.String()))
+		return false, errors.New(fmt.Sprintf("elasticsearch index exists error: %s", res.String()))
+	}
+}
+
+// ListIndices returns names of concrete indices matching wildcard pattern
+func (r *elasticsearchRepository) ListIndices(pattern string) ([]string, error) {
+	req := esapi.IndicesGetRequest{
+		Index: []string{pattern},
+	}
+
+	res, err := req.Do(context.Background(), r.client)
+	if err != nil {
+		r.logger.Error("Failed to list indices", zap.Error(err), zap.String("pattern", pattern))
+		return nil, errors.Wrap(err, "esapi.IndicesGetRequest.Do")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return []string{}, nil
+		}
+		r.logger.Error("Elasticsearch list indices error", zap.String("response", res.String()))
+		return nil, errors.New(fmt.Sprintf("elasticsearch list indices error: %s", res.String()))
+	}
+
+	var result map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		r.logger.Error("Failed to decode indices response", zap.Error(err))
+		return nil, errors.Wrap(err, "json.Decode")
+	}
+
+	indices := make([]string, 0, len(result))
+	for index := range result {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	return indices, nil
+}
+
+// GetAliasIndices returns concrete indices the alias points to, empty if alias doesn't exist
+func (r *elasticsearchRepository) GetAliasIndices(alias string) ([]string, error) {
+	req := esapi.IndicesGetAliasRequest{
+		Name: []string{alias},
+	}
+
+	res, err := req.Do(context.Background(), r.client)
+	if err != nil {
+		r.logger.Error("Failed to get alias", zap.Error(err), zap.String("alias", alias))
+		return nil, errors.Wrap(err, "esapi.IndicesGetAliasRequest.Do")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return []string{}, nil
+		}
+		r.logger.Error("Elasticsearch get alias error", zap.String("response", res.String()))
+		return nil, errors.New(fmt.Sprintf("elasticsearch get alias error: %s", res.String()))
+	}
+
+	var result map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		r.logger.Error("Failed to decode alias response", zap.Error(err))
+		return nil, errors.Wrap(err, "json.Decode")
+	}
+
+	indices := make([]string, 0, len(result))
+	for index := range result {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	return indices, nil
+}
+
+// SwapAlias atomically points alias to the index, removing it from all other indices.
+// A legacy concrete index with the alias name is removed in the same request.
+func (r *elasticsearchRepository) SwapAlias(alias, indexName string) error {
+	current, err := r.GetAliasIndices(alias)
+	if err != nil {
+		return errors.Wrap(err, "GetAliasIndices")
+	}
+
+	actions := make([]interface{}, 0, len(current)+2)
+	for _, index := range current {
+		if index == indexName {
+			continue
+		}
+		actions = append(actions, map[string]interface{}{
+			"remove": map[string]interface{}{"index": index, "alias": alias},
+		})
+	}
+
+	if len(current) == 0 {
+		exists, err := r.IndexExists(alias)
+		if err != nil {
+			return errors.Wrap(err, "IndexExists")
+		}
+		if exists {
+			actions = append(actions, map[string]interface{}{
+				"remove_index": map[string]interface{}{"index": alias},
+			})
+		}
+	}
+
+	actions = append(actions, map[string]interface{}{
+		"add": map[string]interface{}{"index": indexName, "alias": alias},
+	})
+
+	body, err := json.Marshal(map[string]interface{}{"actions": actions})
+	if err != nil {
+		r.logger.Error("Failed to marshal alias actions", zap.Error(err))
+		return errors.Wrap(err, "json.Marshal")
+	}
+
+	req := esapi.IndicesUpdateAliasesRequest{
+		Body: bytes.NewReader(body),
+	}
+
+	res, err := req.Do(context.Background(), r.client)
+	if err != nil {
+		r.logger.Error("Failed to update aliases", zap.Error(err), zap.String("alias", alias), zap.String("index", indexName))
+		return errors.Wrap(err, "esapi.IndicesUpdateAliasesRequest.Do")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		r.logger.Error("Elasticsearch update aliases error", zap.String("response", res.String()))
+		return errors.New(fmt.Sprintf("elasticsearch update aliases error: %s", res.String()))
+	}
+
+	r.logger.Info("Alias swapped successfully", zap.String("alias", alias), zap.String("index", indexName), zap.Strings("previous", current))
+	return nil
+}
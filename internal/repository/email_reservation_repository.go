@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	reserveEmailQuery = `INSERT INTO microservices.account_emails (email, aggregate_id, reserved_at) VALUES ($1, $2, now())
+	ON CONFLICT (email) DO UPDATE SET reserved_at = account_emails.reserved_at WHERE account_emails.aggregate_id = EXCLUDED.aggregate_id`
+
+	releaseEmailQuery = `DELETE FROM microservices.account_emails WHERE email = $1 AND aggregate_id = $2`
+)
+
+type emailReservationRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewEmailReservationRepository creates postgres repository of account email reservations
+func NewEmailReservationRepository(db *pgxpool.Pool, logger *zap.Logger) domain.EmailReservationRepository {
+	return &emailReservationRepository{db: db, logger: logger}
+}
+
+// Reserve implements domain.EmailReservationRepository.
+func (r *emailReservationRepository) Reserve(ctx context.Context, email string, aggregateID string) error {
+	result, err := r.db.Exec(ctx, reserveEmailQuery, email, aggregateID)
+	if err != nil {
+		r.logger.Error("(Reserve Email) db.Exec error", zap.String("aggregate_id", aggregateID), zap.Error(err))
+		return errors.Wrap(err, "db.Exec")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.Wrapf(bankAccountErrors.ErrEmailAlreadyExists, "email: %s", email)
+	}
+	return nil
+}
+
+// Release implements domain.EmailReservationRepository.
+func (r *emailReservationRepository) Release(ctx context.Context, email string, aggregateID string) error {
+	if _, err := r.db.Exec(ctx, releaseEmailQuery, email, aggregateID); err != nil {
+		r.logger.Error("(Release Email) db.Exec error", zap.String("aggregate_id", aggregateID), zap.Error(err))
+		return errors.Wrap(err, "db.Exec")
+	}
+	return nil
+}
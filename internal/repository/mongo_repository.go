@@ -7,7 +7,9 @@ import (
 	"github.com/pkg/errors"
 	"github.com/th1enq/es-demo/config"
 	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/internal/utils"
 	"github.com/th1enq/es-demo/pkg/constants"
+	serviceErrors "github.com/th1enq/es-demo/pkg/errors"
 	"github.com/th1enq/es-demo/pkg/es"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -16,9 +18,10 @@ import (
 )
 
 type bankAccountMongoRepository struct {
-	cfg    *config.Config
-	db     *mongo.Client
-	logger *zap.Logger
+	cfg        *config.Config
+	db         *mongo.Client
+	collection string
+	logger     *zap.Logger
 }
 
 func NewBankAccountMongoRepository(
@@ -27,12 +30,105 @@ func NewBankAccountMongoRepository(
 	logger *zap.Logger,
 ) domain.MongoRepository {
 	return &bankAccountMongoRepository{
-		cfg:    cfg,
-		db:     db,
-		logger: logger,
+		cfg:        cfg,
+		db:         db,
+		collection: domain.BankAccountsCollection,
+		logger:     logger,
 	}
 }
 
+// WithCollection implements domain.MongoRepository.
+func (b *bankAccountMongoRepository) WithCollection(collection string) domain.MongoRepository {
+	return &bankAccountMongoRepository{
+		cfg:        b.cfg,
+		db:         b.db,
+		collection: collection,
+		logger:     b.logger.With(zap.String("collection", collection)),
+	}
+}
+
+// EnsureCollection implements domain.MongoRepository.
+func (b *bankAccountMongoRepository) EnsureCollection(ctx context.Context) error {
+	err := b.db.Database(b.cfg.MongoDB.Db).CreateCollection(ctx, b.collection)
+	if err != nil && !utils.CheckErrForMessagesCaseInSensitive(err, serviceErrors.ErrMsgMongoCollectionAlreadyExists) {
+		b.logger.Error("MongoDB create collection failed", zap.String("collection", b.collection), zap.Error(err))
+		return errors.Wrapf(err, "EnsureCollection [CreateCollection] collection: %s", b.collection)
+	}
+
+	// aggregate id and email are unique, email index is used for authentication,
+	// hold expiry index is used by the expired holds release job
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: constants.MongoAggregateID, Value: 1}},
+			Options: options.Index().SetSparse(true).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetSparse(true).SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "holds.expires_at", Value: 1}},
+		},
+	}
+
+	names, err := b.bankAccountsCollection().Indexes().CreateMany(ctx, indexes)
+	if err != nil && !utils.CheckErrForMessagesCaseInSensitive(err, serviceErrors.ErrMsgAlreadyExists) {
+		b.logger.Error("MongoDB create indexes failed", zap.String("collection", b.collection), zap.Error(err))
+		return errors.Wrapf(err, "EnsureCollection [CreateMany] collection: %s", b.collection)
+	}
+
+	b.logger.Info("Ensured bank accounts collection", zap.String("collection", b.collection), zap.Strings("indexes", names))
+	return nil
+}
+
+// Exists implements domain.MongoRepository.
+func (b *bankAccountMongoRepository) Exists(ctx context.Context) (bool, error) {
+	names, err := b.db.Database(b.cfg.MongoDB.Db).ListCollectionNames(ctx, bson.M{"name": b.collection})
+	if err != nil {
+		b.logger.Error("MongoDB list collections failed", zap.String("collection", b.collection), zap.Error(err))
+		return false, errors.Wrapf(err, "Exists [ListCollectionNames] collection: %s", b.collection)
+	}
+	return len(names) > 0, nil
+}
+
+// Count implements domain.MongoRepository.
+func (b *bankAccountMongoRepository) Count(ctx context.Context) (int64, error) {
+	count, err := b.bankAccountsCollection().CountDocuments(ctx, bson.M{})
+	if err != nil {
+		b.logger.Error("MongoDB count failed", zap.String("collection", b.collection), zap.Error(err))
+		return 0, errors.Wrapf(err, "Count [CountDocuments] collection: %s", b.collection)
+	}
+	return count, nil
+}
+
+// Drop implements domain.MongoRepository.
+func (b *bankAccountMongoRepository) Drop(ctx context.Context) error {
+	if err := b.bankAccountsCollection().Drop(ctx); err != nil {
+		b.logger.Error("MongoDB drop collection failed", zap.String("collection", b.collection), zap.Error(err))
+		return errors.Wrapf(err, "Drop collection: %s", b.collection)
+	}
+	b.logger.Info("Dropped bank accounts collection", zap.String("collection", b.collection))
+	return nil
+}
+
+// RenameTo implements domain.MongoRepository.
+func (b *bankAccountMongoRepository) RenameTo(ctx context.Context, collection string) error {
+	db := b.cfg.MongoDB.Db
+	command := bson.D{
+		{Key: "renameCollection", Value: db + "." + b.collection},
+		{Key: "to", Value: db + "." + collection},
+		{Key: "dropTarget", Value: true},
+	}
+
+	if err := b.db.Database("admin").RunCommand(ctx, command).Err(); err != nil {
+		b.logger.Error("MongoDB rename collection failed", zap.String("collection", b.collection), zap.String("to", collection), zap.Error(err))
+		return errors.Wrapf(err, "RenameTo [renameCollection] collection: %s, to: %s", b.collection, collection)
+	}
+
+	b.logger.Info("Renamed bank accounts collection", zap.String("collection", b.collection), zap.String("to", collection))
+	return nil
+}
+
 // DeleteByAggregateID implements domain.MongoRepository.
 func (b *bankAccountMongoRepository) DeleteByAggregateID(ctx context.Context, aggregateID string) error {
 	b.logger.Info("Deleting bank account", zap.String("aggregateID", aggregateID))
@@ -48,6 +144,19 @@ func (b *bankAccountMongoRepository) DeleteByAggregateID(ctx context.Context, ag
 	return nil
 }
 
+// DeleteAll implements domain.MongoRepository.
+func (b *bankAccountMongoRepository) DeleteAll(ctx context.Context) error {
+	b.logger.Info("Deleting all bank accounts")
+
+	result, err := b.bankAccountsCollection().DeleteMany(ctx, bson.M{})
+	if err != nil {
+		b.logger.Error("MongoDB delete all failed", zap.Error(err))
+		return errors.Wrap(err, "DeleteAll [DeleteMany]")
+	}
+	b.logger.Info("Deleted all bank accounts", zap.Int64("count", result.DeletedCount))
+	return nil
+}
+
 // GetByAggregateID implements domain.MongoRepository.
 func (b *bankAccountMongoRepository) GetByAggregateID(ctx context.Context, aggregateID string) (*domain.BankAccountMongoProjection, error) {
 	b.logger.Info("Getting bank account", zap.String("aggregateID", aggregateID))
@@ -78,6 +187,36 @@ func (b *bankAccountMongoRepository) GetByEmail(ctx context.Context, email strin
 	return &projection, nil
 }
 
+// GetIDsWithExpiredHolds implements domain.MongoRepository.
+func (b *bankAccountMongoRepository) GetIDsWithExpiredHolds(ctx context.Context, at time.Time, cursor string, limit int) ([]string, error) {
+	filter := bson.M{"holds.expires_at": bson.M{"$lte": at}}
+	if cursor != "" {
+		filter[constants.MongoAggregateID] = bson.M{"$gt": cursor}
+	}
+	ops := options.Find().
+		SetProjection(bson.M{constants.MongoAggregateID: 1}).
+		SetSort(bson.D{{Key: constants.MongoAggregateID, Value: 1}}).
+		SetLimit(int64(limit))
+
+	found, err := b.bankAccountsCollection().Find(ctx, filter, ops)
+	if err != nil {
+		b.logger.Error("MongoDB find expired holds failed", zap.Error(err))
+		return nil, errors.Wrap(err, "GetIDsWithExpiredHolds [Find]")
+	}
+	defer found.Close(ctx)
+
+	var projections []domain.BankAccountMongoProjection
+	if err := found.All(ctx, &projections); err != nil {
+		return nil, errors.Wrap(err, "GetIDsWithExpiredHolds [All]")
+	}
+
+	ids := make([]string, 0, len(projections))
+	for _, projection := range projections {
+		ids = append(ids, projection.AggregateID)
+	}
+	return ids, nil
+}
+
 // Update implements domain.MongoRepository.
 func (b *bankAccountMongoRepository) Update(ctx context.Context, projection *domain.BankAccountMongoProjection) error {
 	b.logger.Info("Updating bank account", zap.String("aggregateID", projection.AggregateID))
@@ -187,5 +326,5 @@ func (b *bankAccountMongoRepository) Insert(ctx context.Context, projection *dom
 }
 
 func (b *bankAccountMongoRepository) bankAccountsCollection() *mongo.Collection {
-	return b.db.Database(b.cfg.MongoDB.Db).Collection("bank_accounts")
+	return b.db.Database(b.cfg.MongoDB.Db).Collection(b.collection)
 }
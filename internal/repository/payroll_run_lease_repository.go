@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	acquirePayrollRunLeaseQuery = `INSERT INTO microservices.payroll_run_leases (run_id, owner, expires_at) VALUES ($1, $2, $4)
+	ON CONFLICT (run_id) DO UPDATE SET owner = EXCLUDED.owner, expires_at = EXCLUDED.expires_at
+	WHERE payroll_run_leases.owner = EXCLUDED.owner OR payroll_run_leases.expires_at < $3`
+
+	renewPayrollRunLeaseQuery = `UPDATE microservices.payroll_run_leases SET expires_at = $3 WHERE run_id = $1 AND owner = $2`
+
+	releasePayrollRunLeaseQuery = `DELETE FROM microservices.payroll_run_leases WHERE run_id = $1 AND owner = $2`
+)
+
+type payrollRunLeaseRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewPayrollRunLeaseRepository creates postgres repository of payroll run leases
+func NewPayrollRunLeaseRepository(db *pgxpool.Pool, logger *zap.Logger) domain.PayrollRunLeaseRepository {
+	return &payrollRunLeaseRepository{db: db, logger: logger}
+}
+
+// Acquire implements domain.PayrollRunLeaseRepository.
+func (r *payrollRunLeaseRepository) Acquire(ctx context.Context, runID string, owner string, now time.Time, expiresAt time.Time) (bool, error) {
+	result, err := r.db.Exec(ctx, acquirePayrollRunLeaseQuery, runID, owner, now, expiresAt)
+	if err != nil {
+		r.logger.Error("(Acquire Payroll Run Lease) db.Exec error", zap.String("run_id", runID), zap.Error(err))
+		return false, errors.Wrap(err, "db.Exec")
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// Renew implements domain.PayrollRunLeaseRepository.
+func (r *payrollRunLeaseRepository) Renew(ctx context.Context, runID string, owner string, expiresAt time.Time) (bool, error) {
+	result, err := r.db.Exec(ctx, renewPayrollRunLeaseQuery, runID, owner, expiresAt)
+	if err != nil {
+		r.logger.Error("(Renew Payroll Run Lease) db.Exec error", zap.String("run_id", runID), zap.Error(err))
+		return false, errors.Wrap(err, "db.Exec")
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// Release implements domain.PayrollRunLeaseRepository.
+func (r *payrollRunLeaseRepository) Release(ctx context.Context, runID string, owner string) error {
+	if _, err := r.db.Exec(ctx, releasePayrollRunLeaseQuery, runID, owner); err != nil {
+		r.logger.Error("(Release Payroll Run Lease) db.Exec error", zap.String("run_id", runID), zap.Error(err))
+		return errors.Wrap(err, "db.Exec")
+	}
+	return nil
+}
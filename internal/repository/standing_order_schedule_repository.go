@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	saveStandingOrderScheduleQuery = `INSERT INTO microservices.standing_order_schedule (aggregate_id, due_at, updated_at)
+	VALUES ($1, $2, now()) ON CONFLICT (aggregate_id) DO UPDATE SET due_at = EXCLUDED.due_at, updated_at = now()`
+
+	ensureStandingOrderScheduleQuery = `INSERT INTO microservices.standing_order_schedule (aggregate_id, due_at, updated_at)
+	VALUES ($1, $2, now()) ON CONFLICT (aggregate_id) DO NOTHING`
+
+	deleteStandingOrderScheduleQuery = `DELETE FROM microservices.standing_order_schedule WHERE aggregate_id = $1`
+
+	claimDueStandingOrdersQuery = `SELECT aggregate_id FROM microservices.standing_order_schedule WHERE due_at <= $1
+	ORDER BY due_at LIMIT $2 FOR UPDATE SKIP LOCKED`
+)
+
+type standingOrderScheduleRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewStandingOrderScheduleRepository creates postgres repository of standing order due times
+func NewStandingOrderScheduleRepository(db *pgxpool.Pool, logger *zap.Logger) domain.StandingOrderScheduleRepository {
+	return &standingOrderScheduleRepository{db: db, logger: logger}
+}
+
+// Save implements domain.StandingOrderScheduleRepository.
+func (r *standingOrderScheduleRepository) Save(ctx context.Context, id string, dueAt *time.Time) error {
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return saveSchedule(ctx, tx, id, dueAt)
+	})
+	if err != nil {
+		r.logger.Error("(Save Standing Order Schedule) tx.Exec error", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Ensure implements domain.StandingOrderScheduleRepository.
+func (r *standingOrderScheduleRepository) Ensure(ctx context.Context, id string, dueAt time.Time) error {
+	if _, err := r.db.Exec(ctx, ensureStandingOrderScheduleQuery, id, dueAt); err != nil {
+		r.logger.Error("(Ensure Standing Order Schedule) db.Exec error", zap.String("id", id), zap.Error(err))
+		return errors.Wrap(err, "db.Exec")
+	}
+	return nil
+}
+
+// ClaimDue implements domain.StandingOrderScheduleRepository. Orders failing fn keep their due time
+// and are claimed again by the next poll.
+func (r *standingOrderScheduleRepository) ClaimDue(ctx context.Context, now time.Time, limit int, fn domain.ClaimFunc) (count int, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "db.Begin")
+	}
+	defer func() {
+		if txErr := tx.Rollback(ctx); txErr != nil && !errors.Is(txErr, pgx.ErrTxClosed) && err == nil {
+			err = errors.Wrap(txErr, "tx.Rollback")
+		}
+	}()
+
+	rows, err := tx.Query(ctx, claimDueStandingOrdersQuery, now, limit)
+	if err != nil {
+		r.logger.Error("(Claim Due Standing Orders) tx.Query error", zap.Error(err))
+		return 0, errors.Wrap(err, "tx.Query")
+	}
+
+	ids := make([]string, 0, limit)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, errors.Wrap(err, "rows.Scan")
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "rows.Err")
+	}
+
+	for _, id := range ids {
+		dueAt, err := fn(ctx, id)
+		if err != nil {
+			r.logger.Error("Failed to execute claimed standing order", zap.String("id", id), zap.Error(err))
+			continue
+		}
+		if err := saveSchedule(ctx, tx, id, dueAt); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return count, errors.Wrap(err, "tx.Commit")
+	}
+	return count, nil
+}
+
+func saveSchedule(ctx context.Context, tx pgx.Tx, id string, dueAt *time.Time) error {
+	var err error
+	if dueAt == nil {
+		_, err = tx.Exec(ctx, deleteStandingOrderScheduleQuery, id)
+	} else {
+		_, err = tx.Exec(ctx, saveStandingOrderScheduleQuery, id, *dueAt)
+	}
+	if err != nil {
+		return errors.Wrap(err, "tx.Exec")
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/config"
+	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/internal/utils"
+	"github.com/th1enq/es-demo/pkg/constants"
+	serviceErrors "github.com/th1enq/es-demo/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+type transactionMongoRepository struct {
+	cfg    *config.Config
+	db     *mongo.Client
+	logger *zap.Logger
+}
+
+func NewTransactionMongoRepository(
+	cfg *config.Config,
+	db *mongo.Client,
+	logger *zap.Logger,
+) domain.TransactionRepository {
+	return &transactionMongoRepository{
+		cfg:    cfg,
+		db:     db,
+		logger: logger,
+	}
+}
+
+// EnsureCollection implements domain.TransactionRepository.
+func (t *transactionMongoRepository) EnsureCollection(ctx context.Context) error {
+	err := t.db.Database(t.cfg.MongoDB.Db).CreateCollection(ctx, domain.TransactionsCollection)
+	if err != nil && !utils.CheckErrForMessagesCaseInSensitive(err, serviceErrors.ErrMsgMongoCollectionAlreadyExists) {
+		t.logger.Error("MongoDB create collection failed", zap.String("collection", domain.TransactionsCollection), zap.Error(err))
+		return errors.Wrapf(err, "EnsureCollection [CreateCollection] collection: %s", domain.TransactionsCollection)
+	}
+
+	// one entry per event, pages and date ranges are read per account
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: constants.MongoAggregateID, Value: 1}, {Key: "version", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: constants.MongoAggregateID, Value: 1}, {Key: "timestamp", Value: -1}},
+		},
+	}
+
+	names, err := t.transactionsCollection().Indexes().CreateMany(ctx, indexes)
+	if err != nil && !utils.CheckErrForMessagesCaseInSensitive(err, serviceErrors.ErrMsgAlreadyExists) {
+		t.logger.Error("MongoDB create indexes failed", zap.String("collection", domain.TransactionsCollection), zap.Error(err))
+		return errors.Wrapf(err, "EnsureCollection [CreateMany] collection: %s", domain.TransactionsCollection)
+	}
+
+	t.logger.Info("Ensured transactions collection", zap.Strings("indexes", names))
+	return nil
+}
+
+// Upsert implements domain.TransactionRepository.
+func (t *transactionMongoRepository) Upsert(ctx context.Context, transaction *domain.TransactionMongoProjection) error {
+	transaction.ID = ""
+	filter := bson.M{constants.MongoAggregateID: transaction.AggregateID, "version": transaction.Version}
+
+	_, err := t.transactionsCollection().ReplaceOne(ctx, filter, transaction, options.Replace().SetUpsert(true))
+	if err != nil {
+		t.logger.Error("MongoDB transaction upsert failed", zap.String("aggregateID", transaction.AggregateID), zap.Uint64("version", transaction.Version), zap.Error(err))
+		return errors.Wrapf(err, "Upsert [ReplaceOne] aggregateID: %s, version: %d", transaction.AggregateID, transaction.Version)
+	}
+	return nil
+}
+
+// GetLastBefore implements domain.TransactionRepository.
+func (t *transactionMongoRepository) GetLastBefore(ctx context.Context, aggregateID string, version uint64) (*domain.TransactionMongoProjection, error) {
+	filter := bson.M{constants.MongoAggregateID: aggregateID, "version": bson.M{"$lt": version}}
+	ops := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+
+	var transaction domain.TransactionMongoProjection
+	if err := t.transactionsCollection().FindOne(ctx, filter, ops).Decode(&transaction); err != nil {
+		return nil, errors.Wrapf(err, "GetLastBefore [FindOne] aggregateID: %s, version: %d", aggregateID, version)
+	}
+	return &transaction, nil
+}
+
+// List implements domain.TransactionRepository.
+func (t *transactionMongoRepository) List(ctx context.Context, filter domain.TransactionFilter) ([]*domain.TransactionMongoProjection, int64, error) {
+	query := bson.M{constants.MongoAggregateID: filter.AggregateID}
+	if filter.From != nil || filter.To != nil {
+		timestamp := bson.M{}
+		if filter.From != nil {
+			timestamp["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			timestamp["$lt"] = *filter.To
+		}
+		query["timestamp"] = timestamp
+	}
+
+	total, err := t.transactionsCollection().CountDocuments(ctx, query)
+	if err != nil {
+		t.logger.Error("MongoDB transactions count failed", zap.String("aggregateID", filter.AggregateID), zap.Error(err))
+		return nil, 0, errors.Wrapf(err, "List [CountDocuments] aggregateID: %s", filter.AggregateID)
+	}
+
+	ops := options.Find().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetSkip(int64((filter.Page - 1) * filter.Size)).
+		SetLimit(int64(filter.Size))
+
+	cursor, err := t.transactionsCollection().Find(ctx, query, ops)
+	if err != nil {
+		t.logger.Error("MongoDB transactions find failed", zap.String("aggregateID", filter.AggregateID), zap.Error(err))
+		return nil, 0, errors.Wrapf(err, "List [Find] aggregateID: %s", filter.AggregateID)
+	}
+
+	transactions := make([]*domain.TransactionMongoProjection, 0, filter.Size)
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, 0, errors.Wrapf(err, "List [cursor.All] aggregateID: %s", filter.AggregateID)
+	}
+	return transactions, total, nil
+}
+
+// DeleteByAggregateID implements domain.TransactionRepository.
+func (t *transactionMongoRepository) DeleteByAggregateID(ctx context.Context, aggregateID string) error {
+	result, err := t.transactionsCollection().DeleteMany(ctx, bson.M{constants.MongoAggregateID: aggregateID})
+	if err != nil {
+		t.logger.Error("MongoDB transactions delete failed", zap.String("aggregateID", aggregateID), zap.Error(err))
+		return errors.Wrapf(err, "DeleteByAggregateID [DeleteMany] aggregateID: %s", aggregateID)
+	}
+	t.logger.Info("Deleted account transactions", zap.String("aggregateID", aggregateID), zap.Int64("count", result.DeletedCount))
+	return nil
+}
+
+// DeleteAll implements domain.TransactionRepository.
+func (t *transactionMongoRepository) DeleteAll(ctx context.Context) error {
+	result, err := t.transactionsCollection().DeleteMany(ctx, bson.M{})
+	if err != nil {
+		t.logger.Error("MongoDB transactions delete all failed", zap.Error(err))
+		return errors.Wrap(err, "DeleteAll [DeleteMany]")
+	}
+	t.logger.Info("Deleted all transactions", zap.Int64("count", result.DeletedCount))
+	return nil
+}
+
+func (t *transactionMongoRepository) transactionsCollection() *mongo.Collection {
+	return t.db.Database(t.cfg.MongoDB.Db).Collection(domain.TransactionsCollection)
+}
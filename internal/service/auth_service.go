@@ -9,6 +9,7 @@ import (
 	"github.com/th1enq/es-demo/internal/domain"
 	"github.com/th1enq/es-demo/internal/dto"
 	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/pkg/es"
 	"go.uber.org/zap"
 )
 
@@ -17,13 +18,48 @@ type AuthService interface {
 	Register(ctx context.Context, req dto.RegisterRequest) (*dto.RegisterResponse, error)
 	ValidateToken(tokenString string) (*jwt.Token, error)
 	RefreshToken(ctx context.Context, refreshToken string) (*dto.LoginResponse, error)
+	// CheckSession verifies the account of the valid token can still use it, tokens of closed accounts
+	// and tokens issued before the password was changed are rejected. The account is loaded from the
+	// event store, so revocation applies as soon as the change is saved.
+	CheckSession(ctx context.Context, claims *CustomClaims) error
+}
+
+// Roles carried by the access tokens.
+const (
+	// RoleAdmin is the role of operator accounts, allowed to manage any account and the event store.
+	RoleAdmin = "admin"
+	// RoleCustomer is the role of every other account, allowed to act on its own account only.
+	RoleCustomer = "customer"
+)
+
+// Caller authenticated user of a request, users are the accounts they signed in with.
+type Caller struct {
+	UserID string
+	Role   string
+}
+
+// IsAdmin reports whether the caller may act on any account.
+func (c Caller) IsAdmin() bool {
+	return c.Role == RoleAdmin
+}
+
+// CanAccess reports whether the caller owns the account or is an admin.
+func (c Caller) CanAccess(accountID string) bool {
+	return c.IsAdmin() || (c.UserID != "" && c.UserID == accountID)
 }
 
 type authService struct {
-	queryService QueryService
-	commandBus   CommandBus
-	jwtSecret    []byte
-	logger       *zap.Logger
+	queryService   QueryService
+	commandBus     CommandBus
+	aggregateStore es.AggregateStore
+	jwtSecret      []byte
+	adminAccounts  map[string]bool
+	logger         *zap.Logger
+}
+
+func init() {
+	// issue times in milliseconds, a token issued within the second of a credentials change is told apart
+	jwt.TimePrecision = time.Millisecond
 }
 
 type CustomClaims struct {
@@ -36,14 +72,23 @@ type CustomClaims struct {
 func NewAuthService(
 	queryService QueryService,
 	commandBus CommandBus,
+	aggregateStore es.AggregateStore,
 	jwtSecret string,
+	adminAccountIDs []string,
 	logger *zap.Logger,
 ) AuthService {
+	adminAccounts := make(map[string]bool, len(adminAccountIDs))
+	for _, id := range adminAccountIDs {
+		adminAccounts[id] = true
+	}
+
 	return &authService{
-		queryService: queryService,
-		commandBus:   commandBus,
-		jwtSecret:    []byte(jwtSecret),
-		logger:       logger,
+		queryService:   queryService,
+		commandBus:     commandBus,
+		aggregateStore: aggregateStore,
+		jwtSecret:      []byte(jwtSecret),
+		adminAccounts:  adminAccounts,
+		logger:         logger,
 	}
 }
 
@@ -63,6 +108,12 @@ func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (*dto.Log
 		return nil, bankAccountErrors.ErrInvalidCredentials
 	}
 
+	// Closed accounts cannot log in
+	if bankAccount.IsClosed() {
+		s.logger.Warn("Login attempt to closed account", zap.String("email", req.Email))
+		return nil, bankAccountErrors.ErrAccountInactive
+	}
+
 	// Generate tokens
 	accessToken, err := s.generateAccessToken(bankAccount)
 	if err != nil {
@@ -108,8 +159,9 @@ func (s *authService) Register(ctx context.Context, req dto.RegisterRequest) (*d
 		Email:       req.Email,
 		FirstName:   req.FirstName,
 		LastName:    req.LastName,
+		Currency:    req.Currency,
 		Balance:     req.InitialBalance,
-		Status:      "active",
+		Status:      string(domain.AccountStatusActive),
 		Password:    req.Password,
 	}
 
@@ -150,10 +202,13 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*d
 	}
 
 	// Get current user data
-	bankAccount, err := s.queryService.GetBankAccountByID(ctx, claims.UserID)
+	bankAccount, err := s.loadSessionAccount(ctx, claims)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkSession(bankAccount, claims); err != nil {
+		return nil, err
+	}
 
 	// Generate new tokens
 	accessToken, err := s.generateAccessToken(bankAccount)
@@ -180,10 +235,61 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*d
 	}, nil
 }
 
+func (s *authService) CheckSession(ctx context.Context, claims *CustomClaims) error {
+	bankAccount, err := s.loadSessionAccount(ctx, claims)
+	if err != nil {
+		return err
+	}
+	return checkSession(bankAccount, claims)
+}
+
+// loadSessionAccount loads the account of the token from the event store, the read model may lag behind
+// a password change or closing the account.
+func (s *authService) loadSessionAccount(ctx context.Context, claims *CustomClaims) (*domain.BankAccount, error) {
+	aggregate := domain.NewBankAccountAggregate(claims.UserID)
+	if aggregate == nil {
+		return nil, bankAccountErrors.ErrInvalidToken
+	}
+	if err := s.aggregateStore.Load(ctx, aggregate); err != nil {
+		if errors.Is(err, bankAccountErrors.ErrUnknownEventType) {
+			return nil, bankAccountErrors.ErrInvalidToken
+		}
+		return nil, errors.Wrapf(err, "aggregateStore.Load aggregateID: %s", claims.UserID)
+	}
+	if aggregate.GetVersion() == 0 {
+		return nil, bankAccountErrors.ErrInvalidToken
+	}
+	return aggregate.BankAccount, nil
+}
+
+func checkSession(bankAccount *domain.BankAccount, claims *CustomClaims) error {
+	if bankAccount.IsClosed() {
+		return bankAccountErrors.ErrAccountInactive
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	if bankAccount.IsTokenRevoked(issuedAt) {
+		return bankAccountErrors.ErrInvalidToken
+	}
+	return nil
+}
+
+// role returns the role of the account, admins are the accounts configured by id.
+func (s *authService) role(accountID string) string {
+	if s.adminAccounts[accountID] {
+		return RoleAdmin
+	}
+	return RoleCustomer
+}
+
 func (s *authService) generateAccessToken(bankAccount *domain.BankAccount) (string, error) {
 	claims := CustomClaims{
 		UserID: bankAccount.AggregateID,
 		Email:  bankAccount.Email,
+		Role:   s.role(bankAccount.AggregateID),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -199,6 +305,7 @@ func (s *authService) generateRefreshToken(bankAccount *domain.BankAccount) (str
 	claims := CustomClaims{
 		UserID: bankAccount.AggregateID,
 		Email:  bankAccount.Email,
+		Role:   s.role(bankAccount.AggregateID),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7)), // 7 days
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -0,0 +1,457 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+const (
+	consistencyChecksListLimit = 20
+	consistencyCheckBatchSize  = 100
+
+	// maxConsistencyDrifts bounds the drifts persisted with a check, counters keep the totals
+	maxConsistencyDrifts = 1000
+
+	// consistencyCheckHeartbeatInterval how often the owner of the running check refreshes its heartbeat
+	consistencyCheckHeartbeatInterval = 10 * time.Second
+	// consistencyCheckHeartbeatTimeout after which a running check is considered abandoned by its owner
+	consistencyCheckHeartbeatTimeout = 6 * consistencyCheckHeartbeatInterval
+)
+
+var (
+	errConsistencyCheckInterrupted = errors.New("consistency check interrupted by shutdown")
+)
+
+// ConsistencyService verifies read models against the event store: every account is replayed from
+// its event stream and compared with the MongoDB projection and the Elasticsearch document.
+// Drifted accounts are optionally repaired by rebuilding their stream in the drifted read model.
+// A check is owned by the instance running it, other instances interrupt it only after its heartbeat expired.
+type ConsistencyService struct {
+	eventStore  es.EventStore
+	serializer  es.Serializer
+	mongoRepo   domain.MongoRepository
+	esRepo      domain.ElasticsearchRepository
+	mongoRunner *es.ProjectionRunner
+	esRunner    *es.ProjectionRunner
+	checks      domain.ConsistencyCheckRepository
+	instanceID  string
+	logger      *zap.Logger
+
+	mu        sync.Mutex
+	running   context.CancelCauseFunc
+	runningID string
+	wg        sync.WaitGroup
+}
+
+// NewConsistencyService creates a new consistency service
+func NewConsistencyService(
+	eventStore es.EventStore,
+	serializer es.Serializer,
+	mongoRepo domain.MongoRepository,
+	esRepo domain.ElasticsearchRepository,
+	mongoRunner *es.ProjectionRunner,
+	esRunner *es.ProjectionRunner,
+	checks domain.ConsistencyCheckRepository,
+	instanceID string,
+	logger *zap.Logger,
+) *ConsistencyService {
+	return &ConsistencyService{
+		eventStore:  eventStore,
+		serializer:  serializer,
+		mongoRepo:   mongoRepo,
+		esRepo:      esRepo,
+		mongoRunner: mongoRunner,
+		esRunner:    esRunner,
+		checks:      checks,
+		instanceID:  instanceID,
+		logger:      logger,
+	}
+}
+
+// Run heartbeats the check of this instance, marks checks abandoned by stopped instances as interrupted
+// and on shutdown interrupts the running check, waiting until its result is persisted.
+func (s *ConsistencyService) Run(ctx context.Context) error {
+	if err := s.interruptAbandoned(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(consistencyCheckHeartbeatInterval)
+	defer ticker.Stop()
+
+	for done := false; !done; {
+		select {
+		case <-ctx.Done():
+			done = true
+		case <-ticker.C:
+			if err := s.heartbeat(ctx); err != nil {
+				s.logger.Error("Failed to heartbeat consistency check", zap.Error(err))
+			}
+			if err := s.interruptAbandoned(ctx); err != nil {
+				s.logger.Error("Failed to interrupt abandoned consistency checks", zap.Error(err))
+			}
+		}
+	}
+
+	s.mu.Lock()
+	if s.running != nil {
+		s.running(errConsistencyCheckInterrupted)
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// heartbeat refreshes the heartbeat of the check running in this instance.
+func (s *ConsistencyService) heartbeat(ctx context.Context) error {
+	s.mu.Lock()
+	id := s.runningID
+	s.mu.Unlock()
+
+	if id == "" {
+		return nil
+	}
+	return s.checks.Heartbeat(ctx, s.instanceID, id, time.Now().UTC())
+}
+
+// interruptAbandoned marks running checks without heartbeat within the timeout as interrupted,
+// checks of live instances keep running.
+func (s *ConsistencyService) interruptAbandoned(ctx context.Context) error {
+	count, err := s.checks.MarkInterrupted(ctx, time.Now().UTC().Add(-consistencyCheckHeartbeatTimeout))
+	if err != nil {
+		return errors.Wrap(err, "checks.MarkInterrupted")
+	}
+	if count > 0 {
+		s.logger.Warn("Consistency checks abandoned by stopped instances interrupted", zap.Int64("count", count))
+	}
+	return nil
+}
+
+// StartCheck creates consistency check of the accounts, or of all accounts when none are given,
+// and runs it in background. With repair drifted accounts are rebuilt in the drifted read model.
+func (s *ConsistencyService) StartCheck(ctx context.Context, aggregateIDs []string, repair bool) (*domain.ConsistencyCheck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running != nil {
+		return nil, bankAccountErrors.ErrConsistencyCheckRunning
+	}
+
+	now := time.Now().UTC()
+	check := &domain.ConsistencyCheck{
+		ID:           uuid.NewV4().String(),
+		Status:       domain.ConsistencyCheckStatusRunning,
+		Repair:       repair,
+		AggregateIDs: aggregateIDs,
+		Drifts:       []*domain.AccountDrift{},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		Owner:        s.instanceID,
+		HeartbeatAt:  &now,
+	}
+
+	if err := s.checks.Create(ctx, check); err != nil {
+		return nil, errors.Wrap(err, "checks.Create")
+	}
+
+	// check outlives the request, it is stopped by application shutdown
+	checkCtx, cancel := context.WithCancelCause(context.Background())
+	s.running = cancel
+	s.runningID = check.ID
+	s.wg.Add(1)
+
+	go s.execute(checkCtx, check)
+
+	s.logger.Info("Consistency check started", zap.String("check_id", check.ID), zap.Int("accounts", len(aggregateIDs)), zap.Bool("repair", repair))
+	return check, nil
+}
+
+// GetCheck returns check with persisted progress and drifts.
+func (s *ConsistencyService) GetCheck(ctx context.Context, id string) (*domain.ConsistencyCheck, error) {
+	return s.checks.Get(ctx, id)
+}
+
+// ListChecks returns latest checks.
+func (s *ConsistencyService) ListChecks(ctx context.Context) ([]*domain.ConsistencyCheck, error) {
+	return s.checks.List(ctx, consistencyChecksListLimit)
+}
+
+func (s *ConsistencyService) execute(ctx context.Context, check *domain.ConsistencyCheck) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		s.running = nil
+		s.runningID = ""
+		s.mu.Unlock()
+	}()
+
+	err := s.checkAccounts(ctx, check)
+
+	now := time.Now().UTC()
+	check.UpdatedAt = now
+	switch {
+	case err == nil:
+		check.Status = domain.ConsistencyCheckStatusCompleted
+		check.FinishedAt = &now
+	case errors.Is(context.Cause(ctx), errConsistencyCheckInterrupted):
+		check.Status = domain.ConsistencyCheckStatusInterrupted
+	default:
+		check.Status = domain.ConsistencyCheckStatusFailed
+		check.Error = err.Error()
+	}
+
+	if err := s.checks.Update(context.WithoutCancel(ctx), check); err != nil {
+		s.logger.Error("Failed to persist consistency check", zap.String("check_id", check.ID), zap.Error(err))
+	}
+
+	s.logger.Info("Consistency check finished",
+		zap.String("check_id", check.ID),
+		zap.String("status", string(check.Status)),
+		zap.Int("checked", check.Checked),
+		zap.Int("drifted", check.Drifted),
+		zap.Int("repaired", check.Repaired))
+}
+
+// checkAccounts compares accounts in batches ordered by aggregate id, progress is persisted after every batch.
+func (s *ConsistencyService) checkAccounts(ctx context.Context, check *domain.ConsistencyCheck) error {
+	filter := es.StreamFilter{
+		AggregateIDs:  check.AggregateIDs,
+		AggregateType: domain.BankAccountAggregateType,
+	}
+
+	cursor := ""
+	for {
+		aggregateIDs, err := s.eventStore.LoadAggregateIDs(ctx, filter, cursor, consistencyCheckBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "eventStore.LoadAggregateIDs")
+		}
+
+		for _, aggregateID := range aggregateIDs {
+			if err := s.checkAccount(ctx, check, aggregateID); err != nil {
+				return err
+			}
+		}
+
+		check.UpdatedAt = time.Now().UTC()
+		if err := s.checks.Update(ctx, check); err != nil {
+			return errors.Wrap(err, "checks.Update")
+		}
+
+		if len(aggregateIDs) < consistencyCheckBatchSize {
+			return nil
+		}
+		cursor = aggregateIDs[len(aggregateIDs)-1]
+	}
+}
+
+func (s *ConsistencyService) checkAccount(ctx context.Context, check *domain.ConsistencyCheck, aggregateID string) error {
+	expected, err := s.replayAccount(ctx, aggregateID)
+	if err != nil {
+		return err
+	}
+
+	mongoDrift, err := s.compareMongo(ctx, expected)
+	if err != nil {
+		return err
+	}
+	esDrift, err := s.compareElasticsearch(ctx, expected)
+	if err != nil {
+		return err
+	}
+
+	check.Checked++
+	for _, drift := range []*domain.AccountDrift{mongoDrift, esDrift} {
+		if drift == nil {
+			continue
+		}
+
+		check.Drifted++
+		s.logger.Warn("Read model drift detected",
+			zap.String("check_id", check.ID),
+			zap.String("aggregate_id", aggregateID),
+			zap.String("read_model", drift.ReadModel),
+			zap.Bool("missing", drift.Missing),
+			zap.Any("fields", drift.Fields))
+
+		if check.Repair {
+			s.repair(ctx, drift)
+			if drift.Repaired {
+				check.Repaired++
+			}
+		}
+
+		if len(check.Drifts) < maxConsistencyDrifts {
+			check.Drifts = append(check.Drifts, drift)
+		}
+	}
+	return nil
+}
+
+// replayAccount rebuilds the account from its full event stream, snapshots are not used
+// so the result depends on the events only.
+func (s *ConsistencyService) replayAccount(ctx context.Context, aggregateID string) (*domain.BankAccountAggregate, error) {
+	events, err := s.eventStore.LoadEvents(ctx, aggregateID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "eventStore.LoadEvents aggregateID: %s", aggregateID)
+	}
+
+	aggregate := domain.NewBankAccountAggregate(aggregateID)
+	for _, event := range events {
+		deserializedEvent, err := s.serializer.DeserializeEvent(event)
+		if err != nil {
+			return nil, errors.Wrapf(err, "serializer.DeserializeEvent aggregateID: %s, version: %d", aggregateID, event.GetVersion())
+		}
+		if err := aggregate.RaiseEvent(deserializedEvent); err != nil {
+			return nil, errors.Wrapf(err, "aggregate.RaiseEvent aggregateID: %s, version: %d", aggregateID, event.GetVersion())
+		}
+	}
+	return aggregate, nil
+}
+
+func (s *ConsistencyService) compareMongo(ctx context.Context, expected *domain.BankAccountAggregate) (*domain.AccountDrift, error) {
+	drift := &domain.AccountDrift{AggregateID: expected.GetID(), ReadModel: domain.ReadModelMongo}
+
+	projection, err := s.mongoRepo.GetByAggregateID(ctx, expected.GetID())
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			drift.Missing = true
+			return drift, nil
+		}
+		return nil, errors.Wrapf(err, "mongoRepo.GetByAggregateID aggregateID: %s", expected.GetID())
+	}
+
+	drift.Fields = compareAccount(expected, accountState{
+		version:   projection.Version,
+		email:     projection.Email,
+		firstName: projection.FirstName,
+		lastName:  projection.LastName,
+		balance:   projection.Balance.Amount,
+		currency:  projection.Balance.Currency,
+		status:    projection.Status,
+	})
+	if len(drift.Fields) == 0 {
+		return nil, nil
+	}
+	return drift, nil
+}
+
+func (s *ConsistencyService) compareElasticsearch(ctx context.Context, expected *domain.BankAccountAggregate) (*domain.AccountDrift, error) {
+	drift := &domain.AccountDrift{AggregateID: expected.GetID(), ReadModel: domain.ReadModelElasticsearch}
+
+	document, err := s.esRepo.GetDocument(BankAccountIndexName, expected.GetID())
+	if err != nil {
+		if errors.Is(err, bankAccountErrors.ErrDocumentNotFound) {
+			drift.Missing = true
+			return drift, nil
+		}
+		return nil, errors.Wrapf(err, "esRepo.GetDocument aggregateID: %s", expected.GetID())
+	}
+
+	actual := accountState{
+		version:   document.Version,
+		email:     document.Email,
+		firstName: document.FirstName,
+		lastName:  document.LastName,
+		status:    domain.AccountStatus(document.Status),
+	}
+	if document.Balance != nil {
+		actual.balance = document.Balance.Amount
+		actual.currency = document.Balance.Currency
+	}
+
+	drift.Fields = compareAccount(expected, actual)
+	if len(drift.Fields) == 0 {
+		return nil, nil
+	}
+	return drift, nil
+}
+
+// repair rebuilds the drifted account stream in the read model, failures are recorded on the drift.
+func (s *ConsistencyService) repair(ctx context.Context, drift *domain.AccountDrift) {
+	runner := s.esRunner
+	if drift.ReadModel == domain.ReadModelMongo {
+		runner = s.mongoRunner
+	}
+
+	stats, err := runner.RebuildStreams(ctx, []string{drift.AggregateID})
+	switch {
+	case err != nil:
+		drift.RepairError = err.Error()
+	case stats.Failed > 0:
+		drift.RepairError = fmt.Sprintf("failed events: %d", stats.Failed)
+	default:
+		drift.Repaired = true
+	}
+
+	if drift.RepairError != "" {
+		s.logger.Error("Failed to repair read model drift",
+			zap.String("aggregate_id", drift.AggregateID),
+			zap.String("read_model", drift.ReadModel),
+			zap.String("error", drift.RepairError))
+	}
+}
+
+// accountState account fields read model is compared on, balance in minor units.
+type accountState struct {
+	version   uint64
+	email     string
+	firstName string
+	lastName  string
+	balance   int64
+	currency  string
+	status    domain.AccountStatus
+}
+
+func compareAccount(expected *domain.BankAccountAggregate, actual accountState) []domain.FieldDrift {
+	account := expected.BankAccount
+
+	want := accountState{
+		version:   expected.GetVersion(),
+		email:     account.Email,
+		firstName: account.FirstName,
+		lastName:  account.LastName,
+		status:    account.Status,
+	}
+	if account.Balance != nil {
+		want.balance = account.Balance.Amount()
+		want.currency = account.Balance.Currency().Code
+	}
+
+	drifts := make([]domain.FieldDrift, 0)
+	addDrift := func(field string, expected, actual any) {
+		if expected != actual {
+			drifts = append(drifts, domain.FieldDrift{
+				Field:    field,
+				Expected: fmt.Sprint(expected),
+				Actual:   fmt.Sprint(actual),
+			})
+		}
+	}
+
+	addDrift("version", want.version, actual.version)
+	addDrift("email", want.email, actual.email)
+	addDrift("first_name", want.firstName, actual.firstName)
+	addDrift("last_name", want.lastName, actual.lastName)
+	addDrift("balance", want.balance, actual.balance)
+	addDrift("currency", want.currency, actual.currency)
+	addDrift("status", statusOrActive(want.status), statusOrActive(actual.status))
+	return drifts
+}
+
+// statusOrActive status of state recorded before accounts had a lifecycle is active.
+func statusOrActive(status domain.AccountStatus) domain.AccountStatus {
+	if status == "" {
+		return domain.AccountStatusActive
+	}
+	return status
+}
@@ -0,0 +1,555 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/es/serializer"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// eventArchiveBatchSize events loaded per export query and imported per transaction
+	eventArchiveBatchSize = 500
+	// maxImportConflicts conflicts listed in the import result, all of them are counted
+	maxImportConflicts = 100
+	anonymizedRedacted = "redacted"
+)
+
+var (
+	ErrEventImportConflicts = errors.New("event archive conflicts with the event store")
+)
+
+// EventExportRequest selects the streams to export, empty type and ids export the whole store. Anonymize replaces
+// emails, names, payout targets and reasons with stable pseudonyms and password hashes with the hash of
+// AnonymizedPassword, a random one when empty.
+type EventExportRequest struct {
+	AggregateType      es.AggregateType
+	AggregateIDs       []string
+	Anonymize          bool
+	AnonymizedPassword string
+}
+
+// EventImportOptions of importing an archive. Archives keep their global positions with PreservePositions, which
+// needs the positions to be free in the store, otherwise events are appended after the events of the store.
+// SkipConflicts imports the streams without conflicts instead of rejecting the archive, Publish publishes the
+// imported events to the event bus so projections pick them up.
+type EventImportOptions struct {
+	DryRun            bool `json:"dry_run"`
+	PreservePositions bool `json:"preserve_positions"`
+	SkipConflicts     bool `json:"skip_conflicts"`
+	Publish           bool `json:"publish"`
+}
+
+// EventImportConflict stream of the archive which can not be imported.
+type EventImportConflict struct {
+	AggregateID string `json:"aggregate_id"`
+	Version     uint64 `json:"version"`
+	Position    uint64 `json:"position"`
+	Reason      string `json:"reason"`
+}
+
+// EventImportResult of importing an archive. Existing events are stored already with the same content, importing
+// an archive again only appends what is missing.
+type EventImportResult struct {
+	Header        es.ArchiveHeader      `json:"header"`
+	Manifest      *es.ArchiveManifest   `json:"manifest,omitempty"`
+	Options       EventImportOptions    `json:"options"`
+	Events        int64                 `json:"events"`
+	Imported      int64                 `json:"imported"`
+	Existing      int64                 `json:"existing"`
+	Skipped       int64                 `json:"skipped"`
+	Published     int64                 `json:"published"`
+	ConflictCount int                   `json:"conflict_count"`
+	Conflicts     []EventImportConflict `json:"conflicts,omitempty"`
+	StartedAt     time.Time             `json:"started_at"`
+	FinishedAt    time.Time             `json:"finished_at"`
+}
+
+// EventArchiveService exports event streams to gzip NDJSON archives and imports them into the event store, to back
+// up the store and to move streams between environments.
+type EventArchiveService struct {
+	eventStore es.EventStore
+	eventBus   es.EventsBus
+	logger     *zap.Logger
+}
+
+// NewEventArchiveService creates a new event archive service
+func NewEventArchiveService(
+	eventStore es.EventStore,
+	eventBus es.EventsBus,
+	logger *zap.Logger,
+) *EventArchiveService {
+	return &EventArchiveService{
+		eventStore: eventStore,
+		eventBus:   eventBus,
+		logger:     logger,
+	}
+}
+
+// Export writes the selected streams up to the current head position to w in global position order. The export is
+// not a consistent snapshot: positions are taken from a sequence when events are inserted, so a transaction still
+// committing at export time may commit a position below the head after the batch covering it was read, and is left
+// out. Export from a quiesced store for a complete backup.
+func (s *EventArchiveService) Export(ctx context.Context, w io.Writer, request EventExportRequest) (_ *es.ArchiveManifest, err error) {
+	ctx, span := tracing.StartSpan(ctx, "EventArchiveService.Export")
+	defer func() {
+		tracing.TraceErr(span, err)
+		span.End()
+	}()
+
+	head, err := s.eventStore.GetHeadPosition(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "eventStore.GetHeadPosition")
+	}
+	filter := es.StreamFilter{
+		AggregateType: request.AggregateType,
+		AggregateIDs:  request.AggregateIDs,
+		ToPosition:    head,
+	}
+
+	var anonymizer *eventAnonymizer
+	if request.Anonymize {
+		if anonymizer, err = newEventAnonymizer(request.AnonymizedPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	writer, err := es.NewArchiveWriter(w, es.ArchiveHeader{
+		CreatedAt:    time.Now().UTC(),
+		Filter:       &filter,
+		HeadPosition: head,
+		Anonymized:   request.Anonymize,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "es.NewArchiveWriter")
+	}
+
+	// the head is fixed before the first batch, so events appended during the export are left out, positions below
+	// the head committing after their batch was read are missed as well
+	for position := uint64(0); position < head; {
+		batch, err := s.eventStore.LoadEventsByFilter(ctx, filter, position, eventArchiveBatchSize)
+		if err != nil {
+			return nil, errors.Wrapf(err, "eventStore.LoadEventsByFilter position: %d", position)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, event := range batch {
+			if anonymizer != nil {
+				if err := anonymizer.anonymize(&event); err != nil {
+					return nil, errors.Wrapf(err, "anonymize position: %d", event.GetPosition())
+				}
+			}
+			if err := writer.Write(event); err != nil {
+				return nil, errors.Wrap(err, "writer.Write")
+			}
+		}
+		position = batch[len(batch)-1].GetPosition()
+	}
+
+	manifest, err := writer.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "writer.Close")
+	}
+
+	s.logger.Info("Event store exported",
+		zap.String("aggregate_type", string(request.AggregateType)),
+		zap.Int("aggregate_ids", len(request.AggregateIDs)),
+		zap.Bool("anonymized", request.Anonymize),
+		zap.Int64("events", manifest.Events),
+		zap.Int64("aggregates", manifest.Aggregates),
+		zap.Uint64("head_position", head))
+	return manifest, nil
+}
+
+// importStream state of one stream of the archive against the stream in the store.
+type importStream struct {
+	stored        []es.Event
+	storedVersion uint64
+	lastVersion   uint64
+	events        int64
+	existing      int64
+	conflict      bool
+}
+
+// Import verifies the archive and checks every stream against the store before writing anything: events stored
+// already must match, new events must continue the stored stream. Conflicts reject the archive unless skipped.
+// The archive is read twice, so it must be seekable. Events are imported in transactions of a batch, an import
+// failing half way is completed by importing the archive again.
+func (s *EventArchiveService) Import(ctx context.Context, archive io.ReadSeeker, options EventImportOptions) (_ *EventImportResult, err error) {
+	ctx, span := tracing.StartSpan(ctx, "EventArchiveService.Import")
+	defer func() {
+		tracing.TraceErr(span, err)
+		span.End()
+	}()
+
+	result := &EventImportResult{Options: options, StartedAt: time.Now().UTC()}
+	streams, err := s.planImport(ctx, archive, options, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.ConflictCount > 0 && !options.SkipConflicts {
+		result.FinishedAt = time.Now().UTC()
+		return result, errors.Wrapf(ErrEventImportConflicts, "%d conflicting streams", result.ConflictCount)
+	}
+	if options.DryRun {
+		result.FinishedAt = time.Now().UTC()
+		return result, nil
+	}
+
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "archive.Seek")
+	}
+	reader, err := es.NewArchiveReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	batch := make([]es.Event, 0, eventArchiveBatchSize)
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		stream := streams[event.GetAggregateID()]
+		if stream.conflict || event.GetVersion() <= stream.storedVersion {
+			continue
+		}
+
+		batch = append(batch, *event)
+		if len(batch) == eventArchiveBatchSize {
+			if err := s.importBatch(ctx, batch, options, result); err != nil {
+				return nil, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := s.importBatch(ctx, batch, options, result); err != nil {
+		return nil, err
+	}
+
+	result.FinishedAt = time.Now().UTC()
+	s.logger.Info("Event archive imported",
+		zap.Int64("events", result.Events),
+		zap.Int64("imported", result.Imported),
+		zap.Int64("existing", result.Existing),
+		zap.Int64("skipped", result.Skipped),
+		zap.Int64("published", result.Published),
+		zap.Int("conflicts", result.ConflictCount))
+	return result, nil
+}
+
+// planImport reads the whole archive, verifying its checksums, and compares its streams with the store.
+func (s *EventArchiveService) planImport(ctx context.Context, archive io.Reader, options EventImportOptions, result *EventImportResult) (map[string]*importStream, error) {
+	reader, err := es.NewArchiveReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	result.Header = reader.Header()
+
+	streams := make(map[string]*importStream)
+	conflict := func(stream *importStream, event *es.Event, reason string) {
+		stream.conflict = true
+		stream.stored = nil
+		result.ConflictCount++
+		if len(result.Conflicts) < maxImportConflicts {
+			result.Conflicts = append(result.Conflicts, EventImportConflict{
+				AggregateID: event.GetAggregateID(),
+				Version:     event.GetVersion(),
+				Position:    event.GetPosition(),
+				Reason:      reason,
+			})
+		}
+	}
+
+	// positions of new events are checked against the store per batch when they are preserved
+	pending := make(map[uint64]*es.Event)
+	checkPositions := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		positions := make([]uint64, 0, len(pending))
+		for position := range pending {
+			positions = append(positions, position)
+		}
+		taken, err := s.eventStore.LoadEventsByPositions(ctx, positions)
+		if err != nil {
+			return errors.Wrap(err, "eventStore.LoadEventsByPositions")
+		}
+		for _, stored := range taken {
+			event := pending[stored.GetPosition()]
+			if stream := streams[event.GetAggregateID()]; !stream.conflict {
+				conflict(stream, event, fmt.Sprintf("position is taken by version %d of stream %s", stored.GetVersion(), stored.GetAggregateID()))
+			}
+		}
+		clear(pending)
+		return nil
+	}
+
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Events++
+
+		stream, ok := streams[event.GetAggregateID()]
+		if !ok {
+			stored, err := s.eventStore.LoadEvents(ctx, event.GetAggregateID())
+			if err != nil {
+				return nil, errors.Wrapf(err, "eventStore.LoadEvents aggregateID: %s", event.GetAggregateID())
+			}
+			stream = &importStream{stored: stored}
+			if len(stored) > 0 {
+				stream.storedVersion = stored[len(stored)-1].GetVersion()
+			}
+			streams[event.GetAggregateID()] = stream
+		}
+		stream.events++
+		if stream.conflict {
+			continue
+		}
+
+		if event.GetVersion() != stream.lastVersion+1 {
+			conflict(stream, event, fmt.Sprintf("archive stream continues version %d with version %d", stream.lastVersion, event.GetVersion()))
+			continue
+		}
+		stream.lastVersion = event.GetVersion()
+
+		if event.GetVersion() <= stream.storedVersion {
+			if reason := compareStoredEvent(stream.stored, event); reason != "" {
+				conflict(stream, event, reason)
+				continue
+			}
+			stream.existing++
+			if event.GetVersion() == stream.storedVersion {
+				stream.stored = nil
+			}
+			continue
+		}
+
+		if options.PreservePositions {
+			pending[event.GetPosition()] = event
+			if len(pending) == eventArchiveBatchSize {
+				if err := checkPositions(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if err := checkPositions(); err != nil {
+		return nil, err
+	}
+	result.Manifest = reader.Manifest()
+
+	// events of conflicting streams are skipped as a whole, including the ones read before the conflict
+	for _, stream := range streams {
+		if stream.conflict {
+			result.Skipped += stream.events
+			continue
+		}
+		result.Existing += stream.existing
+	}
+	return streams, nil
+}
+
+// compareStoredEvent describes how the stored event of the version differs from the archived one, empty when equal.
+func compareStoredEvent(stored []es.Event, event *es.Event) string {
+	index := int(event.GetVersion()) - 1
+	if index < 0 || index >= len(stored) || stored[index].GetVersion() != event.GetVersion() {
+		return fmt.Sprintf("stored stream has no version %d", event.GetVersion())
+	}
+
+	existing := stored[index]
+	switch {
+	case existing.GetAggregateType() != event.GetAggregateType():
+		return fmt.Sprintf("stored aggregate type %s differs from %s", existing.GetAggregateType(), event.GetAggregateType())
+	case existing.GetEventType() != event.GetEventType():
+		return fmt.Sprintf("stored event type %s differs from %s", existing.GetEventType(), event.GetEventType())
+	case !equalJSON(existing.GetData(), event.GetData()):
+		return "stored event data differs"
+	default:
+		return ""
+	}
+}
+
+func (s *EventArchiveService) importBatch(ctx context.Context, batch []es.Event, options EventImportOptions, result *EventImportResult) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := s.eventStore.ImportEvents(ctx, batch, options.PreservePositions); err != nil {
+		return errors.Wrapf(err, "eventStore.ImportEvents imported: %d", result.Imported)
+	}
+	result.Imported += int64(len(batch))
+
+	if !options.Publish {
+		return nil
+	}
+	// the bus publishes the events of one aggregate per message, runs of the same stream keep the batch order
+	for start := 0; start < len(batch); {
+		end := start + 1
+		for end < len(batch) && batch[end].GetAggregateID() == batch[start].GetAggregateID() {
+			end++
+		}
+		if err := s.eventBus.ProcessEvents(ctx, batch[start:end]); err != nil {
+			return errors.Wrapf(err, "eventBus.ProcessEvents aggregateID: %s", batch[start].GetAggregateID())
+		}
+		result.Published += int64(end - start)
+		start = end
+	}
+	return nil
+}
+
+func equalJSON(a, b []byte) bool {
+	var compactA, compactB bytes.Buffer
+	if json.Compact(&compactA, a) != nil || json.Compact(&compactB, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	if bytes.Equal(compactA.Bytes(), compactB.Bytes()) {
+		return true
+	}
+
+	// jsonb does not keep key order, compare the decoded values
+	var valueA, valueB any
+	if serializer.Unmarshal(a, &valueA) != nil || serializer.Unmarshal(b, &valueB) != nil {
+		return false
+	}
+	encodedA, errA := serializer.Marshal(valueA)
+	encodedB, errB := serializer.Marshal(valueB)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
+
+// eventAnonymizer replaces personal data of bank account events. Pseudonyms are derived from the replaced values,
+// so an email changed later keeps matching its earlier events.
+type eventAnonymizer struct {
+	passwordHash string
+}
+
+func newEventAnonymizer(password string) (*eventAnonymizer, error) {
+	if password == "" {
+		random := make([]byte, 24)
+		if _, err := rand.Read(random); err != nil {
+			return nil, errors.Wrap(err, "rand.Read")
+		}
+		password = hex.EncodeToString(random)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.Wrap(err, "bcrypt.GenerateFromPassword")
+	}
+	return &eventAnonymizer{passwordHash: string(hash)}, nil
+}
+
+func (a *eventAnonymizer) anonymize(event *es.Event) error {
+	var fields map[string]json.RawMessage
+	if err := serializer.Unmarshal(event.GetData(), &fields); err != nil {
+		return errors.Wrap(err, "serializer.Unmarshal")
+	}
+
+	changed := false
+	replace := func(field string, pseudonym func(value string) string) error {
+		raw, ok := fields[field]
+		if !ok {
+			return nil
+		}
+		var value string
+		if err := serializer.Unmarshal(raw, &value); err != nil || value == "" {
+			return nil
+		}
+		encoded, err := serializer.Marshal(pseudonym(value))
+		if err != nil {
+			return errors.Wrapf(err, "serializer.Marshal field: %s", field)
+		}
+		fields[field] = encoded
+		changed = true
+		return nil
+	}
+
+	var replacements map[string]func(string) string
+	switch event.GetEventType() {
+	case events.BankAccountCreatedEventTypeV1:
+		replacements = map[string]func(string) string{
+			"email": pseudonymEmail, "first_name": pseudonymFirstName, "last_name": pseudonymLastName, "password_hash": a.password,
+		}
+	case events.EmailChangedEventTypeV1:
+		replacements = map[string]func(string) string{"email": pseudonymEmail, "old_email": pseudonymEmail}
+	case events.ProfileUpdatedEventTypeV1:
+		replacements = map[string]func(string) string{"first_name": pseudonymFirstName, "last_name": pseudonymLastName}
+	case events.PasswordChangedEventTypeV1:
+		replacements = map[string]func(string) string{"password_hash": a.password}
+	case events.AccountClosedEventTypeV1:
+		replacements = map[string]func(string) string{"payout_target": pseudonymPayoutTarget, "reason": redacted}
+	default:
+		replacements = map[string]func(string) string{"reason": redacted}
+	}
+
+	for field, pseudonym := range replacements {
+		if err := replace(field, pseudonym); err != nil {
+			return err
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	data, err := serializer.Marshal(fields)
+	if err != nil {
+		return errors.Wrap(err, "serializer.Marshal")
+	}
+	event.SetData(data)
+	return nil
+}
+
+func (a *eventAnonymizer) password(string) string {
+	return a.passwordHash
+}
+
+func pseudonym(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func pseudonymEmail(email string) string {
+	return fmt.Sprintf("user-%s@example.com", pseudonym(email))
+}
+
+func pseudonymFirstName(string) string {
+	return "Customer"
+}
+
+func pseudonymLastName(name string) string {
+	return pseudonym(name)
+}
+
+func pseudonymPayoutTarget(target string) string {
+	return "payout-" + pseudonym(target)
+}
+
+func redacted(string) string {
+	return anonymizedRedacted
+}
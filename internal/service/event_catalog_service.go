@@ -0,0 +1,78 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+// EventCatalogEntry contract of an event type published to Kafka: the topic carrying it and the JSON Schema
+// of its payload.
+type EventCatalogEntry struct {
+	EventType     es.EventType     `json:"eventType"`
+	AggregateType es.AggregateType `json:"aggregateType"`
+	Topic         string           `json:"topic"`
+	Description   string           `json:"description"`
+	Revision      int              `json:"revision"`
+	Checksum      string           `json:"checksum"`
+	RegisteredAt  *time.Time       `json:"registeredAt,omitempty"`
+	Schema        json.RawMessage  `json:"schema"`
+}
+
+// EventCatalogEntryHistory catalog entry with all schema revisions of the event type, oldest first.
+type EventCatalogEntryHistory struct {
+	EventCatalogEntry
+	Revisions []*es.EventSchema `json:"revisions"`
+}
+
+// EventCatalogService lists the event types of the schema registry for downstream consumers.
+type EventCatalogService struct {
+	schemas       *es.SchemaRegistry
+	publisherConf es.KafkaEventsBusConfig
+}
+
+// NewEventCatalogService creates a new event catalog service
+func NewEventCatalogService(schemas *es.SchemaRegistry, publisherConf es.KafkaEventsBusConfig) *EventCatalogService {
+	return &EventCatalogService{
+		schemas:       schemas,
+		publisherConf: publisherConf,
+	}
+}
+
+// ListEventTypes returns the latest schema revision of every event type ordered by aggregate type and event type.
+func (s *EventCatalogService) ListEventTypes() []*EventCatalogEntry {
+	schemas := s.schemas.LatestSchemas()
+	entries := make([]*EventCatalogEntry, 0, len(schemas))
+	for _, schema := range schemas {
+		entries = append(entries, s.catalogEntry(schema))
+	}
+	return entries
+}
+
+// GetEventType returns the event type with its schema revisions, es.ErrEventSchemaNotFound for unknown types.
+func (s *EventCatalogService) GetEventType(eventType es.EventType) (*EventCatalogEntryHistory, error) {
+	revisions, err := s.schemas.Revisions(eventType)
+	if err != nil {
+		return nil, errors.Wrap(err, "schemas.Revisions")
+	}
+
+	return &EventCatalogEntryHistory{
+		EventCatalogEntry: *s.catalogEntry(revisions[len(revisions)-1]),
+		Revisions:         revisions,
+	}, nil
+}
+
+func (s *EventCatalogService) catalogEntry(schema *es.EventSchema) *EventCatalogEntry {
+	return &EventCatalogEntry{
+		EventType:     schema.EventType,
+		AggregateType: schema.AggregateType,
+		Topic:         es.GetTopicName(s.publisherConf.TopicPrefix, string(schema.AggregateType)),
+		Description:   schema.Description,
+		Revision:      schema.Revision,
+		Checksum:      schema.Checksum,
+		RegisteredAt:  schema.RegisteredAt,
+		Schema:        schema.Schema,
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+// HoldsConfig expired holds release job config.
+type HoldsConfig struct {
+	// ExpiryInterval delay between runs releasing expired holds
+	ExpiryInterval time.Duration
+	// BatchSize accounts loaded by one page of the scan
+	BatchSize int
+}
+
+// HoldExpiryService releases holds past their expiry. Expired holds stop reserving funds as soon as they expire,
+// the job records their release so that projections and the ledger stop showing them. Accounts are found through
+// the mongo projection and checked against the event store, an account the projection has not caught up with
+// is a no-op and is released again by the next run.
+type HoldExpiryService struct {
+	cfg             HoldsConfig
+	aggregateStore  es.AggregateStore
+	mongoRepository domain.MongoRepository
+	logger          *zap.Logger
+}
+
+// NewHoldExpiryService creates a new hold expiry service
+func NewHoldExpiryService(
+	cfg HoldsConfig,
+	aggregateStore es.AggregateStore,
+	mongoRepository domain.MongoRepository,
+	logger *zap.Logger,
+) *HoldExpiryService {
+	return &HoldExpiryService{
+		cfg:             cfg,
+		aggregateStore:  aggregateStore,
+		mongoRepository: mongoRepository,
+		logger:          logger,
+	}
+}
+
+// Run releases expired holds every expiry interval until shutdown.
+func (s *HoldExpiryService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.ExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ReleaseExpired(ctx, time.Now().UTC()); err != nil && ctx.Err() == nil {
+			s.logger.Error("Failed to release expired holds", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReleaseExpired releases holds of every account expired at now.
+func (s *HoldExpiryService) ReleaseExpired(ctx context.Context, now time.Time) error {
+	released, failed := 0, 0
+
+	cursor := ""
+	for {
+		ids, err := s.mongoRepository.GetIDsWithExpiredHolds(ctx, now, cursor, s.cfg.BatchSize)
+		if err != nil {
+			return errors.Wrap(err, "mongoRepository.GetIDsWithExpiredHolds")
+		}
+
+		for _, id := range ids {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			ok, err := s.releaseAccount(ctx, id, now)
+			if err != nil {
+				s.logger.Error("Failed to release expired holds of account", zap.String("aggregate_id", id), zap.Error(err))
+				failed++
+				continue
+			}
+			if ok {
+				released++
+			}
+		}
+
+		if len(ids) < s.cfg.BatchSize {
+			break
+		}
+		cursor = ids[len(ids)-1]
+	}
+
+	if released > 0 || failed > 0 {
+		s.logger.Info("Released expired holds", zap.Int("accounts", released), zap.Int("failed", failed))
+	}
+	return nil
+}
+
+// releaseAccount releases expired holds of the account, it returns whether any hold was released.
+func (s *HoldExpiryService) releaseAccount(ctx context.Context, id string, now time.Time) (bool, error) {
+	account, err := loadAccount(ctx, s.aggregateStore, id)
+	if err != nil {
+		return false, err
+	}
+
+	if err := account.ReleaseExpiredHolds(ctx, now); err != nil {
+		return false, err
+	}
+	if len(account.GetChanges()) == 0 {
+		return false, nil
+	}
+
+	if err := s.aggregateStore.Save(ctx, account); err != nil {
+		return false, errors.Wrapf(err, "aggregateStore.Save aggregateID: %s", id)
+	}
+	return true, nil
+}
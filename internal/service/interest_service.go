@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+// InterestConfig interest accrual job config.
+type InterestConfig struct {
+	// RunInterval delay between runs accruing interest up to the previous UTC day
+	RunInterval time.Duration
+	// BatchSize accounts loaded by one page of the scan
+	BatchSize int
+}
+
+// InterestRunResult summary of the accrual run, accounts already accrued up to the day are not counted.
+type InterestRunResult struct {
+	Through        string    `json:"through"`
+	Accounts       int       `json:"accounts"`
+	DaysAccrued    int       `json:"days_accrued"`
+	MonthsPosted   int       `json:"months_posted"`
+	Failed         int       `json:"failed"`
+	FailedAccounts []string  `json:"failed_accounts,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+}
+
+// InterestService accrues daily interest of accounts with an interest rate and posts it monthly. Interest of a day
+// is computed from the closing balance and the rate at the end of the day, both rebuilt from the account events
+// recorded before the day ended, so running the job again for the same day gives the same result and the account
+// ignores days it has already accrued. Instances running the job at the same time are serialized by the
+// optimistic concurrency of the event store, the loser skips the account until the next run.
+type InterestService struct {
+	cfg            InterestConfig
+	aggregateStore es.AggregateStore
+	serializer     es.Serializer
+	logger         *zap.Logger
+	mu             sync.Mutex
+}
+
+// NewInterestService creates a new interest service
+func NewInterestService(
+	cfg InterestConfig,
+	aggregateStore es.AggregateStore,
+	serializer es.Serializer,
+	logger *zap.Logger,
+) *InterestService {
+	return &InterestService{
+		cfg:            cfg,
+		aggregateStore: aggregateStore,
+		serializer:     serializer,
+		logger:         logger,
+	}
+}
+
+// Run accrues interest up to the previous UTC day every run interval until shutdown.
+func (s *InterestService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.RunInterval)
+	defer ticker.Stop()
+
+	for {
+		yesterday := domain.InterestDay(time.Now()).AddDate(0, 0, -1)
+		if _, err := s.AccrueThrough(ctx, yesterday); err != nil && ctx.Err() == nil {
+			s.logger.Error("Failed to accrue interest", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// AccrueThrough accrues interest of every account for the days after its last accrued day up to the finished
+// UTC day through, and posts the months ending within them.
+func (s *InterestService) AccrueThrough(ctx context.Context, through time.Time) (*InterestRunResult, error) {
+	through = domain.InterestDay(through)
+	if through.AddDate(0, 0, 1).After(time.Now().UTC()) {
+		return nil, errors.Wrapf(bankAccountErrors.ErrInterestPeriodNotOver, "day: %s", through.Format(time.DateOnly))
+	}
+
+	// runs of this instance do not race each other for the same accounts
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := &InterestRunResult{Through: through.Format(time.DateOnly), StartedAt: time.Now().UTC()}
+	filter := es.StreamFilter{AggregateType: domain.BankAccountAggregateType}
+
+	cursor := ""
+	for {
+		ids, err := s.aggregateStore.LoadAggregateIDs(ctx, filter, cursor, s.cfg.BatchSize)
+		if err != nil {
+			return nil, errors.Wrap(err, "aggregateStore.LoadAggregateIDs")
+		}
+
+		for _, id := range ids {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			accrued, posted, err := s.accrueAccount(ctx, id, through)
+			if err != nil {
+				s.logger.Error("Failed to accrue account interest", zap.String("aggregate_id", id), zap.Error(err))
+				result.Failed++
+				result.FailedAccounts = append(result.FailedAccounts, id)
+				continue
+			}
+			if accrued > 0 || posted > 0 {
+				result.Accounts++
+				result.DaysAccrued += accrued
+				result.MonthsPosted += posted
+			}
+		}
+
+		if len(ids) < s.cfg.BatchSize {
+			break
+		}
+		cursor = ids[len(ids)-1]
+	}
+
+	result.FinishedAt = time.Now().UTC()
+	s.logger.Info("Interest accrued",
+		zap.String("through", result.Through),
+		zap.Int("accounts", result.Accounts),
+		zap.Int("days_accrued", result.DaysAccrued),
+		zap.Int("months_posted", result.MonthsPosted),
+		zap.Int("failed", result.Failed))
+	return result, nil
+}
+
+// accrueAccount accrues the days of the account not accrued yet up to through, it returns the number
+// of accrued days and posted months.
+func (s *InterestService) accrueAccount(ctx context.Context, id string, through time.Time) (int, int, error) {
+	account, err := loadAccount(ctx, s.aggregateStore, id)
+	if err != nil {
+		return 0, 0, err
+	}
+	if account.BankAccount.IsClosed() {
+		return 0, 0, nil
+	}
+
+	interest := account.BankAccount.Interest
+	if interest.AccruingSince.IsZero() {
+		// no rate was ever set
+		return 0, 0, nil
+	}
+
+	start := domain.InterestDay(interest.AccruingSince)
+	if interest.LastAccruedDay != "" {
+		lastAccrued, err := time.Parse(time.DateOnly, interest.LastAccruedDay)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "last accrued day: %s", interest.LastAccruedDay)
+		}
+		start = maxTime(start, lastAccrued.AddDate(0, 0, 1))
+	}
+	// rate was zero at the end of every day since and nothing is left to post
+	if interest.RateBps == 0 && interest.Accrued == 0 && !domain.InterestDay(interest.RateChangedAt).After(start) {
+		return 0, 0, nil
+	}
+	if start.After(through) {
+		return 0, 0, nil
+	}
+
+	accountEvents, err := s.aggregateStore.LoadEvents(ctx, id)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "aggregateStore.LoadEvents aggregateID: %s", id)
+	}
+
+	// history is the account as it was at the end of the day
+	history := domain.NewBankAccountAggregate(id)
+	next := 0
+	accrued, posted := 0, 0
+	for day := start; !day.After(through); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		for ; next < len(accountEvents) && accountEvents[next].GetTimeStamp().Before(dayEnd); next++ {
+			deserializedEvent, err := s.serializer.DeserializeEvent(accountEvents[next])
+			if err != nil {
+				return 0, 0, errors.Wrapf(err, "serializer.DeserializeEvent aggregateID: %s, version: %d", id, accountEvents[next].GetVersion())
+			}
+			if err := history.When(deserializedEvent); err != nil {
+				return 0, 0, errors.Wrapf(err, "history.When aggregateID: %s, version: %d", id, accountEvents[next].GetVersion())
+			}
+		}
+		if history.BankAccount.IsClosed() {
+			break
+		}
+
+		if rateBps := history.BankAccount.Interest.RateBps; rateBps > 0 {
+			if err := account.AccrueInterest(ctx, day, history.BankAccount.Balance.Amount(), rateBps); err != nil {
+				return 0, 0, err
+			}
+			accrued++
+		}
+
+		if domain.IsLastDayOfMonth(day) {
+			changes := len(account.GetChanges())
+			if err := account.PostInterest(ctx, day); err != nil {
+				return 0, 0, err
+			}
+			if len(account.GetChanges()) > changes {
+				// posted interest is part of the balance from the next day, as if the month was posted by its own run
+				if err := history.When(account.GetChanges()[changes]); err != nil {
+					return 0, 0, errors.Wrapf(err, "history.When aggregateID: %s, day: %s", id, day.Format(time.DateOnly))
+				}
+				posted++
+			}
+		}
+	}
+
+	if len(account.GetChanges()) == 0 {
+		return 0, 0, nil
+	}
+	if err := s.aggregateStore.Save(ctx, account); err != nil {
+		return 0, 0, errors.Wrapf(err, "aggregateStore.Save aggregateID: %s", id)
+	}
+	return accrued, posted, nil
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
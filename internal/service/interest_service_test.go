@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+// clockAggregateStore keeps serialized events in memory stamped with the store clock, so runs of the past days
+// store events as they were stored by runs at that time. Methods not used by the services are left unimplemented.
+type clockAggregateStore struct {
+	es.AggregateStore
+	serializer es.Serializer
+	events     []es.Event
+	now        time.Time
+}
+
+func (s *clockAggregateStore) Load(ctx context.Context, aggregate es.Aggregate) error {
+	for _, event := range s.events {
+		if event.GetAggregateID() != aggregate.GetID() {
+			continue
+		}
+		deserializedEvent, err := s.serializer.DeserializeEvent(event)
+		if err != nil {
+			return err
+		}
+		if err := aggregate.RaiseEvent(deserializedEvent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *clockAggregateStore) LoadEvents(ctx context.Context, aggregateID string) ([]es.Event, error) {
+	events := make([]es.Event, 0)
+	for _, event := range s.events {
+		if event.GetAggregateID() == aggregateID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *clockAggregateStore) LoadAggregateIDs(ctx context.Context, filter es.StreamFilter, after string, limit int) ([]string, error) {
+	ids := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, event := range s.events {
+		id := event.GetAggregateID()
+		if event.GetAggregateType() == filter.AggregateType && id > after && !seen[id] && len(ids) < limit {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (s *clockAggregateStore) Save(ctx context.Context, aggregate es.Aggregate) error {
+	changes := aggregate.GetChanges()
+	firstVersion := aggregate.GetVersion() - uint64(len(changes)) + 1
+	for i, change := range changes {
+		event, err := s.serializer.SerializeEvent(aggregate, change)
+		if err != nil {
+			return err
+		}
+		event.SetVersion(firstVersion + uint64(i))
+		event.Timestamp = s.now
+		s.events = append(s.events, event)
+	}
+	aggregate.ClearChanges()
+	return nil
+}
+
+// newTestInterestStore stores an account opened on the first of January 2025 with a balance earning 5%.
+func newTestInterestStore(t *testing.T) *clockAggregateStore {
+	t.Helper()
+	opened := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	store := &clockAggregateStore{serializer: domain.NewEventSerializer(), now: opened}
+
+	aggregate := domain.NewBankAccountAggregate("account-1")
+	require.NoError(t, aggregate.Apply(&events.BankAccountCreatedEventV1{Email: "account@example.com", Balance: money.New(100_000_000, money.USD)}))
+	require.NoError(t, aggregate.Apply(&events.InterestRateChangedEventV1{RateBps: 500, ChangedAt: opened}))
+	require.NoError(t, store.Save(context.Background(), aggregate))
+	return store
+}
+
+func TestInterestServiceCatchUpAcrossMonths(t *testing.T) {
+	ctx := context.Background()
+	months := []time.Time{
+		time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	// every month accrued and posted by a run on the next day
+	monthly := newTestInterestStore(t)
+	monthlyService := NewInterestService(InterestConfig{BatchSize: 10}, monthly, monthly.serializer, zap.NewNop())
+	for _, through := range months {
+		monthly.now = through.AddDate(0, 0, 1).Add(time.Hour)
+		result, err := monthlyService.AccrueThrough(ctx, through)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.MonthsPosted)
+	}
+
+	// all months accrued and posted by a single run catching up
+	single := newTestInterestStore(t)
+	single.now = months[len(months)-1].AddDate(0, 0, 1).Add(time.Hour)
+	result, err := NewInterestService(InterestConfig{BatchSize: 10}, single, single.serializer, zap.NewNop()).AccrueThrough(ctx, months[len(months)-1])
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.MonthsPosted)
+	assert.Equal(t, 31+28+31, result.DaysAccrued)
+
+	accruals := func(store *clockAggregateStore) map[string]int64 {
+		amounts := make(map[string]int64)
+		for _, event := range store.events {
+			if event.GetEventType() != events.InterestAccruedEventTypeV1 {
+				continue
+			}
+			deserializedEvent, err := store.serializer.DeserializeEvent(event)
+			require.NoError(t, err)
+			accrued := deserializedEvent.(*events.InterestAccruedEventV1)
+			amounts[accrued.Day] = accrued.Amount
+		}
+		return amounts
+	}
+	assert.Equal(t, accruals(monthly), accruals(single), "daily interest does not depend on how often the job runs")
+
+	monthlyAccount, err := loadAccount(ctx, monthly, "account-1")
+	require.NoError(t, err)
+	singleAccount, err := loadAccount(ctx, single, "account-1")
+	require.NoError(t, err)
+	assert.Equal(t, monthlyAccount.BankAccount.Balance.Amount(), singleAccount.BankAccount.Balance.Amount())
+	assert.Equal(t, monthlyAccount.BankAccount.Interest, singleAccount.BankAccount.Interest)
+	assert.Greater(t, singleAccount.BankAccount.Balance.Amount(), int64(100_000_000))
+}
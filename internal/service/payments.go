@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/pkg/es"
+)
+
+// findPayment looks up the deposit or withdrawal with the payment id in the account stream and returns
+// its version. Background jobs use deterministic payment ids to not repeat payments saved before they stopped.
+func findPayment(ctx context.Context, eventStore es.EventStore, serializer es.Serializer, accountID string, eventType es.EventType, paymentID string) (uint64, bool, error) {
+	accountEvents, err := eventStore.LoadEvents(ctx, accountID)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "eventStore.LoadEvents aggregateID: %s", accountID)
+	}
+
+	for _, event := range accountEvents {
+		if event.GetEventType() != eventType {
+			continue
+		}
+		deserializedEvent, err := serializer.DeserializeEvent(event)
+		if err != nil {
+			return 0, false, errors.Wrapf(err, "serializer.DeserializeEvent aggregateID: %s, version: %d", accountID, event.GetVersion())
+		}
+
+		switch evt := deserializedEvent.(type) {
+		case *events.BalanceDepositedEventV1:
+			if evt.PaymentID == paymentID {
+				return event.GetVersion(), true, nil
+			}
+		case *events.BalanceWithdrawedEventV1:
+			if evt.PaymentID == paymentID {
+				return event.GetVersion(), true, nil
+			}
+		}
+	}
+	return 0, false, nil
+}
+
+// isDepositRejected reports deposit errors caused by the account or the payment, retrying them does not help.
+func isDepositRejected(err error) bool {
+	return errors.Is(err, bankAccountErrors.ErrAccountClosed) ||
+		errors.Is(err, bankAccountErrors.ErrCurrencyMismatch) ||
+		errors.Is(err, bankAccountErrors.ErrInvalidCurrency) ||
+		errors.Is(err, bankAccountErrors.ErrInvalidBalanceAmount) ||
+		errors.Is(err, bankAccountErrors.ErrBankAccountNotFound)
+}
+
+// loadAccount loads the existing account, ids of other aggregate types are not accounts.
+func loadAccount(ctx context.Context, aggregateStore es.AggregateStore, accountID string) (*domain.BankAccountAggregate, error) {
+	account := domain.NewBankAccountAggregate(accountID)
+	if account == nil {
+		return nil, errors.Wrap(bankAccountErrors.ErrBankAccountNotFound, "empty id")
+	}
+
+	if err := aggregateStore.Load(ctx, account); err != nil {
+		if errors.Is(err, bankAccountErrors.ErrUnknownEventType) {
+			return nil, errors.Wrapf(bankAccountErrors.ErrBankAccountNotFound, "aggregateID: %s", accountID)
+		}
+		return nil, errors.Wrapf(err, "aggregateStore.Load aggregateID: %s", accountID)
+	}
+	if account.GetVersion() == 0 {
+		return nil, errors.Wrapf(bankAccountErrors.ErrBankAccountNotFound, "aggregateID: %s", accountID)
+	}
+	return account, nil
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/command"
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+const (
+	payrollRunsBatchSize   = 100
+	payrollDepositAttempts = 3
+	payrollRetryDelay      = time.Second
+	// payrollRunLeaseDuration lease of an executing run, renewed before every line
+	payrollRunLeaseDuration = time.Minute
+)
+
+// PayrollService executes payroll runs: batches of deposits validated up front and executed line by line
+// in background. Every line result is saved to the run event stream, so runs stopped by shutdown or
+// failures are resumed on the next start. A run is executed by the instance holding its lease only.
+// Line deposits have deterministic payment ids, a deposit saved by an earlier execution is found in the
+// account stream and is not repeated.
+type PayrollService struct {
+	aggregateStore es.AggregateStore
+	serializer     es.Serializer
+	deposit        command.DepositeBalance
+	leases         domain.PayrollRunLeaseRepository
+	instanceID     string
+	logger         *zap.Logger
+
+	// runs outlive the requests, they are stopped by application shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	running map[string]struct{}
+	wg      sync.WaitGroup
+}
+
+// NewPayrollService creates a new payroll service
+func NewPayrollService(
+	aggregateStore es.AggregateStore,
+	serializer es.Serializer,
+	deposit command.DepositeBalance,
+	leases domain.PayrollRunLeaseRepository,
+	instanceID string,
+	logger *zap.Logger,
+) *PayrollService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PayrollService{
+		aggregateStore: aggregateStore,
+		serializer:     serializer,
+		deposit:        deposit,
+		leases:         leases,
+		instanceID:     instanceID,
+		logger:         logger,
+		ctx:            ctx,
+		cancel:         cancel,
+		running:        make(map[string]struct{}),
+	}
+}
+
+// Run resumes runs left incomplete by a previous process and on shutdown stops the executing runs,
+// waiting until their current line is recorded.
+func (s *PayrollService) Run(ctx context.Context) error {
+	if err := s.resumeRuns(ctx); err != nil {
+		s.logger.Error("Failed to resume payroll runs", zap.Error(err))
+	}
+
+	<-ctx.Done()
+
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+// CreateRun validates every line against the accounts and starts the run in background. Run id makes
+// creation idempotent: the existing run is returned when it was created from the same lines, created is false then.
+func (s *PayrollService) CreateRun(ctx context.Context, id string, source string, lines []domain.PayrollLine) (*domain.PayrollRun, bool, error) {
+	aggregate, err := s.loadRun(ctx, id)
+	if err != nil {
+		if errors.Is(err, bankAccountErrors.ErrPayrollRunNotFound) {
+			return nil, false, errors.Wrapf(bankAccountErrors.ErrPayrollRunConflict, "id: %s is used by other aggregate", id)
+		}
+		return nil, false, err
+	}
+
+	if aggregate.GetVersion() > 0 {
+		if !aggregate.PayrollRun.SameLines(lines) {
+			return nil, false, errors.Wrapf(bankAccountErrors.ErrPayrollRunConflict, "id: %s", id)
+		}
+		if !aggregate.PayrollRun.IsCompleted() {
+			s.start(id)
+		}
+		return aggregate.PayrollRun, false, nil
+	}
+
+	if err := s.validateLines(ctx, lines); err != nil {
+		return nil, false, err
+	}
+
+	if err := aggregate.CreatePayrollRun(ctx, source, lines); err != nil {
+		return nil, false, err
+	}
+	if err := s.aggregateStore.Save(ctx, aggregate); err != nil {
+		return nil, false, errors.Wrap(err, "aggregateStore.Save")
+	}
+
+	s.logger.Info("Payroll run created", zap.String("run_id", id), zap.String("source", source), zap.Int("lines", len(lines)))
+	s.start(id)
+	return aggregate.PayrollRun, true, nil
+}
+
+// GetRun returns the run with progress and per line status replayed from its event stream.
+func (s *PayrollService) GetRun(ctx context.Context, id string) (*domain.PayrollRun, error) {
+	aggregate, err := s.loadRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if aggregate.GetVersion() == 0 {
+		return nil, errors.Wrapf(bankAccountErrors.ErrPayrollRunNotFound, "id: %s", id)
+	}
+	return aggregate.PayrollRun, nil
+}
+
+// loadRun loads the run, aggregate has zero version when the run does not exist.
+func (s *PayrollService) loadRun(ctx context.Context, id string) (*domain.PayrollRunAggregate, error) {
+	aggregate := domain.NewPayrollRunAggregate(id)
+	if aggregate == nil {
+		return nil, errors.Wrap(bankAccountErrors.ErrPayrollRunNotFound, "empty id")
+	}
+
+	if err := s.aggregateStore.Load(ctx, aggregate); err != nil {
+		if errors.Is(err, bankAccountErrors.ErrUnknownEventType) {
+			return nil, errors.Wrapf(bankAccountErrors.ErrPayrollRunNotFound, "id: %s is not a payroll run", id)
+		}
+		return nil, errors.Wrapf(err, "aggregateStore.Load id: %s", id)
+	}
+	return aggregate, nil
+}
+
+// validateLines checks lines and their accounts, all invalid lines are reported in PayrollValidationError.
+func (s *PayrollService) validateLines(ctx context.Context, lines []domain.PayrollLine) error {
+	lineErrors := domain.ValidatePayrollLines(lines)
+	if len(lineErrors) > 0 && lineErrors[0].LineNo == 0 {
+		return &domain.PayrollValidationError{Lines: lineErrors}
+	}
+
+	invalid := make(map[int]bool, len(lineErrors))
+	for _, lineError := range lineErrors {
+		invalid[lineError.LineNo] = true
+	}
+
+	accounts := make(map[string]*domain.BankAccountAggregate)
+	for i, line := range lines {
+		lineNo := i + 1
+		if invalid[lineNo] {
+			continue
+		}
+
+		account, ok := accounts[line.AccountID]
+		if !ok {
+			account = domain.NewBankAccountAggregate(line.AccountID)
+			if err := s.aggregateStore.Load(ctx, account); err != nil {
+				if !errors.Is(err, bankAccountErrors.ErrUnknownEventType) {
+					return errors.Wrapf(err, "aggregateStore.Load aggregateID: %s", line.AccountID)
+				}
+				// id of other aggregate type is not an account
+				account = domain.NewBankAccountAggregate(line.AccountID)
+			}
+			accounts[line.AccountID] = account
+		}
+
+		switch {
+		case account.GetVersion() == 0:
+			lineErrors = append(lineErrors, domain.PayrollLineError{LineNo: lineNo, Error: fmt.Sprintf("%s: %s", bankAccountErrors.ErrBankAccountNotFound, line.AccountID)})
+		case account.BankAccount.IsClosed():
+			lineErrors = append(lineErrors, domain.PayrollLineError{LineNo: lineNo, Error: fmt.Sprintf("%s: %s", bankAccountErrors.ErrAccountClosed, line.AccountID)})
+		case line.Currency != "" && line.Currency != account.BankAccount.Currency():
+			lineErrors = append(lineErrors, domain.PayrollLineError{LineNo: lineNo, Error: fmt.Sprintf("%s: %s, account currency: %s", bankAccountErrors.ErrCurrencyMismatch, line.Currency, account.BankAccount.Currency())})
+		}
+	}
+
+	if len(lineErrors) == 0 {
+		return nil
+	}
+	sort.SliceStable(lineErrors, func(i, j int) bool {
+		return lineErrors[i].LineNo < lineErrors[j].LineNo
+	})
+	return &domain.PayrollValidationError{Lines: lineErrors}
+}
+
+// resumeRuns starts every incomplete run, runs are scanned in batches ordered by id.
+func (s *PayrollService) resumeRuns(ctx context.Context) error {
+	filter := es.StreamFilter{AggregateType: domain.PayrollRunAggregateType}
+
+	cursor := ""
+	for {
+		ids, err := s.aggregateStore.LoadAggregateIDs(ctx, filter, cursor, payrollRunsBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "aggregateStore.LoadAggregateIDs")
+		}
+
+		for _, id := range ids {
+			aggregate, err := s.loadRun(ctx, id)
+			if err != nil {
+				s.logger.Error("Failed to load payroll run", zap.String("run_id", id), zap.Error(err))
+				continue
+			}
+			if aggregate.PayrollRun.IsCompleted() {
+				continue
+			}
+
+			s.logger.Info("Resuming payroll run", zap.String("run_id", id), zap.Int("pending", aggregate.PayrollRun.Pending))
+			s.start(id)
+		}
+
+		if len(ids) < payrollRunsBatchSize {
+			return nil
+		}
+		cursor = ids[len(ids)-1]
+	}
+}
+
+// start executes the run in background unless it is already executing.
+func (s *PayrollService) start(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return
+	}
+	if _, ok := s.running[id]; ok {
+		return
+	}
+
+	s.running[id] = struct{}{}
+	s.wg.Add(1)
+	go s.execute(s.ctx, id)
+}
+
+func (s *PayrollService) execute(ctx context.Context, id string) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, id)
+		s.mu.Unlock()
+	}()
+
+	acquired, err := s.leases.Acquire(ctx, id, s.instanceID, time.Now().UTC(), time.Now().UTC().Add(payrollRunLeaseDuration))
+	if err != nil {
+		s.logger.Error("Failed to lease payroll run, resumed on next start or when submitted again", zap.String("run_id", id), zap.Error(err))
+		return
+	}
+	if !acquired {
+		s.logger.Info("Payroll run is executed by other instance", zap.String("run_id", id))
+		return
+	}
+	defer func() {
+		if err := s.leases.Release(context.WithoutCancel(ctx), id, s.instanceID); err != nil {
+			s.logger.Error("Failed to release payroll run lease", zap.String("run_id", id), zap.Error(err))
+		}
+	}()
+
+	run, err := s.executeRun(ctx, id)
+	switch {
+	case err == nil:
+		s.logger.Info("Payroll run completed",
+			zap.String("run_id", id),
+			zap.Int("succeeded", run.Succeeded),
+			zap.Int("failed", run.Failed))
+	case ctx.Err() != nil:
+		s.logger.Warn("Payroll run interrupted by shutdown, resumed on next start", zap.String("run_id", id))
+	default:
+		s.logger.Error("Payroll run stopped, resumed on next start or when submitted again", zap.String("run_id", id), zap.Error(err))
+	}
+}
+
+// executeRun deposits pending lines in order, the result of every line is saved before the next one starts.
+// The lease is renewed before every line, the run stops when the lease was taken over.
+func (s *PayrollService) executeRun(ctx context.Context, id string) (*domain.PayrollRun, error) {
+	aggregate, err := s.loadRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for line := aggregate.PayrollRun.NextPendingLine(); line != nil; line = aggregate.PayrollRun.NextPendingLine() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		renewed, err := s.leases.Renew(ctx, id, s.instanceID, time.Now().UTC().Add(payrollRunLeaseDuration))
+		if err != nil {
+			return nil, err
+		}
+		if !renewed {
+			return nil, errors.Wrapf(bankAccountErrors.ErrPayrollRunLeaseLost, "runID: %s", id)
+		}
+
+		if err := s.executeLine(ctx, aggregate, line); err != nil {
+			return nil, err
+		}
+
+		if err := s.save(ctx, aggregate); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := aggregate.CompletePayrollRun(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, aggregate); err != nil {
+		return nil, err
+	}
+	return aggregate.PayrollRun, nil
+}
+
+// executeLine deposits the line and records the result. Deposits rejected by the account fail the line,
+// other errors are retried and stop the run when attempts are exhausted, the line stays pending then.
+// The account stream is checked for the line payment before every attempt, the deposit may be saved
+// by an earlier execution or attempt that stopped before recording it.
+func (s *PayrollService) executeLine(ctx context.Context, aggregate *domain.PayrollRunAggregate, line *domain.PayrollLine) error {
+	paymentID := line.PaymentID(aggregate.GetID())
+
+	var lastErr error
+	for attempt := 1; attempt <= payrollDepositAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt-1) * payrollRetryDelay):
+			}
+		}
+
+		version, found, err := findPayment(ctx, s.aggregateStore, s.serializer, line.AccountID, events.BalancedDepositedEventTypeV1, paymentID)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if found {
+			s.logger.Info("Payroll line deposit already saved", zap.String("run_id", aggregate.GetID()), zap.Int("line", line.LineNo))
+			return aggregate.RecordLineSucceeded(ctx, line.LineNo, version)
+		}
+
+		version, err = s.deposit.Handle(ctx, command.DepositeBalanceCommand{
+			AggregateID: line.AccountID,
+			Amount:      line.Amount,
+			Currency:    line.Currency,
+			PaymentID:   paymentID,
+		})
+		if err == nil {
+			return aggregate.RecordLineSucceeded(ctx, line.LineNo, version)
+		}
+		if isDepositRejected(err) {
+			s.logger.Warn("Payroll line rejected", zap.String("run_id", aggregate.GetID()), zap.Int("line", line.LineNo), zap.Error(err))
+			return aggregate.RecordLineFailed(ctx, line.LineNo, err.Error())
+		}
+
+		lastErr = err
+		s.logger.Warn("Payroll line deposit failed",
+			zap.String("run_id", aggregate.GetID()),
+			zap.Int("line", line.LineNo),
+			zap.Int("attempt", attempt),
+			zap.Error(err))
+	}
+	return errors.Wrapf(lastErr, "deposit line: %d", line.LineNo)
+}
+
+// save saves recorded line results, the aggregate keeps being used for the next lines.
+func (s *PayrollService) save(ctx context.Context, aggregate *domain.PayrollRunAggregate) error {
+	// results already recorded are not lost when shutdown interrupts the run
+	if err := s.aggregateStore.Save(context.WithoutCancel(ctx), aggregate); err != nil {
+		return errors.Wrapf(err, "aggregateStore.Save runID: %s", aggregate.GetID())
+	}
+	aggregate.ClearChanges()
+	return nil
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+const (
+	bankAccountIndexVersionPrefix = BankAccountIndexName + "_v"
+)
+
+var (
+	ErrNoPreviousIndex = errors.New("no previous index version to roll back to")
+)
+
+// IndexVersion versioned bank accounts index.
+type IndexVersion struct {
+	Name      string `json:"name"`
+	Version   int    `json:"version"`
+	Documents int64  `json:"documents"`
+	Current   bool   `json:"current"`
+}
+
+// IndexStatus indices behind the bank accounts search alias.
+type IndexStatus struct {
+	Alias   string          `json:"alias"`
+	Current []string        `json:"current"`
+	Indices []*IndexVersion `json:"indices"`
+}
+
+type indexVersion struct {
+	name    string
+	version int
+}
+
+// EnsureIndex creates the first index version behind the alias if search has no index yet.
+// Legacy concrete index named as the alias is kept until the first recreate replay replaces it.
+func (s *ReplayService) EnsureIndex(ctx context.Context) error {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	exists, err := s.esRepo.IndexExists(BankAccountIndexName)
+	if err != nil {
+		return errors.Wrap(err, "esRepo.IndexExists")
+	}
+	if exists {
+		return nil
+	}
+
+	indexName, err := s.nextIndexName()
+	if err != nil {
+		return err
+	}
+	if err := s.esRepo.CreateIndex(indexName); err != nil {
+		return errors.Wrap(err, "esRepo.CreateIndex")
+	}
+	if err := s.esRepo.SwapAlias(BankAccountIndexName, indexName); err != nil {
+		return errors.Wrap(err, "esRepo.SwapAlias")
+	}
+
+	s.logger.Info("Elasticsearch index created", zap.String("alias", BankAccountIndexName), zap.String("index", indexName))
+	return nil
+}
+
+// GetIndexStatus returns index versions with document counts and the ones serving search.
+func (s *ReplayService) GetIndexStatus(ctx context.Context) (*IndexStatus, error) {
+	current, err := s.esRepo.GetAliasIndices(BankAccountIndexName)
+	if err != nil {
+		return nil, errors.Wrap(err, "esRepo.GetAliasIndices")
+	}
+
+	versions, err := s.listIndexVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &IndexStatus{
+		Alias:   BankAccountIndexName,
+		Current: current,
+		Indices: make([]*IndexVersion, 0, len(versions)),
+	}
+	for _, version := range versions {
+		documents, err := s.esRepo.CountDocuments(version.name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "esRepo.CountDocuments index: %s", version.name)
+		}
+		status.Indices = append(status.Indices, &IndexVersion{
+			Name:      version.name,
+			Version:   version.version,
+			Documents: documents,
+			Current:   slices.Contains(current, version.name),
+		})
+	}
+	return status, nil
+}
+
+// RollbackIndex points search back to the previous index version. The previous index is caught up
+// with events projected since it was replaced, so it is served complete.
+func (s *ReplayService) RollbackIndex(ctx context.Context) (*IndexStatus, error) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	current, err := s.esRepo.GetAliasIndices(BankAccountIndexName)
+	if err != nil {
+		return nil, errors.Wrap(err, "esRepo.GetAliasIndices")
+	}
+
+	versions, err := s.listIndexVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	currentVersion := -1
+	for _, version := range versions {
+		if slices.Contains(current, version.name) {
+			currentVersion = version.version
+		}
+	}
+
+	var previous *indexVersion
+	for i := range versions {
+		if currentVersion >= 0 && versions[i].version < currentVersion {
+			previous = &versions[i]
+		}
+	}
+	if previous == nil {
+		return nil, errors.Wrapf(ErrNoPreviousIndex, "current: %v", current)
+	}
+
+	resume, err := s.pauseProjection(s.runner)
+	if err != nil {
+		return nil, err
+	}
+	defer resume()
+
+	runner := s.newIndexRunner(previous.name)
+	stats, err := runner.CatchUp(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "catch up index: %s", previous.name)
+	}
+	if stats.Failed > 0 {
+		return nil, errors.Wrapf(ErrReplayValidation, "index: %s, failed events: %d", previous.name, stats.Failed)
+	}
+
+	if err := s.switchIndex(ctx, current, runner, previous.name, stats.Position); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Elasticsearch index rolled back", zap.Strings("from", current), zap.String("to", previous.name))
+	return s.GetIndexStatus(ctx)
+}
+
+// reindex builds the job target index from the event store and switches search to it.
+func (s *ReplayService) reindex(ctx context.Context, job *es.ReplayJob, progress *replayProgress) error {
+	if job.TargetIndex == "" {
+		if err := s.createTargetIndex(ctx, job); err != nil {
+			return err
+		}
+	} else {
+		exists, err := s.esRepo.IndexExists(job.TargetIndex)
+		if err != nil {
+			return errors.Wrap(err, "esRepo.IndexExists")
+		}
+		if !exists {
+			return errors.Wrapf(ErrReplayTargetNotFound, "index: %s", job.TargetIndex)
+		}
+	}
+
+	// the target index has its own checkpoints, a resumed job continues from them
+	runner := s.newIndexRunner(job.TargetIndex)
+	if _, err := runner.CatchUp(ctx, progress.update); err != nil {
+		return errors.Wrapf(err, "build index: %s", job.TargetIndex)
+	}
+	progress.commit()
+
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	current, err := s.esRepo.GetAliasIndices(BankAccountIndexName)
+	if err != nil {
+		return errors.Wrap(err, "esRepo.GetAliasIndices")
+	}
+
+	// live projection writes to the current index until the switch, it is paused
+	// so the events it has not applied yet are caught up by the new index
+	resume, err := s.pauseProjection(s.runner)
+	if err != nil {
+		return err
+	}
+	defer resume()
+
+	stats, err := runner.CatchUp(ctx, progress.update)
+	if err != nil {
+		return errors.Wrapf(err, "catch up index: %s", job.TargetIndex)
+	}
+	if stats.Failed > 0 {
+		return errors.Wrapf(ErrReplayValidation, "index: %s, failed events: %d", job.TargetIndex, stats.Failed)
+	}
+
+	if err := s.switchIndex(ctx, current, runner, job.TargetIndex, stats.Position); err != nil {
+		return err
+	}
+
+	s.logger.Info("Elasticsearch index swapped", zap.Strings("from", current), zap.String("to", job.TargetIndex))
+	s.cleanupIndexVersions(ctx, current)
+	return nil
+}
+
+func (s *ReplayService) createTargetIndex(ctx context.Context, job *es.ReplayJob) error {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	indexName, err := s.nextIndexName()
+	if err != nil {
+		return err
+	}
+	if err := s.esRepo.CreateIndex(indexName); err != nil {
+		return errors.Wrap(err, "esRepo.CreateIndex")
+	}
+
+	job.TargetIndex = indexName
+	if err := s.jobStore.Update(ctx, job); err != nil {
+		return errors.Wrap(err, "jobStore.Update")
+	}
+
+	s.logger.Info("Replay job target index created", zap.String("job_id", job.ID), zap.String("index", indexName))
+	return nil
+}
+
+// switchIndex validates the index and points the alias to it, live projection must be paused.
+// Checkpoints follow the indices: the replaced index keeps the live checkpoints for rollback
+// and the live projection continues from the checkpoints of the new index.
+func (s *ReplayService) switchIndex(ctx context.Context, current []string, runner *es.ProjectionRunner, indexName string, position uint64) error {
+	if err := s.validateIndex(ctx, indexName, position); err != nil {
+		return err
+	}
+
+	for _, index := range current {
+		if !strings.HasPrefix(index, bankAccountIndexVersionPrefix) {
+			continue
+		}
+		if err := s.checkpoints.CopyCheckpoints(ctx, s.runner.Name(), s.newIndexRunner(index).Name()); err != nil {
+			return errors.Wrapf(err, "checkpoints.CopyCheckpoints index: %s", index)
+		}
+	}
+
+	if err := s.esRepo.SwapAlias(BankAccountIndexName, indexName); err != nil {
+		return errors.Wrap(err, "esRepo.SwapAlias")
+	}
+
+	if err := s.checkpoints.CopyCheckpoints(ctx, runner.Name(), s.runner.Name()); err != nil {
+		return errors.Wrap(err, "checkpoints.CopyCheckpoints")
+	}
+	return nil
+}
+
+// validateIndex checks that every bank account up to position has a document in the index.
+func (s *ReplayService) validateIndex(ctx context.Context, indexName string, position uint64) error {
+	documents, err := s.esRepo.CountDocuments(indexName)
+	if err != nil {
+		return errors.Wrap(err, "esRepo.CountDocuments")
+	}
+	return s.validateCount(ctx, indexName, documents, position)
+}
+
+// cleanupIndexVersions deletes index versions older than the replaced ones, which are kept for rollback.
+func (s *ReplayService) cleanupIndexVersions(ctx context.Context, replaced []string) {
+	versions, err := s.listIndexVersions()
+	if err != nil {
+		s.logger.Warn("Failed to list index versions for cleanup", zap.Error(err))
+		return
+	}
+
+	keep := -1
+	for _, version := range versions {
+		if slices.Contains(replaced, version.name) && (keep < 0 || version.version < keep) {
+			keep = version.version
+		}
+	}
+
+	for _, version := range versions {
+		if keep < 0 || version.version >= keep {
+			continue
+		}
+		if err := s.deleteIndexVersion(ctx, version.name); err != nil {
+			s.logger.Warn("Failed to delete old index version", zap.String("index", version.name), zap.Error(err))
+		}
+	}
+}
+
+func (s *ReplayService) deleteIndexVersion(ctx context.Context, indexName string) error {
+	if err := s.esRepo.DeleteIndex(indexName); err != nil {
+		return errors.Wrapf(err, "esRepo.DeleteIndex index: %s", indexName)
+	}
+	if err := s.checkpoints.ResetCheckpoints(ctx, s.newIndexRunner(indexName).Name()); err != nil {
+		return errors.Wrapf(err, "checkpoints.ResetCheckpoints index: %s", indexName)
+	}
+	s.logger.Info("Old index version deleted", zap.String("index", indexName))
+	return nil
+}
+
+func (s *ReplayService) nextIndexName() (string, error) {
+	versions, err := s.listIndexVersions()
+	if err != nil {
+		return "", err
+	}
+
+	next := 1
+	if len(versions) > 0 {
+		next = versions[len(versions)-1].version + 1
+	}
+	return fmt.Sprintf("%s%d", bankAccountIndexVersionPrefix, next), nil
+}
+
+// listIndexVersions returns versioned indices ordered by version.
+func (s *ReplayService) listIndexVersions() ([]indexVersion, error) {
+	indices, err := s.esRepo.ListIndices(bankAccountIndexVersionPrefix + "*")
+	if err != nil {
+		return nil, errors.Wrap(err, "esRepo.ListIndices")
+	}
+
+	versions := make([]indexVersion, 0, len(indices))
+	for _, index := range indices {
+		version, err := strconv.Atoi(strings.TrimPrefix(index, bankAccountIndexVersionPrefix))
+		if err != nil {
+			continue
+		}
+		versions = append(versions, indexVersion{name: index, version: version})
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].version < versions[j].version
+	})
+	return versions, nil
+}
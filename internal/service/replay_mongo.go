@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+const (
+	bankAccountsShadowCollection   = domain.BankAccountsCollection + "_shadow"
+	bankAccountsPreviousCollection = domain.BankAccountsCollection + "_previous"
+)
+
+// rebuildMongo rebuilds bank accounts into a shadow collection while the live collection keeps serving
+// reads, then pauses the live projection, catches the shadow up, verifies counts and swaps collections.
+// The replaced collection is kept as bank_accounts_previous. The live consumer resumes from its
+// uncommitted offset and skips events already in the new collection by its checkpoints.
+func (s *ReplayService) rebuildMongo(ctx context.Context, job *es.ReplayJob, progress *replayProgress) error {
+	shadow := s.mongoRepo.WithCollection(bankAccountsShadowCollection)
+	runner := s.newCollRunner(bankAccountsShadowCollection)
+
+	if job.TargetIndex == "" {
+		if err := s.createShadowCollection(ctx, shadow, runner); err != nil {
+			return err
+		}
+		job.TargetIndex = bankAccountsShadowCollection
+		if err := s.jobStore.Update(ctx, job); err != nil {
+			return errors.Wrap(err, "jobStore.Update")
+		}
+	} else {
+		exists, err := shadow.Exists(ctx)
+		if err != nil {
+			return errors.Wrap(err, "shadow.Exists")
+		}
+		if !exists {
+			return errors.Wrapf(ErrReplayTargetNotFound, "collection: %s", bankAccountsShadowCollection)
+		}
+	}
+
+	// the shadow collection has its own checkpoints, a resumed job continues from them
+	if _, err := runner.CatchUp(ctx, progress.update); err != nil {
+		return errors.Wrapf(err, "build collection: %s", bankAccountsShadowCollection)
+	}
+	progress.commit()
+
+	resume, err := s.pauseProjection(s.mongoRunner)
+	if err != nil {
+		return err
+	}
+	defer resume()
+
+	stats, err := runner.CatchUp(ctx, progress.update)
+	if err != nil {
+		return errors.Wrapf(err, "catch up collection: %s", bankAccountsShadowCollection)
+	}
+	if stats.Failed > 0 {
+		return errors.Wrapf(ErrReplayValidation, "collection: %s, failed events: %d", bankAccountsShadowCollection, stats.Failed)
+	}
+
+	count, err := shadow.Count(ctx)
+	if err != nil {
+		return errors.Wrap(err, "shadow.Count")
+	}
+	if err := s.validateCount(ctx, bankAccountsShadowCollection, count, stats.Position); err != nil {
+		return err
+	}
+
+	if err := s.swapMongoCollections(ctx, shadow); err != nil {
+		return err
+	}
+
+	// live projection continues from the checkpoints of the rebuilt collection
+	if err := s.checkpoints.CopyCheckpoints(ctx, runner.Name(), s.mongoRunner.Name()); err != nil {
+		return errors.Wrap(err, "checkpoints.CopyCheckpoints")
+	}
+	if err := s.checkpoints.ResetCheckpoints(ctx, runner.Name()); err != nil {
+		s.logger.Warn("Failed to reset shadow collection checkpoints", zap.Error(err))
+	}
+
+	s.logger.Info("MongoDB collection rebuilt",
+		zap.String("collection", domain.BankAccountsCollection),
+		zap.String("previous", bankAccountsPreviousCollection),
+		zap.Int64("documents", count))
+	return nil
+}
+
+func (s *ReplayService) createShadowCollection(ctx context.Context, shadow domain.MongoRepository, runner *es.ProjectionRunner) error {
+	if err := shadow.Drop(ctx); err != nil {
+		return errors.Wrap(err, "shadow.Drop")
+	}
+	if err := s.checkpoints.ResetCheckpoints(ctx, runner.Name()); err != nil {
+		return errors.Wrap(err, "checkpoints.ResetCheckpoints")
+	}
+	if err := shadow.EnsureCollection(ctx); err != nil {
+		return errors.Wrap(err, "shadow.EnsureCollection")
+	}
+	return nil
+}
+
+// swapMongoCollections keeps the live collection as previous and renames shadow to live.
+// MongoDB has no collection aliases, reads between the two renames find no collection.
+func (s *ReplayService) swapMongoCollections(ctx context.Context, shadow domain.MongoRepository) error {
+	exists, err := s.mongoRepo.Exists(ctx)
+	if err != nil {
+		return errors.Wrap(err, "mongoRepo.Exists")
+	}
+	if exists {
+		if err := s.mongoRepo.RenameTo(ctx, bankAccountsPreviousCollection); err != nil {
+			return errors.Wrap(err, "mongoRepo.RenameTo previous")
+		}
+	}
+
+	if err := shadow.RenameTo(ctx, domain.BankAccountsCollection); err != nil {
+		return errors.Wrap(err, "shadow.RenameTo live")
+	}
+	return nil
+}
@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"slices"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 	"github.com/th1enq/es-demo/internal/domain"
-	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/internal/projection"
 	"github.com/th1enq/es-demo/pkg/es"
 	"go.uber.org/zap"
 )
@@ -16,183 +19,538 @@ const (
 	BankAccountIndexName = "bank_accounts"
 )
 
-// ReplayService handles replaying events from PostgreSQL to Elasticsearch
+const (
+	replayJobsListLimit      = 20
+	selectiveReplayBatchSize = 100
+	// pendingReplayPollInterval how often the service looks for jobs enqueued by esctl
+	pendingReplayPollInterval = 5 * time.Second
+	// replayJobHeartbeatInterval how often the owner of running jobs refreshes their heartbeat
+	replayJobHeartbeatInterval = 10 * time.Second
+	// replayJobHeartbeatTimeout after which a running job is considered abandoned by its owner
+	replayJobHeartbeatTimeout = 6 * replayJobHeartbeatInterval
+)
+
+// ReplayTarget read model replay writes to.
+type ReplayTarget string
+
+const (
+	ReplayTargetElasticsearch ReplayTarget = "elasticsearch"
+	ReplayTargetMongo         ReplayTarget = "mongo"
+)
+
+// FeatureReplay health feature of replays into Elasticsearch, disabled while the cluster is unhealthy.
+const FeatureReplay = "replay"
+
+// FeatureChecker reports whether the dependencies of the feature are healthy, see health.Registry.
+type FeatureChecker interface {
+	CheckFeature(ctx context.Context, feature string) error
+}
+
+var (
+	ErrInvalidReplayTarget  = errors.New("invalid replay target")
+	ErrInvalidReplayFilter  = errors.New("invalid replay filter")
+	ErrReplayValidation     = errors.New("replay validation failed")
+	ErrReplayTargetNotFound = errors.New("replay job target no longer exists, start a new replay")
+	ErrReplayUnavailable    = errors.New("replay is disabled while its dependencies are unhealthy")
+
+	errReplayJobCancelled   = errors.New("replay job cancelled")
+	errReplayJobInterrupted = errors.New("replay job interrupted by shutdown")
+)
+
+// IndexRunnerFactory creates projection runner writing to the concrete index, runners of the same index
+// share checkpoints so an interrupted build continues where it stopped.
+type IndexRunnerFactory func(indexName string) *es.ProjectionRunner
+
+// CollectionRunnerFactory creates projection runner writing to the MongoDB collection.
+type CollectionRunnerFactory func(collection string) *es.ProjectionRunner
+
+// ReplayService handles replaying events from PostgreSQL to Elasticsearch and MongoDB as background jobs.
+// Search reads the BankAccountIndexName alias, recreate jobs build a new versioned index and
+// switch the alias to it only after validation, so search is never empty during replay.
+// Jobs are owned by the instance running them, instances share the job store and interrupt only
+// the jobs whose owner stopped sending heartbeats.
 type ReplayService struct {
-	eventStore es.EventStore
-	esRepo     domain.ElasticsearchRepository
-	serializer es.Serializer
-	logger     *zap.Logger
+	runner         *es.ProjectionRunner
+	mongoRunner    *es.ProjectionRunner
+	newIndexRunner IndexRunnerFactory
+	newCollRunner  CollectionRunnerFactory
+	eventStore     es.EventStore
+	checkpoints    es.CheckpointStore
+	jobStore       es.ReplayJobStore
+	esRepo         domain.ElasticsearchRepository
+	mongoRepo      domain.MongoRepository
+	features       FeatureChecker
+	instanceID     string
+	logger         *zap.Logger
+
+	mu      sync.Mutex
+	running map[string]context.CancelCauseFunc
+	wg      sync.WaitGroup
+
+	// indexMu serializes alias switches
+	indexMu sync.Mutex
 }
 
 // NewReplayService creates a new replay service
 func NewReplayService(
+	runner *es.ProjectionRunner,
+	mongoRunner *es.ProjectionRunner,
+	newIndexRunner IndexRunnerFactory,
+	newCollRunner CollectionRunnerFactory,
 	eventStore es.EventStore,
+	checkpoints es.CheckpointStore,
+	jobStore es.ReplayJobStore,
 	esRepo domain.ElasticsearchRepository,
-	serializer es.Serializer,
+	mongoRepo domain.MongoRepository,
+	features FeatureChecker,
+	instanceID string,
 	logger *zap.Logger,
 ) *ReplayService {
 	return &ReplayService{
-		eventStore: eventStore,
-		esRepo:     esRepo,
-		serializer: serializer,
-		logger:     logger,
+		runner:         runner,
+		mongoRunner:    mongoRunner,
+		newIndexRunner: newIndexRunner,
+		newCollRunner:  newCollRunner,
+		eventStore:     eventStore,
+		checkpoints:    checkpoints,
+		jobStore:       jobStore,
+		esRepo:         esRepo,
+		mongoRepo:      mongoRepo,
+		features:       features,
+		instanceID:     instanceID,
+		logger:         logger,
+		running:        make(map[string]context.CancelCauseFunc),
 	}
 }
 
-// ReplayResult contains the result of a replay operation
-type ReplayResult struct {
-	TotalEvents      int              `json:"totalEvents"`
-	ProcessedEvents  int              `json:"processedEvents"`
-	CreatedAccounts  int              `json:"createdAccounts"`
-	UpdatedAccounts  int              `json:"updatedAccounts"`
-	Errors           []string         `json:"errors,omitempty"`
-	Duration         time.Duration    `json:"duration"`
-	AccountSummaries []AccountSummary `json:"accountSummaries"`
+// Run heartbeats the jobs of this instance, marks jobs abandoned by stopped instances as interrupted,
+// runs pending jobs when no job is running and on shutdown interrupts running jobs, waiting until
+// their progress is persisted.
+func (s *ReplayService) Run(ctx context.Context) error {
+	if err := s.interruptAbandoned(ctx); err != nil {
+		return err
+	}
+
+	pending := time.NewTicker(pendingReplayPollInterval)
+	defer pending.Stop()
+	heartbeat := time.NewTicker(replayJobHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for done := false; !done; {
+		select {
+		case <-ctx.Done():
+			done = true
+		case <-heartbeat.C:
+			if err := s.heartbeat(ctx); err != nil {
+				s.logger.Error("Failed to heartbeat replay jobs", zap.Error(err))
+			}
+			if err := s.interruptAbandoned(ctx); err != nil {
+				s.logger.Error("Failed to interrupt abandoned replay jobs", zap.Error(err))
+			}
+		case <-pending.C:
+			if err := s.startPending(ctx); err != nil {
+				s.logger.Error("Failed to start pending replay job", zap.Error(err))
+			}
+		}
+	}
+
+	s.mu.Lock()
+	for _, cancel := range s.running {
+		cancel(errReplayJobInterrupted)
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return nil
 }
 
-// AccountSummary provides a summary of each account after replay
-type AccountSummary struct {
-	AggregateID      string    `json:"aggregateId"`
-	Email            string    `json:"email"`
-	FullName         string    `json:"fullName"`
-	Balance          int64     `json:"balance"`
-	TransactionCount int       `json:"transactionCount"`
-	LastActivity     time.Time `json:"lastActivity"`
+// heartbeat refreshes the heartbeat of the jobs running in this instance.
+func (s *ReplayService) heartbeat(ctx context.Context) error {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.running))
+	for id := range s.running {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.jobStore.Heartbeat(ctx, s.instanceID, ids, time.Now().UTC())
 }
 
-// ReplayAllEvents replays all events from PostgreSQL to Elasticsearch
-func (s *ReplayService) ReplayAllEvents(ctx context.Context, recreateIndex bool) (*ReplayResult, error) {
-	startTime := time.Now()
-	result := &ReplayResult{
-		Errors:           make([]string, 0),
-		AccountSummaries: make([]AccountSummary, 0),
+// interruptAbandoned marks running jobs without heartbeat within the timeout as interrupted,
+// jobs of live instances keep running.
+func (s *ReplayService) interruptAbandoned(ctx context.Context) error {
+	count, err := s.jobStore.MarkInterrupted(ctx, time.Now().UTC().Add(-replayJobHeartbeatTimeout))
+	if err != nil {
+		return errors.Wrap(err, "jobStore.MarkInterrupted")
+	}
+	if count > 0 {
+		s.logger.Warn("Replay jobs abandoned by stopped instances interrupted, resume them via API", zap.Int64("count", count))
 	}
+	return nil
+}
 
-	s.logger.Info("Starting event replay to Elasticsearch", zap.Bool("recreate_index", recreateIndex))
+// StartReplay creates replay job of the target read model and runs it in background.
+// With recreateIndex the read model is rebuilt from scratch next to the live one and swapped in:
+// a new versioned index for Elasticsearch, a shadow collection for MongoDB.
+// Otherwise already projected events are skipped.
+func (s *ReplayService) StartReplay(ctx context.Context, target ReplayTarget, recreateIndex bool) (*es.ReplayJob, error) {
+	runner, err := s.targetRunner(target)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAvailable(ctx, runner.Name()); err != nil {
+		return nil, err
+	}
 
-	// Recreate index if requested
-	if recreateIndex {
-		if err := s.esRepo.DeleteIndex(BankAccountIndexName); err != nil {
-			s.logger.Warn("Failed to delete existing index (may not exist)", zap.Error(err))
-		}
+	job := newReplayJob(runner.Name())
+	job.Recreate = recreateIndex
 
-		if err := s.esRepo.CreateIndex(BankAccountIndexName); err != nil {
-			s.logger.Error("Failed to create index", zap.Error(err))
-			return nil, errors.Wrap(err, "failed to create index")
-		}
-		s.logger.Info("Created new Elasticsearch index", zap.String("index", BankAccountIndexName))
+	if err := s.start(ctx, job, s.jobStore.Create); err != nil {
+		return nil, err
 	}
 
-	// Get all events from event store
-	allEvents, err := s.eventStore.GetAllEvents(ctx)
+	s.logger.Info("Replay job started", zap.String("job_id", job.ID), zap.String("target", string(target)), zap.Bool("recreate_index", recreateIndex))
+	return job, nil
+}
+
+// StartSelectiveReplay creates job rebuilding only the streams matching the filter in the target read model,
+// every matched account is rebuilt from its full event stream.
+func (s *ReplayService) StartSelectiveReplay(ctx context.Context, target ReplayTarget, filter es.StreamFilter) (*es.ReplayJob, error) {
+	runner, err := s.targetRunner(target)
 	if err != nil {
-		s.logger.Error("Failed to get all events", zap.Error(err))
-		return nil, errors.Wrap(err, "failed to get all events")
+		return nil, err
+	}
+
+	if err := ValidateReplayFilter(filter); err != nil {
+		return nil, err
+	}
+	if err := s.checkAvailable(ctx, runner.Name()); err != nil {
+		return nil, err
 	}
 
-	result.TotalEvents = len(allEvents)
-	s.logger.Info("Retrieved all events for replay", zap.Int("count", result.TotalEvents))
+	job := newReplayJob(runner.Name())
+	job.Filter = &filter
 
-	// Group events by aggregate ID to maintain projection state
-	aggregateProjections := make(map[string]*domain.BankAccountElasticsearchProjection)
+	if err := s.start(ctx, job, s.jobStore.Create); err != nil {
+		return nil, err
+	}
 
-	// Process events in order
-	for _, event := range allEvents {
-		if err := s.processEvent(ctx, event, aggregateProjections); err != nil {
-			errMsg := fmt.Sprintf("Failed to process event %s for aggregate %s: %v", event.EventID, event.AggregateID, err)
-			result.Errors = append(result.Errors, errMsg)
-			s.logger.Error("Failed to process event",
-				zap.String("event_id", event.EventID),
-				zap.String("aggregate_id", event.AggregateID),
-				zap.Error(err))
-			continue
+	s.logger.Info("Selective replay job started", zap.String("job_id", job.ID), zap.String("target", string(target)), zap.Any("filter", filter))
+	return job, nil
+}
+
+// EnqueueReplay persists pending replay job of the target read model for processes without the projections,
+// the service runs pending jobs in creation order once no other job is running. A job with filter is selective.
+func EnqueueReplay(ctx context.Context, jobStore es.ReplayJobStore, target ReplayTarget, recreateIndex bool, filter *es.StreamFilter) (*es.ReplayJob, error) {
+	var name string
+	switch target {
+	case ReplayTargetElasticsearch, "":
+		name = projection.BankAccountElasticsearchProjectionName
+	case ReplayTargetMongo:
+		name = projection.BankAccountMongoProjectionName
+	default:
+		return nil, errors.Wrapf(ErrInvalidReplayTarget, "target: %s", target)
+	}
+
+	if filter != nil {
+		if recreateIndex {
+			return nil, errors.Wrap(ErrInvalidReplayFilter, "selective replay rebuilds streams in the live read model, it cannot recreate it")
 		}
-		result.ProcessedEvents++
-	}
-
-	// Index all projections to Elasticsearch
-	documents := make(map[string]interface{})
-	for aggregateID, projection := range aggregateProjections {
-		documents[aggregateID] = projection
-
-		// Create account summary
-		summary := AccountSummary{
-			AggregateID:      projection.AggregateID,
-			Email:            projection.Email,
-			FullName:         projection.GetFullName(),
-			Balance:          projection.Balance.Amount,
-			TransactionCount: projection.TransactionCount,
-			LastActivity:     projection.LastActivity,
+		if err := ValidateReplayFilter(*filter); err != nil {
+			return nil, err
 		}
-		result.AccountSummaries = append(result.AccountSummaries, summary)
 	}
 
-	if len(documents) > 0 {
-		if err := s.esRepo.BulkIndex(BankAccountIndexName, documents); err != nil {
-			s.logger.Error("Failed to bulk index documents", zap.Error(err))
-			return nil, errors.Wrap(err, "failed to bulk index documents")
-		}
-		s.logger.Info("Successfully indexed all projections", zap.Int("count", len(documents)))
+	job := newReplayJob(name)
+	job.Status = es.ReplayJobStatusPending
+	job.StartedAt = nil
+	job.Recreate = recreateIndex
+	job.Filter = filter
+
+	if err := jobStore.Create(ctx, job); err != nil {
+		return nil, errors.Wrap(err, "jobStore.Create")
 	}
+	return job, nil
+}
 
-	result.CreatedAccounts = len(aggregateProjections)
-	result.Duration = time.Since(startTime)
+// ValidateReplayFilter checks that the filter of selective replay selects streams and its windows are ordered.
+func ValidateReplayFilter(filter es.StreamFilter) error {
+	if filter.IsEmpty() {
+		return errors.Wrap(ErrInvalidReplayFilter, "filter is empty, use full replay instead")
+	}
+	if filter.ToPosition > 0 && filter.ToPosition < filter.FromPosition {
+		return errors.Wrapf(ErrInvalidReplayFilter, "toPosition %d is before fromPosition %d", filter.ToPosition, filter.FromPosition)
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return errors.Wrap(ErrInvalidReplayFilter, "to is before from")
+	}
+	return nil
+}
 
-	s.logger.Info("Event replay completed successfully",
-		zap.Int("total_events", result.TotalEvents),
-		zap.Int("processed_events", result.ProcessedEvents),
-		zap.Int("created_accounts", result.CreatedAccounts),
-		zap.Duration("duration", result.Duration))
+func newReplayJob(projection string) *es.ReplayJob {
+	now := time.Now().UTC()
+	return &es.ReplayJob{
+		ID:         uuid.NewV4().String(),
+		Projection: projection,
+		Status:     es.ReplayJobStatusRunning,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		StartedAt:  &now,
+	}
+}
+
+func (s *ReplayService) targetRunner(target ReplayTarget) (*es.ProjectionRunner, error) {
+	switch target {
+	case ReplayTargetElasticsearch, "":
+		return s.runner, nil
+	case ReplayTargetMongo:
+		return s.mongoRunner, nil
+	default:
+		return nil, errors.Wrapf(ErrInvalidReplayTarget, "target: %s", target)
+	}
+}
 
-	return result, nil
+// checkAvailable rejects replays into Elasticsearch while the health checks of the replay feature fail,
+// the readiness report lists the feature as disabled at the same time.
+func (s *ReplayService) checkAvailable(ctx context.Context, projection string) error {
+	if projection != s.runner.Name() {
+		return nil
+	}
+	if err := s.features.CheckFeature(ctx, FeatureReplay); err != nil {
+		return errors.Wrap(ErrReplayUnavailable, err.Error())
+	}
+	return nil
 }
 
-// processEvent processes a single event and updates the appropriate projection
-func (s *ReplayService) processEvent(ctx context.Context, event es.Event, projections map[string]*domain.BankAccountElasticsearchProjection) error {
-	// Get or create projection for this aggregate
-	projection, exists := projections[event.AggregateID]
-	if !exists {
-		projection = domain.NewBankAccountElasticsearchProjection(event.AggregateID)
-		projections[event.AggregateID] = projection
+// projectionRunner returns live runner of the job projection.
+func (s *ReplayService) projectionRunner(projection string) (*es.ProjectionRunner, error) {
+	for _, runner := range []*es.ProjectionRunner{s.runner, s.mongoRunner} {
+		if runner.Name() == projection {
+			return runner, nil
+		}
 	}
+	return nil, errors.Wrapf(es.ErrProjectionNotFound, "name: %s", projection)
+}
 
-	// Deserialize the event
-	deserializedEvent, err := s.serializer.DeserializeEvent(event)
+// ResumeReplay continues cancelled, interrupted or failed job from its last persisted position.
+func (s *ReplayService) ResumeReplay(ctx context.Context, id string) (*es.ReplayJob, error) {
+	job, err := s.jobStore.Get(ctx, id)
 	if err != nil {
-		return errors.Wrap(err, "failed to deserialize event")
+		return nil, err
+	}
+
+	if job.IsActive() {
+		return nil, errors.Wrapf(es.ErrReplayJobRunning, "id: %s, status: %s", id, job.Status)
+	}
+	if !job.IsResumable() {
+		return nil, errors.Wrapf(es.ErrReplayJobFinished, "id: %s", id)
+	}
+	if err := s.checkAvailable(ctx, job.Projection); err != nil {
+		return nil, err
 	}
 
-	// Apply event to projection based on event type
-	switch event.EventType {
-	case events.BankAccountCreatedEventTypeV1:
-		if createEvent, ok := deserializedEvent.(*events.BankAccountCreatedEventV1); ok {
-			projection.WhenBankAccountCreated(*createEvent, event.AggregateID, event.Version, event.Timestamp)
-		} else {
-			return errors.New("failed to cast to BankAccountCreatedEventV1")
+	now := time.Now().UTC()
+	job.Status = es.ReplayJobStatusRunning
+	job.Error = ""
+	job.UpdatedAt = now
+	job.FinishedAt = nil
+
+	if err := s.start(ctx, job, s.jobStore.Update); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Replay job resumed", zap.String("job_id", job.ID), zap.Uint64("position", job.Position))
+	return job, nil
+}
+
+// CancelReplay stops running job, its progress is kept so it can be resumed.
+func (s *ReplayService) CancelReplay(ctx context.Context, id string) (*es.ReplayJob, error) {
+	s.mu.Lock()
+	cancel, ok := s.running[id]
+	s.mu.Unlock()
+
+	if !ok {
+		job, err := s.jobStore.Get(ctx, id)
+		if err != nil {
+			return nil, err
 		}
+		return nil, errors.Wrapf(es.ErrReplayJobNotRunning, "id: %s, status: %s", id, job.Status)
+	}
+
+	cancel(errReplayJobCancelled)
+	s.logger.Info("Replay job cancel requested", zap.String("job_id", id))
+
+	return s.jobStore.Get(ctx, id)
+}
+
+// GetReplayJob returns job with persisted progress.
+func (s *ReplayService) GetReplayJob(ctx context.Context, id string) (*es.ReplayJob, error) {
+	return s.jobStore.Get(ctx, id)
+}
+
+// ListReplayJobs returns latest jobs.
+func (s *ReplayService) ListReplayJobs(ctx context.Context) ([]*es.ReplayJob, error) {
+	return s.jobStore.List(ctx, replayJobsListLimit)
+}
+
+// start persists job and launches it, only one replay job runs at a time.
+func (s *ReplayService) start(ctx context.Context, job *es.ReplayJob, persist func(ctx context.Context, job *es.ReplayJob) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	case events.BalancedDepositedEventTypeV1:
-		if depositEvent, ok := deserializedEvent.(*events.BalanceDepositedEventV1); ok {
-			projection.WhenBalanceDeposited(*depositEvent, event.Version, event.Timestamp)
-		} else {
-			return errors.New("failed to cast to BalanceDepositedEventV1")
+	if len(s.running) > 0 {
+		return es.ErrReplayJobRunning
+	}
+	return s.launch(ctx, job, persist)
+}
+
+// startPending claims the oldest pending job and launches it unless a job is running,
+// a job of unknown projection is failed.
+func (s *ReplayService) startPending(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.running) > 0 {
+		return nil
+	}
+
+	job, err := s.jobStore.ClaimPending(ctx, s.instanceID, time.Now().UTC())
+	if err != nil {
+		return errors.Wrap(err, "jobStore.ClaimPending")
+	}
+	if job == nil {
+		return nil
+	}
+
+	if err := s.launch(ctx, job, s.jobStore.Update); err != nil {
+		now := time.Now().UTC()
+		job.Status = es.ReplayJobStatusFailed
+		job.Error = err.Error()
+		job.UpdatedAt = now
+		job.FinishedAt = &now
+		if updateErr := s.jobStore.Update(ctx, job); updateErr != nil {
+			s.logger.Error("Failed to persist replay job", zap.String("job_id", job.ID), zap.Error(updateErr))
 		}
+		return errors.Wrapf(err, "job: %s", job.ID)
+	}
+
+	s.logger.Info("Pending replay job started", zap.String("job_id", job.ID), zap.String("projection", job.Projection), zap.Bool("selective", job.IsSelective()))
+	return nil
+}
+
+// launch persists job and runs it in background, the caller holds mu.
+func (s *ReplayService) launch(ctx context.Context, job *es.ReplayJob, persist func(ctx context.Context, job *es.ReplayJob) error) error {
+	runner, err := s.projectionRunner(job.Projection)
+	if err != nil {
+		return err
+	}
+
+	if status, err := runner.Status(ctx); err == nil {
+		job.HeadPosition = status.HeadPosition
+	}
+
+	now := time.Now().UTC()
+	job.Owner = s.instanceID
+	job.HeartbeatAt = &now
+
+	if err := persist(ctx, job); err != nil {
+		return errors.Wrap(err, "persist replay job")
+	}
+
+	// job outlives the request, it is stopped by cancel or application shutdown
+	jobCtx, cancel := context.WithCancelCause(context.Background())
+	s.running[job.ID] = cancel
+	s.wg.Add(1)
+
+	go s.execute(jobCtx, runner, job)
+	return nil
+}
 
-	case events.BalanceWithdrawedEventTypeV1:
-		if withdrawEvent, ok := deserializedEvent.(*events.BalanceWithdrawedEventV1); ok {
-			projection.WhenBalanceWithdrawn(*withdrawEvent, event.Version, event.Timestamp)
-		} else {
-			return errors.New("failed to cast to BalanceWithdrawedEventV1")
+func (s *ReplayService) execute(ctx context.Context, runner *es.ProjectionRunner, job *es.ReplayJob) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, job.ID)
+		s.mu.Unlock()
+	}()
+
+	progress := newReplayProgress(job, s.jobStore)
+
+	var err error
+	switch {
+	case job.IsSelective():
+		err = s.replayStreams(ctx, runner, job, progress)
+	case runner == s.mongoRunner && job.Recreate:
+		err = s.rebuildMongo(ctx, job, progress)
+	case runner == s.mongoRunner:
+		_, err = runner.Replay(ctx, job.Position, progress.update)
+	case job.Recreate:
+		err = s.reindex(ctx, job, progress)
+	default:
+		if err = s.EnsureIndex(ctx); err == nil {
+			_, err = runner.Replay(ctx, job.Position, progress.update)
 		}
+	}
 
+	now := time.Now().UTC()
+	job.UpdatedAt = now
+	switch {
+	case err == nil:
+		job.Status = es.ReplayJobStatusCompleted
+		job.FinishedAt = &now
+	case errors.Is(context.Cause(ctx), errReplayJobCancelled):
+		job.Status = es.ReplayJobStatusCancelled
+	case errors.Is(context.Cause(ctx), errReplayJobInterrupted):
+		job.Status = es.ReplayJobStatusInterrupted
 	default:
-		s.logger.Warn("Unknown event type encountered during replay",
-			zap.String("event_type", string(event.EventType)),
-			zap.String("aggregate_id", event.AggregateID))
-		return nil // Skip unknown events, don't fail the entire replay
+		job.Status = es.ReplayJobStatusFailed
+		job.Error = err.Error()
 	}
 
-	return nil
+	if err := s.jobStore.Update(context.WithoutCancel(ctx), job); err != nil {
+		s.logger.Error("Failed to persist replay job", zap.String("job_id", job.ID), zap.Error(err))
+	}
+
+	s.logger.Info("Replay job finished",
+		zap.String("job_id", job.ID),
+		zap.String("status", string(job.Status)),
+		zap.Uint64("position", job.Position),
+		zap.Int("processed_events", job.Processed),
+		zap.Int("skipped_events", job.Skipped),
+		zap.Int("failed_events", job.Failed))
+}
+
+// replayStreams rebuilds streams matching the job filter in batches ordered by aggregate id,
+// the cursor is persisted after every batch so the job resumes after the last rebuilt stream.
+func (s *ReplayService) replayStreams(ctx context.Context, runner *es.ProjectionRunner, job *es.ReplayJob, progress *replayProgress) error {
+	for {
+		aggregateIDs, err := s.eventStore.LoadAggregateIDs(ctx, *job.Filter, job.Cursor, selectiveReplayBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "eventStore.LoadAggregateIDs")
+		}
+		if len(aggregateIDs) == 0 {
+			return nil
+		}
+
+		stats, err := runner.RebuildStreams(ctx, aggregateIDs)
+		if err != nil {
+			return errors.Wrap(err, "runner.RebuildStreams")
+		}
+
+		job.Cursor = aggregateIDs[len(aggregateIDs)-1]
+		stats.Position = job.Position
+		if err := progress.update(ctx, stats); err != nil {
+			return errors.Wrap(err, "replay progress")
+		}
+		progress.commit()
+
+		if len(aggregateIDs) < selectiveReplayBatchSize {
+			return nil
+		}
+	}
 }
 
 // GetAccountByID retrieves a bank account from Elasticsearch
@@ -219,6 +577,18 @@ func (s *ReplayService) SearchAccounts(ctx context.Context, query map[string]int
 	return projections, nil
 }
 
+// CurrencySummary money totals of accounts held in the currency, amounts are in minor units.
+type CurrencySummary struct {
+	Currency         string  `json:"currency"`
+	Accounts         int     `json:"accounts"`
+	TotalBalance     int64   `json:"totalBalance"`
+	TotalDeposits    int64   `json:"totalDeposits"`
+	TotalWithdrawals int64   `json:"totalWithdrawals"`
+	TotalInterest    int64   `json:"totalInterest"`
+	NetFlow          int64   `json:"netFlow"`
+	AverageBalance   float64 `json:"averageBalance"`
+}
+
 // GetAccountSummary provides analytics summary for accounts
 func (s *ReplayService) GetAccountSummary(ctx context.Context) (map[string]interface{}, error) {
 	// Get all accounts
@@ -234,48 +604,147 @@ func (s *ReplayService) GetAccountSummary(ctx context.Context) (map[string]inter
 		return nil, errors.Wrap(err, "failed to get all accounts")
 	}
 
-	totalBalance := int64(0)
-	totalDeposits := int64(0)
-	totalWithdrawals := int64(0)
+	// money totals are kept per currency, amounts in minor units of different currencies do not add up
+	byCurrency := make(map[string]*CurrencySummary)
 	totalTransactions := 0
 	activeAccounts := 0
 
 	for _, account := range accounts {
-		totalBalance += account.Balance.Amount
-		totalDeposits += account.TotalDeposits
-		totalWithdrawals += account.TotalWithdrawals
+		currencySummary, ok := byCurrency[account.Balance.Currency]
+		if !ok {
+			currencySummary = &CurrencySummary{Currency: account.Balance.Currency}
+			byCurrency[account.Balance.Currency] = currencySummary
+		}
+		currencySummary.Accounts++
+		currencySummary.TotalBalance += account.Balance.Amount
+		currencySummary.TotalDeposits += account.TotalDeposits
+		currencySummary.TotalWithdrawals += account.TotalWithdrawals
+		currencySummary.TotalInterest += account.TotalInterest
+
 		totalTransactions += account.TransactionCount
 		if account.IsActive() {
 			activeAccounts++
 		}
 	}
 
+	currencies := make([]*CurrencySummary, 0, len(byCurrency))
+	for _, currencySummary := range byCurrency {
+		currencySummary.NetFlow = currencySummary.TotalDeposits + currencySummary.TotalInterest - currencySummary.TotalWithdrawals
+		currencySummary.AverageBalance = float64(currencySummary.TotalBalance) / float64(currencySummary.Accounts)
+		currencies = append(currencies, currencySummary)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i].Currency < currencies[j].Currency })
+
 	summary := map[string]interface{}{
 		"totalAccounts":     len(accounts),
 		"activeAccounts":    activeAccounts,
-		"totalBalance":      totalBalance,
-		"totalDeposits":     totalDeposits,
-		"totalWithdrawals":  totalWithdrawals,
 		"totalTransactions": totalTransactions,
-		"netFlow":           totalDeposits - totalWithdrawals,
-		"averageBalance":    float64(totalBalance) / float64(len(accounts)),
+		"currencies":        currencies,
 	}
 
 	return summary, nil
 }
 
-// DeleteElasticsearchIndex deletes the bank accounts index from Elasticsearch
+// DeleteElasticsearchIndex deletes all bank accounts from Elasticsearch together with previous index versions,
+// the live index is kept empty and projection checkpoints are reset so the next replay projects every event.
 func (s *ReplayService) DeleteElasticsearchIndex(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.running) > 0 {
+		return es.ErrReplayJobRunning
+	}
+
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
 	s.logger.Info("Deleting Elasticsearch index", zap.String("index", BankAccountIndexName))
 
-	err := s.esRepo.DeleteIndex(BankAccountIndexName)
-	if err != nil {
+	if err := s.runner.Reset(ctx); err != nil {
 		s.logger.Error("Failed to delete Elasticsearch index",
 			zap.String("index", BankAccountIndexName),
 			zap.Error(err))
 		return errors.Wrap(err, "failed to delete Elasticsearch index")
 	}
 
+	current, err := s.esRepo.GetAliasIndices(BankAccountIndexName)
+	if err != nil {
+		return errors.Wrap(err, "esRepo.GetAliasIndices")
+	}
+
+	versions, err := s.listIndexVersions()
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if slices.Contains(current, version.name) {
+			continue
+		}
+		if err := s.deleteIndexVersion(ctx, version.name); err != nil {
+			return err
+		}
+	}
+
 	s.logger.Info("Successfully deleted Elasticsearch index", zap.String("index", BankAccountIndexName))
 	return nil
 }
+
+// pauseProjection pauses live projection before its read model is swapped,
+// returned func resumes it unless it was paused before.
+func (s *ReplayService) pauseProjection(runner *es.ProjectionRunner) (func(), error) {
+	if runner.State() == es.ProjectionStatePaused {
+		return func() {}, nil
+	}
+
+	if err := runner.Pause(); err != nil {
+		return nil, errors.Wrap(err, "runner.Pause")
+	}
+	return func() {
+		if err := runner.Resume(); err != nil {
+			s.logger.Error("Failed to resume live projection", zap.String("projection", runner.Name()), zap.Error(err))
+		}
+	}, nil
+}
+
+// validateCount checks that every bank account up to position is in the rebuilt read model.
+func (s *ReplayService) validateCount(ctx context.Context, target string, count int64, position uint64) error {
+	expected, err := s.eventStore.CountAggregates(ctx, domain.BankAccountAggregateType, position)
+	if err != nil {
+		return errors.Wrap(err, "eventStore.CountAggregates")
+	}
+
+	if count != expected {
+		return errors.Wrapf(ErrReplayValidation, "target: %s, documents: %d, expected: %d", target, count, expected)
+	}
+	return nil
+}
+
+// replayProgress persists job progress accumulated over several replays of the same job.
+type replayProgress struct {
+	job   *es.ReplayJob
+	base  es.ReplayJob
+	store es.ReplayJobStore
+}
+
+func newReplayProgress(job *es.ReplayJob, store es.ReplayJobStore) *replayProgress {
+	return &replayProgress{job: job, base: *job, store: store}
+}
+
+func (p *replayProgress) update(ctx context.Context, stats *es.ReplayStats) error {
+	job := p.job
+	job.Position = stats.Position
+	job.Total = p.base.Total + stats.Total
+	job.Processed = p.base.Processed + stats.Processed
+	job.Skipped = p.base.Skipped + stats.Skipped
+	job.Failed = p.base.Failed + stats.Failed
+	job.Errors = append(append([]string(nil), p.base.Errors...), stats.Errors...)
+	if job.Position > job.HeadPosition {
+		job.HeadPosition = job.Position
+	}
+	job.UpdatedAt = time.Now().UTC()
+	return p.store.Update(ctx, job)
+}
+
+// commit keeps stats of the finished replay, the next replay of the job adds to them.
+func (p *replayProgress) commit() {
+	p.base = *p.job
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/th1enq/es-demo/pkg/es"
+	"github.com/th1enq/es-demo/pkg/health"
+	"go.uber.org/zap"
+)
+
+// namedProjector only has a name, the replays are rejected before it applies events.
+type namedProjector struct {
+	es.Projector
+	name string
+}
+
+func (p *namedProjector) Name() string {
+	return p.name
+}
+
+// memoryReplayJobStore serves stored jobs, methods not used by the tests are left unimplemented.
+type memoryReplayJobStore struct {
+	es.ReplayJobStore
+	jobs map[string]*es.ReplayJob
+}
+
+func (s *memoryReplayJobStore) Get(ctx context.Context, id string) (*es.ReplayJob, error) {
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, errors.Wrapf(es.ErrReplayJobNotFound, "id: %s", id)
+	}
+	return job, nil
+}
+
+// unhealthyFeatures disables every feature.
+type unhealthyFeatures struct{}
+
+func (unhealthyFeatures) CheckFeature(ctx context.Context, feature string) error {
+	return errors.Wrapf(health.ErrFeatureDisabled, "feature: %s, check: elasticsearch: connection refused", feature)
+}
+
+func TestReplayServiceUnavailable(t *testing.T) {
+	runner := es.NewProjectionRunner(es.ProjectionConfig{ReplayBatchSize: 10}, &namedProjector{name: "bank_account_elasticsearch"}, nil, nil, zap.NewNop())
+	jobs := &memoryReplayJobStore{jobs: map[string]*es.ReplayJob{
+		"job-1": {ID: "job-1", Projection: runner.Name(), Status: es.ReplayJobStatusInterrupted},
+	}}
+	service := NewReplayService(runner, nil, nil, nil, nil, nil, jobs, nil, nil, unhealthyFeatures{}, "instance-1", zap.NewNop())
+	ctx := context.Background()
+
+	_, err := service.StartReplay(ctx, ReplayTargetElasticsearch, false)
+	assert.ErrorIs(t, err, ErrReplayUnavailable)
+
+	_, err = service.StartSelectiveReplay(ctx, ReplayTargetElasticsearch, es.StreamFilter{AggregateIDs: []string{"account-1"}})
+	assert.ErrorIs(t, err, ErrReplayUnavailable)
+
+	_, err = service.ResumeReplay(ctx, "job-1")
+	assert.ErrorIs(t, err, ErrReplayUnavailable)
+	assert.Equal(t, es.ReplayJobStatusInterrupted, jobs.jobs["job-1"].Status, "rejected job is not resumed")
+}
@@ -30,19 +30,35 @@ type BankAccountService struct {
 }
 
 func NewBankAccountService(
+	cfg query.Config,
 	logger *zap.Logger,
 	aggregateStore es.AggregateStore,
 	serializer es.Serializer,
 	mongoRepository domain.MongoRepository,
+	transactionRepository domain.TransactionRepository,
+	emailReservations domain.EmailReservationRepository,
 ) *BankAccountService {
 	bankAccountCommand := command.NewBankAccountCommand(
-		command.NewCreateBankAccountCmdHandler(aggregateStore, logger),
+		command.NewCreateBankAccountCmdHandler(aggregateStore, emailReservations, logger),
 		command.NewDepositeBalanceCmdHandler(aggregateStore, logger),
 		command.NewWithdrawBalanceCmdHandler(aggregateStore, logger),
+		command.NewFreezeAccountCmdHandler(aggregateStore, logger),
+		command.NewUnfreezeAccountCmdHandler(aggregateStore, logger),
+		command.NewCloseAccountCmdHandler(aggregateStore, logger),
+		command.NewChangeWithdrawalPolicyCmdHandler(aggregateStore, logger),
+		command.NewChangeInterestRateCmdHandler(aggregateStore, logger),
+		command.NewPlaceHoldCmdHandler(aggregateStore, logger),
+		command.NewCaptureHoldCmdHandler(aggregateStore, logger),
+		command.NewReleaseHoldCmdHandler(aggregateStore, logger),
+		command.NewReverseTransactionCmdHandler(aggregateStore, serializer, logger),
+		command.NewChangeEmailCmdHandler(aggregateStore, emailReservations, logger),
+		command.NewUpdateProfileCmdHandler(aggregateStore, logger),
+		command.NewChangePasswordCmdHandler(aggregateStore, logger),
 	)
 
 	bankAccountQuery := query.NewBankAccountQuery(
 		query.NewGetBankAccountByIDQuery(
+			cfg,
 			mongoRepository,
 			aggregateStore,
 			logger,
@@ -55,6 +71,10 @@ func NewBankAccountService(
 			aggregateStore,
 			logger,
 		),
+		query.NewGetTransactionsQuery(
+			transactionRepository,
+			logger,
+		),
 		aggregateStore,
 		logger,
 	)
@@ -93,13 +113,15 @@ func (s *BankAccountService) GetBankAccountByEmail(ctx context.Context, email st
 // Helper method to convert projection to domain model
 func (s *BankAccountService) projectionToBankAccount(projection *domain.BankAccountMongoProjection) *domain.BankAccount {
 	return &domain.BankAccount{
-		AggregateID:  projection.AggregateID,
-		Email:        projection.Email,
-		FirstName:    projection.FirstName,
-		LastName:     projection.LastName,
-		PasswordHash: projection.PasswordHash,
-		CreatedAt:    projection.CreatedAt,
-		UpdatedAt:    projection.UpdatedAt,
+		AggregateID:          projection.AggregateID,
+		Email:                projection.Email,
+		FirstName:            projection.FirstName,
+		LastName:             projection.LastName,
+		Status:               projection.Status,
+		PasswordHash:         projection.PasswordHash,
+		CredentialsChangedAt: projection.CredentialsChangedAt,
+		CreatedAt:            projection.CreatedAt,
+		UpdatedAt:            projection.UpdatedAt,
 		// Balance conversion from projection Balance to money.Money will be handled by the domain
 		// For authentication purposes, we mainly need the other fields
 	}
@@ -112,10 +134,12 @@ func (s *BankAccountService) CreateBankAccount(ctx context.Context, req dto.Crea
 		Email:       req.Email,
 		FirstName:   req.FirstName,
 		LastName:    req.LastName,
+		Currency:    req.Currency,
 		Balance:     req.Balance,
 		Password:    req.Password,
 	}
-	return s.Commands.CreateBankAccount.Handle(ctx, createCmd)
+	_, err := s.Commands.CreateBankAccount.Handle(ctx, createCmd)
+	return err
 }
 
 func (s *BankAccountService) DepositBalance(ctx context.Context, id string, amount int64, paymentID string) error {
@@ -124,7 +148,8 @@ func (s *BankAccountService) DepositBalance(ctx context.Context, id string, amou
 		Amount:      amount,
 		PaymentID:   paymentID,
 	}
-	return s.Commands.DepositeBalance.Handle(ctx, depositCmd)
+	_, err := s.Commands.DepositeBalance.Handle(ctx, depositCmd)
+	return err
 }
 
 func (s *BankAccountService) WithdrawBalance(ctx context.Context, id string, amount int64, paymentID string) error {
@@ -133,5 +158,6 @@ func (s *BankAccountService) WithdrawBalance(ctx context.Context, id string, amo
 		Amount:      amount,
 		PaymentID:   paymentID,
 	}
-	return s.Commands.WithdrawBalance.Handle(ctx, withdrawCmd)
+	_, err := s.Commands.WithdrawBalance.Handle(ctx, withdrawCmd)
+	return err
 }
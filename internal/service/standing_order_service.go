@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/command"
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+const (
+	standingOrdersSyncBatchSize = 100
+)
+
+var (
+	errTransferRefunded = errors.New("transfer deposit rejected, withdrawal refunded")
+)
+
+// SchedulerConfig standing orders scheduler config.
+type SchedulerConfig struct {
+	// PollInterval delay between polls of due standing orders
+	PollInterval time.Duration
+	// BatchSize due standing orders claimed by one poll
+	BatchSize int
+	// MaxAttempts default attempts of an occurrence before it is skipped
+	MaxAttempts int
+	// RetryDelay delay before the first retry of a failed occurrence, doubled with every attempt
+	RetryDelay time.Duration
+}
+
+// StandingOrderService stores scheduled and recurring deposits, withdrawals and transfers as standing order
+// aggregates and dispatches bank account commands when they are due. Due orders are claimed from the postgres
+// schedule with row locks, so every occurrence is executed by a single instance. Payments have deterministic
+// ids, a payment saved before the instance stopped is found in the account stream and is not repeated.
+type StandingOrderService struct {
+	cfg            SchedulerConfig
+	aggregateStore es.AggregateStore
+	serializer     es.Serializer
+	commands       *command.BankAccountCommand
+	schedule       domain.StandingOrderScheduleRepository
+	logger         *zap.Logger
+}
+
+// NewStandingOrderService creates a new standing order service
+func NewStandingOrderService(
+	cfg SchedulerConfig,
+	aggregateStore es.AggregateStore,
+	serializer es.Serializer,
+	commands *command.BankAccountCommand,
+	schedule domain.StandingOrderScheduleRepository,
+	logger *zap.Logger,
+) *StandingOrderService {
+	return &StandingOrderService{
+		cfg:            cfg,
+		aggregateStore: aggregateStore,
+		serializer:     serializer,
+		commands:       commands,
+		schedule:       schedule,
+		logger:         logger,
+	}
+}
+
+// Run adds active orders missing in the schedule and polls due orders until shutdown.
+func (s *StandingOrderService) Run(ctx context.Context) error {
+	if err := s.syncSchedule(ctx); err != nil {
+		s.logger.Error("Failed to sync standing orders schedule", zap.Error(err))
+	}
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.dispatchDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// CreateOrder schedules the instruction owned by the caller, who must own the account unless admin. Empty
+// currency means the account currency, zero maxAttempts means the configured default.
+func (s *StandingOrderService) CreateOrder(
+	ctx context.Context,
+	caller Caller,
+	id string,
+	instruction domain.StandingOrderInstruction,
+	schedule domain.Schedule,
+	maxAttempts int,
+) (*domain.StandingOrder, error) {
+	if !caller.CanAccess(instruction.AccountID) {
+		return nil, errors.Wrapf(bankAccountErrors.ErrForbidden, "account: %s does not belong to user: %s", instruction.AccountID, caller.UserID)
+	}
+
+	exists, err := s.aggregateStore.Exists(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "aggregateStore.Exists")
+	}
+	if exists {
+		return nil, errors.Wrapf(bankAccountErrors.ErrStandingOrderExists, "id: %s", id)
+	}
+
+	account, err := loadAccount(ctx, s.aggregateStore, instruction.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.BankAccount.IsClosed() {
+		return nil, errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", instruction.AccountID)
+	}
+	if instruction.Currency == "" {
+		instruction.Currency = account.BankAccount.Currency()
+	}
+	if instruction.Currency != account.BankAccount.Currency() {
+		return nil, errors.Wrapf(bankAccountErrors.ErrCurrencyMismatch, "currency: %s, account currency: %s", instruction.Currency, account.BankAccount.Currency())
+	}
+
+	if instruction.Kind == domain.StandingOrderKindTransfer && instruction.TargetAccountID != "" {
+		target, err := loadAccount(ctx, s.aggregateStore, instruction.TargetAccountID)
+		if err != nil {
+			return nil, err
+		}
+		if target.BankAccount.IsClosed() {
+			return nil, errors.Wrapf(bankAccountErrors.ErrAccountClosed, "aggregateID: %s", instruction.TargetAccountID)
+		}
+		if target.BankAccount.Currency() != instruction.Currency {
+			return nil, errors.Wrapf(bankAccountErrors.ErrCurrencyMismatch, "currency: %s, target account currency: %s", instruction.Currency, target.BankAccount.Currency())
+		}
+	}
+
+	if maxAttempts == 0 {
+		maxAttempts = s.cfg.MaxAttempts
+	}
+
+	aggregate := domain.NewStandingOrderAggregate(id)
+	if err := aggregate.CreateStandingOrder(ctx, caller.UserID, instruction, schedule, maxAttempts); err != nil {
+		return nil, err
+	}
+	if err := s.aggregateStore.Save(ctx, aggregate); err != nil {
+		return nil, errors.Wrap(err, "aggregateStore.Save")
+	}
+
+	// order missing in the schedule is added by the sync on the next start
+	if err := s.schedule.Save(ctx, id, aggregate.StandingOrder.DueAt()); err != nil {
+		s.logger.Error("Failed to schedule standing order", zap.String("id", id), zap.Error(err))
+	}
+
+	s.logger.Info("Standing order created",
+		zap.String("id", id),
+		zap.String("kind", string(instruction.Kind)),
+		zap.String("frequency", string(schedule.Frequency)),
+		zap.Timep("next_run_at", aggregate.StandingOrder.NextRunAt))
+	return aggregate.StandingOrder, nil
+}
+
+// GetOrder returns the order of the caller with the occurrence due next and the latest executions.
+func (s *StandingOrderService) GetOrder(ctx context.Context, caller Caller, id string) (*domain.StandingOrder, error) {
+	aggregate, err := s.loadCallerOrder(ctx, caller, id)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate.StandingOrder, nil
+}
+
+// CancelOrder stops further occurrences of the order of the caller.
+func (s *StandingOrderService) CancelOrder(ctx context.Context, caller Caller, id string, reason string) (*domain.StandingOrder, error) {
+	aggregate, err := s.loadCallerOrder(ctx, caller, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := aggregate.CancelStandingOrder(ctx, reason); err != nil {
+		return nil, err
+	}
+	if err := s.aggregateStore.Save(ctx, aggregate); err != nil {
+		return nil, errors.Wrap(err, "aggregateStore.Save")
+	}
+
+	// order left in the schedule is removed when it is claimed
+	if err := s.schedule.Save(ctx, id, nil); err != nil {
+		s.logger.Error("Failed to unschedule standing order", zap.String("id", id), zap.Error(err))
+	}
+	return aggregate.StandingOrder, nil
+}
+
+// loadCallerOrder loads the order owned by the caller, admins may load any order.
+func (s *StandingOrderService) loadCallerOrder(ctx context.Context, caller Caller, id string) (*domain.StandingOrderAggregate, error) {
+	aggregate, err := s.loadOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !caller.IsAdmin() && (caller.UserID == "" || caller.UserID != aggregate.StandingOrder.OwnerID) {
+		return nil, errors.Wrapf(bankAccountErrors.ErrForbidden, "standing order: %s does not belong to user: %s", id, caller.UserID)
+	}
+	return aggregate, nil
+}
+
+func (s *StandingOrderService) loadOrder(ctx context.Context, id string) (*domain.StandingOrderAggregate, error) {
+	aggregate := domain.NewStandingOrderAggregate(id)
+	if aggregate == nil {
+		return nil, errors.Wrap(bankAccountErrors.ErrStandingOrderNotFound, "empty id")
+	}
+
+	if err := s.aggregateStore.Load(ctx, aggregate); err != nil {
+		if errors.Is(err, bankAccountErrors.ErrUnknownEventType) {
+			return nil, errors.Wrapf(bankAccountErrors.ErrStandingOrderNotFound, "id: %s is not a standing order", id)
+		}
+		return nil, errors.Wrapf(err, "aggregateStore.Load id: %s", id)
+	}
+	if aggregate.GetVersion() == 0 {
+		return nil, errors.Wrapf(bankAccountErrors.ErrStandingOrderNotFound, "id: %s", id)
+	}
+	return aggregate, nil
+}
+
+// syncSchedule adds active orders missing in the schedule, orders are scanned in batches ordered by id.
+func (s *StandingOrderService) syncSchedule(ctx context.Context) error {
+	filter := es.StreamFilter{AggregateType: domain.StandingOrderAggregateType}
+
+	cursor := ""
+	for {
+		ids, err := s.aggregateStore.LoadAggregateIDs(ctx, filter, cursor, standingOrdersSyncBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "aggregateStore.LoadAggregateIDs")
+		}
+
+		for _, id := range ids {
+			aggregate, err := s.loadOrder(ctx, id)
+			if err != nil {
+				s.logger.Error("Failed to load standing order", zap.String("id", id), zap.Error(err))
+				continue
+			}
+			if dueAt := aggregate.StandingOrder.DueAt(); dueAt != nil {
+				if err := s.schedule.Ensure(ctx, id, *dueAt); err != nil {
+					return errors.Wrap(err, "schedule.Ensure")
+				}
+			}
+		}
+
+		if len(ids) < standingOrdersSyncBatchSize {
+			return nil
+		}
+		cursor = ids[len(ids)-1]
+	}
+}
+
+// dispatchDue executes due orders batch by batch until none is due.
+func (s *StandingOrderService) dispatchDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		count, err := s.schedule.ClaimDue(ctx, time.Now().UTC(), s.cfg.BatchSize, s.execute)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("Failed to dispatch due standing orders", zap.Error(err))
+			}
+			return
+		}
+		if count < s.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// execute executes the claimed order when it is due and records the result, it returns when the order is due next.
+// Failed attempts are retried until max attempts unless the transfer was refunded or the account is gone.
+func (s *StandingOrderService) execute(ctx context.Context, id string) (*time.Time, error) {
+	aggregate, err := s.loadOrder(ctx, id)
+	if err != nil {
+		if errors.Is(err, bankAccountErrors.ErrStandingOrderNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	order := aggregate.StandingOrder
+	dueAt := order.DueAt()
+	if dueAt == nil || dueAt.After(time.Now().UTC()) {
+		return dueAt, nil
+	}
+
+	runAt := *order.NextRunAt
+	paymentID := order.PaymentID(runAt)
+	dispatchErr := s.dispatch(ctx, order, paymentID)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if dispatchErr == nil {
+		err = aggregate.RecordExecuted(ctx, runAt, paymentID)
+	} else {
+		retryable := !errors.Is(dispatchErr, errTransferRefunded) &&
+			!errors.Is(dispatchErr, bankAccountErrors.ErrAccountClosed) &&
+			!errors.Is(dispatchErr, bankAccountErrors.ErrBankAccountNotFound)
+		s.logger.Warn("Standing order execution failed",
+			zap.String("id", id),
+			zap.Time("run_at", runAt),
+			zap.Int("attempt", order.Attempts+1),
+			zap.Bool("retryable", retryable),
+			zap.Error(dispatchErr))
+		err = aggregate.RecordFailed(ctx, runAt, dispatchErr.Error(), retryable, s.cfg.RetryDelay)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.aggregateStore.Save(ctx, aggregate); err != nil {
+		return nil, errors.Wrap(err, "aggregateStore.Save")
+	}
+	return order.DueAt(), nil
+}
+
+// dispatch executes the instruction of the order with the payment id of the occurrence.
+func (s *StandingOrderService) dispatch(ctx context.Context, order *domain.StandingOrder, paymentID string) error {
+	switch order.Kind {
+	case domain.StandingOrderKindDeposit:
+		return s.deposit(ctx, order.AccountID, order.Amount, order.Currency, paymentID)
+
+	case domain.StandingOrderKindWithdrawal:
+		return s.withdraw(ctx, order.AccountID, order.Amount, order.Currency, paymentID)
+
+	case domain.StandingOrderKindTransfer:
+		if err := s.withdraw(ctx, order.AccountID, order.Amount, order.Currency, paymentID); err != nil {
+			return err
+		}
+		err := s.deposit(ctx, order.TargetAccountID, order.Amount, order.Currency, paymentID)
+		if err == nil || !isDepositRejected(err) {
+			return err
+		}
+		// money withdrawn for the rejected transfer is returned, the occurrence is not retried
+		if refundErr := s.deposit(ctx, order.AccountID, order.Amount, order.Currency, paymentID+":refund"); refundErr != nil {
+			return errors.Wrapf(refundErr, "refund of rejected transfer: %v", err)
+		}
+		return errors.Wrap(errTransferRefunded, err.Error())
+
+	default:
+		return errors.Wrapf(bankAccountErrors.ErrInvalidStandingOrder, "kind: %s", order.Kind)
+	}
+}
+
+func (s *StandingOrderService) deposit(ctx context.Context, accountID string, amount int64, currency string, paymentID string) error {
+	_, found, err := findPayment(ctx, s.aggregateStore, s.serializer, accountID, events.BalancedDepositedEventTypeV1, paymentID)
+	if err != nil || found {
+		return err
+	}
+
+	_, err = s.commands.DepositeBalance.Handle(ctx, command.DepositeBalanceCommand{
+		AggregateID: accountID,
+		Amount:      amount,
+		Currency:    currency,
+		PaymentID:   paymentID,
+	})
+	return err
+}
+
+func (s *StandingOrderService) withdraw(ctx context.Context, accountID string, amount int64, currency string, paymentID string) error {
+	_, found, err := findPayment(ctx, s.aggregateStore, s.serializer, accountID, events.BalanceWithdrawedEventTypeV1, paymentID)
+	if err != nil || found {
+		return err
+	}
+
+	_, err = s.commands.WithdrawBalance.Handle(ctx, command.WithdrawBalanceCommand{
+		AggregateID: accountID,
+		Amount:      amount,
+		Currency:    currency,
+		PaymentID:   paymentID,
+	})
+	return err
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+)
+
+const (
+	camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+	// camt053BankTransactionIssuer issuer of the proprietary bank transaction codes, the transaction types
+	camt053BankTransactionIssuer = "ES-DEMO"
+)
+
+// RenderStatementCSV writes the statement as CSV with amounts in major units of the currency. The first
+// and the last row are the opening and the closing balance.
+func RenderStatementCSV(w io.Writer, statement *Statement) error {
+	writer := csv.NewWriter(w)
+	rows := [][]string{
+		{"booked_at", "event_id", "version", "type", "direction", "amount", "currency", "payment_id", "reference", "balance_after"},
+		{statement.From, "", "", "opening_balance", "", "", statement.Currency, "", "", formatMinorUnits(statement.OpeningBalance, statement.Currency)},
+	}
+	for _, entry := range statement.Entries {
+		rows = append(rows, []string{
+			entry.BookedAt.Format(time.RFC3339),
+			entry.EventID,
+			strconv.FormatUint(entry.Version, 10),
+			string(entry.Type),
+			string(entry.Direction),
+			formatMinorUnits(entry.Amount, statement.Currency),
+			statement.Currency,
+			entry.PaymentID,
+			entry.Reference,
+			formatMinorUnits(entry.BalanceAfter, statement.Currency),
+		})
+	}
+	rows = append(rows, []string{statement.To, "", "", "closing_balance", "", "", statement.Currency, "", "", formatMinorUnits(statement.ClosingBalance, statement.Currency)})
+
+	if err := writer.WriteAll(rows); err != nil {
+		return errors.Wrap(err, "csv.WriteAll")
+	}
+	return nil
+}
+
+// RenderStatementCamt053 writes the statement as ISO 20022 camt.053.001.02 bank to customer statement.
+func RenderStatementCamt053(w io.Writer, statement *Statement) error {
+	from, err := time.Parse(time.DateOnly, statement.From)
+	if err != nil {
+		return errors.Wrapf(err, "from: %s", statement.From)
+	}
+	to, err := time.Parse(time.DateOnly, statement.To)
+	if err != nil {
+		return errors.Wrapf(err, "to: %s", statement.To)
+	}
+	createdAt := statement.GeneratedAt.UTC().Format(time.RFC3339)
+	net := statement.TotalCredits - statement.TotalDebits
+
+	stmt := camtStatement{
+		ID:        statement.ID,
+		CreatedAt: createdAt,
+		Period: camtPeriod{
+			From: from.Format(time.RFC3339),
+			To:   to.AddDate(0, 0, 1).Add(-time.Second).Format(time.RFC3339),
+		},
+		Account: camtAccount{
+			ID:       camtAccountID{Other: camtOtherID{ID: statement.AccountID}},
+			Currency: statement.Currency,
+			Owner:    &camtParty{Name: strings.TrimSpace(statement.HolderName)},
+		},
+		Balances: []camtBalance{
+			newCamtBalance("OPBD", statement.OpeningBalance, statement.Currency, statement.From),
+			newCamtBalance("CLBD", statement.ClosingBalance, statement.Currency, statement.To),
+		},
+		Summary: camtSummary{
+			Entries: camtEntriesTotal{
+				Count:        len(statement.Entries),
+				Sum:          formatMinorUnits(statement.TotalCredits+statement.TotalDebits, statement.Currency),
+				NetAmount:    formatMinorUnits(abs(net), statement.Currency),
+				NetIndicator: camtIndicator(net),
+			},
+			Credits: camtEntriesCount{Sum: formatMinorUnits(statement.TotalCredits, statement.Currency)},
+			Debits:  camtEntriesCount{Sum: formatMinorUnits(statement.TotalDebits, statement.Currency)},
+		},
+	}
+
+	for _, entry := range statement.Entries {
+		signed := entry.Amount
+		if entry.Direction == domain.TransactionDirectionDebit {
+			signed = -signed
+			stmt.Summary.Debits.Count++
+		} else {
+			stmt.Summary.Credits.Count++
+		}
+
+		bookedAt := entry.BookedAt.UTC().Format(time.RFC3339)
+		camtEntry := camtEntry{
+			Reference:   strconv.FormatUint(entry.Version, 10),
+			Amount:      camtAmount{Currency: statement.Currency, Value: formatMinorUnits(entry.Amount, statement.Currency)},
+			Indicator:   camtIndicator(signed),
+			Status:      "BOOK",
+			BookingDate: camtDateTime{DateTime: bookedAt},
+			ValueDate:   camtDateTime{DateTime: bookedAt},
+			ServicerRef: entry.EventID,
+			BankTxCode: camtBankTxCode{Proprietary: camtProprietary{
+				Code:   string(entry.Type),
+				Issuer: camt053BankTransactionIssuer,
+			}},
+		}
+		if entry.PaymentID != "" || entry.Reference != "" {
+			details := camtTxDetails{AdditionalInfo: entry.Reference}
+			if entry.PaymentID != "" {
+				details.Refs = &camtRefs{EndToEndID: entry.PaymentID}
+			}
+			camtEntry.Details = &camtEntryDetails{Tx: details}
+		}
+		stmt.Entries = append(stmt.Entries, camtEntry)
+	}
+
+	document := camtDocument{
+		Namespace: camt053Namespace,
+		Statement: camtBankToCustomerStatement{
+			Header:    camtGroupHeader{MessageID: statement.ID, CreatedAt: createdAt},
+			Statement: stmt,
+		},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.Wrap(err, "io.WriteString")
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return errors.Wrap(err, "xml.Encode")
+	}
+	return nil
+}
+
+// formatMinorUnits formats the amount in minor units as decimal number in major units of the currency.
+func formatMinorUnits(amount int64, currency string) string {
+	fraction := 2
+	if c := money.GetCurrency(currency); c != nil {
+		fraction = c.Fraction
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	if fraction == 0 {
+		return sign + strconv.FormatInt(amount, 10)
+	}
+
+	digits := fmt.Sprintf("%0*d", fraction+1, amount)
+	return sign + digits[:len(digits)-fraction] + "." + digits[len(digits)-fraction:]
+}
+
+func camtIndicator(signed int64) string {
+	if signed < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+func newCamtBalance(code string, amount int64, currency string, day string) camtBalance {
+	return camtBalance{
+		Type:      camtBalanceType{CodeOrProprietary: camtCode{Code: code}},
+		Amount:    camtAmount{Currency: currency, Value: formatMinorUnits(abs(amount), currency)},
+		Indicator: camtIndicator(amount),
+		Date:      camtDate{Date: day},
+	}
+}
+
+func abs(amount int64) int64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
+
+// camt.053.001.02 elements used by the statement, optional elements not known to the account are omitted.
+type camtDocument struct {
+	XMLName   xml.Name                    `xml:"Document"`
+	Namespace string                      `xml:"xmlns,attr"`
+	Statement camtBankToCustomerStatement `xml:"BkToCstmrStmt"`
+}
+
+type camtBankToCustomerStatement struct {
+	Header    camtGroupHeader `xml:"GrpHdr"`
+	Statement camtStatement   `xml:"Stmt"`
+}
+
+type camtGroupHeader struct {
+	MessageID string `xml:"MsgId"`
+	CreatedAt string `xml:"CreDtTm"`
+}
+
+type camtStatement struct {
+	ID        string        `xml:"Id"`
+	CreatedAt string        `xml:"CreDtTm"`
+	Period    camtPeriod    `xml:"FrToDt"`
+	Account   camtAccount   `xml:"Acct"`
+	Balances  []camtBalance `xml:"Bal"`
+	Summary   camtSummary   `xml:"TxsSummry"`
+	Entries   []camtEntry   `xml:"Ntry"`
+}
+
+type camtPeriod struct {
+	From string `xml:"FrDtTm"`
+	To   string `xml:"ToDtTm"`
+}
+
+type camtAccount struct {
+	ID       camtAccountID `xml:"Id"`
+	Currency string        `xml:"Ccy"`
+	Owner    *camtParty    `xml:"Ownr,omitempty"`
+}
+
+type camtAccountID struct {
+	Other camtOtherID `xml:"Othr"`
+}
+
+type camtOtherID struct {
+	ID string `xml:"Id"`
+}
+
+type camtParty struct {
+	Name string `xml:"Nm,omitempty"`
+}
+
+type camtBalance struct {
+	Type      camtBalanceType `xml:"Tp"`
+	Amount    camtAmount      `xml:"Amt"`
+	Indicator string          `xml:"CdtDbtInd"`
+	Date      camtDate        `xml:"Dt"`
+}
+
+type camtBalanceType struct {
+	CodeOrProprietary camtCode `xml:"CdOrPrtry"`
+}
+
+type camtCode struct {
+	Code string `xml:"Cd"`
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtDate struct {
+	Date string `xml:"Dt"`
+}
+
+type camtDateTime struct {
+	DateTime string `xml:"DtTm"`
+}
+
+type camtSummary struct {
+	Entries camtEntriesTotal `xml:"TtlNtries"`
+	Credits camtEntriesCount `xml:"TtlCdtNtries"`
+	Debits  camtEntriesCount `xml:"TtlDbtNtries"`
+}
+
+type camtEntriesTotal struct {
+	Count        int    `xml:"NbOfNtries"`
+	Sum          string `xml:"Sum"`
+	NetAmount    string `xml:"TtlNetNtryAmt"`
+	NetIndicator string `xml:"CdtDbtInd"`
+}
+
+type camtEntriesCount struct {
+	Count int    `xml:"NbOfNtries"`
+	Sum   string `xml:"Sum"`
+}
+
+type camtEntry struct {
+	Reference   string            `xml:"NtryRef"`
+	Amount      camtAmount        `xml:"Amt"`
+	Indicator   string            `xml:"CdtDbtInd"`
+	Status      string            `xml:"Sts"`
+	BookingDate camtDateTime      `xml:"BookgDt"`
+	ValueDate   camtDateTime      `xml:"ValDt"`
+	ServicerRef string            `xml:"AcctSvcrRef"`
+	BankTxCode  camtBankTxCode    `xml:"BkTxCd"`
+	Details     *camtEntryDetails `xml:"NtryDtls,omitempty"`
+}
+
+type camtBankTxCode struct {
+	Proprietary camtProprietary `xml:"Prtry"`
+}
+
+type camtProprietary struct {
+	Code   string `xml:"Cd"`
+	Issuer string `xml:"Issr"`
+}
+
+type camtEntryDetails struct {
+	Tx camtTxDetails `xml:"TxDtls"`
+}
+
+type camtTxDetails struct {
+	Refs           *camtRefs `xml:"Refs,omitempty"`
+	AdditionalInfo string    `xml:"AddtlTxInf,omitempty"`
+}
+
+type camtRefs struct {
+	EndToEndID string `xml:"EndToEndId"`
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/internal/domain"
+	bankAccountErrors "github.com/th1enq/es-demo/internal/errors"
+	"github.com/th1enq/es-demo/internal/events"
+	"github.com/th1enq/es-demo/pkg/es"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxStatementDays longest period of one statement
+	MaxStatementDays = 366
+)
+
+// StatementFormat rendering of the statement.
+type StatementFormat string
+
+const (
+	StatementFormatJSON StatementFormat = "json"
+	StatementFormatCSV  StatementFormat = "csv"
+	// StatementFormatCamt053 ISO 20022 bank to customer statement, camt.053.001.02
+	StatementFormatCamt053 StatementFormat = "camt.053"
+)
+
+var (
+	ErrInvalidStatementPeriod = errors.New("invalid statement period")
+	ErrInvalidStatementFormat = errors.New("invalid statement format")
+)
+
+// ParseStatementFormat parses the format, empty format is json.
+func ParseStatementFormat(format string) (StatementFormat, error) {
+	switch StatementFormat(format) {
+	case "", StatementFormatJSON:
+		return StatementFormatJSON, nil
+	case StatementFormatCSV:
+		return StatementFormatCSV, nil
+	case StatementFormatCamt053, "camt053":
+		return StatementFormatCamt053, nil
+	default:
+		return "", errors.Wrapf(ErrInvalidStatementFormat, "format: %s", format)
+	}
+}
+
+// Statement of the account for the UTC days From to To inclusive, amounts in minor units of Currency.
+type Statement struct {
+	ID             string `json:"id"`
+	AccountID      string `json:"account_id"`
+	HolderName     string `json:"holder_name"`
+	Currency       string `json:"currency"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	OpeningBalance int64  `json:"opening_balance"`
+	ClosingBalance int64  `json:"closing_balance"`
+	TotalCredits   int64  `json:"total_credits"`
+	TotalDebits    int64  `json:"total_debits"`
+	// Version of the account at the end of the period
+	Version     uint64           `json:"version"`
+	Entries     []StatementEntry `json:"entries"`
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+// StatementEntry balance changing event of the period.
+type StatementEntry struct {
+	EventID   string                      `json:"event_id"`
+	Version   uint64                      `json:"version"`
+	Type      domain.TransactionType      `json:"type"`
+	Direction domain.TransactionDirection `json:"direction"`
+	Amount    int64                       `json:"amount"`
+	PaymentID string                      `json:"payment_id,omitempty"`
+	// Reference hold id of captures, reversed event id of reversals
+	Reference    string    `json:"reference,omitempty"`
+	BalanceAfter int64     `json:"balance_after"`
+	BookedAt     time.Time `json:"booked_at"`
+}
+
+// StatementService computes account statements from the event store, so that balances are exact even when
+// the projections lag or are being rebuilt.
+type StatementService struct {
+	aggregateStore es.AggregateStore
+	serializer     es.Serializer
+	logger         *zap.Logger
+}
+
+// NewStatementService creates a new statement service
+func NewStatementService(
+	aggregateStore es.AggregateStore,
+	serializer es.Serializer,
+	logger *zap.Logger,
+) *StatementService {
+	return &StatementService{
+		aggregateStore: aggregateStore,
+		serializer:     serializer,
+		logger:         logger,
+	}
+}
+
+// Generate computes the statement of the account for the UTC days from to to inclusive. The opening balance
+// is the balance before the first event of the period and the closing balance the balance after the last one.
+func (s *StatementService) Generate(ctx context.Context, accountID string, from, to time.Time) (*Statement, error) {
+	from, to = domain.InterestDay(from), domain.InterestDay(to)
+	if to.Before(from) {
+		return nil, errors.Wrapf(ErrInvalidStatementPeriod, "from: %s, to: %s", from.Format(time.DateOnly), to.Format(time.DateOnly))
+	}
+	if to.Sub(from) >= MaxStatementDays*24*time.Hour {
+		return nil, errors.Wrapf(ErrInvalidStatementPeriod, "period longer than %d days", MaxStatementDays)
+	}
+	periodEnd := to.AddDate(0, 0, 1)
+
+	accountEvents, err := s.aggregateStore.LoadEvents(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "aggregateStore.LoadEvents aggregateID: %s", accountID)
+	}
+	if len(accountEvents) == 0 || accountEvents[0].GetAggregateType() != domain.BankAccountAggregateType {
+		return nil, errors.Wrapf(bankAccountErrors.ErrBankAccountNotFound, "aggregateID: %s", accountID)
+	}
+
+	statement := &Statement{
+		ID:          statementID(accountID, from, to),
+		AccountID:   accountID,
+		From:        from.Format(time.DateOnly),
+		To:          to.Format(time.DateOnly),
+		Entries:     make([]StatementEntry, 0),
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	// history is the account as it was after each event
+	history := domain.NewBankAccountAggregate(accountID)
+	for _, event := range accountEvents {
+		timestamp := event.GetTimeStamp()
+		if !timestamp.Before(periodEnd) {
+			break
+		}
+
+		deserializedEvent, err := s.serializer.DeserializeEvent(event)
+		if err != nil {
+			return nil, errors.Wrapf(err, "serializer.DeserializeEvent aggregateID: %s, version: %d", accountID, event.GetVersion())
+		}
+
+		before := history.BankAccount.Balance.Amount()
+		if err := history.When(deserializedEvent); err != nil {
+			return nil, errors.Wrapf(err, "history.When aggregateID: %s, version: %d", accountID, event.GetVersion())
+		}
+		after := history.BankAccount.Balance.Amount()
+		statement.Version = event.GetVersion()
+
+		if timestamp.Before(from) {
+			statement.OpeningBalance = after
+			continue
+		}
+
+		transactionType, paymentID, reference, ok := statementEntryType(deserializedEvent)
+		if !ok || after == before {
+			continue
+		}
+
+		entry := StatementEntry{
+			EventID:      event.GetEventID(),
+			Version:      event.GetVersion(),
+			Type:         transactionType,
+			Direction:    domain.TransactionDirectionCredit,
+			Amount:       after - before,
+			PaymentID:    paymentID,
+			Reference:    reference,
+			BalanceAfter: after,
+			BookedAt:     timestamp.UTC(),
+		}
+		if entry.Amount < 0 {
+			entry.Direction = domain.TransactionDirectionDebit
+			entry.Amount = -entry.Amount
+			statement.TotalDebits += entry.Amount
+		} else {
+			statement.TotalCredits += entry.Amount
+		}
+		statement.Entries = append(statement.Entries, entry)
+	}
+
+	statement.HolderName = fmt.Sprintf("%s %s", history.BankAccount.FirstName, history.BankAccount.LastName)
+	statement.Currency = history.BankAccount.Currency()
+	statement.ClosingBalance = history.BankAccount.Balance.Amount()
+
+	s.logger.Info("Statement generated",
+		zap.String("aggregate_id", accountID),
+		zap.String("from", statement.From),
+		zap.String("to", statement.To),
+		zap.Int("entries", len(statement.Entries)))
+	return statement, nil
+}
+
+// statementEntryType classifies the balance changing event the same way the transactions projection does.
+func statementEntryType(event any) (domain.TransactionType, string, string, bool) {
+	switch evt := event.(type) {
+	case *events.BankAccountCreatedEventV1:
+		return domain.TransactionTypeOpening, "", "", true
+	case *events.BalanceDepositedEventV1:
+		return domain.TransactionTypeDeposit, evt.PaymentID, "", true
+	case *events.BalanceWithdrawedEventV1:
+		return domain.TransactionTypeWithdrawal, evt.PaymentID, "", true
+	case *events.AccountClosedEventV1:
+		return domain.TransactionTypeClosingPayout, evt.PayoutTarget, "", true
+	case *events.InterestPostedEventV1:
+		return domain.TransactionTypeInterest, domain.InterestPaymentID(evt.Month), "", true
+	case *events.HoldCapturedEventV1:
+		return domain.TransactionTypeHoldCapture, evt.PaymentID, evt.HoldID, true
+	case *events.DepositReversedEventV1:
+		return domain.TransactionTypeDepositReversal, evt.PaymentID, evt.OriginalEventID, true
+	case *events.WithdrawalReversedEventV1:
+		return domain.TransactionTypeWithdrawalReversal, evt.PaymentID, evt.OriginalEventID, true
+	default:
+		return "", "", "", false
+	}
+}
+
+// statementID identifies the statement of the account and period, the same period gives the same id. It fits
+// the 35 characters of ISO 20022 identifiers.
+func statementID(accountID string, from, to time.Time) string {
+	sum := sha1.Sum([]byte(accountID + "|" + from.Format(time.DateOnly) + "|" + to.Format(time.DateOnly)))
+	return "STMT" + hex.EncodeToString(sum[:])[:24]
+}
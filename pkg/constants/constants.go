@@ -47,6 +47,7 @@ const (
 	Size   = "size"
 	Search = "search"
 	ID     = "id"
+	Name   = "name"
 
 	EsAll = "$all"
 
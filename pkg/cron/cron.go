@@ -0,0 +1,144 @@
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// searchLimit bounds the search of the next activation, expressions like "0 0 30 2 *" never match.
+const searchLimit = 5
+
+var (
+	ErrInvalidExpression = errors.New("invalid cron expression")
+)
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 6},
+}
+
+// Schedule parsed standard five field cron expression: minute, hour, day of month, month and day of week.
+// Fields support *, values, ranges a-b, steps */n and a-b/n and comma separated lists. Times are in UTC.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// day is matched when either restricted day field matches, like in cron
+	domAny, dowAny bool
+}
+
+// Parse parses the cron expression.
+func Parse(expr string) (*Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, errors.Wrapf(ErrInvalidExpression, "expected %d fields: %q", len(fields), expr)
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, part := range parts {
+		parsed, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "expression: %q", expr)
+		}
+		bits[i] = parsed
+	}
+
+	return &Schedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}, nil
+}
+
+// Next returns the first activation after t, zero time when the expression never matches.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(searchLimit, 0, 0)
+
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := has(s.dom, t.Day())
+	dowMatch := has(s.dow, int(t.Weekday()))
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		from, to, step := f.min, f.max, 1
+
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		if hasStep {
+			parsed, err := strconv.Atoi(stepExpr)
+			if err != nil || parsed <= 0 {
+				return 0, errors.Wrapf(ErrInvalidExpression, "%s step: %q", f.name, part)
+			}
+			step = parsed
+		}
+
+		if rangeExpr != "*" {
+			fromExpr, toExpr, isRange := strings.Cut(rangeExpr, "-")
+			parsed, err := strconv.Atoi(fromExpr)
+			if err != nil {
+				return 0, errors.Wrapf(ErrInvalidExpression, "%s value: %q", f.name, part)
+			}
+			from, to = parsed, parsed
+			if isRange {
+				if to, err = strconv.Atoi(toExpr); err != nil {
+					return 0, errors.Wrapf(ErrInvalidExpression, "%s range: %q", f.name, part)
+				}
+			} else if hasStep {
+				to = f.max
+			}
+		}
+
+		if from < f.min || to > f.max || from > to {
+			return 0, errors.Wrapf(ErrInvalidExpression, "%s out of range %d-%d: %q", f.name, f.min, f.max, part)
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
@@ -6,11 +6,20 @@ import (
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 	"github.com/th1enq/es-demo/pkg/es/serializer"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
 // Load es.Aggregate events using snapshots with given frequency
-func (p *pgEventStore) Load(ctx context.Context, aggregate Aggregate) error {
+func (p *pgEventStore) Load(ctx context.Context, aggregate Aggregate) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "pgEventStore.Load")
+	span.SetAttributes(attribute.String("aggregate_id", aggregate.GetID()))
+	defer func() {
+		tracing.TraceErr(span, err)
+		span.End()
+	}()
+
 	p.logger.Info("Loading aggregate", zap.String("aggregateID", aggregate.String()))
 	snapshot, err := p.GetSnapshot(ctx, aggregate.GetID())
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
@@ -40,7 +49,14 @@ func (p *pgEventStore) Load(ctx context.Context, aggregate Aggregate) error {
 	return nil
 }
 
-func (p *pgEventStore) LoadByVersion(ctx context.Context, aggregate Aggregate, version uint64) error {
+func (p *pgEventStore) LoadByVersion(ctx context.Context, aggregate Aggregate, version uint64) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "pgEventStore.LoadByVersion")
+	span.SetAttributes(attribute.String("aggregate_id", aggregate.GetID()), attribute.Int64("version", int64(version)))
+	defer func() {
+		tracing.TraceErr(span, err)
+		span.End()
+	}()
+
 	p.logger.Info("Loading aggregate", zap.String("aggregateID", aggregate.String()), zap.Uint64("version", version))
 
 	snapshotVersion := version / p.cfg.SnapshotFrequency * p.cfg.SnapshotFrequency
@@ -82,6 +98,13 @@ func (p *pgEventStore) Save(ctx context.Context, aggregate Aggregate) (err error
 		return nil
 	}
 
+	ctx, span := tracing.StartSpan(ctx, "pgEventStore.Save")
+	span.SetAttributes(attribute.String("aggregate_id", aggregate.GetID()), attribute.Int("events", len(aggregate.GetChanges())))
+	defer func() {
+		tracing.TraceErr(span, err)
+		span.End()
+	}()
+
 	p.logger.Info("Save Aggregate", zap.String("aggregate", aggregate.String()))
 
 	tx, err := p.db.Begin(ctx)
@@ -99,16 +122,9 @@ func (p *pgEventStore) Save(ctx context.Context, aggregate Aggregate) (err error
 		}
 	}()
 
-	changes := aggregate.GetChanges()
-	events := make([]Event, 0, len(changes))
-
-	for i := range changes {
-		event, err := p.serializer.SerializeEvent(aggregate, changes[i])
-		if err != nil {
-			p.logger.Error("Failed to serialize event", zap.Error(err))
-			return errors.Wrap(err, "serializer.SerializeEvent")
-		}
-		events = append(events, event)
+	events, err := p.serializeChanges(ctx, aggregate)
+	if err != nil {
+		return err
 	}
 
 	if err := p.saveEventsTx(ctx, tx, events); err != nil {
@@ -116,7 +132,7 @@ func (p *pgEventStore) Save(ctx context.Context, aggregate Aggregate) (err error
 		return errors.Wrap(err, "saveEventsTx")
 	}
 
-	if aggregate.GetVersion()%p.cfg.SnapshotFrequency == 0 {
+	if snapshotDue(aggregate.GetVersion()-uint64(len(events)), aggregate.GetVersion(), p.cfg.SnapshotFrequency) {
 		aggregate.ToSnapshot()
 		if err := p.saveSnapshotTx(ctx, tx, aggregate); err != nil {
 			return errors.Wrap(err, "saveSnapshotTx")
@@ -131,3 +147,35 @@ func (p *pgEventStore) Save(ctx context.Context, aggregate Aggregate) (err error
 	p.logger.Info("Save Aggregate successfully", zap.String("aggregate", aggregate.String()))
 	return tx.Commit(ctx)
 }
+
+// snapshotDue whether saving the versions after fromVersion up to toVersion crosses a multiple of the frequency,
+// changes saved at once may step over the multiple without ending on it.
+func snapshotDue(fromVersion, toVersion, frequency uint64) bool {
+	return fromVersion/frequency != toVersion/frequency
+}
+
+// serializeChanges serialize the aggregate uncommitted changes into events numbered consecutively,
+// the aggregate version is the version after the last change, the changes take the versions before it in order
+func (p *pgEventStore) serializeChanges(ctx context.Context, aggregate Aggregate) ([]Event, error) {
+	changes := aggregate.GetChanges()
+	events := make([]Event, 0, len(changes))
+	firstVersion := aggregate.GetVersion() - uint64(len(changes)) + 1
+
+	for i := range changes {
+		event, err := p.serializer.SerializeEvent(aggregate, changes[i])
+		if err != nil {
+			p.logger.Error("Failed to serialize event", zap.Error(err))
+			return nil, errors.Wrap(err, "serializer.SerializeEvent")
+		}
+		event.SetVersion(firstVersion + uint64(i))
+
+		event.Metadata, err = tracing.InjectMetadata(ctx, event.GetMetadata())
+		if err != nil {
+			p.logger.Error("Failed to inject trace context into event metadata", zap.Error(err))
+			return nil, errors.Wrap(err, "tracing.InjectMetadata")
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
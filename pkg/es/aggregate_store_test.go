@@ -0,0 +1,99 @@
+package es
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testAggregateType AggregateType = "test"
+
+type testAggregate struct {
+	*AggregateBase
+}
+
+func newTestAggregate(id string) *testAggregate {
+	aggregate := &testAggregate{}
+	base := NewAggregateBase(aggregate.When)
+	base.SetType(testAggregateType)
+	base.SetID(id)
+	aggregate.AggregateBase = base
+	return aggregate
+}
+
+func (a *testAggregate) When(event any) error {
+	return nil
+}
+
+type testSerializer struct{}
+
+func (s *testSerializer) SerializeEvent(aggregate Aggregate, event any) (Event, error) {
+	return NewEvent(aggregate, EventType(event.(string)), nil, nil), nil
+}
+
+func (s *testSerializer) DeserializeEvent(event Event) (any, error) {
+	return string(event.GetEventType()), nil
+}
+
+func TestSerializeChangesNumbersEventsConsecutively(t *testing.T) {
+	tests := []struct {
+		name     string
+		loaded   int
+		changes  []string
+		versions []uint64
+	}{
+		{name: "single event on new aggregate", changes: []string{"A"}, versions: []uint64{1}},
+		{name: "several events on new aggregate", changes: []string{"A", "B", "C"}, versions: []uint64{1, 2, 3}},
+		{name: "several events on loaded aggregate", loaded: 4, changes: []string{"A", "B"}, versions: []uint64{5, 6}},
+	}
+
+	store := &pgEventStore{logger: zap.NewNop(), serializer: &testSerializer{}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregate := newTestAggregate("aggregate-1")
+			for i := 0; i < tt.loaded; i++ {
+				require.NoError(t, aggregate.RaiseEvent("loaded"))
+			}
+			for _, change := range tt.changes {
+				require.NoError(t, aggregate.Apply(change))
+			}
+
+			events, err := store.serializeChanges(context.Background(), aggregate)
+			require.NoError(t, err)
+			require.Len(t, events, len(tt.versions))
+
+			for i, event := range events {
+				assert.Equal(t, tt.versions[i], event.GetVersion())
+				assert.Equal(t, EventType(tt.changes[i]), event.GetEventType())
+			}
+		})
+	}
+}
+
+func TestSnapshotDue(t *testing.T) {
+	tests := []struct {
+		name        string
+		fromVersion uint64
+		toVersion   uint64
+		expected    bool
+	}{
+		{name: "single event before the multiple", fromVersion: 8, toVersion: 9},
+		{name: "single event on the multiple", fromVersion: 9, toVersion: 10, expected: true},
+		{name: "single event after the multiple", fromVersion: 10, toVersion: 11},
+		{name: "several events ending on the multiple", fromVersion: 7, toVersion: 10, expected: true},
+		{name: "several events crossing the multiple", fromVersion: 9, toVersion: 12, expected: true},
+		{name: "several events starting on the multiple", fromVersion: 10, toVersion: 13},
+		{name: "several events within the same bucket", fromVersion: 11, toVersion: 19},
+		{name: "several events crossing several multiples", fromVersion: 5, toVersion: 25, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, snapshotDue(tt.fromVersion, tt.toVersion, 10))
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package es
+
+import "context"
+
+// CheckpointStore persists projection progress. Stream checkpoints hold the last applied
+// aggregate version and are used for idempotency, projection position is the highest
+// global event position applied and is used for lag reporting.
+type CheckpointStore interface {
+	// GetStreamCheckpoint returns the last applied version of aggregate stream, 0 if none.
+	GetStreamCheckpoint(ctx context.Context, projection string, aggregateID string) (uint64, error)
+
+	// SaveCheckpoint stores event as the last applied for its stream and advances projection position.
+	SaveCheckpoint(ctx context.Context, projection string, event Event) error
+
+	// GetPosition returns the highest global position applied by projection.
+	GetPosition(ctx context.Context, projection string) (uint64, error)
+
+	// ResetCheckpoints removes all projection checkpoints before rebuild.
+	ResetCheckpoints(ctx context.Context, projection string) error
+
+	// ResetStreamCheckpoint removes checkpoint of a single stream before it is rebuilt.
+	ResetStreamCheckpoint(ctx context.Context, projection string, aggregateID string) error
+
+	// CopyCheckpoints replaces checkpoints of projection to with the checkpoints of projection from.
+	CopyCheckpoints(ctx context.Context, from, to string) error
+}
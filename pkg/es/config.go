@@ -3,4 +3,12 @@ package es
 // Config of es package.
 type Config struct {
 	SnapshotFrequency uint64 `json:"snapshotFrequency" validate:"required,gte=0"`
+	// SchemaValidation how appended events are validated against the schema registry.
+	SchemaValidation SchemaValidationMode `json:"schemaValidation" validate:"oneof=strict warn off"`
+}
+
+// ProjectionConfig of ProjectionRunner.
+type ProjectionConfig struct {
+	// ReplayBatchSize number of events loaded from the event store per replay batch.
+	ReplayBatchSize int `json:"replayBatchSize" validate:"required,gte=1"`
 }
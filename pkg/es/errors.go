@@ -11,3 +11,28 @@ var (
 	ErrInvalidAggregateID  = errors.New("invalid aggregate id")
 	ErrInvalidEventVersion = errors.New("Invalid event version")
 )
+
+var (
+	ErrProjectionNotFound   = errors.New("projection not found")
+	ErrProjectionRebuilding = errors.New("projection rebuild already in progress")
+	ErrProjectionStopped    = errors.New("projection stopped")
+)
+
+var (
+	ErrReplayJobNotFound   = errors.New("replay job not found")
+	ErrReplayJobRunning    = errors.New("replay job already running")
+	ErrReplayJobNotRunning = errors.New("replay job is not running")
+	ErrReplayJobFinished   = errors.New("replay job already completed")
+)
+
+var (
+	ErrInvalidArchive          = errors.New("invalid event archive")
+	ErrArchiveChecksumMismatch = errors.New("event archive checksum mismatch")
+)
+
+var (
+	ErrInvalidEventSchema      = errors.New("invalid event schema")
+	ErrIncompatibleEventSchema = errors.New("incompatible event schema")
+	ErrEventSchemaNotFound     = errors.New("event schema not found")
+	ErrEventSchemaViolation    = errors.New("event payload violates schema")
+)
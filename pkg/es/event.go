@@ -23,6 +23,8 @@ type Event struct {
 	Data          []byte
 	Metadata      []byte
 	Timestamp     time.Time
+	// Position is the global position of the event in the event store, assigned on save.
+	Position uint64
 }
 
 // NewBaseEvent new base Event constructor with configured EventID, Aggregate properties and Timestamp.
@@ -55,6 +57,11 @@ func (e *Event) GetEventID() string {
 	return e.EventID
 }
 
+// GetPosition get global position of the Event in the event store.
+func (e *Event) GetPosition() uint64 {
+	return e.Position
+}
+
 // GetTimeStamp get timestamp of the Event.
 func (e *Event) GetTimeStamp() time.Time {
 	return e.Timestamp
@@ -0,0 +1,348 @@
+package es
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/pkg/es/serializer"
+)
+
+const (
+	// ArchiveFormat identifies gzip NDJSON event archives.
+	ArchiveFormat = "es-demo/events+ndjson"
+	// ArchiveFormatVersion version of the archive layout written by ArchiveWriter.
+	ArchiveFormatVersion = 1
+
+	archiveKindHeader   = "header"
+	archiveKindEvent    = "event"
+	archiveKindManifest = "manifest"
+	archiveChecksumAlgo = "sha256:"
+)
+
+// ArchiveHeader first line of an event archive.
+type ArchiveHeader struct {
+	Kind          string        `json:"kind"`
+	Format        string        `json:"format"`
+	FormatVersion int           `json:"formatVersion"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	Filter        *StreamFilter `json:"filter,omitempty"`
+	// HeadPosition global position of the source store when the export started, events after it are not exported
+	HeadPosition uint64 `json:"headPosition"`
+	Anonymized   bool   `json:"anonymized,omitempty"`
+}
+
+// ArchivedEvent event line of an archive. Position is the global position in the source store and
+// Checksum covers the stream, type, version, timestamp, data and metadata of the event.
+type ArchivedEvent struct {
+	Kind          string          `json:"kind"`
+	Position      uint64          `json:"position"`
+	AggregateID   string          `json:"aggregateId"`
+	AggregateType AggregateType   `json:"aggregateType"`
+	EventType     EventType       `json:"eventType"`
+	Version       uint64          `json:"version"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Data          json.RawMessage `json:"data"`
+	Metadata      json.RawMessage `json:"metadata,omitempty"`
+	Checksum      string          `json:"checksum"`
+}
+
+// ArchiveManifest last line of an archive, Checksum covers every event line as written.
+type ArchiveManifest struct {
+	Kind           string                  `json:"kind"`
+	Events         int64                   `json:"events"`
+	Aggregates     int64                   `json:"aggregates"`
+	AggregateTypes map[AggregateType]int64 `json:"aggregateTypes"`
+	FirstPosition  uint64                  `json:"firstPosition"`
+	LastPosition   uint64                  `json:"lastPosition"`
+	Checksum       string                  `json:"checksum"`
+}
+
+// ArchiveWriter writes events in global position order to a gzip NDJSON archive.
+type ArchiveWriter struct {
+	gzip       *gzip.Writer
+	hash       hash.Hash
+	manifest   ArchiveManifest
+	aggregates map[string]struct{}
+}
+
+// NewArchiveWriter writes the header of the archive to w.
+func NewArchiveWriter(w io.Writer, header ArchiveHeader) (*ArchiveWriter, error) {
+	header.Kind = archiveKindHeader
+	header.Format = ArchiveFormat
+	header.FormatVersion = ArchiveFormatVersion
+
+	writer := &ArchiveWriter{
+		gzip: gzip.NewWriter(w),
+		hash: sha256.New(),
+		manifest: ArchiveManifest{
+			Kind:           archiveKindManifest,
+			AggregateTypes: make(map[AggregateType]int64),
+		},
+		aggregates: make(map[string]struct{}),
+	}
+	if err := writer.writeLine(header); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write appends the event, events must be written in increasing global position.
+func (a *ArchiveWriter) Write(event Event) error {
+	if a.manifest.Events > 0 && event.GetPosition() <= a.manifest.LastPosition {
+		return errors.Errorf("event position %d is not after %d", event.GetPosition(), a.manifest.LastPosition)
+	}
+
+	archived := ArchivedEvent{
+		Kind:          archiveKindEvent,
+		Position:      event.GetPosition(),
+		AggregateID:   event.GetAggregateID(),
+		AggregateType: event.GetAggregateType(),
+		EventType:     event.GetEventType(),
+		Version:       event.GetVersion(),
+		Timestamp:     event.GetTimeStamp().UTC(),
+		Data:          event.GetData(),
+	}
+	if len(event.GetMetadata()) > 0 {
+		archived.Metadata = event.GetMetadata()
+	}
+	archived.Checksum = archived.computeChecksum()
+
+	line, err := encodeArchiveLine(archived)
+	if err != nil {
+		return errors.Wrapf(err, "position: %d", event.GetPosition())
+	}
+	if _, err := a.gzip.Write(line); err != nil {
+		return errors.Wrap(err, "gzip.Write")
+	}
+	a.hash.Write(line)
+
+	if a.manifest.Events == 0 {
+		a.manifest.FirstPosition = event.GetPosition()
+	}
+	a.manifest.LastPosition = event.GetPosition()
+	a.manifest.Events++
+	a.manifest.AggregateTypes[event.GetAggregateType()]++
+	a.aggregates[event.GetAggregateID()] = struct{}{}
+	return nil
+}
+
+// Close writes the manifest and flushes the archive, the underlying writer is not closed.
+func (a *ArchiveWriter) Close() (*ArchiveManifest, error) {
+	a.manifest.Aggregates = int64(len(a.aggregates))
+	a.manifest.Checksum = archiveChecksumAlgo + hex.EncodeToString(a.hash.Sum(nil))
+	if err := a.writeLine(a.manifest); err != nil {
+		return nil, err
+	}
+	if err := a.gzip.Close(); err != nil {
+		return nil, errors.Wrap(err, "gzip.Close")
+	}
+	manifest := a.manifest
+	return &manifest, nil
+}
+
+func (a *ArchiveWriter) writeLine(v any) error {
+	line, err := encodeArchiveLine(v)
+	if err != nil {
+		return err
+	}
+	if _, err := a.gzip.Write(line); err != nil {
+		return errors.Wrap(err, "gzip.Write")
+	}
+	return nil
+}
+
+// encodeArchiveLine encodes the line with trailing newline, without escaping HTML so event payloads keep their bytes.
+func encodeArchiveLine(v any) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := serializer.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, errors.Wrap(err, "encoder.Encode")
+	}
+	return buffer.Bytes(), nil
+}
+
+// ArchiveReader reads and verifies an archive written by ArchiveWriter. Every event is checked against its
+// checksum when read and the archive as a whole against the manifest when the last event has been read.
+type ArchiveReader struct {
+	gzip     *gzip.Reader
+	reader   *bufio.Reader
+	hash     hash.Hash
+	header   ArchiveHeader
+	manifest *ArchiveManifest
+	line     int
+	events   int64
+	position uint64
+}
+
+// NewArchiveReader reads the header of the archive from r.
+func NewArchiveReader(r io.Reader) (*ArchiveReader, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidArchive, err.Error())
+	}
+
+	reader := &ArchiveReader{
+		gzip:   gzipReader,
+		reader: bufio.NewReaderSize(gzipReader, 64*1024),
+		hash:   sha256.New(),
+	}
+
+	line, kind, err := reader.readLine()
+	if err == io.ErrUnexpectedEOF {
+		return nil, errors.Wrap(ErrInvalidArchive, "empty archive")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if kind != archiveKindHeader {
+		return nil, errors.Wrap(ErrInvalidArchive, "missing header")
+	}
+	if err := serializer.Unmarshal(line, &reader.header); err != nil {
+		return nil, errors.Wrapf(ErrInvalidArchive, "header: %v", err)
+	}
+	if reader.header.Format != ArchiveFormat || reader.header.FormatVersion != ArchiveFormatVersion {
+		return nil, errors.Wrapf(ErrInvalidArchive, "unsupported format %s version %d", reader.header.Format, reader.header.FormatVersion)
+	}
+	return reader, nil
+}
+
+// Header of the archive.
+func (a *ArchiveReader) Header() ArchiveHeader {
+	return a.header
+}
+
+// Manifest of the archive, nil until Next returned io.EOF.
+func (a *ArchiveReader) Manifest() *ArchiveManifest {
+	return a.manifest
+}
+
+// Next returns the next event with Position of the source store, or io.EOF after the manifest has been verified.
+func (a *ArchiveReader) Next() (*Event, error) {
+	if a.manifest != nil {
+		return nil, io.EOF
+	}
+
+	line, kind, err := a.readLine()
+	if err == io.ErrUnexpectedEOF {
+		return nil, errors.Wrapf(ErrInvalidArchive, "archive ends after %d events without manifest", a.events)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case archiveKindEvent:
+		var archived ArchivedEvent
+		if err := serializer.Unmarshal(line, &archived); err != nil {
+			return nil, errors.Wrapf(ErrInvalidArchive, "line %d: %v", a.line, err)
+		}
+		if archived.Checksum != archived.computeChecksum() {
+			return nil, errors.Wrapf(ErrArchiveChecksumMismatch, "line %d, position: %d", a.line, archived.Position)
+		}
+		if a.events > 0 && archived.Position <= a.position {
+			return nil, errors.Wrapf(ErrInvalidArchive, "line %d: position %d is not after %d", a.line, archived.Position, a.position)
+		}
+		a.hash.Write(line)
+		a.events++
+		a.position = archived.Position
+		return archived.toEvent(), nil
+
+	case archiveKindManifest:
+		var manifest ArchiveManifest
+		if err := serializer.Unmarshal(line, &manifest); err != nil {
+			return nil, errors.Wrapf(ErrInvalidArchive, "manifest: %v", err)
+		}
+		if manifest.Events != a.events {
+			return nil, errors.Wrapf(ErrArchiveChecksumMismatch, "manifest lists %d events, archive has %d", manifest.Events, a.events)
+		}
+		if checksum := archiveChecksumAlgo + hex.EncodeToString(a.hash.Sum(nil)); manifest.Checksum != checksum {
+			return nil, errors.Wrapf(ErrArchiveChecksumMismatch, "manifest checksum %s, archive %s", manifest.Checksum, checksum)
+		}
+		if _, _, err := a.readLine(); err != io.ErrUnexpectedEOF {
+			return nil, errors.Wrap(ErrInvalidArchive, "data after manifest")
+		}
+		a.manifest = &manifest
+		return nil, io.EOF
+
+	default:
+		return nil, errors.Wrapf(ErrInvalidArchive, "line %d: unknown kind %q", a.line, kind)
+	}
+}
+
+// Close releases the gzip reader, the underlying reader is not closed.
+func (a *ArchiveReader) Close() error {
+	return a.gzip.Close()
+}
+
+// readLine reads the next line with its trailing newline, io.ErrUnexpectedEOF when the archive ends.
+func (a *ArchiveReader) readLine() ([]byte, string, error) {
+	line, err := a.reader.ReadBytes('\n')
+	if err == io.EOF && len(line) == 0 {
+		return nil, "", io.ErrUnexpectedEOF
+	}
+	if err != nil && err != io.EOF {
+		return nil, "", errors.Wrap(ErrInvalidArchive, err.Error())
+	}
+	a.line++
+
+	var kind struct {
+		Kind string `json:"kind"`
+	}
+	if err := serializer.Unmarshal(line, &kind); err != nil {
+		return nil, "", errors.Wrapf(ErrInvalidArchive, "line %d: %v", a.line, err)
+	}
+	return line, kind.Kind, nil
+}
+
+func (e *ArchivedEvent) computeChecksum() string {
+	sum := sha256.New()
+	for _, field := range [][]byte{
+		[]byte(e.AggregateID),
+		[]byte(e.AggregateType),
+		[]byte(e.EventType),
+		[]byte(strconv.FormatUint(e.Version, 10)),
+		[]byte(e.Timestamp.UTC().Format(time.RFC3339Nano)),
+		compactJSON(e.Data),
+		compactJSON(e.Metadata),
+	} {
+		sum.Write(field)
+		sum.Write([]byte{0})
+	}
+	return archiveChecksumAlgo + hex.EncodeToString(sum.Sum(nil))
+}
+
+func (e *ArchivedEvent) toEvent() *Event {
+	event := &Event{
+		EventID:       strconv.FormatUint(e.Position, 10),
+		AggregateID:   e.AggregateID,
+		EventType:     e.EventType,
+		AggregateType: e.AggregateType,
+		Version:       e.Version,
+		Data:          compactJSON(e.Data),
+		Timestamp:     e.Timestamp.UTC(),
+		Position:      e.Position,
+	}
+	if metadata := compactJSON(e.Metadata); len(metadata) > 0 && !bytes.Equal(metadata, []byte("null")) {
+		event.Metadata = metadata
+	}
+	return event
+}
+
+// compactJSON removes insignificant whitespace, so checksums do not depend on how the JSON was encoded.
+func compactJSON(data []byte) []byte {
+	var buffer bytes.Buffer
+	if err := json.Compact(&buffer, data); err != nil {
+		return data
+	}
+	return buffer.Bytes()
+}
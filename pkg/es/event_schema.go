@@ -0,0 +1,336 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// SchemaValidationMode how events are validated against their schema before they are appended.
+type SchemaValidationMode string
+
+const (
+	// SchemaValidationStrict rejects events without schema or with payload violating it.
+	SchemaValidationStrict SchemaValidationMode = "strict"
+	// SchemaValidationWarn logs the violations and appends the events.
+	SchemaValidationWarn SchemaValidationMode = "warn"
+	// SchemaValidationOff appends events without validation.
+	SchemaValidationOff SchemaValidationMode = "off"
+)
+
+// EventSchema JSON Schema revision of the event type payload. Event types carry their version in the name,
+// the revisions of one event type describe the same payload and each revision is compatible with the previous,
+// so every stored payload of the type is valid under the latest revision.
+type EventSchema struct {
+	EventType     EventType       `json:"eventType"`
+	AggregateType AggregateType   `json:"aggregateType"`
+	Revision      int             `json:"revision"`
+	Description   string          `json:"description,omitempty"`
+	Schema        json.RawMessage `json:"schema"`
+	// Checksum hex sha256 of the compacted schema, revisions are immutable once registered.
+	Checksum     string     `json:"checksum"`
+	RegisteredAt *time.Time `json:"registeredAt,omitempty"`
+
+	compiled *jsonschema.Schema
+}
+
+// EventSchemaDefinition file describing an event type with its schema revisions in order.
+type EventSchemaDefinition struct {
+	EventType     EventType         `json:"eventType"`
+	AggregateType AggregateType     `json:"aggregateType"`
+	Description   string            `json:"description"`
+	Revisions     []json.RawMessage `json:"revisions"`
+}
+
+// SchemaViolation payload location failing a schema keyword.
+type SchemaViolation struct {
+	Location string `json:"location"`
+	Keyword  string `json:"keyword"`
+	Message  string `json:"message"`
+}
+
+// SchemaValidationError event payload violating the latest schema revision of its type.
+type SchemaValidationError struct {
+	EventType  EventType         `json:"eventType"`
+	Revision   int               `json:"revision"`
+	Violations []SchemaViolation `json:"violations"`
+}
+
+func (e *SchemaValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, fmt.Sprintf("%s: %s", violation.Location, violation.Message))
+	}
+	return fmt.Sprintf("%s: event type: %s, revision: %d: %s", ErrEventSchemaViolation, e.EventType, e.Revision, strings.Join(messages, "; "))
+}
+
+func (e *SchemaValidationError) Unwrap() error {
+	return ErrEventSchemaViolation
+}
+
+// SchemaStore persists registered schema revisions, so changes to registered revisions are detected across releases.
+type SchemaStore interface {
+	// LoadSchemas loads all stored revisions ordered by event type and revision.
+	LoadSchemas(ctx context.Context) ([]*EventSchema, error)
+
+	// SaveSchema stores the revision unless it is stored already.
+	SaveSchema(ctx context.Context, schema *EventSchema) error
+}
+
+// SchemaRegistry JSON Schemas of event payloads by event type and revision.
+type SchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[EventType][]*EventSchema
+}
+
+// NewSchemaRegistry creates empty registry.
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{schemas: make(map[EventType][]*EventSchema)}
+}
+
+// Register adds the schema as the next revision of the event type. The schema must compile and must not
+// break the latest revision, see CheckSchemaCompatibility.
+func (r *SchemaRegistry) Register(aggregateType AggregateType, eventType EventType, description string, schema []byte) (*EventSchema, error) {
+	if eventType == "" || aggregateType == "" {
+		return nil, errors.Wrap(ErrInvalidEventSchema, "event type and aggregate type are required")
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, schema); err != nil {
+		return nil, errors.Wrapf(ErrInvalidEventSchema, "event type: %s: %v", eventType, err)
+	}
+	checksum := sha256.Sum256(compact.Bytes())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revisions := r.schemas[eventType]
+	eventSchema := &EventSchema{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		Revision:      len(revisions) + 1,
+		Description:   description,
+		Schema:        json.RawMessage(compact.Bytes()),
+		Checksum:      hex.EncodeToString(checksum[:]),
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.AssertFormat = true
+	url := fmt.Sprintf("%s/%d.json", eventType, eventSchema.Revision)
+	if err := compiler.AddResource(url, bytes.NewReader(eventSchema.Schema)); err != nil {
+		return nil, errors.Wrapf(ErrInvalidEventSchema, "event type: %s, revision: %d: %v", eventType, eventSchema.Revision, err)
+	}
+	compiled, err := compiler.Compile(url)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidEventSchema, "event type: %s, revision: %d: %v", eventType, eventSchema.Revision, err)
+	}
+	eventSchema.compiled = compiled
+
+	if len(revisions) > 0 {
+		latest := revisions[len(revisions)-1]
+		if latest.AggregateType != aggregateType {
+			return nil, errors.Wrapf(ErrIncompatibleEventSchema, "event type: %s, aggregate type changed from %s to %s", eventType, latest.AggregateType, aggregateType)
+		}
+		breaking, err := CheckSchemaCompatibility(latest.Schema, eventSchema.Schema)
+		if err != nil {
+			return nil, err
+		}
+		if len(breaking) > 0 {
+			return nil, errors.Wrapf(ErrIncompatibleEventSchema, "event type: %s, revision %d breaks revision %d: %s",
+				eventType, eventSchema.Revision, latest.Revision, strings.Join(breaking, "; "))
+		}
+	}
+
+	r.schemas[eventType] = append(revisions, eventSchema)
+	return eventSchema, nil
+}
+
+// RegisterFS registers the revisions of every EventSchemaDefinition JSON file in the directory, files in name order.
+func (r *SchemaRegistry) RegisterFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return errors.Wrap(err, "fs.Glob")
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return errors.Wrap(err, "fs.ReadFile")
+		}
+
+		var definition EventSchemaDefinition
+		if err := json.Unmarshal(data, &definition); err != nil {
+			return errors.Wrapf(ErrInvalidEventSchema, "file: %s: %v", file, err)
+		}
+		if len(definition.Revisions) == 0 {
+			return errors.Wrapf(ErrInvalidEventSchema, "file: %s: no revisions", file)
+		}
+
+		for _, revision := range definition.Revisions {
+			if _, err := r.Register(definition.AggregateType, definition.EventType, definition.Description, revision); err != nil {
+				return errors.Wrapf(err, "file: %s", file)
+			}
+		}
+	}
+	return nil
+}
+
+// Latest returns the latest revision of the event type schema.
+func (r *SchemaRegistry) Latest(eventType EventType) (*EventSchema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := r.schemas[eventType]
+	if len(revisions) == 0 {
+		return nil, errors.Wrapf(ErrEventSchemaNotFound, "event type: %s", eventType)
+	}
+	return revisions[len(revisions)-1], nil
+}
+
+// Revisions returns all revisions of the event type schema, oldest first.
+func (r *SchemaRegistry) Revisions(eventType EventType) ([]*EventSchema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := r.schemas[eventType]
+	if len(revisions) == 0 {
+		return nil, errors.Wrapf(ErrEventSchemaNotFound, "event type: %s", eventType)
+	}
+	return append([]*EventSchema(nil), revisions...), nil
+}
+
+// LatestSchemas returns the latest revision of every event type ordered by aggregate type and event type.
+func (r *SchemaRegistry) LatestSchemas() []*EventSchema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schemas := make([]*EventSchema, 0, len(r.schemas))
+	for _, revisions := range r.schemas {
+		schemas = append(schemas, revisions[len(revisions)-1])
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		if schemas[i].AggregateType != schemas[j].AggregateType {
+			return schemas[i].AggregateType < schemas[j].AggregateType
+		}
+		return schemas[i].EventType < schemas[j].EventType
+	})
+	return schemas
+}
+
+// Validate checks the event payload against the latest revision of its type schema, violations are returned
+// as *SchemaValidationError.
+func (r *SchemaRegistry) Validate(event Event) error {
+	schema, err := r.Latest(event.GetEventType())
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(event.GetData()))
+	decoder.UseNumber()
+	var payload any
+	if err := decoder.Decode(&payload); err != nil {
+		return &SchemaValidationError{
+			EventType:  schema.EventType,
+			Revision:   schema.Revision,
+			Violations: []SchemaViolation{{Location: "/", Message: fmt.Sprintf("payload is not JSON: %v", err)}},
+		}
+	}
+
+	if err := schema.compiled.Validate(payload); err != nil {
+		var validationErr *jsonschema.ValidationError
+		if !errors.As(err, &validationErr) {
+			return errors.Wrap(err, "schema.Validate")
+		}
+		return &SchemaValidationError{EventType: schema.EventType, Revision: schema.Revision, Violations: schemaViolations(validationErr)}
+	}
+	return nil
+}
+
+// Sync checks registered revisions against the stored ones and stores new revisions. A stored revision with
+// different schema was changed after release and is rejected, revisions stored by newer releases are kept.
+func (r *SchemaRegistry) Sync(ctx context.Context, store SchemaStore) error {
+	stored, err := store.LoadSchemas(ctx)
+	if err != nil {
+		return errors.Wrap(err, "store.LoadSchemas")
+	}
+
+	storedSchemas := make(map[string]*EventSchema, len(stored))
+	for _, schema := range stored {
+		storedSchemas[schemaKey(schema)] = schema
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var changed []string
+	var added []*EventSchema
+	for _, revisions := range r.schemas {
+		for _, schema := range revisions {
+			storedSchema, ok := storedSchemas[schemaKey(schema)]
+			switch {
+			case !ok:
+				added = append(added, schema)
+			case storedSchema.Checksum != schema.Checksum:
+				changed = append(changed, fmt.Sprintf("%s revision %d", schema.EventType, schema.Revision))
+			default:
+				schema.RegisteredAt = storedSchema.RegisteredAt
+			}
+		}
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		return errors.Wrapf(ErrIncompatibleEventSchema, "registered revisions changed, add a new revision instead: %s", strings.Join(changed, ", "))
+	}
+
+	for _, schema := range added {
+		if err := store.SaveSchema(ctx, schema); err != nil {
+			return errors.Wrap(err, "store.SaveSchema")
+		}
+		now := time.Now().UTC()
+		schema.RegisteredAt = &now
+	}
+	return nil
+}
+
+func schemaKey(schema *EventSchema) string {
+	return fmt.Sprintf("%s/%d", schema.EventType, schema.Revision)
+}
+
+// schemaViolations flattens the validation error to the failing keywords.
+func schemaViolations(err *jsonschema.ValidationError) []SchemaViolation {
+	var violations []SchemaViolation
+	var flatten func(err *jsonschema.ValidationError)
+	flatten = func(err *jsonschema.ValidationError) {
+		if len(err.Causes) == 0 {
+			location := err.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			violations = append(violations, SchemaViolation{
+				Location: location,
+				Keyword:  err.KeywordLocation,
+				Message:  err.Message,
+			})
+			return
+		}
+		for _, cause := range err.Causes {
+			flatten(cause)
+		}
+	}
+	flatten(err)
+	return violations
+}
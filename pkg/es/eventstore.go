@@ -29,6 +29,29 @@ type EventStore interface {
 
 	// GetAllEvents loads all events from the store for replay purposes.
 	GetAllEvents(ctx context.Context) ([]Event, error)
+
+	// LoadEventsFromPosition loads up to limit events after the global position, ordered by position.
+	LoadEventsFromPosition(ctx context.Context, position uint64, limit int) ([]Event, error)
+
+	// GetHeadPosition returns the global position of the latest event in the store.
+	GetHeadPosition(ctx context.Context) (uint64, error)
+
+	// CountAggregates returns the number of aggregates of the type with events up to the global position.
+	CountAggregates(ctx context.Context, aggregateType AggregateType, position uint64) (int64, error)
+
+	// LoadAggregateIDs loads up to limit ids of streams matching the filter, ordered by id and greater than after.
+	LoadAggregateIDs(ctx context.Context, filter StreamFilter, after string, limit int) ([]string, error)
+
+	// LoadEventsByFilter loads up to limit events after the global position of streams matching the aggregate ids
+	// and type of the filter, up to its ToPosition, ordered by position.
+	LoadEventsByFilter(ctx context.Context, filter StreamFilter, position uint64, limit int) ([]Event, error)
+
+	// LoadEventsByPositions loads the events stored at the global positions.
+	LoadEventsByPositions(ctx context.Context, positions []uint64) ([]Event, error)
+
+	// ImportEvents appends events of other stores keeping their timestamps in one transaction without publishing them.
+	// With preservePositions events keep their global positions, otherwise positions are assigned to the events.
+	ImportEvents(ctx context.Context, events []Event, preservePositions bool) error
 }
 
 // SnapshotStore is an interface for an event sourcing Snapshot store.
@@ -41,4 +64,8 @@ type SnapshotStore interface {
 
 	// GetSnapshotByVersion load aggregate snapshot by version.
 	GetSnapshotByVersion(ctx context.Context, id string, version uint64) (*Snapshot, error)
+
+	// DeleteSnapshots deletes the aggregate snapshot of the version or all its snapshots with version 0,
+	// returns the number of deleted snapshots.
+	DeleteSnapshots(ctx context.Context, id string, version uint64) (int64, error)
 }
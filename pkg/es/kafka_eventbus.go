@@ -9,6 +9,9 @@ import (
 	"github.com/segmentio/kafka-go"
 	"github.com/th1enq/es-demo/pkg/es/serializer"
 	kafkaClient "github.com/th1enq/es-demo/pkg/kafka"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // KafkaEventsBusConfig kafka eventbus config.
@@ -32,18 +35,31 @@ func NewKafkaEventsBus(producer kafkaClient.Producer, cfg KafkaEventsBusConfig)
 
 // ProcessEvents serialize to json and publish es.Event's to the kafka topic.
 func (e *kafkaEventsBus) ProcessEvents(ctx context.Context, events []Event) error {
+	topic := GetTopicName(e.cfg.TopicPrefix, string(events[0].GetAggregateType()))
+
+	ctx, span := tracing.StartSpan(ctx, "kafkaEventsBus.ProcessEvents", trace.WithSpanKind(trace.SpanKindProducer))
+	span.SetAttributes(
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.destination.name", topic),
+		attribute.String("aggregate_id", events[0].GetAggregateID()),
+		attribute.Int("events", len(events)),
+	)
+	defer span.End()
 
 	eventsBytes, err := serializer.Marshal(events)
 	if err != nil {
-		return errors.Wrap(err, "serializer.Marshal")
+		return tracing.TraceErr(span, errors.Wrap(err, "serializer.Marshal"))
 	}
 
-	return e.producer.PublishMessage(ctx, kafka.Message{
-		Topic:   GetTopicName(e.cfg.TopicPrefix, string(events[0].GetAggregateType())),
+	headers := make([]kafka.Header, 0, len(e.cfg.Headers))
+	headers = append(headers, e.cfg.Headers...)
+
+	return tracing.TraceErr(span, e.producer.PublishMessage(ctx, kafka.Message{
+		Topic:   topic,
 		Value:   eventsBytes,
-		Headers: nil,
+		Headers: tracing.InjectKafkaHeaders(ctx, headers),
 		Time:    time.Now().UTC(),
-	})
+	}))
 }
 
 func GetTopicName(eventStorePrefix string, aggregateType string) string {
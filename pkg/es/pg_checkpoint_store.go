@@ -0,0 +1,116 @@
+package es
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type pgCheckpointStore struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewPgCheckpointStore postgres CheckpointStore constructor.
+func NewPgCheckpointStore(db *pgxpool.Pool, logger *zap.Logger) *pgCheckpointStore {
+	return &pgCheckpointStore{db: db, logger: logger}
+}
+
+func (c *pgCheckpointStore) GetStreamCheckpoint(ctx context.Context, projection string, aggregateID string) (uint64, error) {
+	var version uint64
+	if err := c.db.QueryRow(ctx, getStreamCheckpointQuery, projection, aggregateID).Scan(&version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		c.logger.Error("(Get Stream Checkpoint) db.QueryRow error", zap.String("projection", projection), zap.Error(err))
+		return 0, errors.Wrap(err, "db.QueryRow")
+	}
+	return version, nil
+}
+
+func (c *pgCheckpointStore) SaveCheckpoint(ctx context.Context, projection string, event Event) error {
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		c.logger.Error("(Save Checkpoint) db.Begin error", zap.Error(err))
+		return errors.Wrap(err, "db.Begin")
+	}
+
+	if _, err := tx.Exec(ctx, saveStreamCheckpointQuery, projection, event.GetAggregateID(), event.GetVersion(), event.GetPosition()); err != nil {
+		c.logger.Error("(Save Checkpoint) tx.Exec error", zap.String("projection", projection), zap.Error(err))
+		return RollBackTx(ctx, tx, err)
+	}
+
+	if _, err := tx.Exec(ctx, saveProjectionPositionQuery, projection, event.GetPosition()); err != nil {
+		c.logger.Error("(Save Checkpoint) tx.Exec error", zap.String("projection", projection), zap.Error(err))
+		return RollBackTx(ctx, tx, err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (c *pgCheckpointStore) GetPosition(ctx context.Context, projection string) (uint64, error) {
+	var position uint64
+	if err := c.db.QueryRow(ctx, getProjectionPositionQuery, projection).Scan(&position); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		c.logger.Error("(Get Position) db.QueryRow error", zap.String("projection", projection), zap.Error(err))
+		return 0, errors.Wrap(err, "db.QueryRow")
+	}
+	return position, nil
+}
+
+func (c *pgCheckpointStore) ResetCheckpoints(ctx context.Context, projection string) error {
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		c.logger.Error("(Reset Checkpoints) db.Begin error", zap.Error(err))
+		return errors.Wrap(err, "db.Begin")
+	}
+
+	if _, err := tx.Exec(ctx, deleteStreamCheckpointsQuery, projection); err != nil {
+		return RollBackTx(ctx, tx, err)
+	}
+
+	if _, err := tx.Exec(ctx, deleteProjectionPositionQuery, projection); err != nil {
+		return RollBackTx(ctx, tx, err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (c *pgCheckpointStore) ResetStreamCheckpoint(ctx context.Context, projection string, aggregateID string) error {
+	if _, err := c.db.Exec(ctx, deleteStreamCheckpointQuery, projection, aggregateID); err != nil {
+		c.logger.Error("(Reset Stream Checkpoint) db.Exec error", zap.String("projection", projection), zap.String("aggregate_id", aggregateID), zap.Error(err))
+		return errors.Wrap(err, "db.Exec")
+	}
+	return nil
+}
+
+func (c *pgCheckpointStore) CopyCheckpoints(ctx context.Context, from, to string) error {
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		c.logger.Error("(Copy Checkpoints) db.Begin error", zap.Error(err))
+		return errors.Wrap(err, "db.Begin")
+	}
+
+	if _, err := tx.Exec(ctx, deleteStreamCheckpointsQuery, to); err != nil {
+		return RollBackTx(ctx, tx, err)
+	}
+
+	if _, err := tx.Exec(ctx, deleteProjectionPositionQuery, to); err != nil {
+		return RollBackTx(ctx, tx, err)
+	}
+
+	if _, err := tx.Exec(ctx, copyStreamCheckpointsQuery, from, to); err != nil {
+		return RollBackTx(ctx, tx, err)
+	}
+
+	if _, err := tx.Exec(ctx, copyProjectionPositionQuery, from, to); err != nil {
+		return RollBackTx(ctx, tx, err)
+	}
+
+	return tx.Commit(ctx)
+}
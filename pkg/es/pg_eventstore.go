@@ -2,10 +2,13 @@ package es
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -19,6 +22,7 @@ type pgEventStore struct {
 	db         *pgxpool.Pool
 	eventBus   EventsBus
 	serializer Serializer
+	schemas    *SchemaRegistry
 }
 
 func NewPgEventStore(
@@ -27,6 +31,7 @@ func NewPgEventStore(
 	serializer Serializer,
 	logger *zap.Logger,
 	eventBus EventsBus,
+	schemas *SchemaRegistry,
 ) *pgEventStore {
 	return &pgEventStore{
 		cfg:        cfg,
@@ -34,61 +39,26 @@ func NewPgEventStore(
 		serializer: serializer,
 		logger:     logger,
 		eventBus:   eventBus,
+		schemas:    schemas,
 	}
 }
 
 // SaveEvents save aggregate uncommitted events as one batch and process with event bus using transaction
-func (p *pgEventStore) SaveEvents(ctx context.Context, events []Event) error {
+func (p *pgEventStore) SaveEvents(ctx context.Context, events []Event) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "pgEventStore.SaveEvents")
+	span.SetAttributes(attribute.Int("events", len(events)))
+	defer func() {
+		tracing.TraceErr(span, err)
+		span.End()
+	}()
+
 	tx, err := p.db.Begin(ctx)
 	if err != nil {
 		p.logger.Error("(Save Events) db.Begin error", zap.Error(err))
 		return errors.Wrap(err, "db.Begin")
 	}
 
-	if err := p.handleConcurrency(ctx, tx, events); err != nil {
-		return RollBackTx(ctx, tx, err)
-	}
-
-	// If aggregate changes has single event save it
-	if len(events) == 1 {
-		_, err := tx.Exec(
-			ctx,
-			saveEventQuery,
-			events[0].GetAggregateID(),
-			events[0].GetAggregateType(),
-			events[0].GetEventType(),
-			events[0].GetData(),
-			events[0].GetVersion(),
-			events[0].GetMetadata(),
-		)
-		if err != nil {
-			p.logger.Error("(Save Events) tx.Exec error", zap.Error(err))
-			return RollBackTx(ctx, tx, err)
-		}
-
-		p.logger.Debug("(Save Events) result",
-			zap.String("aggregate_id", events[0].GetAggregateID()),
-			zap.Uint64("event_version", events[0].GetVersion()),
-		)
-
-		return tx.Commit(ctx)
-	}
-
-	batch := &pgx.Batch{}
-	for _, event := range events {
-		batch.Queue(
-			saveEventQuery,
-			event.GetAggregateID(),
-			event.GetAggregateType(),
-			event.GetEventType(),
-			event.GetData(),
-			event.GetVersion(),
-			event.GetMetadata(),
-		)
-	}
-
-	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
-		p.logger.Error("(Save Events) tx.SendBatch error", zap.Error(err))
+	if err := p.saveEventsTx(ctx, tx, events); err != nil {
 		return RollBackTx(ctx, tx, err)
 	}
 
@@ -97,39 +67,22 @@ func (p *pgEventStore) SaveEvents(ctx context.Context, events []Event) error {
 
 // LoadEvents load aggregate events by id
 func (p *pgEventStore) LoadEvents(ctx context.Context, aggregateID string) ([]Event, error) {
+	ctx, span := tracing.StartSpan(ctx, "pgEventStore.LoadEvents")
+	span.SetAttributes(attribute.String("aggregate_id", aggregateID))
+	defer span.End()
+
 	rows, err := p.db.Query(ctx, getEventsQuery, aggregateID)
 	if err != nil {
 		p.logger.Error("(Load Events) db.Query error", zap.Error(err))
-		return nil, errors.Wrap(err, "db.Query")
+		return nil, tracing.TraceErr(span, errors.Wrap(err, "db.Query"))
 	}
 	defer rows.Close()
 
-	events := make([]Event, 0, eventsCapacity)
-
-	for rows.Next() {
-		var event Event
-		if err := rows.Scan(
-			&event.EventID,
-			&event.AggregateID,
-			&event.AggregateType,
-			&event.EventType,
-			&event.Data,
-			&event.Version,
-			&event.Timestamp,
-			&event.Metadata,
-		); err != nil {
-			p.logger.Error("(Load Events) rows.Scan error", zap.Error(err))
-			return nil, errors.Wrap(err, "rows.Scan")
-		}
-
-		events = append(events, event)
-	}
-
-	if err := rows.Err(); err != nil {
-		p.logger.Error("(Load Events) rows.Err error", zap.Error(err))
-		return nil, errors.Wrap(err, "rows.Err")
+	events, err := scanPositionedEvents(rows, eventsCapacity)
+	if err != nil {
+		p.logger.Error("(Load Events) scan error", zap.Error(err))
+		return nil, tracing.TraceErr(span, err)
 	}
-
 	return events, nil
 }
 
@@ -268,13 +221,17 @@ func (p *pgEventStore) loadAggregateEventsByVersionRange(ctx context.Context, ag
 
 // Exists check for exists aggregate by id
 func (p *pgEventStore) Exists(ctx context.Context, aggregateID string) (bool, error) {
+	ctx, span := tracing.StartSpan(ctx, "pgEventStore.Exists")
+	span.SetAttributes(attribute.String("aggregate_id", aggregateID))
+	defer span.End()
+
 	var id string
 	if err := p.db.QueryRow(ctx, getEventQuery, aggregateID).Scan(&id); err != nil {
 		if err == pgx.ErrNoRows {
 			return false, nil
 		}
 		p.logger.Error("(Exists) db.QueryRow error", zap.Error(err))
-		return false, errors.Wrap(err, "db.QueryRow")
+		return false, tracing.TraceErr(span, errors.Wrap(err, "db.QueryRow"))
 	}
 	p.logger.Debug("(Exists Aggregate)", zap.String("id", id))
 
@@ -289,33 +246,11 @@ func (p *pgEventStore) loadEventsByVersion(ctx context.Context, aggregateID stri
 	}
 	defer rows.Close()
 
-	events := make([]Event, 0, p.cfg.SnapshotFrequency)
-
-	for rows.Next() {
-		var event Event
-
-		if err := rows.Scan(
-			&event.EventID,
-			&event.AggregateID,
-			&event.AggregateType,
-			&event.EventType,
-			&event.Data,
-			&event.Version,
-			&event.Timestamp,
-			&event.Metadata,
-		); err != nil {
-			p.logger.Error("(Load Events) rows.Scan error", zap.Error(err))
-			return nil, errors.Wrap(err, "rows.Scan")
-		}
-
-		events = append(events, event)
-	}
-
-	if err := rows.Err(); err != nil {
-		p.logger.Error("(Load Events) rows.Err error", zap.Error(err))
-		return nil, errors.Wrap(err, "rows.Err")
+	events, err := scanPositionedEvents(rows, int(p.cfg.SnapshotFrequency))
+	if err != nil {
+		p.logger.Error("(Load Events) scan error", zap.Error(err))
+		return nil, err
 	}
-
 	return events, nil
 }
 
@@ -372,34 +307,39 @@ func (p *pgEventStore) loadEventsByVersionTx(ctx context.Context, tx pgx.Tx, agg
 	}
 	defer rows.Close()
 
-	events := make([]Event, 0, p.cfg.SnapshotFrequency)
-
-	for rows.Next() {
-		var event Event
-
-		if err := rows.Scan(
-			&event.EventID,
-			&event.AggregateID,
-			&event.AggregateType,
-			&event.EventType,
-			&event.Data,
-			&event.Version,
-			&event.Timestamp,
-			&event.Metadata,
-		); err != nil {
-			p.logger.Error("(Load Events) rows.Scan error", zap.Error(err))
-			return nil, errors.Wrap(err, "rows.Scan")
-		}
-
-		events = append(events, event)
+	events, err := scanPositionedEvents(rows, int(p.cfg.SnapshotFrequency))
+	if err != nil {
+		p.logger.Error("(Load Events) scan error", zap.Error(err))
+		return nil, err
 	}
+	return events, nil
+}
 
-	if err := rows.Err(); err != nil {
-		p.logger.Error("(Load Events) rows.Err error", zap.Error(err))
-		return nil, errors.Wrap(err, "rows.Err")
+// validateEvents validates event payloads against the schema registry, in warn mode violations are only logged.
+func (p *pgEventStore) validateEvents(events []Event) error {
+	if p.schemas == nil || p.cfg.SchemaValidation == SchemaValidationOff {
+		return nil
 	}
 
-	return events, nil
+	for _, event := range events {
+		err := p.schemas.Validate(event)
+		if err == nil {
+			continue
+		}
+		if p.cfg.SchemaValidation != SchemaValidationStrict {
+			p.logger.Warn("(Validate Events) event violates schema",
+				zap.String("aggregate_id", event.GetAggregateID()),
+				zap.String("event_type", string(event.GetEventType())),
+				zap.Error(err))
+			continue
+		}
+		p.logger.Error("(Validate Events) event rejected by schema",
+			zap.String("aggregate_id", event.GetAggregateID()),
+			zap.String("event_type", string(event.GetEventType())),
+			zap.Error(err))
+		return errors.Wrapf(err, "aggregate_id: %s, version: %d", event.GetAggregateID(), event.GetVersion())
+	}
+	return nil
 }
 
 func (p *pgEventStore) handleConcurrency(ctx context.Context, tx pgx.Tx, events []Event) error {
@@ -415,12 +355,18 @@ func (p *pgEventStore) handleConcurrency(ctx context.Context, tx pgx.Tx, events
 }
 
 func (p *pgEventStore) saveEventsTx(ctx context.Context, tx pgx.Tx, events []Event) error {
+	if err := p.validateEvents(events); err != nil {
+		return err
+	}
+
 	if err := p.handleConcurrency(ctx, tx, events); err != nil {
 		return err
 	}
 
+	// event positions are assigned by the store, so they are scanned back before events are published,
+	// the position is the event id on every load path and published events must carry the same id
 	if len(events) == 1 {
-		result, err := tx.Exec(
+		err := tx.QueryRow(
 			ctx,
 			saveEventQuery,
 			events[0].GetAggregateID(),
@@ -429,16 +375,17 @@ func (p *pgEventStore) saveEventsTx(ctx context.Context, tx pgx.Tx, events []Eve
 			events[0].GetData(),
 			events[0].GetVersion(),
 			events[0].GetMetadata(),
-		)
+		).Scan(&events[0].Position)
 		if err != nil {
-			p.logger.Error("(Save Events) tx.Exec error", zap.Error(err))
-			return errors.Wrap(err, "tx.Exec")
+			p.logger.Error("(Save Events) tx.QueryRow error", zap.Error(err))
+			return errors.Wrap(err, "tx.QueryRow")
 		}
+		events[0].EventID = strconv.FormatUint(events[0].Position, 10)
 
 		p.logger.Debug("(saveEventsTx)",
-			zap.String("result", result.String()),
 			zap.String("aggregate_id", events[0].GetAggregateID()),
 			zap.Uint64("event_version", events[0].GetVersion()),
+			zap.Uint64("position", events[0].GetPosition()),
 		)
 
 		return nil
@@ -457,7 +404,17 @@ func (p *pgEventStore) saveEventsTx(ctx context.Context, tx pgx.Tx, events []Eve
 		)
 	}
 
-	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+	br := tx.SendBatch(ctx, batch)
+	for i := range events {
+		if err := br.QueryRow().Scan(&events[i].Position); err != nil {
+			p.logger.Error("(Save Events) batch.QueryRow error", zap.Error(err))
+			_ = br.Close()
+			return errors.Wrap(err, "batch.QueryRow")
+		}
+		events[i].EventID = strconv.FormatUint(events[i].Position, 10)
+	}
+
+	if err := br.Close(); err != nil {
 		p.logger.Error("(Save Events) tx.SendBatch error", zap.Error(err))
 		return errors.Wrap(err, "tx.SendBatch")
 	}
@@ -490,19 +447,52 @@ func (p *pgEventStore) processEvents(ctx context.Context, events []Event) error
 
 // GetAllEvents load all events from the event store for replay purposes
 func (p *pgEventStore) GetAllEvents(ctx context.Context) ([]Event, error) {
+	ctx, span := tracing.StartSpan(ctx, "pgEventStore.GetAllEvents")
+	defer span.End()
+
 	rows, err := p.db.Query(ctx, getAllEventsQuery)
 	if err != nil {
 		p.logger.Error("(Get All Events) db.Query error", zap.Error(err))
-		return nil, errors.Wrap(err, "db.Query")
+		return nil, tracing.TraceErr(span, errors.Wrap(err, "db.Query"))
+	}
+	defer rows.Close()
+
+	events, err := scanPositionedEvents(rows, 0)
+	if err != nil {
+		p.logger.Error("(Get All Events) scan error", zap.Error(err))
+		return nil, tracing.TraceErr(span, err)
+	}
+
+	p.logger.Info("(Get All Events) loaded events", zap.Int("count", len(events)))
+	return events, nil
+}
+
+func RollBackTx(ctx context.Context, tx pgx.Tx, err error) error {
+	if err := tx.Rollback(ctx); err != nil {
+		return errors.Wrap(err, "tx.Rollback")
+	}
+	return err
+}
+
+// LoadEventsFromPosition load up to limit events with global position greater than position, ordered by position
+func (p *pgEventStore) LoadEventsFromPosition(ctx context.Context, position uint64, limit int) ([]Event, error) {
+	ctx, span := tracing.StartSpan(ctx, "pgEventStore.LoadEventsFromPosition")
+	span.SetAttributes(attribute.Int64("position", int64(position)), attribute.Int("limit", limit))
+	defer span.End()
+
+	rows, err := p.db.Query(ctx, getEventsFromPositionQuery, position, limit)
+	if err != nil {
+		p.logger.Error("(Load Events From Position) db.Query error", zap.Error(err))
+		return nil, tracing.TraceErr(span, errors.Wrap(err, "db.Query"))
 	}
 	defer rows.Close()
 
-	events := make([]Event, 0)
+	events := make([]Event, 0, limit)
 
 	for rows.Next() {
 		var event Event
 		if err := rows.Scan(
-			&event.EventID,
+			&event.Position,
 			&event.AggregateID,
 			&event.AggregateType,
 			&event.EventType,
@@ -511,25 +501,252 @@ func (p *pgEventStore) GetAllEvents(ctx context.Context) ([]Event, error) {
 			&event.Timestamp,
 			&event.Metadata,
 		); err != nil {
-			p.logger.Error("(Get All Events) rows.Scan error", zap.Error(err))
-			return nil, errors.Wrap(err, "rows.Scan")
+			p.logger.Error("(Load Events From Position) rows.Scan error", zap.Error(err))
+			return nil, tracing.TraceErr(span, errors.Wrap(err, "rows.Scan"))
 		}
-
+		event.EventID = strconv.FormatUint(event.Position, 10)
 		events = append(events, event)
 	}
 
 	if err := rows.Err(); err != nil {
-		p.logger.Error("(Get All Events) rows.Err error", zap.Error(err))
-		return nil, errors.Wrap(err, "rows.Err")
+		p.logger.Error("(Load Events From Position) rows.Err error", zap.Error(err))
+		return nil, tracing.TraceErr(span, errors.Wrap(err, "rows.Err"))
 	}
 
-	p.logger.Info("(Get All Events) loaded events", zap.Int("count", len(events)))
 	return events, nil
 }
 
-func RollBackTx(ctx context.Context, tx pgx.Tx, err error) error {
-	if err := tx.Rollback(ctx); err != nil {
-		return errors.Wrap(err, "tx.Rollback")
+// GetHeadPosition get global position of the latest event in the event store
+func (p *pgEventStore) GetHeadPosition(ctx context.Context) (uint64, error) {
+	var position uint64
+	if err := p.db.QueryRow(ctx, getHeadPositionQuery).Scan(&position); err != nil {
+		p.logger.Error("(Get Head Position) db.QueryRow error", zap.Error(err))
+		return 0, errors.Wrap(err, "db.QueryRow")
 	}
-	return err
+	return position, nil
+}
+
+// CountAggregates count aggregates of the type which have events up to the global position
+func (p *pgEventStore) CountAggregates(ctx context.Context, aggregateType AggregateType, position uint64) (int64, error) {
+	var count int64
+	if err := p.db.QueryRow(ctx, countAggregatesQuery, aggregateType, position).Scan(&count); err != nil {
+		p.logger.Error("(Count Aggregates) db.QueryRow error", zap.Error(err))
+		return 0, errors.Wrap(err, "db.QueryRow")
+	}
+	return count, nil
+}
+
+// LoadAggregateIDs load ids of streams matching the filter after the given id
+func (p *pgEventStore) LoadAggregateIDs(ctx context.Context, filter StreamFilter, after string, limit int) ([]string, error) {
+	ctx, span := tracing.StartSpan(ctx, "pgEventStore.LoadAggregateIDs")
+	span.SetAttributes(attribute.String("after", after), attribute.Int("limit", limit))
+	defer span.End()
+
+	aggregateIDs := filter.AggregateIDs
+	if aggregateIDs == nil {
+		aggregateIDs = []string{}
+	}
+
+	rows, err := p.db.Query(
+		ctx,
+		getAggregateIDsQuery,
+		string(filter.AggregateType),
+		aggregateIDs,
+		filter.FromPosition,
+		filter.ToPosition,
+		filter.From,
+		filter.To,
+		after,
+		limit,
+	)
+	if err != nil {
+		p.logger.Error("(Load Aggregate IDs) db.Query error", zap.Error(err))
+		return nil, tracing.TraceErr(span, errors.Wrap(err, "db.Query"))
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0, limit)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			p.logger.Error("(Load Aggregate IDs) rows.Scan error", zap.Error(err))
+			return nil, tracing.TraceErr(span, errors.Wrap(err, "rows.Scan"))
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		p.logger.Error("(Load Aggregate IDs) rows.Err error", zap.Error(err))
+		return nil, tracing.TraceErr(span, errors.Wrap(err, "rows.Err"))
+	}
+
+	return ids, nil
+}
+
+// LoadEventsByFilter load up to limit events after the global position of streams matching the filter, ordered by position
+func (p *pgEventStore) LoadEventsByFilter(ctx context.Context, filter StreamFilter, position uint64, limit int) ([]Event, error) {
+	ctx, span := tracing.StartSpan(ctx, "pgEventStore.LoadEventsByFilter")
+	span.SetAttributes(attribute.Int64("position", int64(position)), attribute.Int("limit", limit))
+	defer span.End()
+
+	aggregateIDs := filter.AggregateIDs
+	if aggregateIDs == nil {
+		aggregateIDs = []string{}
+	}
+
+	rows, err := p.db.Query(
+		ctx,
+		getEventsByFilterQuery,
+		position,
+		filter.ToPosition,
+		string(filter.AggregateType),
+		aggregateIDs,
+		limit,
+	)
+	if err != nil {
+		p.logger.Error("(Load Events By Filter) db.Query error", zap.Error(err))
+		return nil, tracing.TraceErr(span, errors.Wrap(err, "db.Query"))
+	}
+	defer rows.Close()
+
+	events, err := scanPositionedEvents(rows, limit)
+	if err != nil {
+		p.logger.Error("(Load Events By Filter) scan error", zap.Error(err))
+		return nil, tracing.TraceErr(span, err)
+	}
+	return events, nil
+}
+
+// LoadEventsByPositions load the events stored at the global positions, ordered by position
+func (p *pgEventStore) LoadEventsByPositions(ctx context.Context, positions []uint64) ([]Event, error) {
+	ctx, span := tracing.StartSpan(ctx, "pgEventStore.LoadEventsByPositions")
+	span.SetAttributes(attribute.Int("positions", len(positions)))
+	defer span.End()
+
+	rows, err := p.db.Query(ctx, getEventsByPositionsQuery, positions)
+	if err != nil {
+		p.logger.Error("(Load Events By Positions) db.Query error", zap.Error(err))
+		return nil, tracing.TraceErr(span, errors.Wrap(err, "db.Query"))
+	}
+	defer rows.Close()
+
+	events, err := scanPositionedEvents(rows, len(positions))
+	if err != nil {
+		p.logger.Error("(Load Events By Positions) scan error", zap.Error(err))
+		return nil, tracing.TraceErr(span, err)
+	}
+	return events, nil
+}
+
+// ImportEvents append events exported from another store in one transaction, keeping their timestamps
+func (p *pgEventStore) ImportEvents(ctx context.Context, events []Event, preservePositions bool) (err error) {
+	if len(events) == 0 {
+		return nil
+	}
+
+	ctx, span := tracing.StartSpan(ctx, "pgEventStore.ImportEvents")
+	span.SetAttributes(attribute.Int("events", len(events)), attribute.Bool("preserve_positions", preservePositions))
+	defer func() {
+		tracing.TraceErr(span, err)
+		span.End()
+	}()
+
+	if err := p.validateEvents(events); err != nil {
+		return err
+	}
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		p.logger.Error("(Import Events) db.Begin error", zap.Error(err))
+		return errors.Wrap(err, "db.Begin")
+	}
+
+	batch := &pgx.Batch{}
+	for _, event := range events {
+		if preservePositions {
+			batch.Queue(
+				importEventAtPositionQuery,
+				event.GetPosition(),
+				event.GetAggregateID(),
+				event.GetAggregateType(),
+				event.GetEventType(),
+				event.GetData(),
+				event.GetVersion(),
+				event.GetMetadata(),
+				event.GetTimeStamp(),
+			)
+			continue
+		}
+		batch.Queue(
+			importEventQuery,
+			event.GetAggregateID(),
+			event.GetAggregateType(),
+			event.GetEventType(),
+			event.GetData(),
+			event.GetVersion(),
+			event.GetMetadata(),
+			event.GetTimeStamp(),
+		)
+	}
+
+	br := tx.SendBatch(ctx, batch)
+	for i := range events {
+		if preservePositions {
+			if _, err := br.Exec(); err != nil {
+				p.logger.Error("(Import Events) batch.Exec error", zap.Error(err))
+				_ = br.Close()
+				return RollBackTx(ctx, tx, errors.Wrapf(err, "batch.Exec aggregateID: %s, version: %d", events[i].GetAggregateID(), events[i].GetVersion()))
+			}
+			continue
+		}
+		if err := br.QueryRow().Scan(&events[i].Position); err != nil {
+			p.logger.Error("(Import Events) batch.QueryRow error", zap.Error(err))
+			_ = br.Close()
+			return RollBackTx(ctx, tx, errors.Wrapf(err, "batch.QueryRow aggregateID: %s, version: %d", events[i].GetAggregateID(), events[i].GetVersion()))
+		}
+	}
+	if err := br.Close(); err != nil {
+		p.logger.Error("(Import Events) batch.Close error", zap.Error(err))
+		return RollBackTx(ctx, tx, errors.Wrap(err, "batch.Close"))
+	}
+
+	// positions written explicitly do not advance the sequence, new events must be appended after them
+	if preservePositions {
+		if _, err := tx.Exec(ctx, syncEventPositionQuery); err != nil {
+			p.logger.Error("(Import Events) sync position error", zap.Error(err))
+			return RollBackTx(ctx, tx, errors.Wrap(err, "tx.Exec"))
+		}
+	}
+
+	for i := range events {
+		events[i].EventID = strconv.FormatUint(events[i].Position, 10)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func scanPositionedEvents(rows pgx.Rows, capacity int) ([]Event, error) {
+	events := make([]Event, 0, capacity)
+	for rows.Next() {
+		var event Event
+		if err := rows.Scan(
+			&event.Position,
+			&event.AggregateID,
+			&event.AggregateType,
+			&event.EventType,
+			&event.Data,
+			&event.Version,
+			&event.Timestamp,
+			&event.Metadata,
+		); err != nil {
+			return nil, errors.Wrap(err, "rows.Scan")
+		}
+		event.EventID = strconv.FormatUint(event.Position, 10)
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+	return events, nil
 }
@@ -0,0 +1,57 @@
+package es
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRows serves rows of event columns in query order, methods not used by the scan are left unimplemented.
+type memoryRows struct {
+	pgx.Rows
+	rows [][]any
+	next int
+}
+
+func (r *memoryRows) Next() bool {
+	r.next++
+	return r.next <= len(r.rows)
+}
+
+func (r *memoryRows) Scan(dest ...any) error {
+	for i, value := range r.rows[r.next-1] {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func (r *memoryRows) Err() error {
+	return nil
+}
+
+func TestScanPositionedEvents(t *testing.T) {
+	timestamp := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := &memoryRows{rows: [][]any{
+		{uint64(40), "account-1", AggregateType("BankAccount"), EventType("BALANCE_DEPOSITED_V1"), []byte(`{}`), uint64(2), timestamp, []byte(nil)},
+		{uint64(45), "account-1", AggregateType("BankAccount"), EventType("BALANCE_WITHDRAWED_V1"), []byte(`{}`), uint64(3), timestamp, []byte(nil)},
+	}}
+
+	events, err := scanPositionedEvents(rows, 2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	for i, expected := range []struct {
+		position uint64
+		eventID  string
+		version  uint64
+	}{{40, "40", 2}, {45, "45", 3}} {
+		assert.Equal(t, expected.position, events[i].GetPosition(), "stream events carry their global position")
+		assert.Equal(t, expected.eventID, events[i].GetEventID(), "event id is the position")
+		assert.Equal(t, expected.version, events[i].GetVersion())
+		assert.Equal(t, timestamp, events[i].GetTimeStamp())
+	}
+}
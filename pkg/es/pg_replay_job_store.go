@@ -0,0 +1,199 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type pgReplayJobStore struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewPgReplayJobStore postgres ReplayJobStore constructor.
+func NewPgReplayJobStore(db *pgxpool.Pool, logger *zap.Logger) *pgReplayJobStore {
+	return &pgReplayJobStore{db: db, logger: logger}
+}
+
+func (s *pgReplayJobStore) Create(ctx context.Context, job *ReplayJob) error {
+	var filter []byte
+	if job.Filter != nil {
+		data, err := json.Marshal(job.Filter)
+		if err != nil {
+			return errors.Wrap(err, "json.Marshal")
+		}
+		filter = data
+	}
+
+	_, err := s.db.Exec(
+		ctx,
+		createReplayJobQuery,
+		job.ID,
+		job.Projection,
+		job.Status,
+		job.Recreate,
+		job.TargetIndex,
+		filter,
+		job.Cursor,
+		job.Position,
+		job.HeadPosition,
+		job.Total,
+		job.Processed,
+		job.Skipped,
+		job.Failed,
+		job.Errors,
+		job.Error,
+		job.CreatedAt,
+		job.UpdatedAt,
+		job.StartedAt,
+		job.FinishedAt,
+		job.Owner,
+		job.HeartbeatAt,
+	)
+	if err != nil {
+		s.logger.Error("(Create Replay Job) db.Exec error", zap.String("id", job.ID), zap.Error(err))
+		return errors.Wrap(err, "db.Exec")
+	}
+	return nil
+}
+
+func (s *pgReplayJobStore) Update(ctx context.Context, job *ReplayJob) error {
+	result, err := s.db.Exec(
+		ctx,
+		updateReplayJobQuery,
+		job.ID,
+		job.Status,
+		job.Position,
+		job.HeadPosition,
+		job.Total,
+		job.Processed,
+		job.Skipped,
+		job.Failed,
+		job.Errors,
+		job.Error,
+		job.UpdatedAt,
+		job.StartedAt,
+		job.FinishedAt,
+		job.TargetIndex,
+		job.Cursor,
+		job.Owner,
+		job.HeartbeatAt,
+	)
+	if err != nil {
+		s.logger.Error("(Update Replay Job) db.Exec error", zap.String("id", job.ID), zap.Error(err))
+		return errors.Wrap(err, "db.Exec")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.Wrapf(ErrReplayJobNotFound, "id: %s", job.ID)
+	}
+	return nil
+}
+
+func (s *pgReplayJobStore) Get(ctx context.Context, id string) (*ReplayJob, error) {
+	job, err := scanReplayJob(s.db.QueryRow(ctx, getReplayJobQuery, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(ErrReplayJobNotFound, "id: %s", id)
+		}
+		s.logger.Error("(Get Replay Job) db.QueryRow error", zap.String("id", id), zap.Error(err))
+		return nil, errors.Wrap(err, "db.QueryRow")
+	}
+	return job, nil
+}
+
+func (s *pgReplayJobStore) List(ctx context.Context, limit int) ([]*ReplayJob, error) {
+	rows, err := s.db.Query(ctx, listReplayJobsQuery, limit)
+	if err != nil {
+		s.logger.Error("(List Replay Jobs) db.Query error", zap.Error(err))
+		return nil, errors.Wrap(err, "db.Query")
+	}
+	defer rows.Close()
+
+	jobs := make([]*ReplayJob, 0, limit)
+	for rows.Next() {
+		job, err := scanReplayJob(rows)
+		if err != nil {
+			s.logger.Error("(List Replay Jobs) rows.Scan error", zap.Error(err))
+			return nil, errors.Wrap(err, "rows.Scan")
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+	return jobs, nil
+}
+
+func (s *pgReplayJobStore) ClaimPending(ctx context.Context, owner string, heartbeatAt time.Time) (*ReplayJob, error) {
+	job, err := scanReplayJob(s.db.QueryRow(ctx, claimPendingReplayJobQuery, owner, heartbeatAt))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		s.logger.Error("(Claim Pending Replay Job) db.QueryRow error", zap.Error(err))
+		return nil, errors.Wrap(err, "db.QueryRow")
+	}
+	return job, nil
+}
+
+func (s *pgReplayJobStore) Heartbeat(ctx context.Context, owner string, ids []string, heartbeatAt time.Time) error {
+	if _, err := s.db.Exec(ctx, heartbeatReplayJobsQuery, owner, ids, heartbeatAt); err != nil {
+		s.logger.Error("(Heartbeat Replay Jobs) db.Exec error", zap.String("owner", owner), zap.Error(err))
+		return errors.Wrap(err, "db.Exec")
+	}
+	return nil
+}
+
+func (s *pgReplayJobStore) MarkInterrupted(ctx context.Context, staleBefore time.Time) (int64, error) {
+	result, err := s.db.Exec(ctx, markReplayJobsInterruptedQuery, staleBefore)
+	if err != nil {
+		s.logger.Error("(Mark Replay Jobs Interrupted) db.Exec error", zap.Error(err))
+		return 0, errors.Wrap(err, "db.Exec")
+	}
+	return result.RowsAffected(), nil
+}
+
+func scanReplayJob(row pgx.Row) (*ReplayJob, error) {
+	var job ReplayJob
+	var filter []byte
+	if err := row.Scan(
+		&job.ID,
+		&job.Projection,
+		&job.Status,
+		&job.Recreate,
+		&job.TargetIndex,
+		&filter,
+		&job.Cursor,
+		&job.Position,
+		&job.HeadPosition,
+		&job.Total,
+		&job.Processed,
+		&job.Skipped,
+		&job.Failed,
+		&job.Errors,
+		&job.Error,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&job.Owner,
+		&job.HeartbeatAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(filter) > 0 {
+		job.Filter = &StreamFilter{}
+		if err := json.Unmarshal(filter, job.Filter); err != nil {
+			return nil, errors.Wrap(err, "json.Unmarshal")
+		}
+	}
+	return &job, nil
+}
@@ -0,0 +1,70 @@
+package es
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type pgSchemaStore struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewPgSchemaStore postgres SchemaStore constructor.
+func NewPgSchemaStore(db *pgxpool.Pool, logger *zap.Logger) *pgSchemaStore {
+	return &pgSchemaStore{db: db, logger: logger}
+}
+
+func (s *pgSchemaStore) LoadSchemas(ctx context.Context) ([]*EventSchema, error) {
+	rows, err := s.db.Query(ctx, getEventSchemasQuery)
+	if err != nil {
+		s.logger.Error("(Load Event Schemas) db.Query error", zap.Error(err))
+		return nil, errors.Wrap(err, "db.Query")
+	}
+	defer rows.Close()
+
+	var schemas []*EventSchema
+	for rows.Next() {
+		var schema EventSchema
+		var registeredAt time.Time
+		if err := rows.Scan(
+			&schema.EventType,
+			&schema.Revision,
+			&schema.AggregateType,
+			&schema.Description,
+			&schema.Schema,
+			&schema.Checksum,
+			&registeredAt,
+		); err != nil {
+			return nil, errors.Wrap(err, "rows.Scan")
+		}
+		schema.RegisteredAt = &registeredAt
+		schemas = append(schemas, &schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+	return schemas, nil
+}
+
+func (s *pgSchemaStore) SaveSchema(ctx context.Context, schema *EventSchema) error {
+	_, err := s.db.Exec(
+		ctx,
+		saveEventSchemaQuery,
+		schema.EventType,
+		schema.Revision,
+		schema.AggregateType,
+		schema.Description,
+		[]byte(schema.Schema),
+		schema.Checksum,
+	)
+	if err != nil {
+		s.logger.Error("(Save Event Schema) db.Exec error", zap.String("event_type", string(schema.EventType)), zap.Int("revision", schema.Revision), zap.Error(err))
+		return errors.Wrap(err, "db.Exec")
+	}
+	return nil
+}
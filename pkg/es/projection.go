@@ -1,8 +1,59 @@
 package es
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Projection When method works and process Event's like Aggregate's for interacting with read database.
 type Projection interface {
 	When(ctx context.Context, event Event) error
 }
+
+// Projector is a named Projection which can be truncated and rebuilt by ProjectionRunner.
+type Projector interface {
+	Projection
+
+	// Name unique projection name, used as checkpoint key.
+	Name() string
+
+	// Reset truncates the read model before rebuild.
+	Reset(ctx context.Context) error
+
+	// ResetStream removes the read model of a single aggregate before it is rebuilt.
+	ResetStream(ctx context.Context, aggregateID string) error
+}
+
+// ProjectionState state of the ProjectionRunner.
+type ProjectionState string
+
+const (
+	ProjectionStateRunning    ProjectionState = "running"
+	ProjectionStatePaused     ProjectionState = "paused"
+	ProjectionStateRebuilding ProjectionState = "rebuilding"
+)
+
+// ProjectionStatus snapshot of projection progress.
+type ProjectionStatus struct {
+	Name         string          `json:"name"`
+	State        ProjectionState `json:"state"`
+	Position     uint64          `json:"position"`
+	HeadPosition uint64          `json:"headPosition"`
+	Lag          uint64          `json:"lag"`
+	Processed    uint64          `json:"processed"`
+	Skipped      uint64          `json:"skipped"`
+	Failed       uint64          `json:"failed"`
+	LastError    string          `json:"lastError,omitempty"`
+	LastErrorAt  *time.Time      `json:"lastErrorAt,omitempty"`
+	LastEventAt  *time.Time      `json:"lastEventAt,omitempty"`
+}
+
+// ReplayStats result of replaying events from the event store into a projection.
+type ReplayStats struct {
+	Total     int      `json:"total"`
+	Processed int      `json:"processed"`
+	Skipped   int      `json:"skipped"`
+	Failed    int      `json:"failed"`
+	Position  uint64   `json:"position"`
+	Errors    []string `json:"errors,omitempty"`
+}
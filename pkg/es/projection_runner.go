@@ -0,0 +1,591 @@
+package es
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/th1enq/es-demo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+const (
+	maxReplayErrors = 100
+)
+
+// ReplayProgressFunc is called after every replayed batch with accumulated stats.
+type ReplayProgressFunc func(ctx context.Context, stats *ReplayStats) error
+
+// ProjectionRunner applies events to a Projector exactly once per stream position.
+// Events are pushed by subscriptions with Handle or replayed from the event store,
+// already applied versions are skipped and version gaps are filled from the event store,
+// so projectors do not need their own version checks.
+type ProjectionRunner struct {
+	cfg         ProjectionConfig
+	projector   Projector
+	eventStore  EventStore
+	checkpoints CheckpointStore
+	logger      *zap.Logger
+
+	// gate is held for reading while an event is applied and for writing on state change,
+	// so pause and rebuild wait for in-flight events.
+	gate   sync.RWMutex
+	state  ProjectionState
+	resume chan struct{}
+
+	// stop is closed on shutdown to release pushed events waiting while projection is paused or rebuilding.
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	// streams holds locks of streams with events in flight, removed when the last holder unlocks.
+	streamsMu sync.Mutex
+	streams   map[string]*streamLock
+
+	rebuildMu sync.Mutex
+
+	mu          sync.Mutex
+	processed   uint64
+	skipped     uint64
+	failed      uint64
+	lastError   string
+	lastErrorAt *time.Time
+	lastEventAt *time.Time
+}
+
+// NewProjectionRunner ProjectionRunner constructor.
+func NewProjectionRunner(
+	cfg ProjectionConfig,
+	projector Projector,
+	eventStore EventStore,
+	checkpoints CheckpointStore,
+	logger *zap.Logger,
+) *ProjectionRunner {
+	return &ProjectionRunner{
+		cfg:         cfg,
+		projector:   projector,
+		eventStore:  eventStore,
+		checkpoints: checkpoints,
+		logger:      logger.With(zap.String("projection", projector.Name())),
+		state:       ProjectionStateRunning,
+		stop:        make(chan struct{}),
+		streams:     make(map[string]*streamLock),
+	}
+}
+
+// streamLock serializes events of one stream, refs counts goroutines holding or waiting for it.
+type streamLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// Name of the underlying projector.
+func (r *ProjectionRunner) Name() string {
+	return r.projector.Name()
+}
+
+// State returns whether projection is running, paused or rebuilding.
+func (r *ProjectionRunner) State() ProjectionState {
+	r.gate.RLock()
+	defer r.gate.RUnlock()
+	return r.state
+}
+
+// Handle applies event pushed by subscription, blocking while projection is paused or rebuilding.
+func (r *ProjectionRunner) Handle(ctx context.Context, event Event) error {
+	for {
+		r.gate.RLock()
+		if r.state == ProjectionStateRunning {
+			_, err := r.apply(ctx, event)
+			r.gate.RUnlock()
+			return err
+		}
+		resume := r.resume
+		r.gate.RUnlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.stop:
+			return ErrProjectionStopped
+		case <-resume:
+		}
+	}
+}
+
+// WaitRunning blocks while projection is paused or rebuilding, subscriptions call it before
+// fetching next message so that paused projections do not hold fetched messages.
+func (r *ProjectionRunner) WaitRunning(ctx context.Context) error {
+	for {
+		r.gate.RLock()
+		state, resume := r.state, r.resume
+		r.gate.RUnlock()
+
+		if state == ProjectionStateRunning {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.stop:
+			return ErrProjectionStopped
+		case <-resume:
+		}
+	}
+}
+
+// Stop releases pushed events waiting while projection is paused or rebuilding with ErrProjectionStopped,
+// so subscriptions can shut down. In-flight events are not interrupted, the projection state is kept.
+func (r *ProjectionRunner) Stop(ctx context.Context) error {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		r.logger.Info("Projection stopped")
+	})
+	return nil
+}
+
+// Pause stops applying pushed events after in-flight events are done.
+func (r *ProjectionRunner) Pause() error {
+	r.gate.Lock()
+	defer r.gate.Unlock()
+
+	switch r.state {
+	case ProjectionStateRebuilding:
+		return ErrProjectionRebuilding
+	case ProjectionStateRunning:
+		r.state = ProjectionStatePaused
+		r.resume = make(chan struct{})
+		r.logger.Info("Projection paused")
+	}
+	return nil
+}
+
+// Resume continues applying pushed events.
+func (r *ProjectionRunner) Resume() error {
+	r.gate.Lock()
+	defer r.gate.Unlock()
+
+	switch r.state {
+	case ProjectionStateRebuilding:
+		return ErrProjectionRebuilding
+	case ProjectionStatePaused:
+		r.setRunning()
+		r.logger.Info("Projection resumed")
+	}
+	return nil
+}
+
+func (r *ProjectionRunner) setRunning() {
+	r.state = ProjectionStateRunning
+	close(r.resume)
+}
+
+// Rebuild truncates the read model and checkpoints and replays all events from the event store.
+// Pushed events wait until rebuild is finished and then are skipped if already replayed.
+func (r *ProjectionRunner) Rebuild(ctx context.Context, progress ReplayProgressFunc) (stats *ReplayStats, err error) {
+	if !r.rebuildMu.TryLock() {
+		return nil, ErrProjectionRebuilding
+	}
+	defer r.rebuildMu.Unlock()
+
+	ctx, span := tracing.StartSpan(ctx, "ProjectionRunner.Rebuild")
+	span.SetAttributes(attribute.String("projection", r.Name()))
+	defer func() {
+		tracing.TraceErr(span, err)
+		span.End()
+	}()
+
+	defer r.beginRebuild()()
+
+	r.logger.Info("Projection rebuild started")
+
+	if err := r.reset(ctx); err != nil {
+		return nil, err
+	}
+
+	stats, err = r.Replay(ctx, 0, progress)
+	if err != nil {
+		return stats, err
+	}
+
+	r.logger.Info("Projection rebuild completed", zap.Int("processed", stats.Processed), zap.Int("failed", stats.Failed))
+	return stats, nil
+}
+
+// Reset truncates the read model and checkpoints without replaying, next replay starts from scratch.
+func (r *ProjectionRunner) Reset(ctx context.Context) error {
+	if !r.rebuildMu.TryLock() {
+		return ErrProjectionRebuilding
+	}
+	defer r.rebuildMu.Unlock()
+
+	defer r.beginRebuild()()
+
+	if err := r.reset(ctx); err != nil {
+		return err
+	}
+
+	r.logger.Info("Projection reset")
+	return nil
+}
+
+// beginRebuild holds pushed events until the returned func restores the previous state.
+func (r *ProjectionRunner) beginRebuild() func() {
+	r.gate.Lock()
+	previous := r.state
+	r.state = ProjectionStateRebuilding
+	if previous == ProjectionStateRunning {
+		r.resume = make(chan struct{})
+	}
+	r.gate.Unlock()
+
+	return func() {
+		r.gate.Lock()
+		defer r.gate.Unlock()
+		if previous == ProjectionStatePaused {
+			r.state = ProjectionStatePaused
+			return
+		}
+		r.setRunning()
+	}
+}
+
+func (r *ProjectionRunner) reset(ctx context.Context) error {
+	if err := r.projector.Reset(ctx); err != nil {
+		r.recordError(err)
+		return errors.Wrap(err, "projector.Reset")
+	}
+
+	if err := r.checkpoints.ResetCheckpoints(ctx, r.Name()); err != nil {
+		r.recordError(err)
+		return errors.Wrap(err, "checkpoints.ResetCheckpoints")
+	}
+
+	r.mu.Lock()
+	r.processed, r.skipped, r.failed = 0, 0, 0
+	r.lastError, r.lastErrorAt = "", nil
+	r.mu.Unlock()
+	return nil
+}
+
+// CatchUp replays events after the projection position, used by projections fed only from the event store.
+func (r *ProjectionRunner) CatchUp(ctx context.Context, progress ReplayProgressFunc) (*ReplayStats, error) {
+	position, err := r.checkpoints.GetPosition(ctx, r.Name())
+	if err != nil {
+		r.recordError(err)
+		return nil, errors.Wrap(err, "checkpoints.GetPosition")
+	}
+	return r.Replay(ctx, position, progress)
+}
+
+// Replay applies events from the event store after position. Already applied events are skipped,
+// so replay is safe to run while subscription is pushing events. Progress is reported after every batch.
+func (r *ProjectionRunner) Replay(ctx context.Context, position uint64, progress ReplayProgressFunc) (*ReplayStats, error) {
+	stats := &ReplayStats{Position: position, Errors: make([]string, 0)}
+
+	for {
+		events, err := r.eventStore.LoadEventsFromPosition(ctx, stats.Position, r.cfg.ReplayBatchSize)
+		if err != nil {
+			r.recordError(err)
+			return stats, errors.Wrap(err, "eventStore.LoadEventsFromPosition")
+		}
+
+		for _, event := range events {
+			stats.Total++
+			stats.Position = event.GetPosition()
+
+			applied, err := r.apply(ctx, event)
+			if err != nil {
+				if ctx.Err() != nil {
+					return stats, ctx.Err()
+				}
+				stats.Failed++
+				if len(stats.Errors) < maxReplayErrors {
+					stats.Errors = append(stats.Errors, fmt.Sprintf("position %d aggregate %s: %v", event.GetPosition(), event.GetAggregateID(), err))
+				}
+				continue
+			}
+			if applied {
+				stats.Processed++
+			} else {
+				stats.Skipped++
+			}
+		}
+
+		if progress != nil {
+			if err := progress(ctx, stats); err != nil {
+				return stats, errors.Wrap(err, "replay progress")
+			}
+		}
+
+		if len(events) < r.cfg.ReplayBatchSize {
+			return stats, nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+	}
+}
+
+// RebuildStreams rebuilds read model of the given aggregates from their full event streams,
+// the rest of the projection is untouched. Stats count streams, failed ones are reported with errors.
+func (r *ProjectionRunner) RebuildStreams(ctx context.Context, aggregateIDs []string) (*ReplayStats, error) {
+	stats := &ReplayStats{Errors: make([]string, 0)}
+
+	for _, aggregateID := range aggregateIDs {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		stats.Total++
+		if err := r.rebuildStream(ctx, aggregateID); err != nil {
+			if ctx.Err() != nil {
+				return stats, ctx.Err()
+			}
+			if errors.Is(err, ErrProjectionRebuilding) {
+				return stats, err
+			}
+			stats.Failed++
+			if len(stats.Errors) < maxReplayErrors {
+				stats.Errors = append(stats.Errors, fmt.Sprintf("aggregate %s: %v", aggregateID, err))
+			}
+			continue
+		}
+		stats.Processed++
+	}
+
+	return stats, nil
+}
+
+// rebuildStream resets and replays a single stream under its lock, so pushed events of the stream
+// wait and are skipped afterwards.
+func (r *ProjectionRunner) rebuildStream(ctx context.Context, aggregateID string) error {
+	r.gate.RLock()
+	defer r.gate.RUnlock()
+	if r.state == ProjectionStateRebuilding {
+		return ErrProjectionRebuilding
+	}
+
+	unlock := r.lockStream(aggregateID)
+	defer unlock()
+
+	events, err := r.eventStore.LoadEvents(ctx, aggregateID)
+	if err != nil {
+		r.recordError(err)
+		return errors.Wrapf(err, "eventStore.LoadEvents aggregateID: %s", aggregateID)
+	}
+
+	if err := r.projector.ResetStream(ctx, aggregateID); err != nil {
+		r.recordError(err)
+		return errors.Wrapf(err, "projector.ResetStream aggregateID: %s", aggregateID)
+	}
+
+	if err := r.checkpoints.ResetStreamCheckpoint(ctx, r.Name(), aggregateID); err != nil {
+		r.recordError(err)
+		return errors.Wrap(err, "checkpoints.ResetStreamCheckpoint")
+	}
+
+	for _, event := range events {
+		if err := r.applyOne(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	r.logger.Debug("Projection stream rebuilt", zap.String("aggregateID", aggregateID), zap.Int("events", len(events)))
+	return nil
+}
+
+// apply applies event under stream lock, returns false if event was already applied.
+func (r *ProjectionRunner) apply(ctx context.Context, event Event) (bool, error) {
+	unlock := r.lockStream(event.GetAggregateID())
+	defer unlock()
+
+	version, err := r.checkpoints.GetStreamCheckpoint(ctx, r.Name(), event.GetAggregateID())
+	if err != nil {
+		r.recordError(err)
+		return false, errors.Wrap(err, "checkpoints.GetStreamCheckpoint")
+	}
+
+	if event.GetVersion() <= version {
+		r.logger.Debug("Skip already applied event", zap.String("aggregateID", event.GetAggregateID()), zap.Uint64("version", event.GetVersion()), zap.Uint64("checkpoint", version))
+		r.mu.Lock()
+		r.skipped++
+		r.mu.Unlock()
+		return false, nil
+	}
+
+	if event.GetVersion() > version+1 {
+		if err := r.catchUpStream(ctx, event.GetAggregateID(), version, event.GetVersion()); err != nil {
+			return false, err
+		}
+	}
+
+	if err := r.applyOne(ctx, event); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// catchUpStream applies missing stream events between checkpoint and the given version from the event store,
+// events may arrive out of order when subscription workers process the same stream concurrently.
+func (r *ProjectionRunner) catchUpStream(ctx context.Context, aggregateID string, fromVersion, toVersion uint64) error {
+	r.logger.Warn("Projection stream gap, loading missing events", zap.String("aggregateID", aggregateID), zap.Uint64("checkpoint", fromVersion), zap.Uint64("version", toVersion))
+
+	events, err := r.eventStore.LoadEvents(ctx, aggregateID)
+	if err != nil {
+		r.recordError(err)
+		return errors.Wrapf(err, "eventStore.LoadEvents aggregateID: %s", aggregateID)
+	}
+
+	expected := fromVersion + 1
+	for _, event := range events {
+		if event.GetVersion() <= fromVersion || event.GetVersion() >= toVersion {
+			continue
+		}
+		if event.GetVersion() != expected {
+			err := errors.Wrapf(ErrInvalidEventVersion, "aggregateID: %s, expected: %d, got: %d", aggregateID, expected, event.GetVersion())
+			r.recordError(err)
+			return err
+		}
+		if err := r.applyOne(ctx, event); err != nil {
+			return err
+		}
+		expected++
+	}
+
+	if expected != toVersion {
+		err := errors.Wrapf(ErrInvalidEventVersion, "aggregateID: %s, expected: %d, got: %d", aggregateID, expected, toVersion)
+		r.recordError(err)
+		return err
+	}
+	return nil
+}
+
+func (r *ProjectionRunner) applyOne(ctx context.Context, event Event) error {
+	if err := r.projector.When(ctx, event); err != nil {
+		r.recordError(err)
+		return errors.Wrapf(err, "When type: %s, aggregateID: %s, version: %d", event.GetEventType(), event.GetAggregateID(), event.GetVersion())
+	}
+
+	if err := r.checkpoints.SaveCheckpoint(ctx, r.Name(), event); err != nil {
+		r.recordError(err)
+		return errors.Wrap(err, "checkpoints.SaveCheckpoint")
+	}
+
+	now := time.Now().UTC()
+	r.mu.Lock()
+	r.processed++
+	r.lastEventAt = &now
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *ProjectionRunner) lockStream(aggregateID string) func() {
+	r.streamsMu.Lock()
+	lock, ok := r.streams[aggregateID]
+	if !ok {
+		lock = &streamLock{}
+		r.streams[aggregateID] = lock
+	}
+	lock.refs++
+	r.streamsMu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		r.streamsMu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(r.streams, aggregateID)
+		}
+		r.streamsMu.Unlock()
+	}
+}
+
+func (r *ProjectionRunner) recordError(err error) {
+	now := time.Now().UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed++
+	r.lastError = err.Error()
+	r.lastErrorAt = &now
+	r.logger.Error("Projection error", zap.Error(err))
+}
+
+// Status returns projection state with position and lag behind the event store head.
+func (r *ProjectionRunner) Status(ctx context.Context) (*ProjectionStatus, error) {
+	position, err := r.checkpoints.GetPosition(ctx, r.Name())
+	if err != nil {
+		return nil, errors.Wrap(err, "checkpoints.GetPosition")
+	}
+
+	head, err := r.eventStore.GetHeadPosition(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "eventStore.GetHeadPosition")
+	}
+
+	r.gate.RLock()
+	state := r.state
+	r.gate.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := &ProjectionStatus{
+		Name:         r.Name(),
+		State:        state,
+		Position:     position,
+		HeadPosition: head,
+		Processed:    r.processed,
+		Skipped:      r.skipped,
+		Failed:       r.failed,
+		LastError:    r.lastError,
+		LastErrorAt:  r.lastErrorAt,
+		LastEventAt:  r.lastEventAt,
+	}
+	if head > position {
+		status.Lag = head - position
+	}
+	return status, nil
+}
+
+// ProjectionRegistry holds projection runners by name for the admin API.
+type ProjectionRegistry struct {
+	runners map[string]*ProjectionRunner
+}
+
+// NewProjectionRegistry ProjectionRegistry constructor.
+func NewProjectionRegistry(runners ...*ProjectionRunner) *ProjectionRegistry {
+	registry := &ProjectionRegistry{runners: make(map[string]*ProjectionRunner, len(runners))}
+	for _, runner := range runners {
+		registry.runners[runner.Name()] = runner
+	}
+	return registry
+}
+
+// Get returns runner by projection name.
+func (p *ProjectionRegistry) Get(name string) (*ProjectionRunner, error) {
+	runner, ok := p.runners[name]
+	if !ok {
+		return nil, errors.Wrapf(ErrProjectionNotFound, "name: %s", name)
+	}
+	return runner, nil
+}
+
+// List returns all runners sorted by name.
+func (p *ProjectionRegistry) List() []*ProjectionRunner {
+	runners := make([]*ProjectionRunner, 0, len(p.runners))
+	for _, runner := range p.runners {
+		runners = append(runners, runner)
+	}
+	sort.Slice(runners, func(i, j int) bool {
+		return runners[i].Name() < runners[j].Name()
+	})
+	return runners
+}
@@ -0,0 +1,286 @@
+package es
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryEventStore serves events by position, methods not used by ProjectionRunner are left unimplemented.
+type memoryEventStore struct {
+	EventStore
+	events []Event
+}
+
+func (s *memoryEventStore) append(aggregateID string, eventType EventType) Event {
+	var version uint64
+	for _, event := range s.events {
+		if event.AggregateID == aggregateID {
+			version = event.Version
+		}
+	}
+	event := Event{
+		AggregateID: aggregateID,
+		EventType:   eventType,
+		Version:     version + 1,
+		Position:    uint64(len(s.events) + 1),
+	}
+	s.events = append(s.events, event)
+	return event
+}
+
+func (s *memoryEventStore) LoadEvents(ctx context.Context, aggregateID string) ([]Event, error) {
+	events := make([]Event, 0)
+	for _, event := range s.events {
+		if event.AggregateID == aggregateID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *memoryEventStore) LoadEventsFromPosition(ctx context.Context, position uint64, limit int) ([]Event, error) {
+	events := make([]Event, 0, limit)
+	for _, event := range s.events {
+		if event.Position > position && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *memoryEventStore) GetHeadPosition(ctx context.Context) (uint64, error) {
+	return uint64(len(s.events)), nil
+}
+
+type memoryCheckpointStore struct {
+	mu        sync.Mutex
+	streams   map[string]uint64
+	positions map[string]uint64
+}
+
+func newMemoryCheckpointStore() *memoryCheckpointStore {
+	return &memoryCheckpointStore{streams: make(map[string]uint64), positions: make(map[string]uint64)}
+}
+
+func (s *memoryCheckpointStore) GetStreamCheckpoint(ctx context.Context, projection string, aggregateID string) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.streams[projection+"/"+aggregateID], nil
+}
+
+func (s *memoryCheckpointStore) SaveCheckpoint(ctx context.Context, projection string, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streams[projection+"/"+event.GetAggregateID()] = event.GetVersion()
+	if event.GetPosition() > s.positions[projection] {
+		s.positions[projection] = event.GetPosition()
+	}
+	return nil
+}
+
+func (s *memoryCheckpointStore) GetPosition(ctx context.Context, projection string) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.positions[projection], nil
+}
+
+func (s *memoryCheckpointStore) ResetCheckpoints(ctx context.Context, projection string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streams = make(map[string]uint64)
+	delete(s.positions, projection)
+	return nil
+}
+
+func (s *memoryCheckpointStore) ResetStreamCheckpoint(ctx context.Context, projection string, aggregateID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.streams, projection+"/"+aggregateID)
+	return nil
+}
+
+func (s *memoryCheckpointStore) CopyCheckpoints(ctx context.Context, from, to string) error {
+	return errors.New("not implemented")
+}
+
+// recordingProjector records the applied events in order.
+type recordingProjector struct {
+	mu      sync.Mutex
+	applied []string
+	resets  int
+}
+
+func (p *recordingProjector) Name() string {
+	return "recording"
+}
+
+func (p *recordingProjector) When(ctx context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.applied = append(p.applied, event.String())
+	return nil
+}
+
+func (p *recordingProjector) Reset(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.applied = nil
+	p.resets++
+	return nil
+}
+
+func (p *recordingProjector) ResetStream(ctx context.Context, aggregateID string) error {
+	return nil
+}
+
+func (p *recordingProjector) Applied() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.applied...)
+}
+
+func newTestProjectionRunner(store *memoryEventStore) (*ProjectionRunner, *recordingProjector, *memoryCheckpointStore) {
+	projector := &recordingProjector{}
+	checkpoints := newMemoryCheckpointStore()
+	runner := NewProjectionRunner(ProjectionConfig{ReplayBatchSize: 2}, projector, store, checkpoints, zap.NewNop())
+	return runner, projector, checkpoints
+}
+
+func TestProjectionRunnerHandleCheckpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		handle   []int
+		expected []int
+	}{
+		{name: "events in order are applied once", handle: []int{0, 1, 2}, expected: []int{0, 1, 2}},
+		{name: "redelivered events are skipped", handle: []int{0, 1, 0, 1, 2}, expected: []int{0, 1, 2}},
+		{name: "stream gap is filled from the event store", handle: []int{2}, expected: []int{0, 1, 2}},
+		{name: "late events after gap fill are skipped", handle: []int{2, 0, 1}, expected: []int{0, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryEventStore{}
+			for i := 0; i < 3; i++ {
+				store.append("account-1", "Deposited")
+			}
+			runner, projector, checkpoints := newTestProjectionRunner(store)
+
+			for _, i := range tt.handle {
+				require.NoError(t, runner.Handle(context.Background(), store.events[i]))
+			}
+
+			expected := make([]string, 0, len(tt.expected))
+			for _, i := range tt.expected {
+				expected = append(expected, store.events[i].String())
+			}
+			assert.Equal(t, expected, projector.Applied())
+
+			version, err := checkpoints.GetStreamCheckpoint(context.Background(), runner.Name(), "account-1")
+			require.NoError(t, err)
+			assert.Equal(t, uint64(3), version)
+			assert.Empty(t, runner.streams, "stream locks are released after use")
+		})
+	}
+}
+
+func TestProjectionRunnerPause(t *testing.T) {
+	store := &memoryEventStore{}
+	event := store.append("account-1", "Deposited")
+	runner, projector, _ := newTestProjectionRunner(store)
+
+	require.NoError(t, runner.Pause())
+	assert.Equal(t, ProjectionStatePaused, runner.State())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runner.Handle(context.Background(), event)
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Handle returned while paused: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Empty(t, projector.Applied())
+
+	require.NoError(t, runner.Resume())
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{event.String()}, projector.Applied())
+	assert.Equal(t, ProjectionStateRunning, runner.State())
+}
+
+func TestProjectionRunnerStopReleasesPausedHandle(t *testing.T) {
+	store := &memoryEventStore{}
+	event := store.append("account-1", "Deposited")
+	runner, projector, _ := newTestProjectionRunner(store)
+
+	require.NoError(t, runner.Pause())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runner.Handle(context.WithoutCancel(context.Background()), event)
+	}()
+
+	require.NoError(t, runner.Stop(context.Background()))
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrProjectionStopped)
+	case <-time.After(time.Second):
+		t.Fatal("Handle did not return after Stop")
+	}
+	assert.ErrorIs(t, runner.WaitRunning(context.Background()), ErrProjectionStopped)
+	assert.Empty(t, projector.Applied())
+}
+
+func TestProjectionRunnerRebuild(t *testing.T) {
+	tests := []struct {
+		name  string
+		pause bool
+		state ProjectionState
+	}{
+		{name: "running projection stays running", state: ProjectionStateRunning},
+		{name: "paused projection stays paused", pause: true, state: ProjectionStatePaused},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryEventStore{}
+			store.append("account-1", "Deposited")
+			store.append("account-2", "Deposited")
+			store.append("account-1", "Withdrawn")
+			runner, projector, checkpoints := newTestProjectionRunner(store)
+
+			_, err := runner.Replay(context.Background(), 0, nil)
+			require.NoError(t, err)
+			if tt.pause {
+				require.NoError(t, runner.Pause())
+			}
+
+			var batches []uint64
+			stats, err := runner.Rebuild(context.Background(), func(ctx context.Context, stats *ReplayStats) error {
+				batches = append(batches, stats.Position)
+				return nil
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, 1, projector.resets)
+			assert.Len(t, projector.Applied(), 3)
+			assert.Equal(t, 3, stats.Processed)
+			assert.Equal(t, 0, stats.Skipped)
+			assert.Equal(t, []uint64{2, 3}, batches)
+			assert.Equal(t, tt.state, runner.State())
+
+			position, err := checkpoints.GetPosition(context.Background(), runner.Name())
+			require.NoError(t, err)
+			assert.Equal(t, uint64(3), position)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package es
+
+import (
+	"context"
+	"time"
+)
+
+// ReplayJobStatus status of the background replay job.
+type ReplayJobStatus string
+
+const (
+	ReplayJobStatusPending     ReplayJobStatus = "pending"
+	ReplayJobStatusRunning     ReplayJobStatus = "running"
+	ReplayJobStatusCompleted   ReplayJobStatus = "completed"
+	ReplayJobStatusFailed      ReplayJobStatus = "failed"
+	ReplayJobStatusCancelled   ReplayJobStatus = "cancelled"
+	ReplayJobStatusInterrupted ReplayJobStatus = "interrupted"
+)
+
+// ReplayJob persisted progress of replaying the event store into a projection.
+// Position is the last replayed global position, so cancelled or interrupted jobs resume from it.
+// Recreate jobs build TargetIndex next to the live index and switch reads to it when done.
+// Selective jobs rebuild only streams matching Filter in id order, Cursor is the last rebuilt stream.
+// Running jobs are owned by the instance executing them, which refreshes HeartbeatAt while the job runs.
+type ReplayJob struct {
+	ID           string          `json:"id"`
+	Projection   string          `json:"projection"`
+	Status       ReplayJobStatus `json:"status"`
+	Recreate     bool            `json:"recreate"`
+	TargetIndex  string          `json:"targetIndex,omitempty"`
+	Filter       *StreamFilter   `json:"filter,omitempty"`
+	Cursor       string          `json:"cursor,omitempty"`
+	Position     uint64          `json:"position"`
+	HeadPosition uint64          `json:"headPosition"`
+	Total        int             `json:"total"`
+	Processed    int             `json:"processed"`
+	Skipped      int             `json:"skipped"`
+	Failed       int             `json:"failed"`
+	Errors       []string        `json:"errors,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	UpdatedAt    time.Time       `json:"updatedAt"`
+	StartedAt    *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt   *time.Time      `json:"finishedAt,omitempty"`
+	Owner        string          `json:"owner,omitempty"`
+	HeartbeatAt  *time.Time      `json:"heartbeatAt,omitempty"`
+}
+
+// IsSelective job rebuilds only the streams matching its filter.
+func (j *ReplayJob) IsSelective() bool {
+	return j.Filter != nil
+}
+
+// IsActive job is running or waiting to be claimed by the service.
+func (j *ReplayJob) IsActive() bool {
+	return j.Status == ReplayJobStatusRunning || j.Status == ReplayJobStatusPending
+}
+
+// IsResumable job was stopped before completion and can continue from its position.
+func (j *ReplayJob) IsResumable() bool {
+	switch j.Status {
+	case ReplayJobStatusCancelled, ReplayJobStatusInterrupted, ReplayJobStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// ReplayJobStore persists replay jobs.
+type ReplayJobStore interface {
+	Create(ctx context.Context, job *ReplayJob) error
+	Update(ctx context.Context, job *ReplayJob) error
+	Get(ctx context.Context, id string) (*ReplayJob, error)
+	List(ctx context.Context, limit int) ([]*ReplayJob, error)
+
+	// ClaimPending marks the oldest pending job running by owner and returns it, nil when no job is pending.
+	// A pending job is claimed by one process only.
+	ClaimPending(ctx context.Context, owner string, heartbeatAt time.Time) (*ReplayJob, error)
+
+	// Heartbeat refreshes the heartbeat of the running jobs of owner.
+	Heartbeat(ctx context.Context, owner string, ids []string, heartbeatAt time.Time) error
+
+	// MarkInterrupted marks running jobs with heartbeat older than staleBefore as interrupted,
+	// their owner stopped without persisting them.
+	MarkInterrupted(ctx context.Context, staleBefore time.Time) (int64, error)
+}
@@ -0,0 +1,216 @@
+package es
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// lowerBoundKeywords may be lowered by a new revision, raising them rejects stored payloads.
+	lowerBoundKeywords = []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties"}
+	// upperBoundKeywords may be raised by a new revision, lowering them rejects stored payloads.
+	upperBoundKeywords = []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties"}
+	// exactKeywords may be removed by a new revision, adding or changing them rejects stored payloads.
+	exactKeywords = []string{"const", "pattern", "format", "multipleOf", "uniqueItems"}
+	// opaqueKeywords combine or reference schemas, they are compatible only when unchanged.
+	opaqueKeywords = []string{"$ref", "$defs", "allOf", "anyOf", "oneOf", "not", "if", "then", "else",
+		"patternProperties", "dependentRequired", "dependentSchemas", "prefixItems", "contains"}
+)
+
+// CheckSchemaCompatibility returns the changes of next breaking previous. Producers and consumers of an event
+// type are upgraded independently, so next must accept every payload valid under previous and must keep every
+// property previous guarantees: properties are not removed, not made required or optional, types and enums are
+// only widened and constraints only relaxed. Adding optional properties is compatible.
+func CheckSchemaCompatibility(previous, next []byte) ([]string, error) {
+	var prev, nxt map[string]any
+	if err := decodeSchema(previous, &prev); err != nil {
+		return nil, errors.Wrapf(ErrInvalidEventSchema, "previous: %v", err)
+	}
+	if err := decodeSchema(next, &nxt); err != nil {
+		return nil, errors.Wrapf(ErrInvalidEventSchema, "next: %v", err)
+	}
+
+	var breaking []string
+	compareSchemas("", prev, nxt, &breaking)
+	return breaking, nil
+}
+
+func decodeSchema(data []byte, schema *map[string]any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(schema)
+}
+
+func compareSchemas(location string, prev, next map[string]any, breaking *[]string) {
+	at := location
+	if at == "" {
+		at = "/"
+	}
+	report := func(format string, args ...any) {
+		*breaking = append(*breaking, at+": "+fmt.Sprintf(format, args...))
+	}
+
+	for _, keyword := range opaqueKeywords {
+		if !reflect.DeepEqual(prev[keyword], next[keyword]) {
+			report("%s changed, compatibility of combined schemas is not checked", keyword)
+		}
+	}
+
+	if prevTypes, nextTypes := schemaTypes(prev), schemaTypes(next); nextTypes != nil {
+		for _, t := range sortedKeys(prevTypes) {
+			if !nextTypes[t] && !(t == "integer" && nextTypes["number"]) {
+				report("type %s is no longer accepted", t)
+			}
+		}
+		if prevTypes == nil {
+			report("type restricted to %v", sortedKeys(nextTypes))
+		}
+	}
+
+	if nextEnum, ok := next["enum"].([]any); ok {
+		prevEnum, ok := prev["enum"].([]any)
+		if !ok {
+			report("enum added")
+		}
+		for _, value := range prevEnum {
+			if !containsValue(nextEnum, value) {
+				report("enum value %v removed", value)
+			}
+		}
+	}
+
+	for _, keyword := range exactKeywords {
+		if value, ok := next[keyword]; ok && !reflect.DeepEqual(prev[keyword], value) {
+			report("%s added or changed", keyword)
+		}
+	}
+	for _, keyword := range lowerBoundKeywords {
+		if value, ok := next[keyword]; ok && !boundRelaxed(prev[keyword], value, false) {
+			report("%s raised to %v", keyword, value)
+		}
+	}
+	for _, keyword := range upperBoundKeywords {
+		if value, ok := next[keyword]; ok && !boundRelaxed(prev[keyword], value, true) {
+			report("%s lowered to %v", keyword, value)
+		}
+	}
+
+	prevRequired, nextRequired := stringSet(prev["required"]), stringSet(next["required"])
+	for _, name := range sortedKeys(nextRequired) {
+		if !prevRequired[name] {
+			report("property %s became required", name)
+		}
+	}
+	for _, name := range sortedKeys(prevRequired) {
+		if !nextRequired[name] {
+			report("required property %s became optional", name)
+		}
+	}
+
+	if additional, ok := next["additionalProperties"].(bool); ok && !additional {
+		if prevAdditional, ok := prev["additionalProperties"].(bool); !ok || prevAdditional {
+			report("additional properties are no longer allowed")
+		}
+	}
+
+	prevProperties, _ := prev["properties"].(map[string]any)
+	nextProperties, _ := next["properties"].(map[string]any)
+	for _, name := range sortedKeys(prevProperties) {
+		nextProperty, ok := nextProperties[name].(map[string]any)
+		if !ok {
+			report("property %s removed", name)
+			continue
+		}
+		if prevProperty, ok := prevProperties[name].(map[string]any); ok {
+			compareSchemas(location+"/"+name, prevProperty, nextProperty, breaking)
+		}
+	}
+
+	if nextItems, ok := next["items"].(map[string]any); ok {
+		prevItems, ok := prev["items"].(map[string]any)
+		if !ok {
+			report("items schema added")
+		} else {
+			compareSchemas(location+"/items", prevItems, nextItems, breaking)
+		}
+	}
+}
+
+// schemaTypes types accepted by the schema, nil when the schema does not restrict the type.
+func schemaTypes(schema map[string]any) map[string]bool {
+	switch t := schema["type"].(type) {
+	case string:
+		return map[string]bool{t: true}
+	case []any:
+		types := make(map[string]bool, len(t))
+		for _, value := range t {
+			if name, ok := value.(string); ok {
+				types[name] = true
+			}
+		}
+		return types
+	default:
+		return nil
+	}
+}
+
+func stringSet(value any) map[string]bool {
+	values, _ := value.([]any)
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		if name, ok := value.(string); ok {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsValue(values []any, value any) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// boundRelaxed reports whether the next bound accepts every value the previous bound accepts.
+func boundRelaxed(prev, next any, upper bool) bool {
+	if prev == nil {
+		return false
+	}
+	prevNumber, ok := prev.(json.Number)
+	if !ok {
+		return reflect.DeepEqual(prev, next)
+	}
+	nextNumber, ok := next.(json.Number)
+	if !ok {
+		return false
+	}
+	p, err := prevNumber.Float64()
+	if err != nil {
+		return false
+	}
+	n, err := nextNumber.Float64()
+	if err != nil {
+		return false
+	}
+	if upper {
+		return n >= p
+	}
+	return n <= p
+}
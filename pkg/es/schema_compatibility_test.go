@@ -0,0 +1,166 @@
+package es
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSchemaCompatibility(t *testing.T) {
+	const base = `{"type":"object","required":["amount"],"properties":{
+		"amount":{"type":"integer","minimum":1,"maximum":1000},
+		"currency":{"type":"string","enum":["USD","EUR"]},
+		"tags":{"type":"array","items":{"type":"string","maxLength":10}}}}`
+
+	tests := []struct {
+		name     string
+		previous string
+		next     string
+		breaking []string
+	}{
+		{name: "unchanged schema", previous: base, next: base},
+		{
+			name:     "optional property added",
+			previous: `{"type":"object","properties":{"amount":{"type":"integer"}}}`,
+			next:     `{"type":"object","properties":{"amount":{"type":"integer"},"note":{"type":"string"}}}`,
+		},
+		{
+			name:     "property removed",
+			previous: `{"type":"object","properties":{"amount":{"type":"integer"},"note":{"type":"string"}}}`,
+			next:     `{"type":"object","properties":{"amount":{"type":"integer"}}}`,
+			breaking: []string{"/: property note removed"},
+		},
+		{
+			name:     "property became required",
+			previous: `{"type":"object","properties":{"note":{"type":"string"}}}`,
+			next:     `{"type":"object","required":["note"],"properties":{"note":{"type":"string"}}}`,
+			breaking: []string{"/: property note became required"},
+		},
+		{
+			name:     "required property became optional",
+			previous: `{"type":"object","required":["note"],"properties":{"note":{"type":"string"}}}`,
+			next:     `{"type":"object","properties":{"note":{"type":"string"}}}`,
+			breaking: []string{"/: required property note became optional"},
+		},
+		{
+			name:     "integer widened to number",
+			previous: `{"type":"integer"}`,
+			next:     `{"type":"number"}`,
+		},
+		{
+			name:     "type widened to a list",
+			previous: `{"type":"string"}`,
+			next:     `{"type":["string","null"]}`,
+		},
+		{
+			name:     "type narrowed",
+			previous: `{"type":"number"}`,
+			next:     `{"type":"integer"}`,
+			breaking: []string{"/: type number is no longer accepted"},
+		},
+		{
+			name:     "type restricted",
+			previous: `{}`,
+			next:     `{"type":"string"}`,
+			breaking: []string{"/: type restricted to [string]"},
+		},
+		{
+			name:     "enum value added",
+			previous: `{"enum":["USD"]}`,
+			next:     `{"enum":["USD","EUR"]}`,
+		},
+		{
+			name:     "enum value removed",
+			previous: `{"enum":["USD","EUR"]}`,
+			next:     `{"enum":["USD"]}`,
+			breaking: []string{"/: enum value EUR removed"},
+		},
+		{
+			name:     "enum added",
+			previous: `{"type":"string"}`,
+			next:     `{"type":"string","enum":["USD"]}`,
+			breaking: []string{"/: enum added"},
+		},
+		{
+			name:     "bounds relaxed",
+			previous: `{"minimum":1,"maximum":1000,"minLength":2,"maxLength":10}`,
+			next:     `{"minimum":0,"maximum":5000,"minLength":1,"maxLength":20}`,
+		},
+		{
+			name:     "bounds tightened",
+			previous: `{"minimum":1,"maxLength":10}`,
+			next:     `{"minimum":5,"maxLength":5}`,
+			breaking: []string{"/: minimum raised to 5", "/: maxLength lowered to 5"},
+		},
+		{
+			name:     "bound added",
+			previous: `{"type":"integer"}`,
+			next:     `{"type":"integer","maximum":100}`,
+			breaking: []string{"/: maximum lowered to 100"},
+		},
+		{
+			name:     "bound removed",
+			previous: `{"type":"integer","maximum":100}`,
+			next:     `{"type":"integer"}`,
+		},
+		{
+			name:     "pattern added",
+			previous: `{"type":"string"}`,
+			next:     `{"type":"string","pattern":"^[A-Z]+$"}`,
+			breaking: []string{"/: pattern added or changed"},
+		},
+		{
+			name:     "pattern removed",
+			previous: `{"type":"string","pattern":"^[A-Z]+$"}`,
+			next:     `{"type":"string"}`,
+		},
+		{
+			name:     "additional properties disallowed",
+			previous: `{"type":"object"}`,
+			next:     `{"type":"object","additionalProperties":false}`,
+			breaking: []string{"/: additional properties are no longer allowed"},
+		},
+		{
+			name:     "combined schema changed",
+			previous: `{"anyOf":[{"type":"string"}]}`,
+			next:     `{"anyOf":[{"type":"integer"}]}`,
+			breaking: []string{"/: anyOf changed, compatibility of combined schemas is not checked"},
+		},
+		{
+			name:     "nested property narrowed",
+			previous: base,
+			next: `{"type":"object","required":["amount"],"properties":{
+				"amount":{"type":"integer","minimum":1,"maximum":100},
+				"currency":{"type":"string","enum":["USD"]},
+				"tags":{"type":"array","items":{"type":"string","maxLength":5}}}}`,
+			breaking: []string{
+				"/amount: maximum lowered to 100",
+				"/currency: enum value EUR removed",
+				"/tags/items: maxLength lowered to 5",
+			},
+		},
+		{
+			name:     "items schema added",
+			previous: `{"type":"array"}`,
+			next:     `{"type":"array","items":{"type":"string"}}`,
+			breaking: []string{"/: items schema added"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaking, err := CheckSchemaCompatibility([]byte(tt.previous), []byte(tt.next))
+			require.NoError(t, err)
+			assert.Equal(t, tt.breaking, breaking)
+		})
+	}
+}
+
+func TestCheckSchemaCompatibilityInvalidSchema(t *testing.T) {
+	_, err := CheckSchemaCompatibility([]byte(`{"type":"string"}`), []byte(`{`))
+	assert.ErrorIs(t, err, ErrInvalidEventSchema)
+
+	_, err = CheckSchemaCompatibility([]byte(`[]`), []byte(`{"type":"string"}`))
+	assert.ErrorIs(t, err, ErrInvalidEventSchema)
+}
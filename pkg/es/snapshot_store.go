@@ -49,3 +49,13 @@ func (p *pgEventStore) GetSnapshotByVersion(ctx context.Context, id string, vers
 
 	return &snapshot, nil
 }
+
+func (p *pgEventStore) DeleteSnapshots(ctx context.Context, id string, version uint64) (int64, error) {
+	p.logger.Info("Delete Snapshots", zap.String("aggregateID", id), zap.Uint64("version", version))
+	result, err := p.db.Exec(ctx, deleteSnapshotsQuery, id, version)
+	if err != nil {
+		p.logger.Error("(Delete Snapshots) db.Exec error", zap.Error(err))
+		return 0, errors.Wrap(err, "db.Exec")
+	}
+	return result.RowsAffected(), nil
+}
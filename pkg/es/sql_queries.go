@@ -2,7 +2,7 @@ package es
 
 const (
 	saveEventQuery = `INSERT INTO microservices.events as e (aggregate_id, aggregate_type, event_type, data, version, metadata, timestamp)
-	VALUES ($1, $2, $3, $4, $5, $6, now())`
+	VALUES ($1, $2, $3, $4, $5, $6, now()) RETURNING event_id`
 
 	getEventsQuery = `SELECT event_id, aggregate_id, aggregate_type, event_type, data, version, timestamp, metadata 
 	FROM microservices.events e WHERE aggregate_id = $1 ORDER BY version ASC`
@@ -18,6 +18,40 @@ const (
 	getAllEventsQuery = `SELECT event_id, aggregate_id, aggregate_type, event_type, data, version, timestamp, metadata 
 	FROM microservices.events e ORDER BY timestamp ASC, version ASC`
 
+	getEventsFromPositionQuery = `SELECT event_id, aggregate_id, aggregate_type, event_type, data, version, timestamp, metadata 
+	FROM microservices.events e WHERE event_id > $1 ORDER BY event_id ASC LIMIT $2`
+
+	getHeadPositionQuery = `SELECT COALESCE(MAX(event_id), 0) FROM microservices.events`
+
+	getEventsByFilterQuery = `SELECT event_id, aggregate_id, aggregate_type, event_type, data, version, timestamp, metadata
+	FROM microservices.events e
+	WHERE event_id > $1 AND ($2 = 0 OR event_id <= $2)
+	AND ($3 = '' OR aggregate_type = $3)
+	AND (cardinality($4::text[]) = 0 OR aggregate_id::text = ANY($4::text[]))
+	ORDER BY event_id ASC LIMIT $5`
+
+	getEventsByPositionsQuery = `SELECT event_id, aggregate_id, aggregate_type, event_type, data, version, timestamp, metadata
+	FROM microservices.events e WHERE event_id = ANY($1::bigint[]) ORDER BY event_id ASC`
+
+	importEventQuery = `INSERT INTO microservices.events (aggregate_id, aggregate_type, event_type, data, version, metadata, timestamp)
+	VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING event_id`
+
+	importEventAtPositionQuery = `INSERT INTO microservices.events (event_id, aggregate_id, aggregate_type, event_type, data, version, metadata, timestamp)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	syncEventPositionQuery = `SELECT setval(pg_get_serial_sequence('microservices.events', 'event_id'), COALESCE(MAX(event_id), 1), MAX(event_id) IS NOT NULL)
+	FROM microservices.events`
+
+	getAggregateIDsQuery = `SELECT DISTINCT aggregate_id::text FROM microservices.events e
+	WHERE ($1 = '' OR aggregate_type = $1)
+	AND (cardinality($2::text[]) = 0 OR aggregate_id::text = ANY($2::text[]))
+	AND event_id > $3 AND ($4 = 0 OR event_id <= $4)
+	AND ($5::timestamp IS NULL OR timestamp >= $5) AND ($6::timestamp IS NULL OR timestamp <= $6)
+	AND aggregate_id::text > $7
+	ORDER BY aggregate_id::text ASC LIMIT $8`
+
+	countAggregatesQuery = `SELECT COUNT(DISTINCT aggregate_id) FROM microservices.events WHERE aggregate_type = $1 AND event_id <= $2`
+
 	saveSnapshotQuery = `INSERT INTO microservices.snapshots (aggregate_id, aggregate_type, data, version, timestamp)
 		VALUES ($1, $2, $3, $4, now())
 		ON CONFLICT (aggregate_id, version)
@@ -27,5 +61,61 @@ const (
 
 	getSnapshotByVersionQuery = `SELECT aggregate_id, aggregate_type, data, version FROM microservices.snapshots WHERE aggregate_id = $1 AND version = $2`
 
+	deleteSnapshotsQuery = `DELETE FROM microservices.snapshots WHERE aggregate_id = $1 AND ($2 = 0 OR version = $2)`
+
 	handleConcurrentWriteQuery = `SELECT aggregate_id FROM microservices.events e WHERE e.aggregate_id = $1 LIMIT 1 FOR UPDATE`
+
+	getStreamCheckpointQuery = `SELECT version FROM microservices.projection_checkpoints WHERE projection = $1 AND aggregate_id = $2`
+
+	saveStreamCheckpointQuery = `INSERT INTO microservices.projection_checkpoints (projection, aggregate_id, version, position, updated_at)
+	VALUES ($1, $2, $3, $4, now())
+	ON CONFLICT (projection, aggregate_id)
+	DO UPDATE SET version = EXCLUDED.version, position = GREATEST(microservices.projection_checkpoints.position, EXCLUDED.position), updated_at = now()`
+
+	saveProjectionPositionQuery = `INSERT INTO microservices.projections (name, position, updated_at)
+	VALUES ($1, $2, now())
+	ON CONFLICT (name)
+	DO UPDATE SET position = GREATEST(microservices.projections.position, EXCLUDED.position), updated_at = now()`
+
+	getProjectionPositionQuery = `SELECT position FROM microservices.projections WHERE name = $1`
+
+	deleteStreamCheckpointsQuery = `DELETE FROM microservices.projection_checkpoints WHERE projection = $1`
+
+	deleteProjectionPositionQuery = `DELETE FROM microservices.projections WHERE name = $1`
+
+	deleteStreamCheckpointQuery = `DELETE FROM microservices.projection_checkpoints WHERE projection = $1 AND aggregate_id = $2`
+
+	copyStreamCheckpointsQuery = `INSERT INTO microservices.projection_checkpoints (projection, aggregate_id, version, position, updated_at)
+	SELECT $2, aggregate_id, version, position, now() FROM microservices.projection_checkpoints WHERE projection = $1`
+
+	copyProjectionPositionQuery = `INSERT INTO microservices.projections (name, position, updated_at)
+	SELECT $2, position, now() FROM microservices.projections WHERE name = $1`
+
+	replayJobColumns = `id, projection, status, recreate, target_index, filter, cursor, position, head_position, total, processed, skipped, failed, errors, error, created_at, updated_at, started_at, finished_at, owner, heartbeat_at`
+
+	createReplayJobQuery = `INSERT INTO microservices.replay_jobs (` + replayJobColumns + `)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)`
+
+	updateReplayJobQuery = `UPDATE microservices.replay_jobs SET status = $2, position = $3, head_position = $4, total = $5, processed = $6,
+	skipped = $7, failed = $8, errors = $9, error = $10, updated_at = $11, started_at = $12, finished_at = $13, target_index = $14, cursor = $15, owner = $16, heartbeat_at = GREATEST(heartbeat_at, $17) WHERE id = $1`
+
+	getReplayJobQuery = `SELECT ` + replayJobColumns + ` FROM microservices.replay_jobs WHERE id = $1`
+
+	listReplayJobsQuery = `SELECT ` + replayJobColumns + ` FROM microservices.replay_jobs ORDER BY created_at DESC LIMIT $1`
+
+	claimPendingReplayJobQuery = `UPDATE microservices.replay_jobs SET status = 'running', owner = $1, heartbeat_at = $2, started_at = now(), updated_at = now()
+	WHERE id = (SELECT id FROM microservices.replay_jobs WHERE status = 'pending' ORDER BY created_at ASC LIMIT 1 FOR UPDATE SKIP LOCKED)
+	RETURNING ` + replayJobColumns
+
+	heartbeatReplayJobsQuery = `UPDATE microservices.replay_jobs SET heartbeat_at = $3 WHERE status = 'running' AND owner = $1 AND id = ANY($2)`
+
+	markReplayJobsInterruptedQuery = `UPDATE microservices.replay_jobs SET status = 'interrupted', updated_at = now()
+	WHERE status = 'running' AND (heartbeat_at IS NULL OR heartbeat_at < $1)`
+
+	getEventSchemasQuery = `SELECT event_type, revision, aggregate_type, description, schema, checksum, registered_at
+	FROM microservices.event_schemas ORDER BY event_type ASC, revision ASC`
+
+	saveEventSchemaQuery = `INSERT INTO microservices.event_schemas (event_type, revision, aggregate_type, description, schema, checksum, registered_at)
+	VALUES ($1, $2, $3, $4, $5, $6, now())
+	ON CONFLICT (event_type, revision) DO NOTHING`
 )
@@ -0,0 +1,24 @@
+package es
+
+import "time"
+
+// StreamFilter selects aggregate streams for selective replay. Streams are matched by id, aggregate type
+// and by having at least one event inside the position and time window, empty fields match everything.
+type StreamFilter struct {
+	AggregateIDs  []string      `json:"aggregateIds,omitempty"`
+	AggregateType AggregateType `json:"aggregateType,omitempty"`
+	FromPosition  uint64        `json:"fromPosition,omitempty"`
+	ToPosition    uint64        `json:"toPosition,omitempty"`
+	From          *time.Time    `json:"from,omitempty"`
+	To            *time.Time    `json:"to,omitempty"`
+}
+
+// IsEmpty filter matches every stream.
+func (f *StreamFilter) IsEmpty() bool {
+	return len(f.AggregateIDs) == 0 &&
+		f.AggregateType == "" &&
+		f.FromPosition == 0 &&
+		f.ToPosition == 0 &&
+		f.From == nil &&
+		f.To == nil
+}
@@ -0,0 +1,159 @@
+package health
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrDegraded returned (wrapped) by a check reports a dependency that works but is impaired,
+// e.g. a projection lagging behind, without failing readiness.
+var ErrDegraded = errors.New("degraded")
+
+// ErrFeatureDisabled returned (wrapped) by CheckFeature when a check of the feature is not up.
+var ErrFeatureDisabled = errors.New("feature disabled")
+
+// Status of a single dependency or of the whole service.
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// CheckFunc probes a dependency, returning an error when it is unavailable.
+type CheckFunc func(ctx context.Context) error
+
+// Check describes a dependency probe. A failing critical check marks the service down
+// (not ready), a failing non-critical check only degrades it and disables the listed features.
+type Check struct {
+	Name     string
+	Critical bool
+	Timeout  time.Duration
+	Features []string
+	Check    CheckFunc
+}
+
+// CheckResult result of a single check run.
+type CheckResult struct {
+	Name     string        `json:"name"`
+	Status   Status        `json:"status"`
+	Critical bool          `json:"critical"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report aggregated result of all registered checks.
+type Report struct {
+	Status           Status        `json:"status"`
+	Checks           []CheckResult `json:"checks"`
+	DisabledFeatures []string      `json:"disabledFeatures,omitempty"`
+	Timestamp        time.Time     `json:"timestamp"`
+}
+
+// Registry holds registered dependency checks and runs them concurrently.
+type Registry struct {
+	defaultTimeout time.Duration
+	checks         []Check
+}
+
+// NewRegistry Registry constructor, defaultTimeout is applied to checks registered without timeout.
+func NewRegistry(defaultTimeout time.Duration) *Registry {
+	return &Registry{defaultTimeout: defaultTimeout}
+}
+
+// Register add dependency check to the registry.
+func (r *Registry) Register(check Check) {
+	if check.Timeout <= 0 {
+		check.Timeout = r.defaultTimeout
+	}
+	r.checks = append(r.checks, check)
+}
+
+// Run executes all checks concurrently, each one bounded by its own timeout.
+func (r *Registry) Run(ctx context.Context) Report {
+	results := make([]CheckResult, len(r.checks))
+
+	wg := &sync.WaitGroup{}
+	for i, check := range r.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    StatusUp,
+		Checks:    results,
+		Timestamp: time.Now().UTC(),
+	}
+
+	for i, result := range results {
+		switch {
+		case result.Status == StatusUp:
+			continue
+		case result.Critical && result.Status == StatusDown:
+			report.Status = StatusDown
+		case report.Status != StatusDown:
+			report.Status = StatusDegraded
+		}
+		report.DisabledFeatures = append(report.DisabledFeatures, r.checks[i].Features...)
+	}
+
+	return report
+}
+
+// CheckFeature runs only the checks listing the feature, the feature is disabled when one of them is not up,
+// the same way Run reports it in DisabledFeatures.
+func (r *Registry) CheckFeature(ctx context.Context, feature string) error {
+	for _, check := range r.checks {
+		if !slices.Contains(check.Features, feature) {
+			continue
+		}
+		if result := runCheck(ctx, check); result.Status != StatusUp {
+			return errors.Wrapf(ErrFeatureDisabled, "feature: %s, check: %s: %s", feature, result.Name, result.Error)
+		}
+	}
+	return nil
+}
+
+func runCheck(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{
+		Name:     check.Name,
+		Status:   StatusUp,
+		Critical: check.Critical,
+		Duration: time.Since(start),
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		result.Status = StatusDown
+		if !check.Critical || errors.Is(err, ErrDegraded) {
+			result.Status = StatusDegraded
+		}
+	}
+
+	return result
+}
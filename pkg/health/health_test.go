@@ -0,0 +1,53 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryCheckFeature(t *testing.T) {
+	failing := func(ctx context.Context) error { return errors.New("connection refused") }
+	passing := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		name     string
+		checks   []Check
+		expected error
+	}{
+		{name: "no checks of the feature", checks: []Check{{Name: "postgres", Critical: true, Check: failing}}},
+		{name: "checks of the feature pass", checks: []Check{{Name: "elasticsearch", Features: []string{"replay"}, Check: passing}}},
+		{
+			name:     "failing check disables the feature",
+			checks:   []Check{{Name: "elasticsearch", Features: []string{"search", "replay"}, Check: failing}},
+			expected: ErrFeatureDisabled,
+		},
+		{
+			name:     "degraded check disables the feature",
+			checks:   []Check{{Name: "subscription", Features: []string{"replay"}, Check: func(ctx context.Context) error { return errors.Wrap(ErrDegraded, "lag") }}},
+			expected: ErrFeatureDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(time.Second)
+			for _, check := range tt.checks {
+				registry.Register(check)
+			}
+
+			err := registry.CheckFeature(context.Background(), "replay")
+			if tt.expected != nil {
+				assert.ErrorIs(t, err, tt.expected)
+				return
+			}
+			assert.NoError(t, err)
+
+			report := registry.Run(context.Background())
+			assert.NotContains(t, report.DisabledFeatures, "replay")
+		})
+	}
+}
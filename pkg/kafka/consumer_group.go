@@ -3,6 +3,7 @@ package kafka
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 	"github.com/segmentio/kafka-go/compress"
@@ -27,6 +28,18 @@ type ConsumerGroup interface {
 	ConsumeTopicWithErrGroup(ctx context.Context, groupTopics []string, poolSize int) error
 	GetNewKafkaReader(kafkaURL []string, groupTopics []string, groupID string) *kafka.Reader
 	GetNewKafkaWriter() *kafka.Writer
+	Status() ConsumerGroupStatus
+}
+
+// ConsumerGroupStatus snapshot of the consumer group reader state used by health checks.
+type ConsumerGroupStatus struct {
+	GroupID   string    `json:"groupId"`
+	Topics    []string  `json:"topics"`
+	Running   bool      `json:"running"`
+	StartedAt time.Time `json:"startedAt,omitempty"`
+	Lag       int64     `json:"lag"`
+	Errors    int64     `json:"errors"`
+	LastError string    `json:"lastError,omitempty"`
 }
 
 type consumerGroup struct {
@@ -34,11 +47,59 @@ type consumerGroup struct {
 	GroupID string
 	Worker  WorkerErrGroup
 	log     *zap.Logger
+
+	mu     sync.Mutex
+	reader *kafka.Reader
+	status ConsumerGroupStatus
 }
 
 // NewConsumerGroup kafka consumer group constructor
 func NewConsumerGroup(brokers []string, groupID string, worker WorkerErrGroup, log *zap.Logger) *consumerGroup {
-	return &consumerGroup{Brokers: brokers, GroupID: groupID, Worker: worker, log: log}
+	return &consumerGroup{
+		Brokers: brokers,
+		GroupID: groupID,
+		Worker:  worker,
+		log:     log,
+		status:  ConsumerGroupStatus{GroupID: groupID},
+	}
+}
+
+// Status returns current consumer group status with reader lag and error counters.
+func (c *consumerGroup) Status() ConsumerGroupStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.reader != nil {
+		stats := c.reader.Stats()
+		c.status.Lag = stats.Lag
+		c.status.Errors += stats.Errors
+	}
+
+	status := c.status
+	status.Topics = append([]string(nil), c.status.Topics...)
+	return status
+}
+
+func (c *consumerGroup) setRunning(r *kafka.Reader, groupTopics []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reader = r
+	c.status.Running = true
+	c.status.Topics = groupTopics
+	c.status.StartedAt = time.Now().UTC()
+	c.status.LastError = ""
+}
+
+func (c *consumerGroup) setStopped(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reader = nil
+	c.status.Running = false
+	if err != nil {
+		c.status.LastError = err.Error()
+	}
 }
 
 // GetNewKafkaReader create new kafka reader
@@ -104,11 +165,15 @@ func (c *consumerGroup) ConsumeTopicWithErrGroup(ctx context.Context, groupTopic
 
 	c.log.Info("(Starting ConsumeTopicWithErrGroup) GroupID: %s, topics: %+v, poolSize: %d", zap.String("GroupID", c.GroupID), zap.Any("topics", groupTopics), zap.Int("poolSize", poolSize))
 
+	c.setRunning(r, groupTopics)
+
 	g, ctx := errgroup.WithContext(ctx)
 	for i := 0; i <= poolSize; i++ {
 		g.Go(c.runWorker(ctx, c.Worker, r, i))
 	}
-	return g.Wait()
+	err := g.Wait()
+	c.setStopped(err)
+	return err
 }
 
 func (c *consumerGroup) runWorker(ctx context.Context, worker WorkerErrGroup, r *kafka.Reader, i int) func() error {
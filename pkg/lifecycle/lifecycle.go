@@ -0,0 +1,142 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	ShutdownTimeout time.Duration
+}
+
+// RunFunc runs a component until ctx is cancelled or the component fails.
+type RunFunc func(ctx context.Context) error
+
+// StopFunc gracefully stops a component or releases a resource, bounded by ctx deadline.
+type StopFunc func(ctx context.Context) error
+
+type component struct {
+	name   string
+	run    RunFunc
+	stop   StopFunc
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type closer struct {
+	name  string
+	close StopFunc
+}
+
+// Manager starts long running components (HTTP server, consumer groups, background workers)
+// and on shutdown stops them in reverse registration order, then releases resources
+// (producers, connection pools, clients), all within a single shutdown deadline.
+type Manager struct {
+	cfg        Config
+	logger     *zap.Logger
+	mu         sync.Mutex
+	components []*component
+	closers    []closer
+}
+
+// NewManager Manager constructor.
+func NewManager(cfg Config, logger *zap.Logger) *Manager {
+	return &Manager{cfg: cfg, logger: logger}
+}
+
+// Add register long running component. Run receives its own context which is cancelled
+// after stop (if any) returns, so components without explicit stop just watch ctx.Done().
+func (m *Manager) Add(name string, run RunFunc, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, &component{name: name, run: run, stop: stop})
+}
+
+// AddCloser register resource closed after all components are stopped, in reverse order.
+func (m *Manager) AddCloser(name string, close StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closers = append(m.closers, closer{name: name, close: close})
+}
+
+// Run starts all components and blocks until ctx is done or any component fails,
+// then performs graceful shutdown. Returns the first component failure if any.
+func (m *Manager) Run(ctx context.Context) error {
+	m.mu.Lock()
+	components := append([]*component(nil), m.components...)
+	closers := append([]closer(nil), m.closers...)
+	m.mu.Unlock()
+
+	errCh := make(chan error, len(components))
+	for _, c := range components {
+		runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c.cancel = cancel
+		c.done = make(chan struct{})
+
+		go func(c *component) {
+			defer close(c.done)
+			m.logger.Info("Starting component", zap.String("component", c.name))
+			if err := c.run(runCtx); err != nil && !errors.Is(err, context.Canceled) {
+				m.logger.Error("Component failed", zap.String("component", c.name), zap.Error(err))
+				errCh <- errors.Wrapf(err, "component: %s", c.name)
+				return
+			}
+			m.logger.Info("Component stopped", zap.String("component", c.name))
+		}(c)
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		m.logger.Info("Shutdown signal received")
+	case runErr = <-errCh:
+		m.logger.Error("Shutting down after component failure", zap.Error(runErr))
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), m.cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := m.shutdown(shutdownCtx, components, closers); err != nil && runErr == nil {
+		runErr = err
+	}
+	return runErr
+}
+
+func (m *Manager) shutdown(ctx context.Context, components []*component, closers []closer) error {
+	var shutdownErr error
+
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		m.logger.Info("Stopping component", zap.String("component", c.name))
+
+		if c.stop != nil {
+			if err := c.stop(ctx); err != nil {
+				m.logger.Error("Failed to stop component", zap.String("component", c.name), zap.Error(err))
+				shutdownErr = errors.Wrapf(err, "stop component: %s", c.name)
+			}
+		}
+		c.cancel()
+
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			m.logger.Error("Component did not stop before shutdown deadline", zap.String("component", c.name))
+			shutdownErr = errors.Wrapf(ctx.Err(), "stop component: %s", c.name)
+		}
+	}
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i].close(ctx); err != nil {
+			m.logger.Error("Failed to close resource", zap.String("resource", closers[i].name), zap.Error(err))
+			shutdownErr = errors.Wrapf(err, "close resource: %s", closers[i].name)
+			continue
+		}
+		m.logger.Info("Resource closed", zap.String("resource", closers[i].name))
+	}
+
+	return shutdownErr
+}
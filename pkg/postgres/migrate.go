@@ -12,6 +12,39 @@ import (
 //go:embed migrations/001_event_store.sql
 var eventStoreMigration string
 
+//go:embed migrations/002_projections.sql
+var projectionsMigration string
+
+//go:embed migrations/003_replay_jobs.sql
+var replayJobsMigration string
+
+//go:embed migrations/004_replay_job_target_index.sql
+var replayJobTargetIndexMigration string
+
+//go:embed migrations/005_replay_job_filter.sql
+var replayJobFilterMigration string
+
+//go:embed migrations/006_consistency_checks.sql
+var consistencyChecksMigration string
+
+//go:embed migrations/007_standing_orders.sql
+var standingOrdersMigration string
+
+//go:embed migrations/008_event_schemas.sql
+var eventSchemasMigration string
+
+//go:embed migrations/009_replay_job_owner.sql
+var replayJobOwnerMigration string
+
+//go:embed migrations/010_consistency_check_owner.sql
+var consistencyCheckOwnerMigration string
+
+//go:embed migrations/011_payroll_run_leases.sql
+var payrollRunLeasesMigration string
+
+//go:embed migrations/012_account_emails.sql
+var accountEmailsMigration string
+
 // RunMigrations executes SQL migration files for event store and demo accounts
 func RunMigrations(ctx context.Context, pool *pgxpool.Pool, logger *zap.Logger) error {
 	logger.Info("Starting database migrations...")
@@ -24,6 +57,83 @@ func RunMigrations(ctx context.Context, pool *pgxpool.Pool, logger *zap.Logger)
 	}
 	logger.Info("Event store migration completed")
 
+	_, err = pool.Exec(ctx, projectionsMigration)
+	if err != nil {
+		logger.Error("Failed to execute projections migration", zap.Error(err))
+		return fmt.Errorf("failed to execute projections migration: %w", err)
+	}
+	logger.Info("Projections migration completed")
+
+	_, err = pool.Exec(ctx, replayJobsMigration)
+	if err != nil {
+		logger.Error("Failed to execute replay jobs migration", zap.Error(err))
+		return fmt.Errorf("failed to execute replay jobs migration: %w", err)
+	}
+	logger.Info("Replay jobs migration completed")
+
+	_, err = pool.Exec(ctx, replayJobTargetIndexMigration)
+	if err != nil {
+		logger.Error("Failed to execute replay job target index migration", zap.Error(err))
+		return fmt.Errorf("failed to execute replay job target index migration: %w", err)
+	}
+	logger.Info("Replay job target index migration completed")
+
+	_, err = pool.Exec(ctx, replayJobFilterMigration)
+	if err != nil {
+		logger.Error("Failed to execute replay job filter migration", zap.Error(err))
+		return fmt.Errorf("failed to execute replay job filter migration: %w", err)
+	}
+	logger.Info("Replay job filter migration completed")
+
+	_, err = pool.Exec(ctx, consistencyChecksMigration)
+	if err != nil {
+		logger.Error("Failed to execute consistency checks migration", zap.Error(err))
+		return fmt.Errorf("failed to execute consistency checks migration: %w", err)
+	}
+	logger.Info("Consistency checks migration completed")
+
+	_, err = pool.Exec(ctx, standingOrdersMigration)
+	if err != nil {
+		logger.Error("Failed to execute standing orders migration", zap.Error(err))
+		return fmt.Errorf("failed to execute standing orders migration: %w", err)
+	}
+	logger.Info("Standing orders migration completed")
+
+	_, err = pool.Exec(ctx, eventSchemasMigration)
+	if err != nil {
+		logger.Error("Failed to execute event schemas migration", zap.Error(err))
+		return fmt.Errorf("failed to execute event schemas migration: %w", err)
+	}
+	logger.Info("Event schemas migration completed")
+
+	_, err = pool.Exec(ctx, replayJobOwnerMigration)
+	if err != nil {
+		logger.Error("Failed to execute replay job owner migration", zap.Error(err))
+		return fmt.Errorf("failed to execute replay job owner migration: %w", err)
+	}
+	logger.Info("Replay job owner migration completed")
+
+	_, err = pool.Exec(ctx, consistencyCheckOwnerMigration)
+	if err != nil {
+		logger.Error("Failed to execute consistency check owner migration", zap.Error(err))
+		return fmt.Errorf("failed to execute consistency check owner migration: %w", err)
+	}
+	logger.Info("Consistency check owner migration completed")
+
+	_, err = pool.Exec(ctx, payrollRunLeasesMigration)
+	if err != nil {
+		logger.Error("Failed to execute payroll run leases migration", zap.Error(err))
+		return fmt.Errorf("failed to execute payroll run leases migration: %w", err)
+	}
+	logger.Info("Payroll run leases migration completed")
+
+	_, err = pool.Exec(ctx, accountEmailsMigration)
+	if err != nil {
+		logger.Error("Failed to execute account emails migration", zap.Error(err))
+		return fmt.Errorf("failed to execute account emails migration: %w", err)
+	}
+	logger.Info("Account emails migration completed")
+
 	logger.Info("Database migrations completed successfully")
 	return nil
 }
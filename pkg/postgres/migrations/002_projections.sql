@@ -0,0 +1,21 @@
+-- Migration script for projection checkpoints
+-- This script is idempotent and can be run multiple times safely
+
+-- Last applied version of each aggregate stream per projection
+CREATE TABLE IF NOT EXISTS microservices.projection_checkpoints (
+    projection VARCHAR(255) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    version BIGINT NOT NULL,
+    position BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (projection, aggregate_id)
+);
+
+-- Highest global event position applied per projection
+CREATE TABLE IF NOT EXISTS microservices.projections (
+    name VARCHAR(255) PRIMARY KEY,
+    position BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA microservices TO postgres;
@@ -0,0 +1,26 @@
+-- Migration script for replay jobs
+-- This script is idempotent and can be run multiple times safely
+
+CREATE TABLE IF NOT EXISTS microservices.replay_jobs (
+    id UUID PRIMARY KEY,
+    projection VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    recreate BOOLEAN NOT NULL DEFAULT FALSE,
+    position BIGINT NOT NULL DEFAULT 0,
+    head_position BIGINT NOT NULL DEFAULT 0,
+    total BIGINT NOT NULL DEFAULT 0,
+    processed BIGINT NOT NULL DEFAULT 0,
+    skipped BIGINT NOT NULL DEFAULT 0,
+    failed BIGINT NOT NULL DEFAULT 0,
+    errors TEXT[],
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_replay_jobs_status ON microservices.replay_jobs(status);
+CREATE INDEX IF NOT EXISTS idx_replay_jobs_created_at ON microservices.replay_jobs(created_at);
+
+GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA microservices TO postgres;
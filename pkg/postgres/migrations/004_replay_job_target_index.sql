@@ -0,0 +1,4 @@
+-- Migration script for zero-downtime reindexing
+-- This script is idempotent and can be run multiple times safely
+
+ALTER TABLE microservices.replay_jobs ADD COLUMN IF NOT EXISTS target_index VARCHAR(255) NOT NULL DEFAULT '';
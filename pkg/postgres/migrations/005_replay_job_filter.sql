@@ -0,0 +1,5 @@
+-- Migration script for selective replay jobs
+-- This script is idempotent and can be run multiple times safely
+
+ALTER TABLE microservices.replay_jobs ADD COLUMN IF NOT EXISTS filter JSONB;
+ALTER TABLE microservices.replay_jobs ADD COLUMN IF NOT EXISTS cursor VARCHAR(255) NOT NULL DEFAULT '';
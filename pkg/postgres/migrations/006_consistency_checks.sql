@@ -0,0 +1,21 @@
+-- Migration script for projection consistency checks
+-- This script is idempotent and can be run multiple times safely
+
+CREATE TABLE IF NOT EXISTS microservices.consistency_checks (
+    id UUID PRIMARY KEY,
+    status VARCHAR(32) NOT NULL,
+    repair BOOLEAN NOT NULL DEFAULT FALSE,
+    aggregate_ids TEXT[],
+    checked BIGINT NOT NULL DEFAULT 0,
+    drifted BIGINT NOT NULL DEFAULT 0,
+    repaired BIGINT NOT NULL DEFAULT 0,
+    drifts JSONB NOT NULL DEFAULT '[]',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_consistency_checks_created_at ON microservices.consistency_checks(created_at);
+
+GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA microservices TO postgres;
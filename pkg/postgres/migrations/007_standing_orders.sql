@@ -0,0 +1,14 @@
+-- Migration script for the standing orders schedule
+-- This script is idempotent and can be run multiple times safely
+
+-- Due times of active standing orders, rows are claimed with FOR UPDATE SKIP LOCKED
+-- so every due occurrence is executed by a single instance
+CREATE TABLE IF NOT EXISTS microservices.standing_order_schedule (
+    aggregate_id UUID PRIMARY KEY,
+    due_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_standing_order_schedule_due_at ON microservices.standing_order_schedule(due_at);
+
+GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA microservices TO postgres;
@@ -0,0 +1,15 @@
+-- Migration script for the event schema registry
+-- This script is idempotent and can be run multiple times safely
+
+CREATE TABLE IF NOT EXISTS microservices.event_schemas (
+    event_type VARCHAR(250) NOT NULL,
+    revision INT NOT NULL,
+    aggregate_type VARCHAR(250) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    schema JSONB NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    registered_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (event_type, revision)
+);
+
+GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA microservices TO postgres;
@@ -0,0 +1,7 @@
+-- Migration script for replay job ownership
+-- This script is idempotent and can be run multiple times safely
+
+-- Running jobs record the instance executing them and its last heartbeat,
+-- other instances interrupt a running job only after its heartbeat expired
+ALTER TABLE microservices.replay_jobs ADD COLUMN IF NOT EXISTS owner VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE microservices.replay_jobs ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMP;
@@ -0,0 +1,7 @@
+-- Migration script for consistency check ownership
+-- This script is idempotent and can be run multiple times safely
+
+-- Running checks record the instance executing them and its last heartbeat,
+-- other instances interrupt a running check only after its heartbeat expired
+ALTER TABLE microservices.consistency_checks ADD COLUMN IF NOT EXISTS owner VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE microservices.consistency_checks ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMP;
@@ -0,0 +1,12 @@
+-- Migration script for payroll run leases
+-- This script is idempotent and can be run multiple times safely
+
+-- A payroll run is executed by the instance holding its lease, the lease is renewed while
+-- lines are deposited and is taken over by another instance only after it expired
+CREATE TABLE IF NOT EXISTS microservices.payroll_run_leases (
+    run_id VARCHAR(255) PRIMARY KEY,
+    owner VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA microservices TO postgres;
@@ -0,0 +1,25 @@
+-- Migration script for account email reservations
+-- This script is idempotent and can be run multiple times safely
+
+-- Login emails reserved by accounts, the primary key keeps an email with a single account.
+-- Emails are reserved before the account event is saved and released when the account changes its email
+CREATE TABLE IF NOT EXISTS microservices.account_emails (
+    email VARCHAR(255) PRIMARY KEY,
+    aggregate_id UUID NOT NULL,
+    reserved_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_emails_aggregate_id ON microservices.account_emails(aggregate_id);
+
+-- Reserve current emails of accounts created before reservations, only while the table is empty
+INSERT INTO microservices.account_emails (email, aggregate_id)
+SELECT email, aggregate_id FROM (
+    SELECT DISTINCT ON (aggregate_id) data->>'email' AS email, aggregate_id
+    FROM microservices.events
+    WHERE event_type IN ('BANK_ACCOUNT_CREATED_V1', 'EMAIL_CHANGED_V1')
+    ORDER BY aggregate_id, version DESC
+) current_emails
+WHERE email IS NOT NULL AND NOT EXISTS (SELECT 1 FROM microservices.account_emails)
+ON CONFLICT (email) DO NOTHING;
+
+GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA microservices TO postgres;
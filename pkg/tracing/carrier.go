@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+	"github.com/th1enq/es-demo/pkg/es/serializer"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// kafkaHeadersCarrier adapts kafka message headers to propagation.TextMapCarrier.
+type kafkaHeadersCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (c kafkaHeadersCarrier) Get(key string) string {
+	for _, h := range *c.headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c kafkaHeadersCarrier) Set(key string, value string) {
+	for i, h := range *c.headers {
+		if h.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c kafkaHeadersCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.headers))
+	for _, h := range *c.headers {
+		keys = append(keys, h.Key)
+	}
+	return keys
+}
+
+// InjectKafkaHeaders appends the trace context of ctx to the given kafka headers.
+func InjectKafkaHeaders(ctx context.Context, headers []kafka.Header) []kafka.Header {
+	otel.GetTextMapPropagator().Inject(ctx, kafkaHeadersCarrier{headers: &headers})
+	return headers
+}
+
+// ExtractKafkaHeaders returns ctx enriched with the trace context found in kafka headers.
+func ExtractKafkaHeaders(ctx context.Context, headers []kafka.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, kafkaHeadersCarrier{headers: &headers})
+}
+
+// InjectMetadata merges the trace context of ctx into json event metadata.
+func InjectMetadata(ctx context.Context, metadata []byte) ([]byte, error) {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return metadata, nil
+	}
+
+	values := make(map[string]any)
+	if len(metadata) > 0 {
+		if err := serializer.Unmarshal(metadata, &values); err != nil {
+			return nil, errors.Wrap(err, "serializer.Unmarshal")
+		}
+	}
+	for key, value := range carrier {
+		values[key] = value
+	}
+
+	return serializer.Marshal(values)
+}
+
+// ExtractMetadata returns ctx enriched with the trace context stored in json event metadata.
+func ExtractMetadata(ctx context.Context, metadata []byte) context.Context {
+	if len(metadata) == 0 {
+		return ctx
+	}
+
+	values := make(map[string]any)
+	if err := serializer.Unmarshal(metadata, &values); err != nil {
+		return ctx
+	}
+
+	carrier := propagation.MapCarrier{}
+	for key, value := range values {
+		if s, ok := value.(string); ok {
+			carrier[key] = s
+		}
+	}
+
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
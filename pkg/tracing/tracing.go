@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Values accepted for TRACING_EXPORTER.
+const (
+	// ExporterOTLP sends spans over gRPC to the collector at TRACING_OTLP_ENDPOINT.
+	ExporterOTLP = "otlp"
+	// ExporterStdout pretty prints spans to stdout, intended for local debugging.
+	ExporterStdout = "stdout"
+	// ExporterNone records no spans, only the propagator is installed. It is the default.
+	ExporterNone = "none"
+
+	tracerName = "github.com/th1enq/es-demo"
+)
+
+type Config struct {
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	ServiceName string
+	SampleRatio float64
+}
+
+// ShutdownFunc flushes and stops the tracer provider.
+type ShutdownFunc func(ctx context.Context) error
+
+// NewTracerProvider configures the global tracer provider and text map propagator
+// according to the exporter set in config. With ExporterNone (or an empty exporter)
+// only the propagator is installed, so trace context is still forwarded between services.
+func NewTracerProvider(ctx context.Context, cfg Config) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	switch strings.ToLower(cfg.Exporter) {
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		otlpExporter, err := otlptracegrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, errors.Wrap(err, "otlptracegrpc.New")
+		}
+		exporter = otlpExporter
+	case ExporterStdout:
+		stdoutExporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, errors.Wrap(err, "stdouttrace.New")
+		}
+		exporter = stdoutExporter
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	default:
+		return nil, errors.Errorf("unknown tracing exporter: %s", cfg.Exporter)
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName)),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "resource.Merge")
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a span with the application tracer.
+func StartSpan(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, spanName, opts...)
+}
+
+// TraceErr records err on the span and marks it as failed, returning err unchanged.
+func TraceErr(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}